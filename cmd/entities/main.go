@@ -1,12 +1,11 @@
 package main
 
 import (
-    "net/http"
+    "os"
 
     "github.com/apkatsikas/artist-entities"
 )
 
 func main() {
-    router := entities.ServiceContainer().Setup()
-    http.ListenAndServe(":8080", router)
+    os.Exit(entities.Run(os.Args[1:]))
 }
@@ -1,9 +0,0 @@
-package main
-
-import (
-	"github.com/apkatsikas/artist-entities"
-)
-
-func main() {
-	entities.ServiceContainer().Setup()
-}
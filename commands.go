@@ -0,0 +1,394 @@
+package entities
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/config"
+	"github.com/apkatsikas/artist-entities/infrastructures/logutil"
+	"github.com/apkatsikas/artist-entities/infrastructures/notifier"
+	"github.com/apkatsikas/artist-entities/infrastructures/notifytest"
+	"github.com/apkatsikas/artist-entities/infrastructures/server"
+	"github.com/apkatsikas/artist-entities/migrate"
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/apkatsikas/artist-entities/router"
+)
+
+// How long running jobs get to finish once a command is done
+const stopTimeout = 5 * time.Minute
+
+// command is run by name, like "user add"
+type command struct {
+	name    string
+	summary string
+	// setup adds the command's own flags, then returns what runs it
+	setup func(flags *flag.FlagSet) func(ctx context.Context, k *kernel) error
+	// sections are the settings only this command needs validated
+	sections []config.Section
+}
+
+var commands = []command{
+	{"serve", "Serve the API and run scheduled jobs", serveCommand, []config.Section{config.SectionAuth}},
+	{"migrate", "Bring the database schema up to date", migrateCommand, nil},
+	{"import", "Import artists from a CSV file", importCommand, nil},
+	{"user add", "Add a user", userAddCommand, nil},
+	{"user passwd", "Change a user's password, their existing tokens stop working", userPasswdCommand, nil},
+	{"apikey create", "Create an API key", apiKeyCreateCommand, nil},
+	{"apikey list", "List API keys", apiKeyListCommand, nil},
+	{"apikey revoke", "Revoke an API key", apiKeyRevokeCommand, nil},
+	{"backup", "Back up the database now", backupCommand, nil},
+	{"backup list", "List backups in storage", backupListCommand, nil},
+	{"backup verify", "Download a backup and check it against its manifest", backupVerifyCommand, nil},
+	{"restore", "Restore the database from a backup, or to a point in time", restoreCommand, nil},
+	{"notify test", "Send a test notification to local stub servers and print it", notifyTestCommand, nil},
+}
+
+// find returns the command args name, the longest match wins so "backup list" isn't "backup"
+func find(args []string) (command, []string, bool) {
+	var found command
+	var rest []string
+	for _, c := range commands {
+		words := strings.Fields(c.name)
+		if len(words) <= len(args) && strings.Join(args[:len(words)], " ") == c.name &&
+			len(words) > len(strings.Fields(found.name)) {
+			found, rest = c, args[len(words):]
+		}
+	}
+	return found, rest, found.name != ""
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: entities <command> [flags]\n\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-15v%v\n", c.name, c.summary)
+	}
+	fmt.Fprintf(w, "\nRun entities <command> -help for its flags, every command takes the configuration flags\n")
+}
+
+// printFlags prints the flags include picks, like flag.PrintDefaults
+func printFlags(flags *flag.FlagSet, include func(name string) bool) {
+	flags.VisitAll(func(f *flag.Flag) {
+		if !include(f.Name) {
+			return
+		}
+		kind, usage := flag.UnquoteUsage(f)
+		fmt.Printf("  -%v %v\n    \t%v", f.Name, kind, usage)
+		if f.DefValue != "" && f.DefValue != "0" && f.DefValue != "false" && f.DefValue != "0s" {
+			fmt.Printf(" (default %v)", f.DefValue)
+		}
+		fmt.Println()
+	})
+}
+
+// Run runs the command named by args and returns the exit code
+func Run(args []string) int {
+	c, rest, ok := find(args)
+	if !ok {
+		if len(args) > 0 && (args[0] == "help" || args[0] == "-help" || args[0] == "-h") {
+			usage(os.Stdout)
+			return 0
+		}
+		usage(os.Stderr)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("entities "+c.name, flag.ContinueOnError)
+	// Errors and help are printed below
+	flags.SetOutput(io.Discard)
+	run := c.setup(flags)
+	own := map[string]bool{}
+	flags.VisitAll(func(f *flag.Flag) {
+		own[f.Name] = true
+	})
+	cfg, err := config.Load(flags, rest, c.sections...)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Printf("Usage: entities %v [flags]\n\n%v\n", c.name, c.summary)
+		if len(own) > 0 {
+			fmt.Printf("\nFlags:\n")
+			printFlags(flags, func(name string) bool { return own[name] })
+		}
+		fmt.Printf("\nConfiguration flags:\n")
+		printFlags(flags, func(name string) bool { return !own[name] })
+		return 0
+	}
+	if err == nil && flags.NArg() > 0 {
+		err = fmt.Errorf("unexpected arguments %v", flags.Args())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "entities %v:\n%v\nRun entities %v -help for usage\n", c.name, err, c.name)
+		return 2
+	}
+
+	// Setup logs
+	err = logutil.Setup(cfg.Log)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "entities %v: %v\n", c.name, err)
+		return 1
+	}
+	logutil.Info("Running...", "command", c.name)
+
+	// Ctrl-C or SIGTERM cancels a command, or shuts the server down
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	k := newKernel(cfg)
+	err = run(ctx, k)
+	if err != nil {
+		logutil.Error("Command failed", "command", c.name, "error", err)
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	stopErr := k.Stop(stopCtx)
+	if stopErr != nil {
+		logutil.Error("Jobs were still running at shutdown", "error", stopErr)
+	}
+	if err != nil || stopErr != nil {
+		return 1
+	}
+	return 0
+}
+
+func serveCommand(flags *flag.FlagSet) func(ctx context.Context, k *kernel) error {
+	return func(ctx context.Context, k *kernel) error {
+		s, err := server.New(k.config.Server, k.serve(), router.MetricsRouter())
+		if err != nil {
+			return err
+		}
+		// Returns once ctx is done and in-flight requests have finished
+		return s.Run(ctx)
+	}
+}
+
+func migrateCommand(flags *flag.FlagSet) func(ctx context.Context, k *kernel) error {
+	return func(ctx context.Context, k *kernel) error {
+		// Opening the database migrates it
+		k.repos()
+		logutil.Info("Database migrated")
+		return nil
+	}
+}
+
+func importCommand(flags *flag.FlagSet) func(ctx context.Context, k *kernel) error {
+	file := flags.String("file", "artists.csv", "CSV file with the artists to import in its first row")
+	return func(ctx context.Context, k *kernel) error {
+		return migrate.Migrate(ctx, k.repos().artist, k.artistService(), *file)
+	}
+}
+
+// userFlags are the flags the user commands share
+func userFlags(flags *flag.FlagSet) (name *string, password *string) {
+	name = flags.String("name", "", "User name")
+	password = flags.String("password", "", "Password")
+	return name, password
+}
+
+func userAddCommand(flags *flag.FlagSet) func(ctx context.Context, k *kernel) error {
+	name, password := userFlags(flags)
+	return func(ctx context.Context, k *kernel) error {
+		if *name == "" || *password == "" {
+			return errors.New("-name and -password are required")
+		}
+		_, err := k.auth().CreateUser(ctx, *name, *password)
+		if err != nil {
+			return fmt.Errorf("failed to create user %v: %v", *name, err)
+		}
+		logutil.Info("Created user", "user", *name)
+		return nil
+	}
+}
+
+func userPasswdCommand(flags *flag.FlagSet) func(ctx context.Context, k *kernel) error {
+	name, password := userFlags(flags)
+	return func(ctx context.Context, k *kernel) error {
+		if *name == "" || *password == "" {
+			return errors.New("-name and -password are required")
+		}
+		err := k.auth().ChangePassword(ctx, *name, *password)
+		if err != nil {
+			return fmt.Errorf("failed to change password for user %v: %v", *name, err)
+		}
+		logutil.Info("Changed password", "user", *name)
+		return nil
+	}
+}
+
+func apiKeyCreateCommand(flags *flag.FlagSet) func(ctx context.Context, k *kernel) error {
+	name := flags.String("name", "", "Name of the API key")
+	scopes := flags.String("scopes", "artist:write", "Comma separated scopes")
+	expiry := flags.Duration("expiry", 0, "How long the API key lasts, 0 never expires")
+	return func(ctx context.Context, k *kernel) error {
+		if *name == "" {
+			return errors.New("-name is required")
+		}
+		var expiresAt *time.Time
+		if *expiry > 0 {
+			at := time.Now().Add(*expiry)
+			expiresAt = &at
+		}
+		key, apiKey, err := k.apiKeyService().Create(*name, strings.Split(*scopes, ","), expiresAt)
+		if err != nil {
+			return fmt.Errorf("failed to create API key %v: %v", *name, err)
+		}
+		logutil.Info("Created API key", "apiKeyID", apiKey.ID, "prefix", apiKey.Prefix)
+		// Only ever printed, never logged
+		fmt.Printf("API key %v created, it will not be shown again:\n%v\n", apiKey.ID, key)
+		return nil
+	}
+}
+
+func apiKeyListCommand(flags *flag.FlagSet) func(ctx context.Context, k *kernel) error {
+	return func(ctx context.Context, k *kernel) error {
+		apiKeys, err := k.apiKeyService().List()
+		if err != nil {
+			return fmt.Errorf("failed to list API keys: %v", err)
+		}
+		for _, apiKey := range apiKeys {
+			fmt.Printf("%v\t%v\t%v\t%v\texpires=%v\tlastUsed=%v\trevoked=%v\n",
+				apiKey.ID, apiKey.Name, apiKey.Prefix, apiKey.Scopes,
+				apiKey.ExpiresAt, apiKey.LastUsedAt, apiKey.RevokedAt)
+		}
+		return nil
+	}
+}
+
+func apiKeyRevokeCommand(flags *flag.FlagSet) func(ctx context.Context, k *kernel) error {
+	id := flags.Uint("id", 0, "ID of the API key")
+	return func(ctx context.Context, k *kernel) error {
+		if *id == 0 {
+			return errors.New("-id is required")
+		}
+		err := k.apiKeyService().Revoke(*id)
+		if err != nil {
+			return fmt.Errorf("failed to revoke API key %v: %v", *id, err)
+		}
+		logutil.Info("Revoked API key", "apiKeyID", *id)
+		return nil
+	}
+}
+
+func backupCommand(flags *flag.FlagSet) func(ctx context.Context, k *kernel) error {
+	return func(ctx context.Context, k *kernel) error {
+		run, err := k.admin().Backup(ctx, models.BackupTriggerManual)
+		if err != nil {
+			return err
+		}
+		logutil.Info("Backed up", "object", run.Object, "durationMs", run.DurationMs)
+		return nil
+	}
+}
+
+func backupListCommand(flags *flag.FlagSet) func(ctx context.Context, k *kernel) error {
+	return func(ctx context.Context, k *kernel) error {
+		backups, err := k.admin().ListBackups(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list backups: %v", err)
+		}
+		for _, backup := range backups {
+			fmt.Printf("%v\t%v\t%v\t%v\n", backup.Name, backup.Updated.Format(time.RFC3339),
+				backup.Size, backup.Status)
+		}
+		return nil
+	}
+}
+
+func backupVerifyCommand(flags *flag.FlagSet) func(ctx context.Context, k *kernel) error {
+	name := flags.String("name", "", "Name of the backup")
+	return func(ctx context.Context, k *kernel) error {
+		if *name == "" {
+			return errors.New("-name is required")
+		}
+		err := k.admin().VerifyBackup(ctx, *name)
+		if err != nil {
+			return fmt.Errorf("backup %v failed verification: %v", *name, err)
+		}
+		logutil.Info("Backup verified", "backup", *name)
+		return nil
+	}
+}
+
+func restoreCommand(flags *flag.FlagSet) func(ctx context.Context, k *kernel) error {
+	name := flags.String("name", "", "Name of a backup to restore")
+	at := flags.String("time", "", "RFC 3339 time to restore to from the WAL replica, like 2026-10-19T09:30:00Z")
+	return func(ctx context.Context, k *kernel) error {
+		if (*name == "") == (*at == "") {
+			return errors.New("one of -name or -time is required")
+		}
+
+		if *name != "" {
+			safetyCopy, err := k.admin().Restore(ctx, *name)
+			if err != nil {
+				return fmt.Errorf("failed to restore backup %v: %v", *name, err)
+			}
+			logutil.Warn("Restored backup", "backup", *name, "previous", safetyCopy)
+			return nil
+		}
+
+		t, err := time.Parse(time.RFC3339, *at)
+		if err != nil {
+			return fmt.Errorf("-time must be an RFC 3339 time like 2026-10-19T09:30:00Z: %v", err)
+		}
+		safetyCopy, restoredTo, err := k.admin().RestoreToTime(ctx, t)
+		if err != nil {
+			return fmt.Errorf("failed to restore to %v: %v", *at, err)
+		}
+		logutil.Warn("Restored to changes shipped by a time", "restoredTo",
+			restoredTo.UTC().Format(time.RFC3339), "previous", safetyCopy)
+		return nil
+	}
+}
+
+func notifyTestCommand(flags *flag.FlagSet) func(ctx context.Context, k *kernel) error {
+	return func(ctx context.Context, k *kernel) error {
+		n := k.notifications()
+
+		// Send to local stubs rather than the configured servers, in the configured formats
+		webhook, smtp := notifytest.NewWebhook(), notifytest.NewSMTP()
+		defer webhook.Close()
+		defer smtp.Close()
+		test := &notifier.Notifier{Retries: n.Retries, Backoff: n.Backoff}
+		format, from, to := notifier.FormatJSON, "entities@localhost", []string{"ops@localhost"}
+		for _, channel := range n.Channels {
+			switch channel := channel.(type) {
+			case *notifier.Webhook:
+				format = channel.Format
+			case *notifier.Email:
+				from, to = channel.From, channel.To
+			}
+		}
+		test.Channels = []notifier.Channel{
+			&notifier.Webhook{URL: webhook.URL(), Format: format},
+			&notifier.Email{Addr: smtp.Addr(), From: from, To: to},
+		}
+
+		hostname, _ := os.Hostname()
+		err := test.Send(ctx, models.Notification{
+			Event:   models.EventTest,
+			Failure: true,
+			Subject: fmt.Sprintf("Test notification from %v", hostname),
+			Message: "This is what a failed backup notification looks like",
+			Time:    time.Now(),
+			Fields:  map[string]string{"trigger": models.BackupTriggerScheduled},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to send test notification: %v", err)
+		}
+
+		for _, body := range webhook.Received() {
+			payload, _ := json.MarshalIndent(body, "", "  ")
+			fmt.Printf("Webhook received:\n%s\n\n", payload)
+		}
+		for _, mail := range smtp.Received() {
+			fmt.Printf("Email received from %v to %v:\n%v\n", mail.From, strings.Join(mail.To, ", "), mail.Data)
+		}
+		return nil
+	}
+}
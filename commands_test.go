@@ -0,0 +1,32 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+		rest     []string
+	}{
+		{[]string{"serve"}, "serve", []string{}},
+		{[]string{"backup"}, "backup", []string{}},
+		{[]string{"backup", "list", "-config", "x.yaml"}, "backup list", []string{"-config", "x.yaml"}},
+		{[]string{"backup", "-config", "x.yaml"}, "backup", []string{"-config", "x.yaml"}},
+		{[]string{"user", "add", "-name", "bob"}, "user add", []string{"-name", "bob"}},
+		{[]string{"user"}, "", nil},
+		{[]string{"-migrateDB"}, "", nil},
+		{nil, "", nil},
+	}
+
+	for _, tc := range tests {
+		c, rest, ok := find(tc.args)
+
+		assert.Equal(t, tc.expected != "", ok, tc.args)
+		assert.Equal(t, tc.expected, c.name, tc.args)
+		assert.Equal(t, tc.rest, rest, tc.args)
+	}
+}
@@ -0,0 +1,291 @@
+// Package config holds everything that can be configured
+//
+// Each setting has a default, which a YAML file, then an environment
+// variable, then a flag can override. See Load.
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/infrastructures/logutil"
+	"github.com/apkatsikas/artist-entities/infrastructures/notifier"
+	"github.com/apkatsikas/artist-entities/infrastructures/scheduler"
+	"github.com/apkatsikas/artist-entities/infrastructures/server"
+	"github.com/apkatsikas/artist-entities/infrastructures/tracing"
+	"github.com/apkatsikas/artist-entities/infrastructures/walship"
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/apkatsikas/artist-entities/services/rules"
+	"github.com/apkatsikas/artist-entities/storageclient"
+	"github.com/robfig/cron/v3"
+)
+
+type Config struct {
+	Server   server.Config        `yaml:"server"`
+	Database Database             `yaml:"database"`
+	Log      logutil.Config       `yaml:"log"`
+	Auth     Auth                 `yaml:"auth"`
+	OIDC     OIDC                 `yaml:"oidc"`
+	Backup   Backup               `yaml:"backup"`
+	Storage  storageclient.Config `yaml:"storage"`
+	Schedule Schedule             `yaml:"schedule"`
+	Notify   Notify               `yaml:"notify"`
+	WAL      WAL                  `yaml:"wal"`
+	Health   Health               `yaml:"health"`
+	Tracing  tracing.Config       `yaml:"tracing"`
+}
+
+type Database struct {
+	Path string `yaml:"path" env:"DATABASE_PATH" usage:"SQLite database file"`
+}
+
+type Auth struct {
+	// A keyring allows rotation and asymmetric keys, otherwise a single shared secret is used
+	JWTKeyringFile string `yaml:"jwtKeyringFile" env:"JWT_KEYRING_FILE" usage:"JWT keyring file"`
+	JWTSigningKey  string `yaml:"jwtSigningKey" env:"JWT_SIGNING_KEY" usage:"Shared secret for signing JWTs"`
+}
+
+// OIDC login is off unless an issuer is set
+type OIDC struct {
+	IssuerURL    string `yaml:"issuerURL" env:"OIDC_ISSUER_URL" usage:"OpenID Connect issuer"`
+	ClientID     string `yaml:"clientID" env:"OIDC_CLIENT_ID" usage:"OpenID Connect client ID"`
+	ClientSecret string `yaml:"clientSecret" env:"OIDC_CLIENT_SECRET" usage:"OpenID Connect client secret"`
+	RedirectURL  string `yaml:"redirectURL" env:"OIDC_REDIRECT_URL" usage:"OpenID Connect redirect URL"`
+	RoleMap      string `yaml:"roleMap" env:"OIDC_ROLE_MAP" usage:"Comma separated email=role pairs, role is admin or editor"`
+}
+
+type Backup struct {
+	Compression string `yaml:"compression" env:"BACKUP_COMPRESSION" usage:"Backup compression, none, gzip or zstd"`
+	// A key file holds several keys so old backups can be restored after rotation,
+	// otherwise EncryptionKey is a single base64 key
+	EncryptionKeyFile string    `yaml:"encryptionKeyFile" env:"BACKUP_ENCRYPTION_KEY_FILE" usage:"Backup encryption key file"`
+	EncryptionKey     string    `yaml:"encryptionKey" env:"BACKUP_ENCRYPTION_KEY" usage:"Base64 backup encryption key"`
+	EncryptionKeyID   string    `yaml:"encryptionKeyID" env:"BACKUP_ENCRYPTION_KEY_ID" usage:"ID of the backup encryption key"`
+	Keep              Retention `yaml:"keep"`
+}
+
+// Retention converts to rules.Retention
+type Retention struct {
+	Last    int `yaml:"last" env:"BACKUP_KEEP_LAST" usage:"Most recent backups to keep"`
+	Daily   int `yaml:"daily" env:"BACKUP_KEEP_DAILY" usage:"Days to keep a backup from"`
+	Weekly  int `yaml:"weekly" env:"BACKUP_KEEP_WEEKLY" usage:"Weeks to keep a backup from"`
+	Monthly int `yaml:"monthly" env:"BACKUP_KEEP_MONTHLY" usage:"Months to keep a backup from"`
+}
+
+// Schedule holds cron expressions, or "off"
+type Schedule struct {
+	// Timezone is empty for local time
+	Timezone       string `yaml:"timezone" env:"SCHEDULE_TIMEZONE" usage:"Timezone for schedules, local time by default"`
+	Backup         string `yaml:"backup" env:"BACKUP_SCHEDULE" usage:"Backup schedule"`
+	IntegrityCheck string `yaml:"integrityCheck" env:"INTEGRITY_CHECK_SCHEDULE" usage:"Database integrity check schedule"`
+	KeyringReload  string `yaml:"keyringReload" env:"KEYRING_RELOAD_SCHEDULE" usage:"JWT keyring reload schedule"`
+	TokenCleanup   string `yaml:"tokenCleanup" env:"TOKEN_CLEANUP_SCHEDULE" usage:"Expired revoked token cleanup schedule"`
+	WALSync        string `yaml:"walSync" env:"WAL_SYNC_SCHEDULE" usage:"WAL replication schedule"`
+	LogRotate      string `yaml:"logRotate" env:"LOG_ROTATE_SCHEDULE" usage:"Log file rotation schedule, as well as when it's too big"`
+}
+
+// Location is where schedules run, Validate has checked it exists
+func (s Schedule) Location() *time.Location {
+	if s.Timezone == "" {
+		return time.Local
+	}
+	location, _ := time.LoadLocation(s.Timezone)
+	return location
+}
+
+// Notify sends nothing unless a webhook or SMTP server is set
+type Notify struct {
+	OnSuccess     bool     `yaml:"onSuccess" env:"NOTIFY_ON_SUCCESS" usage:"Notify about successful backups too"`
+	Retries       int      `yaml:"retries" env:"NOTIFY_RETRIES" usage:"Times a failed notification is retried"`
+	WebhookURL    string   `yaml:"webhookURL" env:"NOTIFY_WEBHOOK_URL" usage:"Webhook to notify"`
+	WebhookFormat string   `yaml:"webhookFormat" env:"NOTIFY_WEBHOOK_FORMAT" usage:"Webhook payload, json or slack"`
+	SMTPAddr      string   `yaml:"smtpAddr" env:"NOTIFY_SMTP_ADDR" usage:"SMTP server to send notifications through"`
+	SMTPUsername  string   `yaml:"smtpUsername" env:"NOTIFY_SMTP_USERNAME" usage:"SMTP user name"`
+	SMTPPassword  string   `yaml:"smtpPassword" env:"NOTIFY_SMTP_PASSWORD" usage:"SMTP password"`
+	EmailFrom     string   `yaml:"emailFrom" env:"NOTIFY_EMAIL_FROM" usage:"Notification sender"`
+	EmailTo       []string `yaml:"emailTo" env:"NOTIFY_EMAIL_TO" usage:"Comma separated notification recipients"`
+}
+
+type WAL struct {
+	Replication      bool          `yaml:"replication" env:"WAL_REPLICATION" usage:"Continuously replicate the database to backup storage"`
+	SnapshotInterval time.Duration `yaml:"snapshotInterval" env:"WAL_SNAPSHOT_INTERVAL" usage:"How often replication starts from a fresh snapshot"`
+	Retention        time.Duration `yaml:"retention" env:"WAL_RETENTION" usage:"How far back the database can be restored to"`
+}
+
+// Health configures /readyz
+type Health struct {
+	// MaxBackupAge is 0 to not check backups
+	MaxBackupAge time.Duration `yaml:"maxBackupAge" env:"HEALTH_MAX_BACKUP_AGE" usage:"How old the newest backup can be before not being ready, 0 to not check"`
+	CheckTimeout time.Duration `yaml:"checkTimeout" env:"HEALTH_CHECK_TIMEOUT" usage:"Timeout for each readiness check"`
+	CacheFor     time.Duration `yaml:"cacheFor" env:"HEALTH_CACHE_FOR" usage:"How long readiness check results are reused"`
+}
+
+// Default is the configuration with nothing set
+func Default() *Config {
+	return &Config{
+		Server:   server.DefaultConfig,
+		Database: Database{Path: "entities.db"},
+		Log:      logutil.DefaultConfig,
+		Backup: Backup{
+			Compression:     models.CompressionGzip,
+			EncryptionKeyID: "default",
+			Keep:            Retention(rules.DefaultRetention),
+		},
+		Storage: storageclient.DefaultConfig,
+		Schedule: Schedule{
+			// 2am every day
+			Backup: "0 2 * * *",
+			// 3am on Sundays
+			IntegrityCheck: "0 3 * * 0",
+			KeyringReload:  "@every 5m",
+			TokenCleanup:   "@hourly",
+			WALSync:        "@every 10s",
+			// Midnight every day
+			LogRotate: "0 0 * * *",
+		},
+		Notify: Notify{Retries: notifier.DefaultRetries, WebhookFormat: notifier.FormatJSON},
+		WAL:    WAL{SnapshotInterval: walship.DefaultSnapshotInterval, Retention: walship.DefaultRetention},
+		// A daily backup with time to spare
+		Health:  Health{MaxBackupAge: 26 * time.Hour, CheckTimeout: 5 * time.Second, CacheFor: 5 * time.Second},
+		Tracing: tracing.DefaultConfig,
+	}
+}
+
+// Section is settings only the commands using them check
+type Section string
+
+// SectionAuth is signing tokens, only serve does
+const SectionAuth Section = "auth"
+
+// Validate checks the settings make sense together, and those in sections
+// Every problem is returned, each naming the setting and its variable
+func (c *Config) Validate(sections ...Section) error {
+	names := map[string]string{}
+	for _, s := range settings(c) {
+		names[s.path] = s.name()
+	}
+	var errs []error
+	check := func(ok bool, path string, format string, a ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%v %v", names[path], fmt.Sprintf(format, a...)))
+		}
+	}
+
+	check(c.Server.Addr != "", "server.addr", "must be set")
+	check(c.Server.MetricsAddr != c.Server.Addr, "server.metricsAddr", "must differ from server.addr")
+	for _, timeout := range []struct {
+		path    string
+		timeout time.Duration
+	}{
+		{"server.readHeaderTimeout", c.Server.ReadHeaderTimeout},
+		{"server.readTimeout", c.Server.ReadTimeout},
+		{"server.writeTimeout", c.Server.WriteTimeout},
+		{"server.idleTimeout", c.Server.IdleTimeout},
+		{"server.shutdownTimeout", c.Server.ShutdownTimeout},
+	} {
+		check(timeout.timeout > 0, timeout.path, "must be positive, got %v", timeout.timeout)
+	}
+	tls := c.Server.TLS
+	check(tls.CertFile != "" || !tls.Enabled(), "server.tls.certFile", "must be set with a key file")
+	check(tls.KeyFile != "" || !tls.Enabled(), "server.tls.keyFile", "must be set with a certificate file")
+	check(tls.RedirectAddr == "" || tls.Enabled(), "server.tls.redirectAddr", "can only be set with a certificate")
+	check(tls.ReloadInterval > 0, "server.tls.reloadInterval", "must be positive, got %v", tls.ReloadInterval)
+	check(tls.HSTSMaxAge >= 0, "server.tls.hstsMaxAge", "must not be negative, got %v", tls.HSTSMaxAge)
+	check(c.Database.Path != "", "database.path", "must be set")
+	check(c.Log.File != "" || c.Log.StdoutOnly, "log.file", "must be set unless only logging to stdout")
+	check(c.Log.MaxSize >= logutil.MinMaxSize, "log.maxSize", "must be at least %v bytes, got %v",
+		logutil.MinMaxSize, c.Log.MaxSize)
+	check(c.Log.Keep >= 0, "log.keep", "must not be negative, got %v", c.Log.Keep)
+	check(oneOf(c.Log.Format, logutil.FormatText, logutil.FormatJSON), "log.format", "must be %v or %v, got %q",
+		logutil.FormatText, logutil.FormatJSON, c.Log.Format)
+	_, err := logutil.ParseLevel(c.Log.Level)
+	check(err == nil, "log.level", "must be debug, info, warn or error, got %q", c.Log.Level)
+	if slices.Contains(sections, SectionAuth) {
+		check(c.Auth.JWTKeyringFile != "" || c.Auth.JWTSigningKey != "", "auth.jwtSigningKey",
+			"must be set when there's no keyring file")
+	}
+	check(c.OIDC.IssuerURL == "" || c.OIDC.ClientID != "", "oidc.clientID", "must be set with an issuer")
+	check(c.OIDC.IssuerURL == "" || c.OIDC.RedirectURL != "", "oidc.redirectURL", "must be set with an issuer")
+
+	check(oneOf(c.Backup.Compression, models.CompressionNone, models.CompressionGzip, models.CompressionZstd),
+		"backup.compression", "must be %v, %v or %v, got %q",
+		models.CompressionNone, models.CompressionGzip, models.CompressionZstd, c.Backup.Compression)
+	_, err = base64.StdEncoding.DecodeString(c.Backup.EncryptionKey)
+	check(err == nil, "backup.encryptionKey", "must be base64")
+	for _, keep := range []struct {
+		path string
+		n    int
+	}{
+		{"backup.keep.last", c.Backup.Keep.Last},
+		{"backup.keep.daily", c.Backup.Keep.Daily},
+		{"backup.keep.weekly", c.Backup.Keep.Weekly},
+		{"backup.keep.monthly", c.Backup.Keep.Monthly},
+	} {
+		check(keep.n >= 0, keep.path, "must not be negative, got %v", keep.n)
+	}
+
+	storage := c.Storage
+	check(oneOf(storage.ResolvedBackend(), storageclient.BackendLocal, storageclient.BackendS3, storageclient.BackendGCS),
+		"storage.backend", "must be %v, %v or %v, got %q",
+		storageclient.BackendLocal, storageclient.BackendS3, storageclient.BackendGCS, storage.Backend)
+	check(storage.ResolvedBackend() != storageclient.BackendLocal || storage.Dir != "", "storage.dir",
+		"must be set for local storage")
+	check(storage.ResolvedBackend() != storageclient.BackendS3 || storage.S3.Bucket != "", "storage.s3.bucket",
+		"must be set for S3 storage")
+	check(storage.ResolvedBackend() != storageclient.BackendGCS || storage.GCS.Bucket != "", "storage.gcs.bucket",
+		"must be set for GCS storage")
+	check(storage.Timeout > 0, "storage.timeout", "must be positive, got %v", storage.Timeout)
+	check(storage.TransferTimeout > 0, "storage.transferTimeout", "must be positive, got %v", storage.TransferTimeout)
+	check(storage.ChunkSize >= storageclient.MinChunkSize, "storage.chunkSize", "must be at least %v bytes, got %v",
+		storageclient.MinChunkSize, storage.ChunkSize)
+	check(storage.Retries >= 0, "storage.retries", "must not be negative, got %v", storage.Retries)
+
+	_, err = time.LoadLocation(c.Schedule.Timezone)
+	check(err == nil, "schedule.timezone", "must be a timezone like Europe/London, got %q", c.Schedule.Timezone)
+	for _, schedule := range []struct {
+		path     string
+		schedule string
+	}{
+		{"schedule.backup", c.Schedule.Backup},
+		{"schedule.integrityCheck", c.Schedule.IntegrityCheck},
+		{"schedule.keyringReload", c.Schedule.KeyringReload},
+		{"schedule.tokenCleanup", c.Schedule.TokenCleanup},
+		{"schedule.walSync", c.Schedule.WALSync},
+		{"schedule.logRotate", c.Schedule.LogRotate},
+	} {
+		if schedule.schedule != scheduler.Disabled {
+			_, err = cron.ParseStandard(schedule.schedule)
+			check(err == nil, schedule.path, "must be a cron expression or %v, got %q",
+				scheduler.Disabled, schedule.schedule)
+		}
+	}
+
+	check(c.Notify.Retries >= 0, "notify.retries", "must not be negative, got %v", c.Notify.Retries)
+	check(oneOf(c.Notify.WebhookFormat, notifier.FormatJSON, notifier.FormatSlack), "notify.webhookFormat",
+		"must be %v or %v, got %q", notifier.FormatJSON, notifier.FormatSlack, c.Notify.WebhookFormat)
+	check(c.Notify.SMTPAddr == "" || c.Notify.EmailFrom != "", "notify.emailFrom", "must be set with an SMTP server")
+	check(c.Notify.SMTPAddr == "" || len(c.Notify.EmailTo) > 0, "notify.emailTo", "must be set with an SMTP server")
+
+	check(c.WAL.SnapshotInterval > 0, "wal.snapshotInterval", "must be positive, got %v", c.WAL.SnapshotInterval)
+	check(c.WAL.Retention > 0, "wal.retention", "must be positive, got %v", c.WAL.Retention)
+
+	check(c.Health.MaxBackupAge >= 0, "health.maxBackupAge", "must not be negative, got %v", c.Health.MaxBackupAge)
+	check(c.Health.CheckTimeout > 0, "health.checkTimeout", "must be positive, got %v", c.Health.CheckTimeout)
+	check(c.Health.CacheFor >= 0, "health.cacheFor", "must not be negative, got %v", c.Health.CacheFor)
+
+	check(oneOf(c.Tracing.Exporter, tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout),
+		"tracing.exporter", "must be %v, %v or %v, got %q",
+		tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout, c.Tracing.Exporter)
+	check(c.Tracing.ServiceName != "", "tracing.serviceName", "must be set")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sampleRatio",
+		"must be from 0 to 1, got %v", c.Tracing.SampleRatio)
+
+	return errors.Join(errs...)
+}
+
+func oneOf(value string, allowed ...string) bool {
+	return slices.Contains(allowed, value)
+}
@@ -0,0 +1,370 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/apkatsikas/artist-entities/storageclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, contents string) string {
+	file := filepath.Join(t.TempDir(), "entities.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(contents), 0600))
+	return file
+}
+
+func newFlagSet() *flag.FlagSet {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return flags
+}
+
+func TestLoadDefaults(t *testing.T) {
+	t.Setenv("JWT_SIGNING_KEY", "secret")
+
+	c, err := Load(newFlagSet(), nil)
+
+	require.NoError(t, err)
+	expected := Default()
+	expected.Auth.JWTSigningKey = "secret"
+	assert.Equal(t, expected, c)
+}
+
+func TestLoadSections(t *testing.T) {
+	t.Setenv("JWT_SIGNING_KEY", "")
+
+	// Commands that don't sign tokens don't need a key
+	_, err := Load(newFlagSet(), nil)
+	require.NoError(t, err)
+
+	_, err = Load(newFlagSet(), nil, SectionAuth)
+	assert.ErrorContains(t, err, "auth.jwtSigningKey (JWT_SIGNING_KEY) must be set")
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeFile(t, `
+server:
+  addr: ":9000"
+database:
+  path: file.db
+auth:
+  jwtSigningKey: secret
+backup:
+  compression: zstd
+  keep:
+    daily: 3
+storage:
+  backend: s3
+  s3:
+    bucket: backups
+  timeout: 1m
+notify:
+  emailTo: [a@example.com, b@example.com]
+`)
+	t.Setenv("CONFIG_FILE", file)
+	t.Setenv("BACKUP_COMPRESSION", "none")
+	t.Setenv("BACKUP_SCHEDULE", "off")
+	t.Setenv("SERVER_ADDR", ":9001")
+
+	flags := newFlagSet()
+	name := flags.String("name", "", "A command's own flag")
+
+	c, err := Load(flags, []string{"-server.addr", ":9002", "-name", "bob", "-storage.retries=5", "extra"})
+
+	require.NoError(t, err)
+	// Flags win over everything
+	assert.Equal(t, ":9002", c.Server.Addr)
+	assert.Equal(t, 5, c.Storage.Retries)
+	// Then the environment
+	assert.Equal(t, models.CompressionNone, c.Backup.Compression)
+	assert.Equal(t, "off", c.Schedule.Backup)
+	// Then the file
+	assert.Equal(t, "file.db", c.Database.Path)
+	assert.Equal(t, 3, c.Backup.Keep.Daily)
+	assert.Equal(t, storageclient.BackendS3, c.Storage.Backend)
+	assert.Equal(t, "backups", c.Storage.S3.Bucket)
+	assert.Equal(t, time.Minute, c.Storage.Timeout)
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, c.Notify.EmailTo)
+	// Then the defaults
+	assert.Equal(t, Default().Backup.Keep.Weekly, c.Backup.Keep.Weekly)
+	assert.Equal(t, Default().Storage.TransferTimeout, c.Storage.TransferTimeout)
+	// The command's flags are parsed alongside
+	assert.Equal(t, "bob", *name)
+	assert.Equal(t, []string{"extra"}, flags.Args())
+}
+
+func TestLoadEnv(t *testing.T) {
+	t.Setenv("JWT_SIGNING_KEY", "secret")
+	t.Setenv("WAL_REPLICATION", "true")
+	t.Setenv("WAL_RETENTION", "48h")
+	t.Setenv("NOTIFY_EMAIL_TO", "a@example.com, b@example.com")
+	t.Setenv("STORAGE_CHUNK_SIZE", "")
+	t.Setenv("TRACING_SAMPLE_RATIO", "0.25")
+
+	c, err := Load(newFlagSet(), nil)
+
+	require.NoError(t, err)
+	assert.True(t, c.WAL.Replication)
+	assert.Equal(t, 0.25, c.Tracing.SampleRatio)
+	assert.Equal(t, 48*time.Hour, c.WAL.Retention)
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, c.Notify.EmailTo)
+	// Empty is the same as unset
+	assert.Equal(t, Default().Storage.ChunkSize, c.Storage.ChunkSize)
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		env      map[string]string
+		args     []string
+		expected []string
+	}{
+		{
+			name:     "unknown key",
+			file:     "backup:\n  compresion: zstd\n",
+			expected: []string{"compresion not found"},
+		},
+		{
+			name:     "missing file",
+			args:     []string{"-config", "missing.yaml"},
+			expected: []string{"failed to read missing.yaml"},
+		},
+		{
+			name:     "unknown flag",
+			args:     []string{"-nope"},
+			expected: []string{"-nope"},
+		},
+		{
+			name:     "bad values",
+			env:      map[string]string{"BACKUP_KEEP_DAILY": "seven", "WAL_REPLICATION": "yes please"},
+			args:     []string{"-wal.retention", "1 week", "-tracing.sampleRatio", "half"},
+			expected: []string{"BACKUP_KEEP_DAILY must be a whole number", "WAL_REPLICATION must be true or false", "-wal.retention must be a duration", "-tracing.sampleRatio must be a number"},
+		},
+		{
+			name:     "invalid",
+			env:      map[string]string{"BACKUP_COMPRESSION": "lz4"},
+			expected: []string{`backup.compression (BACKUP_COMPRESSION) must be none, gzip or zstd, got "lz4"`},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("JWT_SIGNING_KEY", "secret")
+			if tc.file != "" {
+				t.Setenv("CONFIG_FILE", writeFile(t, tc.file))
+			}
+			for name, value := range tc.env {
+				t.Setenv(name, value)
+			}
+
+			c, err := Load(newFlagSet(), tc.args)
+
+			assert.Nil(t, c)
+			for _, expected := range tc.expected {
+				assert.ErrorContains(t, err, expected)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(c *Config)
+		sections []Section
+		expected []string
+	}{
+		{
+			name:   "valid",
+			modify: func(c *Config) {},
+		},
+		{
+			name:     "no JWT key",
+			modify:   func(c *Config) { c.Auth.JWTSigningKey = "" },
+			sections: []Section{SectionAuth},
+			expected: []string{"auth.jwtSigningKey (JWT_SIGNING_KEY) must be set when there's no keyring file"},
+		},
+		{
+			name:   "no JWT key without signing tokens",
+			modify: func(c *Config) { c.Auth.JWTSigningKey = "" },
+		},
+		{
+			name: "keyring instead of JWT key",
+			modify: func(c *Config) {
+				c.Auth.JWTSigningKey = ""
+				c.Auth.JWTKeyringFile = "keys.json"
+			},
+			sections: []Section{SectionAuth},
+		},
+		{
+			name:     "OIDC without client",
+			modify:   func(c *Config) { c.OIDC.IssuerURL = "https://issuer.example.com" },
+			expected: []string{"oidc.clientID (OIDC_CLIENT_ID) must be set", "oidc.redirectURL (OIDC_REDIRECT_URL) must be set"},
+		},
+		{
+			name:     "server timeouts",
+			modify:   func(c *Config) { c.Server.WriteTimeout = 0 },
+			expected: []string{"server.writeTimeout (SERVER_WRITE_TIMEOUT) must be positive, got 0s"},
+		},
+		{
+			name:     "metrics on the public address",
+			modify:   func(c *Config) { c.Server.MetricsAddr = c.Server.Addr },
+			expected: []string{"server.metricsAddr (SERVER_METRICS_ADDR) must differ from server.addr"},
+		},
+		{
+			name: "TLS",
+			modify: func(c *Config) {
+				c.Server.TLS.KeyFile = "key.pem"
+				c.Server.TLS.HSTSMaxAge = -time.Hour
+			},
+			expected: []string{
+				"server.tls.certFile (TLS_CERT_FILE) must be set with a key file",
+				"server.tls.hstsMaxAge (TLS_HSTS_MAX_AGE) must not be negative, got -1h0m0s",
+			},
+		},
+		{
+			name:     "redirect without TLS",
+			modify:   func(c *Config) { c.Server.TLS.RedirectAddr = ":80" },
+			expected: []string{"server.tls.redirectAddr (TLS_REDIRECT_ADDR) can only be set with a certificate"},
+		},
+		{
+			name:     "encryption key",
+			modify:   func(c *Config) { c.Backup.EncryptionKey = "not base64!" },
+			expected: []string{"backup.encryptionKey (BACKUP_ENCRYPTION_KEY) must be base64"},
+		},
+		{
+			name:     "retention",
+			modify:   func(c *Config) { c.Backup.Keep.Monthly = -1 },
+			expected: []string{"backup.keep.monthly (BACKUP_KEEP_MONTHLY) must not be negative, got -1"},
+		},
+		{
+			name: "storage",
+			modify: func(c *Config) {
+				c.Storage.Backend = storageclient.BackendGCS
+				c.Storage.ChunkSize = 1
+				c.Storage.Timeout = 0
+			},
+			expected: []string{
+				"storage.gcs.bucket (GCS_BUCKET_NAME) must be set for GCS storage",
+				"storage.chunkSize (STORAGE_CHUNK_SIZE) must be at least",
+				"storage.timeout (STORAGE_TIMEOUT) must be positive, got 0s",
+			},
+		},
+		{
+			name:     "storage backend",
+			modify:   func(c *Config) { c.Storage.Backend = "ftp" },
+			expected: []string{`storage.backend (BACKUP_STORAGE) must be local, s3 or gcs, got "ftp"`},
+		},
+		{
+			name: "schedules",
+			modify: func(c *Config) {
+				c.Schedule.Timezone = "Mars/Olympus"
+				c.Schedule.Backup = "at 2"
+				c.Schedule.WALSync = "off"
+				c.Schedule.LogRotate = "nightly"
+			},
+			expected: []string{
+				`schedule.timezone (SCHEDULE_TIMEZONE) must be a timezone like Europe/London, got "Mars/Olympus"`,
+				`schedule.backup (BACKUP_SCHEDULE) must be a cron expression or off, got "at 2"`,
+				`schedule.logRotate (LOG_ROTATE_SCHEDULE) must be a cron expression or off, got "nightly"`,
+			},
+		},
+		{
+			name: "notify",
+			modify: func(c *Config) {
+				c.Notify.WebhookFormat = "teams"
+				c.Notify.SMTPAddr = "smtp.example.com:587"
+			},
+			expected: []string{
+				`notify.webhookFormat (NOTIFY_WEBHOOK_FORMAT) must be json or slack, got "teams"`,
+				"notify.emailFrom (NOTIFY_EMAIL_FROM) must be set with an SMTP server",
+				"notify.emailTo (NOTIFY_EMAIL_TO) must be set with an SMTP server",
+			},
+		},
+		{
+			name:     "WAL",
+			modify:   func(c *Config) { c.WAL.SnapshotInterval = 0 },
+			expected: []string{"wal.snapshotInterval (WAL_SNAPSHOT_INTERVAL) must be positive"},
+		},
+		{
+			name: "log",
+			modify: func(c *Config) {
+				c.Log.File = ""
+				c.Log.MaxSize = 1000
+				c.Log.Keep = -1
+				c.Log.Format = "logfmt"
+				c.Log.Level = "verbose"
+			},
+			expected: []string{
+				"log.file (LOG_FILE) must be set unless only logging to stdout",
+				"log.maxSize (LOG_MAX_SIZE) must be at least 1048576 bytes, got 1000",
+				"log.keep (LOG_KEEP) must not be negative, got -1",
+				`log.format (LOG_FORMAT) must be text or json, got "logfmt"`,
+				`log.level (LOG_LEVEL) must be debug, info, warn or error, got "verbose"`,
+			},
+		},
+		{
+			name: "stdout only",
+			modify: func(c *Config) {
+				c.Log.File = ""
+				c.Log.StdoutOnly = true
+			},
+		},
+		{
+			name: "health",
+			modify: func(c *Config) {
+				c.Health.MaxBackupAge = -time.Hour
+				c.Health.CheckTimeout = 0
+			},
+			expected: []string{
+				"health.maxBackupAge (HEALTH_MAX_BACKUP_AGE) must not be negative, got -1h0m0s",
+				"health.checkTimeout (HEALTH_CHECK_TIMEOUT) must be positive, got 0s",
+			},
+		},
+		{
+			name: "tracing",
+			modify: func(c *Config) {
+				c.Tracing.Exporter = "jaeger"
+				c.Tracing.SampleRatio = 1.5
+			},
+			expected: []string{
+				`tracing.exporter (TRACING_EXPORTER) must be none, otlp or stdout, got "jaeger"`,
+				"tracing.sampleRatio (TRACING_SAMPLE_RATIO) must be from 0 to 1, got 1.5",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := Default()
+			c.Auth.JWTSigningKey = "secret"
+			tc.modify(c)
+
+			err := c.Validate(tc.sections...)
+
+			if len(tc.expected) == 0 {
+				assert.NoError(t, err)
+			}
+			for _, expected := range tc.expected {
+				assert.ErrorContains(t, err, expected)
+			}
+		})
+	}
+}
+
+func TestExampleFile(t *testing.T) {
+	c := Default()
+
+	require.NoError(t, readFile("../entities.example.yaml", c))
+
+	// It documents the defaults
+	assert.Equal(t, Default(), c)
+}
@@ -0,0 +1,222 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// setting is one value that can be configured
+type setting struct {
+	// path is where it sits in the file, like backup.keep.daily, and its flag
+	path  string
+	env   string
+	usage string
+	value reflect.Value
+}
+
+// name is how errors refer to the setting
+func (s setting) name() string {
+	if s.env == "" {
+		return s.path
+	}
+	return fmt.Sprintf("%v (%v)", s.path, s.env)
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// settings lists every setting in c, pointing at its value
+func settings(c *Config) []setting {
+	var all []setting
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			name, options, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			path := prefix
+			if options != "inline" {
+				path = strings.TrimPrefix(prefix+"."+name, ".")
+			}
+
+			value := v.Field(i)
+			if value.Kind() == reflect.Struct {
+				walk(value, path)
+				continue
+			}
+			all = append(all, setting{path: path, env: field.Tag.Get("env"), usage: field.Tag.Get("usage"),
+				value: value})
+		}
+	}
+	walk(reflect.ValueOf(c).Elem(), "")
+	return all
+}
+
+// set parses raw into a setting's value
+func set(v reflect.Value, raw string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("must be a duration like 90s, got %q", raw)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("must be true or false, got %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("must be a whole number, got %q", raw)
+		}
+		v.SetInt(n)
+	case reflect.Uint:
+		n, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("must be a positive whole number, got %q", raw)
+		}
+		v.SetUint(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("must be a number, got %q", raw)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("can't be set from %q", raw)
+	}
+	return nil
+}
+
+// flagValue holds a flag until the file and environment have been read,
+// so it can be applied over them
+type flagValue struct {
+	raw    string
+	set    bool
+	isBool bool
+}
+
+func (f *flagValue) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.raw
+}
+
+func (f *flagValue) Set(raw string) error {
+	f.raw, f.set = raw, true
+	return nil
+}
+
+func (f *flagValue) IsBoolFlag() bool {
+	return f.isBool
+}
+
+func defaultString(v reflect.Value) string {
+	if v.IsZero() {
+		return ""
+	}
+	if v.Kind() == reflect.Slice {
+		return strings.Join(v.Interface().([]string), ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// readFile applies a YAML file over c, unknown keys are errors
+func readFile(file string, c *Config) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	err = decoder.Decode(c)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// Load builds the configuration from its defaults, then the YAML file named by
+// -config or CONFIG_FILE, then environment variables, then args
+// Its flags are added to flags, beside any a command has, and parsed from args
+// Settings in sections are validated as well as those every command needs
+func Load(flags *flag.FlagSet, args []string, sections ...Section) (*Config, error) {
+	c := Default()
+	all := settings(c)
+
+	file := flags.String("config", os.Getenv("CONFIG_FILE"), "YAML configuration file (CONFIG_FILE)")
+	values := make([]*flagValue, len(all))
+	for i, s := range all {
+		values[i] = &flagValue{raw: defaultString(s.value), isBool: s.value.Kind() == reflect.Bool}
+		usage := s.usage
+		if s.env != "" {
+			usage = fmt.Sprintf("%v (%v)", usage, s.env)
+		}
+		flags.Var(values[i], s.path, usage)
+	}
+	err := flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if *file != "" {
+		err = readFile(*file, c)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %v: %v", *file, err)
+		}
+	}
+
+	// Empty variables are treated as unset
+	var errs []error
+	for _, s := range all {
+		if raw := os.Getenv(s.env); s.env != "" && raw != "" {
+			err = set(s.value, raw)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%v %v", s.env, err))
+			}
+		}
+	}
+	for i, s := range all {
+		if values[i].set {
+			err = set(s.value, values[i].raw)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("-%v %v", s.path, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	err = c.Validate(sections...)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
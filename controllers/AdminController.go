@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	ce "github.com/apkatsikas/artist-entities/customerrors"
+	"github.com/apkatsikas/artist-entities/infrastructures/logutil"
+	"github.com/apkatsikas/artist-entities/interfaces"
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/apkatsikas/artist-entities/viewmodels"
+)
+
+// AdminController manages database backups
+type AdminController struct {
+	AdminService interfaces.IAdminService
+	AuthService  interfaces.IAuthService
+	Scheduler    interfaces.IScheduler
+}
+
+func backupVM(backup *models.Backup) viewmodels.BackupVM {
+	vm := viewmodels.BackupVM{
+		Name:    backup.Name,
+		Updated: backup.Updated,
+		Size:    backup.Size,
+		Status:  backup.Status,
+	}
+	if backup.Manifest != nil {
+		vm.Compression = backup.Manifest.Compression
+		vm.KeyID = backup.Manifest.KeyID
+		vm.Artists = &backup.Manifest.Artists
+		vm.Users = &backup.Manifest.Users
+	}
+	return vm
+}
+
+func backupRunVM(run *models.BackupRun) *viewmodels.BackupRunVM {
+	return &viewmodels.BackupRunVM{
+		Trigger:    run.Trigger,
+		Object:     run.Object,
+		StartedAt:  run.StartedAt,
+		DurationMs: run.DurationMs,
+		Size:       run.Size,
+		Succeeded:  run.Succeeded(),
+		Error:      run.Error,
+	}
+}
+
+// Backup runs a backup now
+func (ac *AdminController) Backup(res http.ResponseWriter, req *http.Request) {
+	err := authorizeJWT(req, ac.AuthService, models.RoleAdmin)
+	if err != nil {
+		handleAuthError(res, req, err)
+	} else {
+		// A client giving up, or the write timeout, mustn't fail a backup partway through
+		run, err := ac.AdminService.Backup(context.WithoutCancel(req.Context()), models.BackupTriggerManual)
+
+		if err != nil {
+			if errors.Is(err, ce.ErrBackupInProgress) {
+				handleRes(
+					res,
+					ResponseError{Message: err.Error()},
+					http.StatusConflict,
+				)
+			} else {
+				// The error is recorded with the run
+				logutil.FromContext(req.Context()).Error("Failed to backup entities DB", "error", err)
+				handleRes(
+					res,
+					ResponseError{Message: UNEXPECTED_ERROR},
+					http.StatusInternalServerError,
+				)
+			}
+		} else {
+			logutil.FromContext(req.Context()).Info("Backed up", "audit", true, "object", run.Object, "ip", clientIP(req))
+			handleRes(res, backupRunVM(run), http.StatusCreated)
+		}
+	}
+}
+
+// List returns the stored backups and how the last backup went
+func (ac *AdminController) List(res http.ResponseWriter, req *http.Request) {
+	err := authorizeJWT(req, ac.AuthService, models.RoleAdmin)
+	if err != nil {
+		handleAuthError(res, req, err)
+	} else {
+		backups, err := ac.AdminService.ListBackups(req.Context())
+		var lastRun *models.BackupRun
+		if err == nil {
+			lastRun, err = ac.AdminService.LastBackupRun()
+			if errors.Is(err, ce.ErrRecordNotFound) {
+				err = nil
+			}
+		}
+
+		if err != nil {
+			logutil.FromContext(req.Context()).Error("Failed to list backups", "error", err)
+			handleRes(
+				res,
+				ResponseError{Message: UNEXPECTED_ERROR},
+				http.StatusInternalServerError,
+			)
+		} else {
+			vm := viewmodels.BackupsVM{Backups: []viewmodels.BackupVM{}}
+			for i := range backups {
+				vm.Backups = append(vm.Backups, backupVM(&backups[i]))
+			}
+			if lastRun != nil {
+				vm.LastRun = backupRunVM(lastRun)
+			}
+			handleRes(res, vm, http.StatusOK)
+		}
+	}
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// Jobs returns the scheduled jobs and when they last and next run
+func (ac *AdminController) Jobs(res http.ResponseWriter, req *http.Request) {
+	err := authorizeJWT(req, ac.AuthService, models.RoleAdmin)
+	if err != nil {
+		handleAuthError(res, req, err)
+	} else {
+		vms := []viewmodels.ScheduledJobVM{}
+		for _, job := range ac.Scheduler.Jobs() {
+			vms = append(vms, viewmodels.ScheduledJobVM{
+				Name:           job.Name,
+				Schedule:       job.Schedule,
+				Running:        job.Running,
+				LastRun:        optionalTime(job.LastRun),
+				LastDurationMs: job.LastDuration.Milliseconds(),
+				LastError:      job.LastError,
+				Skipped:        job.Skipped,
+				NextRun:        optionalTime(job.NextRun),
+			})
+		}
+		handleRes(res, vms, http.StatusOK)
+	}
+}
+
+// Restore replaces the database with a backup
+func (ac *AdminController) Restore(res http.ResponseWriter, req *http.Request) {
+	err := authorizeJWT(req, ac.AuthService, models.RoleAdmin)
+	if err != nil {
+		handleAuthError(res, req, err)
+	} else {
+		var restore viewmodels.RestoreVM
+		decoder := json.NewDecoder(req.Body)
+		decoder.DisallowUnknownFields()
+		decodeError := decoder.Decode(&restore)
+
+		if decodeError != nil || restore.Name == "" {
+			handleRes(
+				res,
+				ResponseError{Message: BAD_REQUEST},
+				http.StatusBadRequest,
+			)
+		} else {
+			// Once the database is being swapped it has to finish, whether the client waits or not
+			safetyCopy, err := ac.AdminService.Restore(context.WithoutCancel(req.Context()), restore.Name)
+
+			if err != nil {
+				if errors.Is(err, ce.ErrRecordNotFound) {
+					handleRes(
+						res,
+						ResponseError{Message: err.Error()},
+						http.StatusNotFound,
+					)
+				} else if errors.Is(err, ce.ErrDataInvalid) {
+					handleRes(
+						res,
+						ResponseError{Message: err.Error()},
+						http.StatusUnprocessableEntity,
+					)
+				} else {
+					logutil.FromContext(req.Context()).Error("Failed to restore backup", "backup", restore.Name, "error", err)
+					handleRes(
+						res,
+						ResponseError{Message: UNEXPECTED_ERROR},
+						http.StatusInternalServerError,
+					)
+				}
+			} else {
+				logutil.FromContext(req.Context()).Warn("Restored backup", "audit", true, "backup", restore.Name, "ip", clientIP(req),
+					"previous", safetyCopy)
+				handleRes(res, viewmodels.RestoredVM{SafetyCopy: safetyCopy}, http.StatusOK)
+			}
+		}
+	}
+}
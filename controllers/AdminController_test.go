@@ -0,0 +1,301 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ce "github.com/apkatsikas/artist-entities/customerrors"
+	"github.com/apkatsikas/artist-entities/interfaces/mocks"
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/apkatsikas/artist-entities/viewmodels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func restoreRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, ADMIN_RESTORE_RP, strings.NewReader(body))
+	req.Header.Add("Authorization", authHeader)
+	return req
+}
+
+func TestRestore(t *testing.T) {
+	adminService := mocks.NewIAdminService(t)
+	adminService.EXPECT().Restore(mock.Anything, "entities-backup1.sqlite").Return("entities-pre-restore2.sqlite", nil)
+
+	adminController := &AdminController{AdminService: adminService, AuthService: authorizedAuthService(t)}
+
+	w := httptest.NewRecorder()
+	adminController.Restore(w, restoreRequest(`{"Name": "entities-backup1.sqlite"}`))
+
+	var restored viewmodels.RestoredVM
+	json.NewDecoder(w.Body).Decode(&restored)
+
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	assert.Equal(t, "entities-pre-restore2.sqlite", restored.SafetyCopy)
+}
+
+func TestRestoreErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "unknown backup", err: ce.ErrRecordNotFound, expectedStatus: http.StatusNotFound},
+		{name: "corrupt backup", err: fmt.Errorf("%w: integrity check failed", ce.ErrDataInvalid),
+			expectedStatus: http.StatusUnprocessableEntity},
+		{name: "unexpected", err: fmt.Errorf("disk full"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adminService := mocks.NewIAdminService(t)
+			adminService.EXPECT().Restore(mock.Anything, "entities-backup1.sqlite").Return("", tt.err)
+
+			adminController := &AdminController{AdminService: adminService, AuthService: authorizedAuthService(t)}
+
+			w := httptest.NewRecorder()
+			adminController.Restore(w, restoreRequest(`{"Name": "entities-backup1.sqlite"}`))
+
+			assert.Equal(t, tt.expectedStatus, w.Result().StatusCode)
+		})
+	}
+}
+
+func TestRestoreBadRequest(t *testing.T) {
+	for _, body := range []string{`{}`, `{"Name": ""}`, `{"File": "x"}`, `not json`} {
+		adminController := &AdminController{AdminService: mocks.NewIAdminService(t),
+			AuthService: authorizedAuthService(t)}
+
+		w := httptest.NewRecorder()
+		adminController.Restore(w, restoreRequest(body))
+
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode, body)
+	}
+}
+
+func TestRestoreUnauthorized(t *testing.T) {
+	adminController := &AdminController{AdminService: mocks.NewIAdminService(t),
+		AuthService: mocks.NewIAuthService(t)}
+
+	req := httptest.NewRequest(http.MethodPost, ADMIN_RESTORE_RP, strings.NewReader(`{"Name": "x"}`))
+	w := httptest.NewRecorder()
+	adminController.Restore(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Result().StatusCode)
+}
+
+func adminRequest(method string, target string) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+	req.Header.Add("Authorization", authHeader)
+	return req
+}
+
+// editorAuthService rejects the token as it's an editor's, not an admin's
+func editorAuthService(t *testing.T) *mocks.IAuthService {
+	authService := mocks.NewIAuthService(t)
+	authService.EXPECT().Authorize(mock.Anything, token, models.RoleAdmin).
+		Return(fmt.Errorf("%w %v", ce.ErrForbidden, models.RoleAdmin))
+	return authService
+}
+
+func TestAdminForbiddenForEditor(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(ac *AdminController) http.HandlerFunc
+		req     *http.Request
+	}{
+		{name: "backup", handler: func(ac *AdminController) http.HandlerFunc { return ac.Backup },
+			req: adminRequest(http.MethodPost, ADMIN_BACKUP_RP)},
+		{name: "list backups", handler: func(ac *AdminController) http.HandlerFunc { return ac.List },
+			req: adminRequest(http.MethodGet, ADMIN_BACKUPS_RP)},
+		{name: "restore", handler: func(ac *AdminController) http.HandlerFunc { return ac.Restore },
+			req: restoreRequest(`{"Name": "entities-backup1.sqlite"}`)},
+		{name: "jobs", handler: func(ac *AdminController) http.HandlerFunc { return ac.Jobs },
+			req: adminRequest(http.MethodGet, ADMIN_JOBS_RP)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Nothing is backed up or restored
+			adminController := &AdminController{AdminService: mocks.NewIAdminService(t),
+				AuthService: editorAuthService(t), Scheduler: mocks.NewIScheduler(t)}
+
+			w := httptest.NewRecorder()
+			tt.handler(adminController)(w, tt.req)
+
+			assert.Equal(t, http.StatusForbidden, w.Result().StatusCode)
+		})
+	}
+}
+
+func TestBackup(t *testing.T) {
+	adminService := mocks.NewIAdminService(t)
+	adminService.EXPECT().Backup(mock.Anything, models.BackupTriggerManual).
+		Return(&models.BackupRun{Trigger: models.BackupTriggerManual, Object: "entities-backup1.sqlite.gz"}, nil)
+
+	adminController := &AdminController{AdminService: adminService, AuthService: authorizedAuthService(t)}
+
+	w := httptest.NewRecorder()
+	adminController.Backup(w, adminRequest(http.MethodPost, ADMIN_BACKUP_RP))
+
+	var run viewmodels.BackupRunVM
+	json.NewDecoder(w.Body).Decode(&run)
+
+	assert.Equal(t, http.StatusCreated, w.Result().StatusCode)
+	assert.Equal(t, "entities-backup1.sqlite.gz", run.Object)
+	assert.True(t, run.Succeeded)
+}
+
+func TestAdminOutlivesClient(t *testing.T) {
+	notCancelled := mock.MatchedBy(func(ctx context.Context) bool { return ctx.Err() == nil })
+	tests := []struct {
+		name    string
+		expect  func(adminService *mocks.IAdminService)
+		request func(adminController *AdminController, w http.ResponseWriter, req *http.Request)
+		req     *http.Request
+	}{
+		{
+			name: "backup",
+			expect: func(adminService *mocks.IAdminService) {
+				adminService.EXPECT().Backup(notCancelled, models.BackupTriggerManual).
+					Return(&models.BackupRun{Trigger: models.BackupTriggerManual}, nil)
+			},
+			request: (*AdminController).Backup,
+			req:     adminRequest(http.MethodPost, ADMIN_BACKUP_RP),
+		},
+		{
+			name: "restore",
+			expect: func(adminService *mocks.IAdminService) {
+				adminService.EXPECT().Restore(notCancelled, "entities-backup1.sqlite").Return("", nil)
+			},
+			request: (*AdminController).Restore,
+			req:     restoreRequest(`{"Name": "entities-backup1.sqlite"}`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adminService := mocks.NewIAdminService(t)
+			tt.expect(adminService)
+			adminController := &AdminController{AdminService: adminService, AuthService: authorizedAuthService(t)}
+			// The client has gone
+			ctx, cancel := context.WithCancel(tt.req.Context())
+			cancel()
+
+			tt.request(adminController, httptest.NewRecorder(), tt.req.WithContext(ctx))
+		})
+	}
+}
+
+func TestBackupErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "in progress", err: ce.ErrBackupInProgress, expectedStatus: http.StatusConflict},
+		{name: "unexpected", err: fmt.Errorf("disk full"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adminService := mocks.NewIAdminService(t)
+			adminService.EXPECT().Backup(mock.Anything, models.BackupTriggerManual).Return(nil, tt.err)
+
+			adminController := &AdminController{AdminService: adminService, AuthService: authorizedAuthService(t)}
+
+			w := httptest.NewRecorder()
+			adminController.Backup(w, adminRequest(http.MethodPost, ADMIN_BACKUP_RP))
+
+			assert.Equal(t, tt.expectedStatus, w.Result().StatusCode)
+		})
+	}
+}
+
+func TestBackupUnauthorized(t *testing.T) {
+	adminController := &AdminController{AdminService: mocks.NewIAdminService(t)}
+
+	req := httptest.NewRequest(http.MethodPost, ADMIN_BACKUP_RP, nil)
+	w := httptest.NewRecorder()
+	adminController.Backup(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Result().StatusCode)
+}
+
+func TestListBackups(t *testing.T) {
+	backups := []models.Backup{
+		{
+			BackupFile: models.BackupFile{Name: "entities-backup2.sqlite.zst", Size: 40},
+			Manifest:   &models.BackupManifest{Compression: models.CompressionZstd, Artists: 10},
+			Status:     models.BackupOK,
+		},
+		{BackupFile: models.BackupFile{Name: "entities-backup1.sqlite"}, Status: models.BackupUnverified},
+	}
+	adminService := mocks.NewIAdminService(t)
+	adminService.EXPECT().ListBackups(mock.Anything).Return(backups, nil)
+	adminService.EXPECT().LastBackupRun().
+		Return(&models.BackupRun{Trigger: models.BackupTriggerScheduled, DurationMs: 1200, Error: "disk full"}, nil)
+
+	adminController := &AdminController{AdminService: adminService, AuthService: authorizedAuthService(t)}
+
+	w := httptest.NewRecorder()
+	adminController.List(w, adminRequest(http.MethodGet, ADMIN_BACKUPS_RP))
+
+	var listed viewmodels.BackupsVM
+	json.NewDecoder(w.Body).Decode(&listed)
+
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	assert.Len(t, listed.Backups, 2)
+	assert.Equal(t, models.CompressionZstd, listed.Backups[0].Compression)
+	assert.Equal(t, int64(10), *listed.Backups[0].Artists)
+	assert.Nil(t, listed.Backups[1].Artists)
+	assert.False(t, listed.LastRun.Succeeded)
+	assert.Equal(t, "disk full", listed.LastRun.Error)
+	assert.Equal(t, int64(1200), listed.LastRun.DurationMs)
+}
+
+func TestListBackupsNeverRun(t *testing.T) {
+	adminService := mocks.NewIAdminService(t)
+	adminService.EXPECT().ListBackups(mock.Anything).Return(nil, nil)
+	adminService.EXPECT().LastBackupRun().Return(nil, ce.ErrRecordNotFound)
+
+	adminController := &AdminController{AdminService: adminService, AuthService: authorizedAuthService(t)}
+
+	w := httptest.NewRecorder()
+	adminController.List(w, adminRequest(http.MethodGet, ADMIN_BACKUPS_RP))
+
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	assert.JSONEq(t, `{"Backups": [], "LastRun": null}`, w.Body.String())
+}
+
+func TestJobs(t *testing.T) {
+	lastRun := time.Date(2026, 10, 14, 2, 0, 0, 0, time.UTC)
+	scheduler := mocks.NewIScheduler(t)
+	scheduler.EXPECT().Jobs().Return([]models.ScheduledJob{
+		{Name: "backup", Schedule: "0 2 * * *", LastRun: lastRun, LastDuration: 1500 * time.Millisecond,
+			LastError: "disk full", NextRun: lastRun.AddDate(0, 0, 1)},
+		{Name: "integrity-check", Schedule: "off"},
+	})
+
+	adminController := &AdminController{AuthService: authorizedAuthService(t), Scheduler: scheduler}
+
+	w := httptest.NewRecorder()
+	adminController.Jobs(w, adminRequest(http.MethodGet, ADMIN_JOBS_RP))
+
+	var jobs []viewmodels.ScheduledJobVM
+	json.NewDecoder(w.Body).Decode(&jobs)
+
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	assert.Len(t, jobs, 2)
+	assert.Equal(t, lastRun, jobs[0].LastRun.UTC())
+	assert.Equal(t, int64(1500), jobs[0].LastDurationMs)
+	assert.Equal(t, "disk full", jobs[0].LastError)
+	assert.Nil(t, jobs[1].LastRun)
+	assert.Nil(t, jobs[1].NextRun)
+}
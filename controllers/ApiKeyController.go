@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	ce "github.com/apkatsikas/artist-entities/customerrors"
+	"github.com/apkatsikas/artist-entities/infrastructures/logutil"
+	"github.com/apkatsikas/artist-entities/interfaces"
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/apkatsikas/artist-entities/viewmodels"
+	"github.com/go-chi/chi/v5"
+)
+
+// ApiKeyController manages API keys
+// Only a logged in user can manage keys, a key can't be used to mint more keys
+type ApiKeyController struct {
+	ApiKeyService interfaces.IApiKeyService
+	AuthService   interfaces.IAuthService
+}
+
+func apiKeyVM(apiKey *models.ApiKey) viewmodels.ApiKeyVM {
+	return viewmodels.ApiKeyVM{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     strings.Split(apiKey.Scopes, ","),
+		CreatedAt:  apiKey.CreatedAt,
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		RevokedAt:  apiKey.RevokedAt,
+	}
+}
+
+func (ac *ApiKeyController) Create(res http.ResponseWriter, req *http.Request) {
+	err := authorizeJWT(req, ac.AuthService, models.RoleAdmin)
+	if err != nil {
+		handleAuthError(res, req, err)
+	} else {
+		var newKey viewmodels.NewApiKeyVM
+		decoder := json.NewDecoder(req.Body)
+		decoder.DisallowUnknownFields()
+		decodeError := decoder.Decode(&newKey)
+
+		if decodeError != nil {
+			handleRes(
+				res,
+				ResponseError{Message: BAD_REQUEST},
+				http.StatusBadRequest,
+			)
+		} else {
+			key, apiKey, err := ac.ApiKeyService.Create(newKey.Name, newKey.Scopes, newKey.ExpiresAt)
+
+			if err != nil {
+				if errors.Is(err, ce.ErrDataInvalid) || errors.Is(err, ce.ErrDataTooLong) {
+					handleRes(
+						res,
+						ResponseError{Message: err.Error()},
+						http.StatusBadRequest,
+					)
+				} else {
+					logutil.FromContext(req.Context()).Error("Failed to create API key", "apiKey", newKey.Name, "error", err)
+					handleRes(
+						res,
+						ResponseError{Message: UNEXPECTED_ERROR},
+						http.StatusInternalServerError,
+					)
+				}
+			} else {
+				handleRes(res,
+					viewmodels.CreatedApiKeyVM{ApiKeyVM: apiKeyVM(apiKey), Key: key}, http.StatusCreated)
+			}
+		}
+	}
+}
+
+func (ac *ApiKeyController) List(res http.ResponseWriter, req *http.Request) {
+	err := authorizeJWT(req, ac.AuthService, models.RoleAdmin)
+	if err != nil {
+		handleAuthError(res, req, err)
+	} else {
+		apiKeys, err := ac.ApiKeyService.List()
+
+		if err != nil {
+			logutil.FromContext(req.Context()).Error("Failed to list API keys", "error", err)
+			handleRes(
+				res,
+				ResponseError{Message: UNEXPECTED_ERROR},
+				http.StatusInternalServerError,
+			)
+		} else {
+			vms := []viewmodels.ApiKeyVM{}
+			for i := range apiKeys {
+				vms = append(vms, apiKeyVM(&apiKeys[i]))
+			}
+			encodeRes(res, vms)
+		}
+	}
+}
+
+func (ac *ApiKeyController) Revoke(res http.ResponseWriter, req *http.Request) {
+	apiKeyID := chi.URLParam(req, "apiKeyID")
+	u64, parseErr := strconv.ParseUint(apiKeyID, 10, 32)
+
+	err := authorizeJWT(req, ac.AuthService, models.RoleAdmin)
+	if err != nil {
+		handleAuthError(res, req, err)
+	} else if parseErr != nil {
+		handleRes(
+			res,
+			ResponseError{Message: BAD_REQUEST},
+			http.StatusBadRequest,
+		)
+	} else {
+		err := ac.ApiKeyService.Revoke(uint(u64))
+
+		if err != nil {
+			if errors.Is(err, ce.ErrRecordNotFound) {
+				handleRes(
+					res,
+					ResponseError{Message: err.Error()},
+					http.StatusNotFound,
+				)
+			} else {
+				logutil.FromContext(req.Context()).Error("Failed to revoke API key", "apiKeyID", apiKeyID, "error", err)
+				handleRes(
+					res,
+					ResponseError{Message: UNEXPECTED_ERROR},
+					http.StatusInternalServerError,
+				)
+			}
+		} else {
+			res.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ce "github.com/apkatsikas/artist-entities/customerrors"
+	"github.com/apkatsikas/artist-entities/interfaces/mocks"
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/apkatsikas/artist-entities/viewmodels"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const createdKey = "ae_createdkeyvalue"
+
+func apiKeyRouter(apiKeyController *ApiKeyController) *chi.Mux {
+	r := chi.NewRouter()
+	r.Post(API_KEY_RP, apiKeyController.Create)
+	r.Get(API_KEY_RP, apiKeyController.List)
+	r.Delete(API_KEY_ID_RP, apiKeyController.Revoke)
+	return r
+}
+
+func authorizedAuthService(t *testing.T) *mocks.IAuthService {
+	authService := mocks.NewIAuthService(t)
+	authService.EXPECT().Authorize(mock.Anything, token, models.RoleAdmin).Return(nil)
+	return authService
+}
+
+func TestCreateApiKey(t *testing.T) {
+	scopes := []string{models.ApiKeyScopeArtistWrite}
+	record := &models.ApiKey{Name: "ingest", Prefix: createdKey[:11], Scopes: models.ApiKeyScopeArtistWrite}
+	record.ID = 3
+
+	apiKeyService := mocks.NewIApiKeyService(t)
+	apiKeyService.EXPECT().Create("ingest", scopes, mock.Anything).Return(createdKey, record, nil)
+
+	apiKeyController := &ApiKeyController{ApiKeyService: apiKeyService,
+		AuthService: authorizedAuthService(t)}
+
+	body, err := json.Marshal(viewmodels.NewApiKeyVM{Name: "ingest", Scopes: scopes})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, API_KEY_RP, bytes.NewBuffer(body))
+	req.Header.Add("Authorization", authHeader)
+
+	w := httptest.NewRecorder()
+	apiKeyRouter(apiKeyController).ServeHTTP(w, req)
+
+	var created viewmodels.CreatedApiKeyVM
+	json.NewDecoder(w.Body).Decode(&created)
+
+	assert.Equal(t, http.StatusCreated, w.Result().StatusCode)
+	assert.Equal(t, createdKey, created.Key)
+	assert.Equal(t, uint(3), created.ID)
+	assert.Equal(t, scopes, created.Scopes)
+}
+
+func TestCreateApiKeyInvalid(t *testing.T) {
+	apiKeyService := mocks.NewIApiKeyService(t)
+	apiKeyService.EXPECT().Create("ingest", []string{"bogus"}, mock.Anything).Return("", nil, ce.ErrDataInvalid)
+
+	apiKeyController := &ApiKeyController{ApiKeyService: apiKeyService,
+		AuthService: authorizedAuthService(t)}
+
+	body, err := json.Marshal(viewmodels.NewApiKeyVM{Name: "ingest", Scopes: []string{"bogus"}})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, API_KEY_RP, bytes.NewBuffer(body))
+	req.Header.Add("Authorization", authHeader)
+
+	w := httptest.NewRecorder()
+	apiKeyRouter(apiKeyController).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+}
+
+func TestCreateApiKeyRequiresJWT(t *testing.T) {
+	// An API key can't be used to create more keys
+	apiKeyController := &ApiKeyController{ApiKeyService: mocks.NewIApiKeyService(t)}
+
+	req := httptest.NewRequest(http.MethodPost, API_KEY_RP, bytes.NewBufferString("{}"))
+	req.Header.Add("X-API-Key", apiKey)
+
+	w := httptest.NewRecorder()
+	apiKeyRouter(apiKeyController).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Result().StatusCode)
+}
+
+func TestCreateApiKeyForbiddenForEditor(t *testing.T) {
+	apiKeyController := &ApiKeyController{ApiKeyService: mocks.NewIApiKeyService(t),
+		AuthService: editorAuthService(t)}
+
+	req := httptest.NewRequest(http.MethodPost, API_KEY_RP, bytes.NewBufferString(`{"Name": "ingest"}`))
+	req.Header.Add("Authorization", authHeader)
+
+	w := httptest.NewRecorder()
+	apiKeyRouter(apiKeyController).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Result().StatusCode)
+}
+
+func TestListApiKeys(t *testing.T) {
+	record := models.ApiKey{Name: "ingest", Prefix: "ae_abcdefgh", Scopes: models.ApiKeyScopeArtistWrite}
+	record.ID = 1
+
+	apiKeyService := mocks.NewIApiKeyService(t)
+	apiKeyService.EXPECT().List().Return([]models.ApiKey{record}, nil)
+
+	apiKeyController := &ApiKeyController{ApiKeyService: apiKeyService,
+		AuthService: authorizedAuthService(t)}
+
+	req := httptest.NewRequest(http.MethodGet, API_KEY_RP, nil)
+	req.Header.Add("Authorization", authHeader)
+
+	w := httptest.NewRecorder()
+	apiKeyRouter(apiKeyController).ServeHTTP(w, req)
+
+	var listed []viewmodels.ApiKeyVM
+	json.NewDecoder(w.Body).Decode(&listed)
+
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "ae_abcdefgh", listed[0].Prefix)
+}
+
+func TestRevokeApiKey(t *testing.T) {
+	apiKeyService := mocks.NewIApiKeyService(t)
+	apiKeyService.EXPECT().Revoke(uint(4)).Return(nil)
+
+	apiKeyController := &ApiKeyController{ApiKeyService: apiKeyService,
+		AuthService: authorizedAuthService(t)}
+
+	req := httptest.NewRequest(http.MethodDelete, API_KEY_RP+"/4", nil)
+	req.Header.Add("Authorization", authHeader)
+
+	w := httptest.NewRecorder()
+	apiKeyRouter(apiKeyController).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+}
+
+func TestRevokeApiKeyNotFound(t *testing.T) {
+	apiKeyService := mocks.NewIApiKeyService(t)
+	apiKeyService.EXPECT().Revoke(uint(4)).Return(ce.ErrRecordNotFound)
+
+	apiKeyController := &ApiKeyController{ApiKeyService: apiKeyService,
+		AuthService: authorizedAuthService(t)}
+
+	req := httptest.NewRequest(http.MethodDelete, API_KEY_RP+"/4", nil)
+	req.Header.Add("Authorization", authHeader)
+
+	w := httptest.NewRecorder()
+	apiKeyRouter(apiKeyController).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+}
@@ -3,14 +3,13 @@ package controllers
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"net/http"
 	"strconv"
-	"strings"
 
 	ce "github.com/apkatsikas/artist-entities/customerrors"
 	"github.com/apkatsikas/artist-entities/infrastructures/logutil"
 	"github.com/apkatsikas/artist-entities/interfaces"
+	"github.com/apkatsikas/artist-entities/models"
 	"github.com/apkatsikas/artist-entities/viewmodels"
 	"github.com/go-chi/chi/v5"
 )
@@ -18,6 +17,7 @@ import (
 type ArtistController struct {
 	ArtistService interfaces.IArtistService
 	AuthService   interfaces.IAuthService
+	ApiKeyService interfaces.IApiKeyService
 }
 
 func (ac *ArtistController) Get(res http.ResponseWriter, req *http.Request) {
@@ -34,7 +34,7 @@ func (ac *ArtistController) Get(res http.ResponseWriter, req *http.Request) {
 		)
 	} else {
 		// Get the artist from the service
-		artist, err := ac.ArtistService.Get(uintID)
+		artist, err := ac.ArtistService.Get(req.Context(), uintID)
 
 		if err != nil {
 			// Record not found
@@ -45,7 +45,7 @@ func (ac *ArtistController) Get(res http.ResponseWriter, req *http.Request) {
 					http.StatusNotFound,
 				)
 			} else {
-				logutil.Error("Failed to get artist with ID of %v. Error was: %v", artistID, err)
+				logutil.FromContext(req.Context()).Error("Failed to get artist", "artistID", artistID, "error", err)
 				handleRes(
 					res,
 					ResponseError{Message: UNEXPECTED_ERROR},
@@ -59,35 +59,11 @@ func (ac *ArtistController) Get(res http.ResponseWriter, req *http.Request) {
 		}
 	}
 }
-func getBearerToken(req *http.Request) (string, error) {
-	authHeader := req.Header.Get("Authorization")
-	if authHeader == "" {
-		return "", fmt.Errorf("authorization header is missing")
-	}
-
-	splitBySpace := strings.Split(authHeader, " ")
-	if len(splitBySpace) != 2 || splitBySpace[0] != "Bearer" {
-		return "", fmt.Errorf("invalid Authorization header format")
-	}
-
-	token := splitBySpace[1]
-	return token, nil
-}
-
 func (ac *ArtistController) Create(res http.ResponseWriter, req *http.Request) {
-	token, err := getBearerToken(req)
+	err := authorizeJWTOrApiKey(req, ac.AuthService, ac.ApiKeyService, models.ApiKeyScopeArtistWrite,
+		models.RoleEditor)
 	if err != nil {
-		handleRes(
-			res,
-			ResponseError{Message: err.Error()},
-			http.StatusUnauthorized,
-		)
-	} else if !ac.AuthService.IsAuthorized(token) {
-		handleRes(
-			res,
-			ResponseError{Message: UNAUTHORZIED},
-			http.StatusUnauthorized,
-		)
+		handleAuthError(res, req, err)
 	} else {
 		var artist viewmodels.ArtistVM
 		decodeError := json.NewDecoder(req.Body).Decode(&artist)
@@ -99,7 +75,7 @@ func (ac *ArtistController) Create(res http.ResponseWriter, req *http.Request) {
 				http.StatusBadRequest,
 			)
 		} else {
-			createdArtist, err := ac.ArtistService.Create(artist.Name)
+			createdArtist, err := ac.ArtistService.Create(req.Context(), artist.Name)
 
 			if err != nil {
 				invalid := errors.Is(err, ce.ErrDataInvalid)
@@ -113,7 +89,7 @@ func (ac *ArtistController) Create(res http.ResponseWriter, req *http.Request) {
 						http.StatusBadRequest,
 					)
 				} else {
-					logutil.Error("Failed to create artist %v. Error was: %v", artist.Name, err)
+					logutil.FromContext(req.Context()).Error("Failed to create artist", "artist", artist.Name, "error", err)
 					handleRes(
 						res,
 						ResponseError{Message: UNEXPECTED_ERROR},
@@ -131,10 +107,10 @@ func (ac *ArtistController) Create(res http.ResponseWriter, req *http.Request) {
 
 func (ac *ArtistController) GetRandom(res http.ResponseWriter, req *http.Request) {
 	// Get the artist from the service
-	artist, err := ac.ArtistService.GetRandom()
+	artist, err := ac.ArtistService.GetRandom(req.Context())
 
 	if err != nil {
-		logutil.Error("Failed to get random artist. Error was: %v", err)
+		logutil.FromContext(req.Context()).Error("Failed to get random artist", "error", err)
 		handleRes(
 			res,
 			ResponseError{Message: UNEXPECTED_ERROR},
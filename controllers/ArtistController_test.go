@@ -18,6 +18,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 const (
@@ -25,6 +26,7 @@ const (
 	artistRoute = "/artist"
 	randomRoute = "/random"
 	token       = "asdijsdfu23r329"
+	apiKey      = "ae_9dk3kfj30dkfjeid93kdf"
 )
 
 var authHeader = fmt.Sprintf("Bearer %v", token)
@@ -60,7 +62,7 @@ func TestGetArtist(t *testing.T) {
 
 	// Setup mock service
 	artistService := mocks.NewIArtistService(t)
-	artistService.EXPECT().Get(artistID).Return(&serviceRecord, nil)
+	artistService.EXPECT().Get(mock.Anything, artistID).Return(&serviceRecord, nil)
 
 	// Inject controller with service
 	artistController := ArtistController{ArtistService: artistService}
@@ -96,7 +98,7 @@ func TestGetArtistNoRecord(t *testing.T) {
 
 	// Setup mock service
 	artistService := mocks.NewIArtistService(t)
-	artistService.EXPECT().Get(artistID).Return(nil, ce.ErrRecordNotFound)
+	artistService.EXPECT().Get(mock.Anything, artistID).Return(nil, ce.ErrRecordNotFound)
 
 	// Inject controller with service
 	artistController := ArtistController{ArtistService: artistService}
@@ -132,7 +134,7 @@ func TestGetArtistUnexpectedError(t *testing.T) {
 
 	// Setup mock service
 	artistService := mocks.NewIArtistService(t)
-	artistService.EXPECT().Get(artistID).Return(nil, errors.New(weirdError))
+	artistService.EXPECT().Get(mock.Anything, artistID).Return(nil, errors.New(weirdError))
 
 	// Inject controller with service
 	artistController := ArtistController{ArtistService: artistService}
@@ -274,10 +276,10 @@ func TestCreateArtist(t *testing.T) {
 
 	// Setup mock service
 	artistService := mocks.NewIArtistService(t)
-	artistService.EXPECT().Create(vmArtist.Name).Return(&serviceRecord, nil)
+	artistService.EXPECT().Create(mock.Anything, vmArtist.Name).Return(&serviceRecord, nil)
 
 	authService := mocks.NewIAuthService(t)
-	authService.EXPECT().IsAuthorized(token).Return(true)
+	authService.EXPECT().Authorize(mock.Anything, token, models.RoleEditor).Return(nil)
 
 	// Inject controller with service
 	artistController := ArtistController{ArtistService: artistService, AuthService: authService}
@@ -328,9 +330,9 @@ func TestCreateArtistRejected(t *testing.T) {
 
 			// Setup mock service
 			artistService := mocks.NewIArtistService(t)
-			artistService.EXPECT().Create(artist.Name).Return(nil, tt.err)
+			artistService.EXPECT().Create(mock.Anything, artist.Name).Return(nil, tt.err)
 			authService := mocks.NewIAuthService(t)
-			authService.EXPECT().IsAuthorized(token).Return(true)
+			authService.EXPECT().Authorize(mock.Anything, token, models.RoleEditor).Return(nil)
 
 			// Inject controller with service
 			artistController := ArtistController{ArtistService: artistService, AuthService: authService}
@@ -372,9 +374,9 @@ func TestCreateArtistUnexpectedError(t *testing.T) {
 	// Setup mock service
 	returnError := errors.New(weirdError)
 	artistService := mocks.NewIArtistService(t)
-	artistService.EXPECT().Create(artist.Name).Return(nil, returnError)
+	artistService.EXPECT().Create(mock.Anything, artist.Name).Return(nil, returnError)
 	authService := mocks.NewIAuthService(t)
-	authService.EXPECT().IsAuthorized(token).Return(true)
+	authService.EXPECT().Authorize(mock.Anything, token, models.RoleEditor).Return(nil)
 
 	// Inject controller with service
 	artistController := ArtistController{ArtistService: artistService, AuthService: authService}
@@ -395,6 +397,74 @@ func TestCreateArtistUnexpectedError(t *testing.T) {
 	assert.Equal(t, expectedStatus, w.Result().StatusCode)
 }
 
+func TestCreateArtistApiKey(t *testing.T) {
+	var testData = []struct {
+		name   string
+		header string
+		value  string
+	}{
+		{name: "x-api-key header", header: "X-API-Key", value: apiKey},
+		{name: "authorization header", header: "Authorization", value: "ApiKey " + apiKey},
+	}
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			artistName := "James Brown"
+			vmArtist := viewmodels.ArtistVM{Name: artistName}
+			serviceRecord := models.Artist{Name: artistName}
+			serviceRecord.ID = 1
+
+			var buf bytes.Buffer
+			_ = json.NewEncoder(&buf).Encode(vmArtist)
+			req := postArtist(&buf)
+			req.Header.Add(tt.header, tt.value)
+
+			artistService := mocks.NewIArtistService(t)
+			artistService.EXPECT().Create(mock.Anything, artistName).Return(&serviceRecord, nil)
+			apiKeyService := mocks.NewIApiKeyService(t)
+			apiKeyService.EXPECT().IsAuthorized(apiKey, models.ApiKeyScopeArtistWrite).Return(true)
+
+			// No AuthService, a JWT is never checked when a key is given
+			artistController := ArtistController{ArtistService: artistService, ApiKeyService: apiKeyService}
+
+			w := httptest.NewRecorder()
+			r := chi.NewRouter()
+			r.HandleFunc(POST_ARTIST_RP, artistController.Create)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusCreated, w.Result().StatusCode)
+		})
+	}
+}
+
+func TestCreateArtistApiKeyUnauthorized(t *testing.T) {
+	artist := viewmodels.ArtistVM{Name: "James Brown"}
+
+	expectedReponseError := ResponseError{}
+	expectedReponseError.Message = UNAUTHORZIED
+	expectedStatus := http.StatusUnauthorized
+
+	var buf bytes.Buffer
+	_ = json.NewEncoder(&buf).Encode(artist)
+	req := postArtist(&buf)
+	req.Header.Add("X-API-Key", apiKey)
+
+	apiKeyService := mocks.NewIApiKeyService(t)
+	apiKeyService.EXPECT().IsAuthorized(apiKey, models.ApiKeyScopeArtistWrite).Return(false)
+
+	artistController := ArtistController{ApiKeyService: apiKeyService}
+
+	w := httptest.NewRecorder()
+	r := chi.NewRouter()
+	r.HandleFunc(POST_ARTIST_RP, artistController.Create)
+	r.ServeHTTP(w, req)
+
+	reponseErrorResult := ResponseError{}
+	json.NewDecoder(w.Body).Decode(&reponseErrorResult)
+
+	assert.Equal(t, expectedReponseError, reponseErrorResult)
+	assert.Equal(t, expectedStatus, w.Result().StatusCode)
+}
+
 func TestCreateArtistUnauthorized(t *testing.T) {
 	artistName := "James Brown"
 	artist := viewmodels.ArtistVM{Name: artistName}
@@ -409,7 +479,7 @@ func TestCreateArtistUnauthorized(t *testing.T) {
 	req.Header.Add("Authorization", authHeader)
 
 	authService := mocks.NewIAuthService(t)
-	authService.EXPECT().IsAuthorized(token).Return(false)
+	authService.EXPECT().Authorize(mock.Anything, token, models.RoleEditor).Return(ce.ErrTokenInvalid)
 
 	artistController := ArtistController{AuthService: authService}
 
@@ -452,7 +522,7 @@ func TestCreateArtistMalformedAuth(t *testing.T) {
 	artistName := "James Brown"
 	artist := viewmodels.ArtistVM{Name: artistName}
 
-	expectedStatus := http.StatusUnauthorized
+	expectedStatus := http.StatusBadRequest
 
 	var buf bytes.Buffer
 	_ = json.NewEncoder(&buf).Encode(artist)
@@ -470,6 +540,45 @@ func TestCreateArtistMalformedAuth(t *testing.T) {
 	json.NewDecoder(w.Body).Decode(&reponseErrorResult)
 
 	assert.Equal(t, expectedStatus, w.Result().StatusCode)
+	assert.Equal(t, `Bearer error="invalid_request"`, w.Result().Header.Get("WWW-Authenticate"))
+}
+
+func TestCreateArtistTokenErrors(t *testing.T) {
+	var testData = []struct {
+		name            string
+		err             error
+		status          int
+		wwwAuthenticate string
+	}{
+		{name: "malformed", err: ce.ErrTokenMalformed, status: http.StatusBadRequest,
+			wwwAuthenticate: `Bearer error="invalid_request"`},
+		{name: "expired", err: ce.ErrTokenExpired, status: http.StatusUnauthorized,
+			wwwAuthenticate: `Bearer error="invalid_token", error_description="token is expired"`},
+		{name: "invalid", err: ce.ErrTokenInvalid, status: http.StatusUnauthorized,
+			wwwAuthenticate: `Bearer error="invalid_token"`},
+		{name: "unexpected", err: errors.New(weirdError), status: http.StatusInternalServerError},
+	}
+	for _, tt := range testData {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			_ = json.NewEncoder(&buf).Encode(viewmodels.ArtistVM{Name: "James Brown"})
+			req := postArtist(&buf)
+			req.Header.Add("Authorization", authHeader)
+
+			authService := mocks.NewIAuthService(t)
+			authService.EXPECT().Authorize(mock.Anything, token, models.RoleEditor).Return(fmt.Errorf("%w: detail", tt.err))
+
+			artistController := ArtistController{AuthService: authService}
+
+			w := httptest.NewRecorder()
+			r := chi.NewRouter()
+			r.HandleFunc(POST_ARTIST_RP, artistController.Create)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Result().StatusCode)
+			assert.Equal(t, tt.wwwAuthenticate, w.Result().Header.Get("WWW-Authenticate"))
+		})
+	}
 }
 
 func TestCreateArtistBadRequest(t *testing.T) {
@@ -490,7 +599,7 @@ func TestCreateArtistBadRequest(t *testing.T) {
 	// Setup mock service
 	artistService := mocks.NewIArtistService(t)
 	authService := mocks.NewIAuthService(t)
-	authService.EXPECT().IsAuthorized(token).Return(true)
+	authService.EXPECT().Authorize(mock.Anything, token, models.RoleEditor).Return(nil)
 
 	// Inject controller with service
 	artistController := ArtistController{ArtistService: artistService, AuthService: authService}
@@ -526,7 +635,7 @@ func TestGetRandomArtist(t *testing.T) {
 
 	// Setup mock service
 	artistService := mocks.NewIArtistService(t)
-	artistService.EXPECT().GetRandom().Return(&serviceRecord, nil)
+	artistService.EXPECT().GetRandom(mock.Anything).Return(&serviceRecord, nil)
 
 	// Inject controller with service
 	artistController := ArtistController{ArtistService: artistService}
@@ -557,7 +666,7 @@ func TestGetRandomArtistUnexpectedError(t *testing.T) {
 	// Setup mock service
 	returnError := errors.New(weirdError)
 	artistService := mocks.NewIArtistService(t)
-	artistService.EXPECT().GetRandom().Return(nil, returnError)
+	artistService.EXPECT().GetRandom(mock.Anything).Return(nil, returnError)
 
 	// Inject controller with service
 	artistController := ArtistController{ArtistService: artistService}
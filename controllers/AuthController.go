@@ -2,14 +2,34 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 
+	ce "github.com/apkatsikas/artist-entities/customerrors"
+	"github.com/apkatsikas/artist-entities/infrastructures/logutil"
+	"github.com/apkatsikas/artist-entities/infrastructures/metrics"
 	"github.com/apkatsikas/artist-entities/interfaces"
+	"github.com/apkatsikas/artist-entities/models"
 	"github.com/apkatsikas/artist-entities/viewmodels"
+	"github.com/go-chi/chi/v5"
 )
 
 type AuthController struct {
-	AuthService interfaces.IAuthService
+	AuthService   interfaces.IAuthService
+	LoginThrottle interfaces.ILoginThrottle
+	// OIDCService is nil when OpenID Connect login isn't configured
+	OIDCService interfaces.IOIDCService
+}
+
+func clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
 }
 
 func (ac *AuthController) Login(res http.ResponseWriter, req *http.Request) {
@@ -17,6 +37,7 @@ func (ac *AuthController) Login(res http.ResponseWriter, req *http.Request) {
 	decoder := json.NewDecoder(req.Body)
 	decoder.DisallowUnknownFields()
 	decodeError := decoder.Decode(&user)
+	ip := clientIP(req)
 
 	if decodeError != nil {
 		handleRes(
@@ -30,17 +51,167 @@ func (ac *AuthController) Login(res http.ResponseWriter, req *http.Request) {
 			ResponseError{Message: BAD_REQUEST},
 			http.StatusBadRequest,
 		)
+	} else if wait := ac.LoginThrottle.RetryAfter(user.UserName, ip); wait > 0 {
+		logutil.FromContext(req.Context()).Warn("Throttled login", "audit", true, "user", user.UserName, "ip", ip)
+		res.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		handleRes(
+			res,
+			ResponseError{Message: TOO_MANY_REQUESTS},
+			http.StatusTooManyRequests,
+		)
 	} else {
-		jwt, err := ac.AuthService.GenerateJWT(user.UserName, user.Password)
+		jwt, err := ac.AuthService.GenerateJWT(req.Context(), user.UserName, user.Password)
 
-		if err != nil {
+		metrics.ObserveLogin(err == nil)
+		if err != nil && !errors.Is(err, ce.ErrRecordNotFound) && !errors.Is(err, ce.ErrCredentialsInvalid) {
+			// Not the user's fault, so it doesn't count towards throttling them
+			logutil.FromContext(req.Context()).Error("Failed to log in", "user", user.UserName, "error", err)
+			handleRes(
+				res,
+				ResponseError{Message: UNEXPECTED_ERROR},
+				http.StatusInternalServerError,
+			)
+		} else if err != nil {
+			failures := ac.LoginThrottle.Fail(user.UserName, ip)
+			logutil.FromContext(req.Context()).Warn("Failed login", "audit", true, "user", user.UserName, "ip", ip,
+				"consecutiveFailures", failures)
 			handleRes(
 				res,
 				ResponseError{Message: UNAUTHORZIED},
 				http.StatusUnauthorized,
 			)
 		} else {
+			ac.LoginThrottle.Succeed(user.UserName, ip)
 			handleRes(res, jwt, http.StatusOK)
 		}
 	}
 }
+
+// OIDCLogin sends the user to the OpenID Connect provider
+func (ac *AuthController) OIDCLogin(res http.ResponseWriter, req *http.Request) {
+	if ac.OIDCService == nil {
+		http.NotFound(res, req)
+		return
+	}
+
+	url, err := ac.OIDCService.AuthCodeURL(req.Context())
+	if err != nil {
+		logutil.FromContext(req.Context()).Error("Failed to start OIDC login", "error", err)
+		handleRes(
+			res,
+			ResponseError{Message: UNEXPECTED_ERROR},
+			http.StatusInternalServerError,
+		)
+	} else {
+		http.Redirect(res, req, url, http.StatusFound)
+	}
+}
+
+// OIDCCallback finishes an OpenID Connect login, responding with our own JWT
+func (ac *AuthController) OIDCCallback(res http.ResponseWriter, req *http.Request) {
+	if ac.OIDCService == nil {
+		http.NotFound(res, req)
+		return
+	}
+
+	query := req.URL.Query()
+	state, code := query.Get("state"), query.Get("code")
+	ip := clientIP(req)
+
+	if query.Get("error") != "" || state == "" || code == "" {
+		logutil.FromContext(req.Context()).Warn("Failed OIDC login", "audit", true, "ip", ip, "providerError", query.Get("error"))
+		handleRes(
+			res,
+			ResponseError{Message: BAD_REQUEST},
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	jwt, err := ac.OIDCService.Exchange(req.Context(), state, code)
+	if err != nil {
+		logutil.FromContext(req.Context()).Warn("Failed OIDC login", "audit", true, "ip", ip, "error", err)
+		if errors.Is(err, ce.ErrLoginStateInvalid) {
+			handleRes(
+				res,
+				ResponseError{Message: BAD_REQUEST},
+				http.StatusBadRequest,
+			)
+		} else if errors.Is(err, ce.ErrTokenInvalid) {
+			handleRes(
+				res,
+				ResponseError{Message: UNAUTHORZIED},
+				http.StatusUnauthorized,
+			)
+		} else if errors.Is(err, ce.ErrIdentityNotAllowed) {
+			handleRes(
+				res,
+				ResponseError{Message: FORBIDDEN},
+				http.StatusForbidden,
+			)
+		} else {
+			logutil.FromContext(req.Context()).Error("Failed to complete OIDC login", "error", err)
+			handleRes(
+				res,
+				ResponseError{Message: UNEXPECTED_ERROR},
+				http.StatusInternalServerError,
+			)
+		}
+	} else {
+		logutil.FromContext(req.Context()).Info("OIDC login", "audit", true, "ip", ip)
+		handleRes(res, jwt, http.StatusOK)
+	}
+}
+
+// JWKS publishes our public signing keys for other services
+func (ac *AuthController) JWKS(res http.ResponseWriter, req *http.Request) {
+	res.Header().Set("Content-Type", "application/json")
+	res.Header().Set("Cache-Control", "public, max-age=300")
+	encodeRes(res, ac.AuthService.JWKS(req.Context()))
+}
+
+// Logout revokes the bearer token used to call it
+func (ac *AuthController) Logout(res http.ResponseWriter, req *http.Request) {
+	token, err := getBearerToken(req)
+	if err == nil {
+		err = ac.AuthService.Logout(req.Context(), token)
+	}
+
+	if err != nil {
+		handleAuthError(res, req, err)
+	} else {
+		res.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// RevokeSessions logs a user out everywhere
+func (ac *AuthController) RevokeSessions(res http.ResponseWriter, req *http.Request) {
+	userName := chi.URLParam(req, "userName")
+
+	err := authorizeJWT(req, ac.AuthService, models.RoleAdmin)
+	if err != nil {
+		handleAuthError(res, req, err)
+	} else {
+		err := ac.AuthService.RevokeSessions(req.Context(), userName)
+
+		if err != nil {
+			if errors.Is(err, ce.ErrRecordNotFound) {
+				handleRes(
+					res,
+					ResponseError{Message: err.Error()},
+					http.StatusNotFound,
+				)
+			} else {
+				logutil.FromContext(req.Context()).Error("Failed to revoke sessions", "user", userName, "error", err)
+				handleRes(
+					res,
+					ResponseError{Message: UNEXPECTED_ERROR},
+					http.StatusInternalServerError,
+				)
+			}
+		} else {
+			logutil.FromContext(req.Context()).Warn("Revoked all sessions", "audit", true, "user", userName, "ip", clientIP(req))
+			res.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
@@ -7,15 +7,22 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	ce "github.com/apkatsikas/artist-entities/customerrors"
 	"github.com/apkatsikas/artist-entities/interfaces/mocks"
+	"github.com/apkatsikas/artist-entities/models"
 	"github.com/apkatsikas/artist-entities/viewmodels"
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
+// remoteIP is the address httptest assigns to requests
+const remoteIP = "192.0.2.1"
+
 type AuthControllerTestSuite struct {
 	suite.Suite
 	authController *AuthController
@@ -33,9 +40,13 @@ func (suite *AuthControllerTestSuite) TestLogin() {
 	password := "password"
 
 	authService := mocks.NewIAuthService(suite.T())
-	authService.EXPECT().GenerateJWT(userName, password).Return(expectedToken, nil)
+	authService.EXPECT().GenerateJWT(mock.Anything, userName, password).Return(expectedToken, nil)
+
+	loginThrottle := mocks.NewILoginThrottle(suite.T())
+	loginThrottle.EXPECT().RetryAfter(userName, remoteIP).Return(0)
+	loginThrottle.EXPECT().Succeed(userName, remoteIP).Return()
 
-	suite.authController = &AuthController{AuthService: authService}
+	suite.authController = &AuthController{AuthService: authService, LoginThrottle: loginThrottle}
 
 	loginJSON, err := json.Marshal(&viewmodels.UserVM{
 		UserName: userName,
@@ -98,6 +109,30 @@ func (suite *AuthControllerTestSuite) TestLoginIncompletePayload() {
 	assert.Equal(suite.T(), expectedStatus, w.Result().StatusCode)
 }
 
+func (suite *AuthControllerTestSuite) TestLoginError() {
+	userName := "user"
+	password := "pass"
+
+	authService := mocks.NewIAuthService(suite.T())
+	authService.EXPECT().GenerateJWT(mock.Anything, userName, password).Return("", fmt.Errorf("database is locked"))
+
+	// Nothing counts against the user
+	loginThrottle := mocks.NewILoginThrottle(suite.T())
+	loginThrottle.EXPECT().RetryAfter(userName, remoteIP).Return(0)
+
+	suite.authController = &AuthController{AuthService: authService, LoginThrottle: loginThrottle}
+
+	loginJSON, err := json.Marshal(&viewmodels.UserVM{
+		UserName: userName,
+		Password: password,
+	})
+	require.NoError(suite.T(), err)
+
+	w := suite.doRequest(loginJSON)
+
+	assert.Equal(suite.T(), http.StatusInternalServerError, w.Result().StatusCode)
+}
+
 func (suite *AuthControllerTestSuite) TestLoginUnauthorized() {
 	expectedStatus := http.StatusUnauthorized
 
@@ -105,9 +140,13 @@ func (suite *AuthControllerTestSuite) TestLoginUnauthorized() {
 	password := "pass"
 
 	authService := mocks.NewIAuthService(suite.T())
-	authService.EXPECT().GenerateJWT(userName, password).Return("", fmt.Errorf("no"))
+	authService.EXPECT().GenerateJWT(mock.Anything, userName, password).Return("", ce.ErrCredentialsInvalid)
+
+	loginThrottle := mocks.NewILoginThrottle(suite.T())
+	loginThrottle.EXPECT().RetryAfter(userName, remoteIP).Return(0)
+	loginThrottle.EXPECT().Fail(userName, remoteIP).Return(1)
 
-	suite.authController = &AuthController{AuthService: authService}
+	suite.authController = &AuthController{AuthService: authService, LoginThrottle: loginThrottle}
 
 	loginJSON, err := json.Marshal(&viewmodels.UserVM{
 		UserName: userName,
@@ -124,6 +163,115 @@ func (suite *AuthControllerTestSuite) TestLoginUnauthorized() {
 	assert.Equal(suite.T(), expectedStatus, w.Result().StatusCode)
 }
 
+func (suite *AuthControllerTestSuite) TestLoginThrottled() {
+	expectedStatus := http.StatusTooManyRequests
+
+	userName := "user"
+	password := "pass"
+
+	// No call to the auth service is expected while throttled
+	authService := mocks.NewIAuthService(suite.T())
+
+	loginThrottle := mocks.NewILoginThrottle(suite.T())
+	loginThrottle.EXPECT().RetryAfter(userName, remoteIP).Return(1500 * time.Millisecond)
+
+	suite.authController = &AuthController{AuthService: authService, LoginThrottle: loginThrottle}
+
+	loginJSON, err := json.Marshal(&viewmodels.UserVM{
+		UserName: userName,
+		Password: password,
+	})
+	require.NoError(suite.T(), err)
+
+	w := suite.doRequest(loginJSON)
+
+	assert.Equal(suite.T(), expectedStatus, w.Result().StatusCode)
+	assert.Equal(suite.T(), "2", w.Result().Header.Get("Retry-After"))
+}
+
+func (suite *AuthControllerTestSuite) TestJWKS() {
+	jwks := viewmodels.JwksVM{Keys: []viewmodels.JwkVM{{Kty: "OKP", Kid: "ed", Alg: "EdDSA", Crv: "Ed25519", X: "abc"}}}
+
+	authService := mocks.NewIAuthService(suite.T())
+	authService.EXPECT().JWKS(mock.Anything).Return(jwks)
+
+	authController := &AuthController{AuthService: authService}
+
+	req := httptest.NewRequest(http.MethodGet, JWKS_RP, nil)
+	w := httptest.NewRecorder()
+	r := chi.NewRouter()
+	r.Get(JWKS_RP, authController.JWKS)
+	r.ServeHTTP(w, req)
+
+	var result viewmodels.JwksVM
+	json.NewDecoder(w.Body).Decode(&result)
+
+	assert.Equal(suite.T(), http.StatusOK, w.Result().StatusCode)
+	assert.Equal(suite.T(), jwks, result)
+}
+
+func (suite *AuthControllerTestSuite) TestLogout() {
+	authService := mocks.NewIAuthService(suite.T())
+	authService.EXPECT().Logout(mock.Anything, "abc").Return(nil)
+
+	authController := &AuthController{AuthService: authService}
+
+	req := httptest.NewRequest(http.MethodPost, LOGOUT, nil)
+	req.Header.Add("Authorization", "Bearer abc")
+	w := httptest.NewRecorder()
+	r := chi.NewRouter()
+	r.Post(LOGOUT, authController.Logout)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusNoContent, w.Result().StatusCode)
+}
+
+func (suite *AuthControllerTestSuite) TestLogoutInvalidToken() {
+	authService := mocks.NewIAuthService(suite.T())
+	authService.EXPECT().Logout(mock.Anything, "abc").Return(ce.ErrTokenInvalid)
+
+	authController := &AuthController{AuthService: authService}
+
+	req := httptest.NewRequest(http.MethodPost, LOGOUT, nil)
+	req.Header.Add("Authorization", "Bearer abc")
+	w := httptest.NewRecorder()
+	r := chi.NewRouter()
+	r.Post(LOGOUT, authController.Logout)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Result().StatusCode)
+}
+
+func (suite *AuthControllerTestSuite) TestRevokeSessions() {
+	var testData = []struct {
+		test   string
+		err    error
+		status int
+	}{
+		{test: "revoked", status: http.StatusNoContent},
+		{test: "no such user", err: ce.ErrRecordNotFound, status: http.StatusNotFound},
+		{test: "unexpected", err: fmt.Errorf("locked"), status: http.StatusInternalServerError},
+	}
+	for _, tt := range testData {
+		suite.Run(tt.test, func() {
+			authService := mocks.NewIAuthService(suite.T())
+			authService.EXPECT().Authorize(mock.Anything, "abc", models.RoleAdmin).Return(nil)
+			authService.EXPECT().RevokeSessions(mock.Anything, "bob").Return(tt.err)
+
+			authController := &AuthController{AuthService: authService}
+
+			req := httptest.NewRequest(http.MethodPost, "/user/bob/revoke-sessions", nil)
+			req.Header.Add("Authorization", "Bearer abc")
+			w := httptest.NewRecorder()
+			r := chi.NewRouter()
+			r.Post(REVOKE_SESSIONS_RP, authController.RevokeSessions)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(suite.T(), tt.status, w.Result().StatusCode)
+		})
+	}
+}
+
 func (suite *AuthControllerTestSuite) doRequest(payload []byte) *httptest.ResponseRecorder {
 	req := httptest.NewRequest(http.MethodPost, LOGIN, bytes.NewBuffer(payload))
 	w := httptest.NewRecorder()
@@ -132,3 +280,76 @@ func (suite *AuthControllerTestSuite) doRequest(payload []byte) *httptest.Respon
 	r.ServeHTTP(w, req)
 	return w
 }
+
+func (suite *AuthControllerTestSuite) TestOIDCLogin() {
+	oidcService := mocks.NewIOIDCService(suite.T())
+	oidcService.EXPECT().AuthCodeURL(mock.Anything).Return("https://issuer.example.com/authorize?state=abc", nil)
+
+	suite.authController = &AuthController{OIDCService: oidcService}
+
+	req := httptest.NewRequest(http.MethodGet, OIDC_LOGIN, nil)
+	w := httptest.NewRecorder()
+	suite.authController.OIDCLogin(w, req)
+
+	assert.Equal(suite.T(), http.StatusFound, w.Code)
+	assert.Equal(suite.T(), "https://issuer.example.com/authorize?state=abc", w.Header().Get("Location"))
+}
+
+func (suite *AuthControllerTestSuite) TestOIDCLoginNotConfigured() {
+	suite.authController = &AuthController{}
+
+	req := httptest.NewRequest(http.MethodGet, OIDC_LOGIN, nil)
+	w := httptest.NewRecorder()
+	suite.authController.OIDCLogin(w, req)
+
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+func (suite *AuthControllerTestSuite) TestOIDCCallback() {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "success", err: nil, expectedStatus: http.StatusOK},
+		{name: "bad state", err: ce.ErrLoginStateInvalid, expectedStatus: http.StatusBadRequest},
+		{name: "invalid token", err: fmt.Errorf("%w: bad nonce", ce.ErrTokenInvalid),
+			expectedStatus: http.StatusUnauthorized},
+		{name: "not allowed", err: ce.ErrIdentityNotAllowed, expectedStatus: http.StatusForbidden},
+		{name: "unexpected", err: fmt.Errorf("provider is down"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			token := ""
+			if tt.err == nil {
+				token = "foo"
+			}
+			oidcService := mocks.NewIOIDCService(suite.T())
+			oidcService.EXPECT().Exchange(mock.Anything, "state", "code").Return(token, tt.err)
+
+			suite.authController = &AuthController{OIDCService: oidcService}
+
+			req := httptest.NewRequest(http.MethodGet, OIDC_CALLBACK+"?state=state&code=code", nil)
+			w := httptest.NewRecorder()
+			suite.authController.OIDCCallback(w, req)
+
+			assert.Equal(suite.T(), tt.expectedStatus, w.Code)
+			if tt.err == nil {
+				var jwt string
+				json.NewDecoder(w.Body).Decode(&jwt)
+				assert.Equal(suite.T(), token, jwt)
+			}
+		})
+	}
+}
+
+func (suite *AuthControllerTestSuite) TestOIDCCallbackProviderError() {
+	suite.authController = &AuthController{OIDCService: mocks.NewIOIDCService(suite.T())}
+
+	req := httptest.NewRequest(http.MethodGet, OIDC_CALLBACK+"?state=state&error=access_denied", nil)
+	w := httptest.NewRecorder()
+	suite.authController.OIDCCallback(w, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	ce "github.com/apkatsikas/artist-entities/customerrors"
+	"github.com/apkatsikas/artist-entities/infrastructures/logutil"
+	"github.com/apkatsikas/artist-entities/interfaces"
+)
+
+const (
+	apiKeyHeader = "X-API-Key"
+	apiKeyScheme = "ApiKey"
+
+	wwwAuthenticate = "WWW-Authenticate"
+	// Error descriptions clients can rely on, see goclient
+	expiredDescription = "token is expired"
+)
+
+var (
+	errAuthorizationMissing   = errors.New("authorization header is missing")
+	errAuthorizationMalformed = errors.New("invalid Authorization header format")
+	errApiKeyUnauthorized     = errors.New(UNAUTHORZIED)
+)
+
+func getBearerToken(req *http.Request) (string, error) {
+	authHeader := req.Header.Get("Authorization")
+	if authHeader == "" {
+		return "", errAuthorizationMissing
+	}
+
+	splitBySpace := strings.Split(authHeader, " ")
+	if len(splitBySpace) != 2 || splitBySpace[0] != "Bearer" {
+		return "", errAuthorizationMalformed
+	}
+
+	token := splitBySpace[1]
+	return token, nil
+}
+
+// getApiKey returns the key from X-API-Key or an "Authorization: ApiKey" header
+// Returns blank if the request doesn't carry one
+func getApiKey(req *http.Request) string {
+	if key := req.Header.Get(apiKeyHeader); key != "" {
+		return key
+	}
+
+	splitBySpace := strings.Split(req.Header.Get("Authorization"), " ")
+	if len(splitBySpace) == 2 && splitBySpace[0] == apiKeyScheme {
+		return splitBySpace[1]
+	}
+	return ""
+}
+
+// authorizeJWT checks the request carries a valid bearer token for a user with the role
+func authorizeJWT(req *http.Request, authService interfaces.IAuthService, role string) error {
+	token, err := getBearerToken(req)
+	if err != nil {
+		return err
+	}
+	return authService.Authorize(req.Context(), token, role)
+}
+
+// authorizeJWTOrApiKey checks the request carries an API key with the scope,
+// falling back to a bearer token for a user with the role if there's no key
+func authorizeJWTOrApiKey(req *http.Request, authService interfaces.IAuthService,
+	apiKeyService interfaces.IApiKeyService, scope string, role string) error {
+	key := getApiKey(req)
+	if key == "" {
+		return authorizeJWT(req, authService, role)
+	}
+	if !apiKeyService.IsAuthorized(key, scope) {
+		return errApiKeyUnauthorized
+	}
+	return nil
+}
+
+// handleAuthError responds to a failed authorization as RFC 6750 describes
+// Malformed requests get a 400, bad or expired tokens a 401 with invalid_token
+func handleAuthError(res http.ResponseWriter, req *http.Request, err error) {
+	switch {
+	case errors.Is(err, errAuthorizationMissing):
+		res.Header().Set(wwwAuthenticate, `Bearer`)
+		handleRes(res, ResponseError{Message: err.Error()}, http.StatusUnauthorized)
+	case errors.Is(err, errAuthorizationMalformed), errors.Is(err, ce.ErrTokenMalformed):
+		res.Header().Set(wwwAuthenticate, `Bearer error="invalid_request"`)
+		handleRes(res, ResponseError{Message: BAD_REQUEST}, http.StatusBadRequest)
+	case errors.Is(err, ce.ErrTokenExpired):
+		res.Header().Set(wwwAuthenticate,
+			`Bearer error="invalid_token", error_description="`+expiredDescription+`"`)
+		handleRes(res, ResponseError{Message: expiredDescription}, http.StatusUnauthorized)
+	case errors.Is(err, ce.ErrTokenInvalid):
+		res.Header().Set(wwwAuthenticate, `Bearer error="invalid_token"`)
+		handleRes(res, ResponseError{Message: UNAUTHORZIED}, http.StatusUnauthorized)
+	case errors.Is(err, errApiKeyUnauthorized):
+		handleRes(res, ResponseError{Message: UNAUTHORZIED}, http.StatusUnauthorized)
+	case errors.Is(err, ce.ErrForbidden):
+		handleRes(res, ResponseError{Message: FORBIDDEN}, http.StatusForbidden)
+	default:
+		logutil.FromContext(req.Context()).Error("Failed to authorize request", "error", err)
+		handleRes(res, ResponseError{Message: UNEXPECTED_ERROR}, http.StatusInternalServerError)
+	}
+}
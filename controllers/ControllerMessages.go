@@ -3,3 +3,5 @@ package controllers
 const UNEXPECTED_ERROR = "Unexpected error."
 const BAD_REQUEST = "Bad request."
 const UNAUTHORZIED = "Unauthorized."
+const TOO_MANY_REQUESTS = "Too many requests."
+const FORBIDDEN = "Forbidden."
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/apkatsikas/artist-entities/interfaces"
+	"github.com/apkatsikas/artist-entities/viewmodels"
+)
+
+const (
+	STATUS_OK   = "ok"
+	STATUS_FAIL = "fail"
+)
+
+// HealthController is for load balancers and orchestrators, it needs no auth
+type HealthController struct {
+	HealthService interfaces.IHealthService
+}
+
+func status(ok bool) string {
+	if ok {
+		return STATUS_OK
+	}
+	return STATUS_FAIL
+}
+
+// Healthz responds while the process is alive
+func (hc *HealthController) Healthz(res http.ResponseWriter, req *http.Request) {
+	handleRes(res, viewmodels.StatusVM{Status: STATUS_OK}, http.StatusOK)
+}
+
+// Readyz responds 503 unless every check passed
+func (hc *HealthController) Readyz(res http.ResponseWriter, req *http.Request) {
+	readiness := hc.HealthService.Ready(req.Context())
+	vm := viewmodels.ReadinessVM{
+		Status: status(readiness.Ready()),
+		Checks: map[string]viewmodels.HealthCheckVM{},
+	}
+	for _, check := range readiness.Checks {
+		vm.Checks[check.Name] = viewmodels.HealthCheckVM{
+			Status:     status(check.Passed()),
+			DurationMs: check.Duration.Milliseconds(),
+		}
+	}
+	statusCode := http.StatusOK
+	if !readiness.Ready() {
+		statusCode = http.StatusServiceUnavailable
+	}
+	// Proxies mustn't keep it, the service only reuses results briefly
+	res.Header().Set("Cache-Control", "no-store")
+	handleRes(res, vm, statusCode)
+}
+
+func (hc *HealthController) Version(res http.ResponseWriter, req *http.Request) {
+	info := hc.HealthService.Version()
+	handleRes(res, viewmodels.VersionVM{
+		Version:   info.Version,
+		Commit:    info.Commit,
+		BuildTime: info.BuildTime,
+		GoVersion: info.GoVersion,
+	}, http.StatusOK)
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/interfaces/mocks"
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/apkatsikas/artist-entities/viewmodels"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func healthRouter(healthController *HealthController) *chi.Mux {
+	r := chi.NewRouter()
+	r.Get(HEALTHZ_RP, healthController.Healthz)
+	r.Get(READYZ_RP, healthController.Readyz)
+	r.Get(VERSION_RP, healthController.Version)
+	return r
+}
+
+func TestHealthz(t *testing.T) {
+	healthController := &HealthController{HealthService: mocks.NewIHealthService(t)}
+
+	w := httptest.NewRecorder()
+	healthRouter(healthController).ServeHTTP(w, httptest.NewRequest(http.MethodGet, HEALTHZ_RP, nil))
+
+	var vm viewmodels.StatusVM
+	json.NewDecoder(w.Body).Decode(&vm)
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	assert.Equal(t, STATUS_OK, vm.Status)
+}
+
+func TestReadyz(t *testing.T) {
+	var testData = []struct {
+		test   string
+		checks []models.HealthCheck
+		status int
+		result string
+	}{
+		{test: "ready", checks: []models.HealthCheck{{Name: "database"}, {Name: "storage"}},
+			status: http.StatusOK, result: STATUS_OK},
+		{test: "not ready", checks: []models.HealthCheck{{Name: "database"}, {Name: "storage", Error: "forbidden"}},
+			status: http.StatusServiceUnavailable, result: STATUS_FAIL},
+	}
+	for _, tt := range testData {
+		t.Run(tt.test, func(t *testing.T) {
+			healthService := mocks.NewIHealthService(t)
+			healthService.EXPECT().Ready(mock.Anything).Return(models.Readiness{Checks: tt.checks})
+			healthController := &HealthController{HealthService: healthService}
+
+			w := httptest.NewRecorder()
+			healthRouter(healthController).ServeHTTP(w, httptest.NewRequest(http.MethodGet, READYZ_RP, nil))
+
+			var vm viewmodels.ReadinessVM
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&vm))
+			assert.Equal(t, tt.status, w.Result().StatusCode)
+			assert.Equal(t, tt.result, vm.Status)
+			require.Len(t, vm.Checks, len(tt.checks))
+			for _, check := range tt.checks {
+				assert.Equal(t, status(check.Passed()), vm.Checks[check.Name].Status)
+			}
+			// Errors can name buckets and paths, they're only logged
+			assert.NotContains(t, w.Body.String(), "forbidden")
+		})
+	}
+}
+
+func TestVersion(t *testing.T) {
+	healthService := mocks.NewIHealthService(t)
+	healthService.EXPECT().Version().Return(models.BuildInfo{Version: "v1.2.0", Commit: "abc123",
+		BuildTime: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC).Format(time.RFC3339), GoVersion: "go1.24.0"})
+	healthController := &HealthController{HealthService: healthService}
+
+	w := httptest.NewRecorder()
+	healthRouter(healthController).ServeHTTP(w, httptest.NewRequest(http.MethodGet, VERSION_RP, nil))
+
+	var vm viewmodels.VersionVM
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&vm))
+	assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+	assert.Equal(t, viewmodels.VersionVM{Version: "v1.2.0", Commit: "abc123",
+		BuildTime: "2026-01-02T00:00:00Z", GoVersion: "go1.24.0"}, vm)
+}
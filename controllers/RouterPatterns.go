@@ -5,3 +5,21 @@ const RANDOM_ARTIST_RP = "/artist/random"
 const POST_ARTIST_RP = "/artist"
 
 const LOGIN = "/login"
+const OIDC_LOGIN = "/login/oidc"
+const OIDC_CALLBACK = "/login/oidc/callback"
+const LOGOUT = "/logout"
+const REVOKE_SESSIONS_RP = "/user/{userName}/revoke-sessions"
+const JWKS_RP = "/.well-known/jwks.json"
+
+const API_KEY_RP = "/apikey"
+const API_KEY_ID_RP = "/apikey/{apiKeyID}"
+
+const ADMIN_BACKUP_RP = "/admin/backup"
+const ADMIN_BACKUPS_RP = "/admin/backups"
+const ADMIN_RESTORE_RP = "/admin/restore"
+const ADMIN_JOBS_RP = "/admin/jobs"
+
+const HEALTHZ_RP = "/healthz"
+const READYZ_RP = "/readyz"
+const VERSION_RP = "/version"
+const METRICS_RP = "/metrics"
@@ -9,3 +9,19 @@ var ErrRecordExists = errors.New("record already exists")
 var ErrDataTooLong = errors.New("data is too long")
 
 var ErrDataInvalid = errors.New("data is invalid")
+
+var ErrCredentialsInvalid = errors.New("user name or password is wrong")
+
+var ErrTokenInvalid = errors.New("token is invalid")
+
+var ErrTokenExpired = errors.New("token is expired")
+
+var ErrTokenMalformed = errors.New("token is malformed")
+
+var ErrLoginStateInvalid = errors.New("login state is invalid or expired")
+
+var ErrIdentityNotAllowed = errors.New("identity is not allowed to log in")
+
+var ErrForbidden = errors.New("user does not have the role")
+
+var ErrBackupInProgress = errors.New("a backup or restore is already in progress")
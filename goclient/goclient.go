@@ -3,11 +3,13 @@ package goclient
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	urlLib "net/url"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/apkatsikas/artist-entities/viewmodels"
@@ -18,6 +20,17 @@ const (
 	artistStr = "artist"
 )
 
+var (
+	// ErrTokenExpired means the JWT should be refreshed with Login
+	ErrTokenExpired = errors.New("token is expired")
+	// ErrTokenInvalid means the JWT was rejected, logging in again may help
+	ErrTokenInvalid = errors.New("token is invalid")
+	// ErrTokenMalformed means the JWT or Authorization header was not understood
+	ErrTokenMalformed = errors.New("token is malformed")
+	// ErrUnauthorized means the request had no usable credentials
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
 // ResponseMetadata represents an http response minus the body
 type ResponseMetadata struct {
 	StatusCode int
@@ -37,10 +50,12 @@ type ArtistResponse struct {
 }
 
 // BackendClient represents an API client for an http service
+// ApiKey is used instead of JwtToken when set
 type BackendClient struct {
 	baseURL    string
 	httpClient *http.Client
 	JwtToken   string
+	ApiKey     string
 }
 
 // New returns a BackendClient for a given baseURL
@@ -80,6 +95,28 @@ func (bc *BackendClient) sendRequest(url *urlLib.URL, httpMethod string, body io
 	return res, nil
 }
 
+// authError turns an auth failure into one of the token errors
+// Returns nil for any other response
+func authError(res *http.Response) error {
+	challenge := res.Header.Get("WWW-Authenticate")
+
+	switch res.StatusCode {
+	case http.StatusBadRequest:
+		if strings.Contains(challenge, `error="invalid_request"`) {
+			return ErrTokenMalformed
+		}
+	case http.StatusUnauthorized:
+		if strings.Contains(challenge, `error="invalid_token"`) {
+			if strings.Contains(challenge, "expired") {
+				return ErrTokenExpired
+			}
+			return ErrTokenInvalid
+		}
+		return ErrUnauthorized
+	}
+	return nil
+}
+
 // GetArtist calls the /artist endpoint for a given id and returns the Artist
 func (bc *BackendClient) GetArtist(id string) (*ArtistResponse, error) {
 	// Setup our artist
@@ -185,7 +222,11 @@ func (bc *BackendClient) CreateArtist(name string) (*ArtistResponse, error) {
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %v", bc.JwtToken))
+	if bc.ApiKey != "" {
+		req.Header.Add("X-API-Key", bc.ApiKey)
+	} else {
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %v", bc.JwtToken))
+	}
 
 	res, err := bc.httpClient.Do(req)
 	if err != nil {
@@ -194,6 +235,12 @@ func (bc *BackendClient) CreateArtist(name string) (*ArtistResponse, error) {
 
 	defer res.Body.Close()
 
+	// Surface auth failures so callers know whether to log in again
+	err = authError(res)
+	if err != nil {
+		return nil, err
+	}
+
 	// Decode and return the response
 	err = json.NewDecoder(res.Body).Decode(&artist)
 	if err != nil {
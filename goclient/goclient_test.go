@@ -0,0 +1,58 @@
+package goclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateArtistAuthErrors(t *testing.T) {
+	var testData = []struct {
+		test            string
+		status          int
+		wwwAuthenticate string
+		err             error
+	}{
+		{test: "expired", status: http.StatusUnauthorized,
+			wwwAuthenticate: `Bearer error="invalid_token", error_description="token is expired"`,
+			err:             ErrTokenExpired},
+		{test: "invalid", status: http.StatusUnauthorized,
+			wwwAuthenticate: `Bearer error="invalid_token"`, err: ErrTokenInvalid},
+		{test: "malformed", status: http.StatusBadRequest,
+			wwwAuthenticate: `Bearer error="invalid_request"`, err: ErrTokenMalformed},
+		{test: "missing", status: http.StatusUnauthorized,
+			wwwAuthenticate: `Bearer`, err: ErrUnauthorized},
+	}
+	for _, tt := range testData {
+		t.Run(tt.test, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("WWW-Authenticate", tt.wwwAuthenticate)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"Message":"nope"}`))
+			}))
+			defer server.Close()
+
+			client := New(server.URL)
+			client.JwtToken = "token"
+
+			res, err := client.CreateArtist("james brown")
+			assert.ErrorIs(t, err, tt.err)
+			assert.Nil(t, res)
+		})
+	}
+}
+
+func TestCreateArtistBadData(t *testing.T) {
+	// A 400 about the artist itself is still returned as a response
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"Message":"data is invalid"}`))
+	}))
+	defer server.Close()
+
+	res, err := New(server.URL).CreateArtist("wow,wow")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
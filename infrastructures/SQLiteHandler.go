@@ -1,27 +1,177 @@
 package infrastructures
 
 import (
+    "context"
+    "database/sql"
+    "fmt"
+    "os"
+    "sync"
+    "time"
+
+    "github.com/apkatsikas/artist-entities/infrastructures/logutil"
+    "github.com/apkatsikas/artist-entities/infrastructures/metrics"
+    "github.com/apkatsikas/artist-entities/infrastructures/tracing"
+    "github.com/mattn/go-sqlite3"
     "gorm.io/driver/sqlite"
     "gorm.io/gorm"
 )
 
+// walDriver opens connections in WAL mode that never checkpoint on their own,
+// leaving checkpoints to the replicator so no frames go unshipped
+const walDriver = "sqlite3_wal"
+
+// Queries slower than this are logged as warnings
+const slowQuery = 200 * time.Millisecond
+
+func init() {
+    sql.Register(walDriver, &sqlite3.SQLiteDriver{
+        ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+            _, err := conn.Exec("PRAGMA journal_mode = WAL; PRAGMA busy_timeout = 5000; PRAGMA wal_autocheckpoint = 0;", nil)
+            return err
+        },
+    })
+}
+
 type SQLiteHandler struct {
+    // WAL opens the database in WAL mode for replication, set it before connecting
+    WAL bool
+
+    mu   sync.RWMutex
+    dsn  string
     conn *gorm.DB
 }
 
 func (handler *SQLiteHandler) Connection() *gorm.DB {
+    handler.mu.RLock()
+    defer handler.mu.RUnlock()
     return handler.conn
 }
 
-func (handler *SQLiteHandler) ConnectSQLite(dsn string) error {
-    db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
-        // These logs do not go to a log file
-        // Could be turned off on the local console with this line
-        //Logger: logger.Default.LogMode(logger.Silent)
+// Path is the database file
+func (handler *SQLiteHandler) Path() string {
+    handler.mu.RLock()
+    defer handler.mu.RUnlock()
+    return handler.dsn
+}
+
+func (handler *SQLiteHandler) open(dsn string) (*gorm.DB, error) {
+    dialector := sqlite.Open(dsn)
+    if handler.WAL {
+        dialector = &sqlite.Dialector{DriverName: walDriver, DSN: dsn}
+    }
+    db, err := gorm.Open(dialector, &gorm.Config{
+        Logger: logutil.GormLogger{SlowThreshold: slowQuery},
     })
+    if err != nil {
+        return nil, err
+    }
+    // Time queries for /metrics and trace them
+    err = db.Use(metrics.GormPlugin{})
+    if err != nil {
+        return nil, err
+    }
+    err = db.Use(tracing.GormPlugin{})
+    if err != nil {
+        return nil, err
+    }
+    return db, nil
+}
+
+func (handler *SQLiteHandler) ConnectSQLite(dsn string) error {
+    db, err := handler.open(dsn)
     if err != nil {
         return err
     }
+    handler.mu.Lock()
+    handler.dsn = dsn
     handler.conn = db
+    handler.mu.Unlock()
     return nil
 }
+
+// Ping checks the database can still be queried
+func (handler *SQLiteHandler) Ping(ctx context.Context) error {
+    return handler.Connection().WithContext(ctx).Exec("SELECT 1").Error
+}
+
+// Close closes the database, it can't be used afterwards
+func (handler *SQLiteHandler) Close() error {
+    handler.mu.Lock()
+    defer handler.mu.Unlock()
+
+    sqlDB, err := handler.conn.DB()
+    if err != nil {
+        return err
+    }
+    return sqlDB.Close()
+}
+
+// WriteLocked runs fc while no other connection can write
+func (handler *SQLiteHandler) WriteLocked(fc func() error) error {
+    sqlDB, err := handler.Connection().DB()
+    if err != nil {
+        return err
+    }
+    ctx := context.Background()
+    conn, err := sqlDB.Conn(ctx)
+    if err != nil {
+        return err
+    }
+    defer conn.Close()
+
+    _, err = conn.ExecContext(ctx, "BEGIN IMMEDIATE")
+    if err != nil {
+        return err
+    }
+    defer conn.ExecContext(ctx, "ROLLBACK")
+
+    return fc()
+}
+
+// Checkpoint copies WAL frames into the database file without waiting on anyone
+// It's true when every frame was copied, open reads can hold some back
+func (handler *SQLiteHandler) Checkpoint() (bool, error) {
+    var busy, frames, checkpointed int
+    err := handler.Connection().Raw("PRAGMA wal_checkpoint(PASSIVE)").Row().Scan(&busy, &frames, &checkpointed)
+    if err != nil {
+        return false, err
+    }
+    return busy == 0 && frames == checkpointed, nil
+}
+
+// Replace atomically moves file over the database and reconnects to it
+// file must be on the same filesystem as the database
+func (handler *SQLiteHandler) Replace(file string) error {
+    handler.mu.Lock()
+    defer handler.mu.Unlock()
+
+    sqlDB, err := handler.conn.DB()
+    if err != nil {
+        return err
+    }
+    err = sqlDB.Close()
+    if err != nil {
+        return err
+    }
+
+    renameErr := os.Rename(file, handler.dsn)
+    if renameErr == nil && handler.WAL {
+        // A connection still open elsewhere keeps the old WAL around,
+        // it must not be replayed into the new database
+        for _, suffix := range []string{"-wal", "-shm"} {
+            err = os.Remove(handler.dsn + suffix)
+            if err != nil && !os.IsNotExist(err) && renameErr == nil {
+                renameErr = err
+            }
+        }
+    }
+
+    // Reconnect even if the rename failed, so we keep serving the old database
+    db, err := handler.open(handler.dsn)
+    if err != nil {
+        return fmt.Errorf("failed to reconnect to %v: %v", handler.dsn, err)
+    }
+    handler.conn = db
+
+    return renameErr
+}
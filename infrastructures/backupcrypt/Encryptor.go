@@ -0,0 +1,298 @@
+// Package backupcrypt encrypts backups with AES-256-GCM before they leave the host
+//
+// Each backup gets its own random data key, which is wrapped with a
+// configured key and stored in the file's header along with that key's ID.
+// The data is sealed in 64KiB chunks so it's never all in memory, and each
+// chunk's nonce counts the chunks and marks the last one, so chunks can't be
+// reordered, dropped or truncated without failing to decrypt.
+package backupcrypt
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// Algorithm is recorded in backup manifests
+const Algorithm = "AES-256-GCM"
+
+const (
+	magic     = "AEBKENC1"
+	keySize   = 32
+	chunkSize = 64 * 1024
+	// maxHeaderSize stops a corrupt length allocating a lot of memory
+	maxHeaderSize   = 4096
+	noncePrefixSize = 7
+)
+
+var (
+	ErrUnknownKey = errors.New("unknown backup encryption key")
+	ErrCorrupt    = errors.New("encrypted backup is corrupt")
+)
+
+type header struct {
+	KeyID       string `json:"kid"`
+	WrappedKey  []byte `json:"wrappedKey"`
+	NoncePrefix []byte `json:"noncePrefix"`
+	ChunkSize   int    `json:"chunkSize"`
+}
+
+// Encryptor encrypts with the active key and decrypts with whichever key a
+// backup names, so old backups can still be restored after rotating
+type Encryptor struct {
+	keys   map[string][]byte
+	active string
+}
+
+// NewEncryptor returns an Encryptor holding keys by ID, encrypting with active
+func NewEncryptor(active string, keys map[string][]byte) (*Encryptor, error) {
+	if _, ok := keys[active]; !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, active)
+	}
+	for kid, key := range keys {
+		if len(key) != keySize {
+			return nil, fmt.Errorf("backup encryption key %q must be %v bytes, got %v", kid, keySize, len(key))
+		}
+	}
+	return &Encryptor{keys: keys, active: active}, nil
+}
+
+// keyFile is a file of base64 keys by ID, for example
+//
+//	{"active": "2026-10", "keys": {"2026-09": "...", "2026-10": "..."}}
+type keyFile struct {
+	Active string            `json:"active"`
+	Keys   map[string]string `json:"keys"`
+}
+
+// Load reads a key file
+func Load(file string) (*Encryptor, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var kf keyFile
+	err = json.Unmarshal(data, &kf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse backup key file %v: %v", file, err)
+	}
+
+	keys := map[string][]byte{}
+	for kid, encoded := range kf.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("backup encryption key %q isn't base64: %v", kid, err)
+		}
+		keys[kid] = key
+	}
+	return NewEncryptor(kf.Active, keys)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce is the prefix, the chunk's index and whether it's the last
+func chunkNonce(prefix []byte, index uint64, last bool) ([]byte, error) {
+	if index > math.MaxUint32 {
+		return nil, errors.New("backup is too large to encrypt")
+	}
+	nonce := make([]byte, 12)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], uint32(index))
+	if last {
+		nonce[11] = 1
+	}
+	return nonce, nil
+}
+
+// Encrypt writes src encrypted to dest, returning the ID of the key used
+func (e *Encryptor) Encrypt(src string, dest string) (string, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	// Wrap a new data key with the active key
+	dataKey := make([]byte, keySize)
+	noncePrefix := make([]byte, noncePrefixSize)
+	wrapNonce := make([]byte, 12)
+	for _, b := range [][]byte{dataKey, noncePrefix, wrapNonce} {
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+	}
+	kek, err := newGCM(e.keys[e.active])
+	if err != nil {
+		return "", err
+	}
+	wrapped := kek.Seal(wrapNonce, wrapNonce, dataKey, []byte(e.active))
+
+	headerJSON, err := json.Marshal(header{
+		KeyID: e.active, WrappedKey: wrapped, NoncePrefix: noncePrefix, ChunkSize: chunkSize,
+	})
+	if err != nil {
+		return "", err
+	}
+	prelude := make([]byte, 0, len(magic)+4+len(headerJSON))
+	prelude = append(prelude, magic...)
+	prelude = binary.BigEndian.AppendUint32(prelude, uint32(len(headerJSON)))
+	prelude = append(prelude, headerJSON...)
+	if _, err := out.Write(prelude); err != nil {
+		return "", err
+	}
+
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	// The header is authenticated with every chunk
+	r := bufio.NewReaderSize(in, chunkSize)
+	buf := make([]byte, chunkSize)
+	sealed := make([]byte, 0, chunkSize+aead.Overhead())
+	for index := uint64(0); ; index++ {
+		n, err := io.ReadFull(r, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return "", err
+		}
+		_, peekErr := r.Peek(1)
+		last := peekErr == io.EOF
+
+		nonce, err := chunkNonce(noncePrefix, index, last)
+		if err != nil {
+			return "", err
+		}
+		sealed = aead.Seal(sealed[:0], nonce, buf[:n], prelude)
+		if _, err := out.Write(sealed); err != nil {
+			return "", err
+		}
+		if last {
+			break
+		}
+		if peekErr != nil {
+			return "", peekErr
+		}
+	}
+
+	if err := out.Close(); err != nil {
+		return "", err
+	}
+	return e.active, nil
+}
+
+// Decrypt writes src decrypted to dest, using the key named in its header
+// Returns ErrUnknownKey if we don't have that key and ErrCorrupt if src
+// was modified or truncated
+func (e *Encryptor) Decrypt(src string, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	r := bufio.NewReaderSize(in, chunkSize+64)
+
+	prelude := make([]byte, len(magic)+4)
+	if _, err := io.ReadFull(r, prelude); err != nil || string(prelude[:len(magic)]) != magic {
+		return fmt.Errorf("%w: not an encrypted backup", ErrCorrupt)
+	}
+	headerSize := binary.BigEndian.Uint32(prelude[len(magic):])
+	if headerSize > maxHeaderSize {
+		return fmt.Errorf("%w: header is too large", ErrCorrupt)
+	}
+	headerJSON := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, headerJSON); err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	prelude = append(prelude, headerJSON...)
+
+	var h header
+	if err := json.Unmarshal(headerJSON, &h); err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	if h.ChunkSize <= 0 || h.ChunkSize > 16*chunkSize || len(h.NoncePrefix) != noncePrefixSize {
+		return fmt.Errorf("%w: invalid header", ErrCorrupt)
+	}
+
+	// Unwrap the data key
+	key, ok := e.keys[h.KeyID]
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownKey, h.KeyID)
+	}
+	kek, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	if len(h.WrappedKey) < kek.NonceSize() {
+		return fmt.Errorf("%w: invalid wrapped key", ErrCorrupt)
+	}
+	dataKey, err := kek.Open(nil, h.WrappedKey[:kek.NonceSize()], h.WrappedKey[kek.NonceSize():], []byte(h.KeyID))
+	if err != nil {
+		return fmt.Errorf("%w: can't unwrap data key: %v", ErrCorrupt, err)
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	buf := make([]byte, h.ChunkSize+aead.Overhead())
+	plain := make([]byte, 0, h.ChunkSize)
+	for index := uint64(0); ; index++ {
+		n, err := io.ReadFull(r, buf)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			if err == io.EOF {
+				return fmt.Errorf("%w: truncated", ErrCorrupt)
+			}
+			return err
+		}
+		_, peekErr := r.Peek(1)
+		last := peekErr == io.EOF
+
+		nonce, err := chunkNonce(h.NoncePrefix, index, last)
+		if err != nil {
+			return err
+		}
+		plain, err = aead.Open(plain[:0], nonce, buf[:n], prelude)
+		if err != nil {
+			return fmt.Errorf("%w: chunk %v: %v", ErrCorrupt, index, err)
+		}
+		if _, err := out.Write(plain); err != nil {
+			return err
+		}
+		if last {
+			break
+		}
+		if peekErr != nil {
+			return peekErr
+		}
+	}
+
+	return out.Close()
+}
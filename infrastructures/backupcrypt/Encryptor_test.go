@@ -0,0 +1,147 @@
+package backupcrypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(t *testing.T) []byte {
+	key := make([]byte, keySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return key
+}
+
+// encrypt writes data to a file and encrypts it, returning the encrypted file
+func encrypt(t *testing.T, e *Encryptor, data []byte) string {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "plain")
+	require.NoError(t, os.WriteFile(src, data, 0600))
+	dest := filepath.Join(dir, "encrypted")
+	_, err := e.Encrypt(src, dest)
+	require.NoError(t, err)
+	return dest
+}
+
+func decrypt(e *Encryptor, src string) ([]byte, error) {
+	dest := src + ".decrypted"
+	err := e.Decrypt(src, dest)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(dest)
+}
+
+func TestRoundTrip(t *testing.T) {
+	e, err := NewEncryptor("k1", map[string][]byte{"k1": testKey(t)})
+	require.NoError(t, err)
+
+	// Empty, under, exactly and over a chunk
+	for _, size := range []int{0, 10, chunkSize, 3*chunkSize + 17} {
+		t.Run(fmt.Sprint(size), func(t *testing.T) {
+			data := make([]byte, size)
+			rand.Read(data)
+
+			encrypted := encrypt(t, e, data)
+			ciphertext, err := os.ReadFile(encrypted)
+			require.NoError(t, err)
+			if size > 0 {
+				assert.False(t, bytes.Contains(ciphertext, data))
+			}
+
+			decrypted, err := decrypt(e, encrypted)
+			require.NoError(t, err)
+			assert.Equal(t, data, decrypted)
+		})
+	}
+}
+
+func TestTampered(t *testing.T) {
+	e, err := NewEncryptor("k1", map[string][]byte{"k1": testKey(t)})
+	require.NoError(t, err)
+	data := make([]byte, 2*chunkSize+5)
+	encrypted := encrypt(t, e, data)
+	original, err := os.ReadFile(encrypted)
+	require.NoError(t, err)
+	headerEnd := len(original) - (len(data) + 3*16)
+
+	tests := []struct {
+		name   string
+		modify func([]byte) []byte
+	}{
+		{"flipped byte", func(b []byte) []byte { b[len(b)-100] ^= 1; return b }},
+		{"flipped header", func(b []byte) []byte { b[headerEnd-3] ^= 1; return b }},
+		{"truncated at a chunk", func(b []byte) []byte { return b[:headerEnd+2*(chunkSize+16)] }},
+		{"truncated mid chunk", func(b []byte) []byte { return b[:len(b)-3] }},
+		{"appended", func(b []byte) []byte { return append(b, 0) }},
+		{"not encrypted", func(b []byte) []byte { return []byte("SQLite format 3") }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tampered := filepath.Join(t.TempDir(), "tampered")
+			require.NoError(t, os.WriteFile(tampered, test.modify(bytes.Clone(original)), 0600))
+
+			_, err := decrypt(e, tampered)
+
+			assert.True(t, errors.Is(err, ErrCorrupt), "got %v", err)
+		})
+	}
+}
+
+func TestRotation(t *testing.T) {
+	oldKey, newKey := testKey(t), testKey(t)
+	before, err := NewEncryptor("old", map[string][]byte{"old": oldKey})
+	require.NoError(t, err)
+	encrypted := encrypt(t, before, []byte("backup"))
+
+	// The old key is still needed for old backups
+	after, err := NewEncryptor("new", map[string][]byte{"old": oldKey, "new": newKey})
+	require.NoError(t, err)
+	decrypted, err := decrypt(after, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("backup"), decrypted)
+
+	// Without it they can't be decrypted
+	retired, err := NewEncryptor("new", map[string][]byte{"new": newKey})
+	require.NoError(t, err)
+	_, err = decrypt(retired, encrypted)
+	assert.True(t, errors.Is(err, ErrUnknownKey))
+}
+
+func TestWrongKeyWithSameID(t *testing.T) {
+	encrypted := encrypt(t, &Encryptor{keys: map[string][]byte{"k1": testKey(t)}, active: "k1"}, []byte("backup"))
+
+	_, err := decrypt(&Encryptor{keys: map[string][]byte{"k1": testKey(t)}, active: "k1"}, encrypted)
+
+	assert.True(t, errors.Is(err, ErrCorrupt))
+}
+
+func TestNewEncryptorInvalid(t *testing.T) {
+	_, err := NewEncryptor("missing", map[string][]byte{"k1": testKey(t)})
+	assert.True(t, errors.Is(err, ErrUnknownKey))
+
+	_, err = NewEncryptor("k1", map[string][]byte{"k1": []byte("short")})
+	assert.NotNil(t, err)
+}
+
+func TestLoad(t *testing.T) {
+	key := testKey(t)
+	file := filepath.Join(t.TempDir(), "keys.json")
+	require.NoError(t, os.WriteFile(file, []byte(fmt.Sprintf(
+		`{"active": "2026-10", "keys": {"2026-10": %q}}`, base64.StdEncoding.EncodeToString(key))), 0600))
+
+	e, err := Load(file)
+
+	require.NoError(t, err)
+	assert.Equal(t, "2026-10", e.active)
+	assert.Equal(t, key, e.keys["2026-10"])
+}
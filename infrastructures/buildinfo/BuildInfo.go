@@ -0,0 +1,41 @@
+// Package buildinfo describes the running binary, set it when building with
+//
+//	-ldflags "-X github.com/apkatsikas/artist-entities/infrastructures/buildinfo.Version=v1.2.0
+//	-X github.com/apkatsikas/artist-entities/infrastructures/buildinfo.Commit=$(git rev-parse HEAD)
+//	-X github.com/apkatsikas/artist-entities/infrastructures/buildinfo.BuildTime=$(date -u +%FT%TZ)"
+//
+// GoVersion can be set the same way, it's the compiler's version otherwise.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+
+	"github.com/apkatsikas/artist-entities/models"
+)
+
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+	GoVersion = ""
+)
+
+// Get falls back to what the Go toolchain recorded for anything not set
+func Get() models.BuildInfo {
+	info := models.BuildInfo{Version: Version, Commit: Commit, BuildTime: BuildTime, GoVersion: GoVersion}
+	if info.GoVersion == "" {
+		info.GoVersion = runtime.Version()
+	}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildTime == "":
+				info.BuildTime = setting.Value
+			}
+		}
+	}
+	return info
+}
@@ -0,0 +1,90 @@
+package compressor
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compressor streams files through gzip or zstd
+type Compressor struct {
+}
+
+func (c *Compressor) Compress(src string, dest string, compression string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	var w io.WriteCloser
+	switch compression {
+	case models.CompressionGzip:
+		w, err = gzip.NewWriterLevel(out, gzip.BestCompression)
+	case models.CompressionZstd:
+		w, err = zstd.NewWriter(out, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	default:
+		err = fmt.Errorf("unknown compression %q", compression)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(w, in); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+func (c *Compressor) Decompress(src string, dest string, compression string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	var r io.Reader
+	switch compression {
+	case models.CompressionGzip:
+		gz, err := gzip.NewReader(in)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	case models.CompressionZstd:
+		zr, err := zstd.NewReader(in)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		r = zr
+	default:
+		return fmt.Errorf("unknown compression %q", compression)
+	}
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, r); err != nil {
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,47 @@
+package compressor
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "vacuum.sqlite")
+	contents := bytes.Repeat([]byte("SQLite format 3\x00"), 4096)
+	require.NoError(t, os.WriteFile(src, contents, 0o600))
+
+	for _, compression := range []string{models.CompressionGzip, models.CompressionZstd} {
+		t.Run(compression, func(t *testing.T) {
+			c := &Compressor{}
+			compressed := filepath.Join(dir, "vacuum.sqlite"+models.CompressionExtension(compression))
+			restored := filepath.Join(dir, compression+".sqlite")
+
+			require.NoError(t, c.Compress(src, compressed, compression))
+			info, err := os.Stat(compressed)
+			require.NoError(t, err)
+			require.Less(t, info.Size(), int64(len(contents)))
+
+			require.NoError(t, c.Decompress(compressed, restored, compression))
+			result, err := os.ReadFile(restored)
+			require.NoError(t, err)
+			require.Equal(t, contents, result)
+		})
+	}
+}
+
+func TestDecompressCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "backup")
+	require.NoError(t, os.WriteFile(src, []byte("not compressed"), 0o600))
+
+	c := &Compressor{}
+	require.Error(t, c.Decompress(src, filepath.Join(dir, "gzip.sqlite"), models.CompressionGzip))
+	require.Error(t, c.Decompress(src, filepath.Join(dir, "zstd.sqlite"), models.CompressionZstd))
+	require.Error(t, c.Compress(src, filepath.Join(dir, "out"), "lzma"))
+}
@@ -1,6 +1,9 @@
 package fileutil
 
 import (
+    "crypto/sha256"
+    "encoding/hex"
+    "io"
     "os"
 )
 
@@ -34,3 +37,27 @@ func (fu *FileUtil) DeleteIfExists(file string) error {
     }
     return nil
 }
+
+// Checksum returns the hex SHA-256 and size of a file
+func (fu *FileUtil) Checksum(file string) (string, int64, error) {
+    f, err := os.Open(file)
+    if err != nil {
+        return "", 0, err
+    }
+    defer f.Close()
+
+    hash := sha256.New()
+    size, err := io.Copy(hash, f)
+    if err != nil {
+        return "", 0, err
+    }
+    return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
+
+func (fu *FileUtil) ReadFile(file string) ([]byte, error) {
+    return os.ReadFile(file)
+}
+
+func (fu *FileUtil) WriteFile(file string, data []byte) error {
+    return os.WriteFile(file, data, 0600)
+}
@@ -0,0 +1,300 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/viewmodels"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultKeyID is used for the single key built from JWT_SIGNING_KEY
+// Tokens without a kid are checked against it
+const DefaultKeyID = "default"
+
+var (
+	ErrNoSigningKey = errors.New("no active JWT signing key")
+	ErrUnknownKey   = errors.New("unknown JWT key id")
+)
+
+// Key is one signing or verification key
+// A key signs tokens from ActivatesAt and verifies them until ExpiresAt
+type Key struct {
+	ID          string
+	Method      jwt.SigningMethod
+	ActivatesAt time.Time
+	ExpiresAt   time.Time
+	signKey     any
+	verifyKey   any
+}
+
+func (k *Key) SignKey() any {
+	return k.signKey
+}
+
+func (k *Key) VerifyKey() any {
+	return k.verifyKey
+}
+
+func (k *Key) expired(now time.Time) bool {
+	return !k.ExpiresAt.IsZero() && !now.Before(k.ExpiresAt)
+}
+
+// keyConfig is one entry of a keyring file, for example
+//
+//	{"keys": [
+//	  {"kid": "2026-09", "alg": "HS256", "secretEnv": "JWT_SIGNING_KEY",
+//	   "expiresAt": "2026-10-01T00:10:00Z"},
+//	  {"kid": "2026-10", "alg": "EdDSA", "privateKeyFile": "keys/2026-10.pem",
+//	   "activatesAt": "2026-10-01T00:00:00Z"}
+//	]}
+type keyConfig struct {
+	Kid            string    `json:"kid"`
+	Alg            string    `json:"alg"`
+	PrivateKeyFile string    `json:"privateKeyFile"`
+	SecretEnv      string    `json:"secretEnv"`
+	ActivatesAt    time.Time `json:"activatesAt"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+}
+
+type keyringConfig struct {
+	Keys []keyConfig `json:"keys"`
+}
+
+// Keyring holds every key we sign or verify with
+//
+// To rotate, add a key with a future activatesAt and give the old key an
+// expiresAt at least one token lifetime after it, then Reload
+type Keyring struct {
+	mu   sync.RWMutex
+	file string
+	keys map[string]*Key
+}
+
+// NewKey returns a key, a zero ExpiresAt never expires
+func NewKey(id string, method jwt.SigningMethod, signKey any, verifyKey any,
+	activatesAt time.Time, expiresAt time.Time) *Key {
+	return &Key{
+		ID:          id,
+		Method:      method,
+		ActivatesAt: activatesAt,
+		ExpiresAt:   expiresAt,
+		signKey:     signKey,
+		verifyKey:   verifyKey,
+	}
+}
+
+// NewKeyring returns a keyring holding the given keys
+func NewKeyring(keys ...*Key) *Keyring {
+	kr := &Keyring{keys: map[string]*Key{}}
+	for _, key := range keys {
+		kr.keys[key.ID] = key
+	}
+	return kr
+}
+
+// NewHMAC returns a keyring with a single HS256 key
+func NewHMAC(secret string) *Keyring {
+	return NewKeyring(NewKey(DefaultKeyID, jwt.SigningMethodHS256,
+		[]byte(secret), []byte(secret), time.Time{}, time.Time{}))
+}
+
+// Load reads a keyring from a JSON file
+func Load(file string) (*Keyring, error) {
+	kr := &Keyring{file: file}
+	err := kr.Reload()
+	if err != nil {
+		return nil, err
+	}
+	return kr, nil
+}
+
+// Reload re-reads the keyring file, keeping the current keys on failure
+func (kr *Keyring) Reload() error {
+	if kr.file == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(kr.file)
+	if err != nil {
+		return err
+	}
+	var config keyringConfig
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return fmt.Errorf("failed to parse keyring %v: %w", kr.file, err)
+	}
+	if len(config.Keys) == 0 {
+		return fmt.Errorf("keyring %v has no keys", kr.file)
+	}
+
+	keys := map[string]*Key{}
+	for _, kc := range config.Keys {
+		key, err := parseKey(kc)
+		if err != nil {
+			return fmt.Errorf("failed to load key %q: %w", kc.Kid, err)
+		}
+		if _, exists := keys[key.ID]; exists {
+			return fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		keys[key.ID] = key
+	}
+
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	kr.keys = keys
+	return nil
+}
+
+func parseKey(kc keyConfig) (*Key, error) {
+	if kc.Kid == "" {
+		return nil, errors.New("kid is required")
+	}
+	key := &Key{ID: kc.Kid, ActivatesAt: kc.ActivatesAt, ExpiresAt: kc.ExpiresAt}
+
+	switch kc.Alg {
+	case "HS256":
+		secret := os.Getenv(kc.SecretEnv)
+		if kc.SecretEnv == "" || secret == "" {
+			return nil, errors.New("HS256 keys need secretEnv pointing at a non-empty variable")
+		}
+		key.Method = jwt.SigningMethodHS256
+		key.signKey = []byte(secret)
+		key.verifyKey = []byte(secret)
+	case "RS256":
+		pem, err := os.ReadFile(kc.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		private, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, err
+		}
+		key.Method = jwt.SigningMethodRS256
+		key.signKey = private
+		key.verifyKey = &private.PublicKey
+	case "EdDSA":
+		pem, err := os.ReadFile(kc.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		private, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, err
+		}
+		edPrivate := private.(ed25519.PrivateKey)
+		key.Method = jwt.SigningMethodEdDSA
+		key.signKey = edPrivate
+		key.verifyKey = edPrivate.Public()
+	default:
+		return nil, fmt.Errorf("unsupported alg %q", kc.Alg)
+	}
+	return key, nil
+}
+
+// SigningKey returns the most recently activated key that hasn't expired
+func (kr *Keyring) SigningKey() (*Key, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	now := time.Now()
+	var signing *Key
+	for _, key := range kr.keys {
+		if key.ActivatesAt.After(now) || key.expired(now) {
+			continue
+		}
+		if signing == nil || key.ActivatesAt.After(signing.ActivatesAt) {
+			signing = key
+		}
+	}
+	if signing == nil {
+		return nil, ErrNoSigningKey
+	}
+	return signing, nil
+}
+
+// VerificationKey returns the key for a kid as long as it hasn't expired
+// Keys that aren't active yet still verify, so every instance accepts
+// tokens as soon as any instance starts signing with them
+func (kr *Keyring) VerificationKey(kid string) (*Key, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	if kid == "" {
+		kid = DefaultKeyID
+	}
+	key, ok := kr.keys[kid]
+	if !ok || key.expired(time.Now()) {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// Methods returns every algorithm in the keyring
+func (kr *Keyring) Methods() []string {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	seen := map[string]bool{}
+	var methods []string
+	for _, key := range kr.keys {
+		alg := key.Method.Alg()
+		if !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// Len returns how many keys are loaded
+func (kr *Keyring) Len() int {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	return len(kr.keys)
+}
+
+// JWKS returns the public halves of the asymmetric keys
+// HMAC secrets are never published
+func (kr *Keyring) JWKS() viewmodels.JwksVM {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	now := time.Now()
+	jwks := viewmodels.JwksVM{Keys: []viewmodels.JwkVM{}}
+	for _, key := range kr.keys {
+		if key.expired(now) {
+			continue
+		}
+		jwk := viewmodels.JwkVM{Kid: key.ID, Alg: key.Method.Alg(), Use: "sig"}
+
+		switch public := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		return jwks.Keys[i].Kid < jwks.Keys[j].Kid
+	})
+	return jwks
+}
@@ -0,0 +1,168 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePEM(t *testing.T, dir string, name string, key any) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, name)
+	err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	require.NoError(t, err)
+	return path
+}
+
+func writeKeyring(t *testing.T, dir string, keys []keyConfig) string {
+	data, err := json.Marshal(keyringConfig{Keys: keys})
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "keyring.json")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+func testKeys(t *testing.T, dir string) []keyConfig {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	t.Setenv("TEST_JWT_SECRET", "secret")
+	now := time.Now()
+
+	return []keyConfig{
+		{Kid: "old", Alg: "HS256", SecretEnv: "TEST_JWT_SECRET", ActivatesAt: now.Add(-48 * time.Hour)},
+		{Kid: "current", Alg: "RS256", PrivateKeyFile: writePEM(t, dir, "rsa.pem", rsaKey),
+			ActivatesAt: now.Add(-time.Hour)},
+		{Kid: "next", Alg: "EdDSA", PrivateKeyFile: writePEM(t, dir, "ed.pem", edKey),
+			ActivatesAt: now.Add(time.Hour)},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	keyring, err := Load(writeKeyring(t, dir, testKeys(t, dir)))
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, keyring.Len())
+	assert.Equal(t, []string{"EdDSA", "HS256", "RS256"}, keyring.Methods())
+}
+
+func TestSigningKeyIsLatestActive(t *testing.T) {
+	dir := t.TempDir()
+	keyring, err := Load(writeKeyring(t, dir, testKeys(t, dir)))
+	require.NoError(t, err)
+
+	key, err := keyring.SigningKey()
+	require.NoError(t, err)
+	// "next" isn't active yet
+	assert.Equal(t, "current", key.ID)
+}
+
+func TestVerificationKeyIncludesInactive(t *testing.T) {
+	dir := t.TempDir()
+	keyring, err := Load(writeKeyring(t, dir, testKeys(t, dir)))
+	require.NoError(t, err)
+
+	for _, kid := range []string{"old", "current", "next"} {
+		key, err := keyring.VerificationKey(kid)
+		require.NoError(t, err)
+		assert.Equal(t, kid, key.ID)
+	}
+	_, err = keyring.VerificationKey("missing")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestExpiredKeyIsDropped(t *testing.T) {
+	dir := t.TempDir()
+	keys := testKeys(t, dir)
+	keys[1].ExpiresAt = time.Now().Add(-time.Minute)
+	keyring, err := Load(writeKeyring(t, dir, keys))
+	require.NoError(t, err)
+
+	_, err = keyring.VerificationKey("current")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	// Falls back to the older key for signing
+	key, err := keyring.SigningKey()
+	require.NoError(t, err)
+	assert.Equal(t, "old", key.ID)
+}
+
+func TestNoSigningKey(t *testing.T) {
+	dir := t.TempDir()
+	keys := testKeys(t, dir)[2:]
+	keyring, err := Load(writeKeyring(t, dir, keys))
+	require.NoError(t, err)
+
+	_, err = keyring.SigningKey()
+	assert.ErrorIs(t, err, ErrNoSigningKey)
+}
+
+func TestJWKSOnlyPublishesPublicKeys(t *testing.T) {
+	dir := t.TempDir()
+	keyring, err := Load(writeKeyring(t, dir, testKeys(t, dir)))
+	require.NoError(t, err)
+
+	jwks := keyring.JWKS()
+	require.Len(t, jwks.Keys, 2)
+
+	assert.Equal(t, "current", jwks.Keys[0].Kid)
+	assert.Equal(t, "RSA", jwks.Keys[0].Kty)
+	assert.Equal(t, "AQAB", jwks.Keys[0].E)
+	assert.NotEmpty(t, jwks.Keys[0].N)
+
+	assert.Equal(t, "next", jwks.Keys[1].Kid)
+	assert.Equal(t, "OKP", jwks.Keys[1].Kty)
+	assert.Equal(t, "Ed25519", jwks.Keys[1].Crv)
+	assert.NotEmpty(t, jwks.Keys[1].X)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	var testData = []struct {
+		test string
+		keys []keyConfig
+	}{
+		{test: "no keys", keys: []keyConfig{}},
+		{test: "missing kid", keys: []keyConfig{{Alg: "HS256", SecretEnv: "TEST_JWT_SECRET"}}},
+		{test: "unknown alg", keys: []keyConfig{{Kid: "a", Alg: "none"}}},
+		{test: "missing secret", keys: []keyConfig{{Kid: "a", Alg: "HS256", SecretEnv: "TEST_JWT_UNSET"}}},
+		{test: "missing pem", keys: []keyConfig{{Kid: "a", Alg: "RS256", PrivateKeyFile: "nope.pem"}}},
+		{test: "duplicate kid", keys: []keyConfig{
+			{Kid: "a", Alg: "HS256", SecretEnv: "TEST_JWT_SECRET"},
+			{Kid: "a", Alg: "HS256", SecretEnv: "TEST_JWT_SECRET"},
+		}},
+	}
+	for _, tt := range testData {
+		t.Run(tt.test, func(t *testing.T) {
+			t.Setenv("TEST_JWT_SECRET", "secret")
+			_, err := Load(writeKeyring(t, t.TempDir(), tt.keys))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestReloadKeepsKeysOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := writeKeyring(t, dir, testKeys(t, dir))
+	keyring, err := Load(path)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0600))
+	assert.Error(t, keyring.Reload())
+	assert.Equal(t, 3, keyring.Len())
+}
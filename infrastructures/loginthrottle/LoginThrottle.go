@@ -0,0 +1,93 @@
+package loginthrottle
+
+import (
+	"sync"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/interfaces"
+)
+
+const (
+	userPrefix = "user:"
+	ipPrefix   = "ip:"
+)
+
+type attempts struct {
+	failures    uint
+	lastFailure time.Time
+}
+
+// LoginThrottle tracks failed logins per user name and per IP in memory
+type LoginThrottle struct {
+	Rules interfaces.ILoginRules
+
+	mu       sync.Mutex
+	attempts map[string]*attempts
+}
+
+func keys(userName string, ip string) []string {
+	return []string{userPrefix + userName, ipPrefix + ip}
+}
+
+// RetryAfter returns how long the caller must wait before trying again
+// Zero means the attempt is allowed
+func (lt *LoginThrottle) RetryAfter(userName string, ip string) time.Duration {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	now := time.Now()
+	var wait time.Duration
+	for _, key := range keys(userName, ip) {
+		a, ok := lt.attempts[key]
+		if !ok {
+			continue
+		}
+		remaining := a.lastFailure.Add(lt.Rules.Delay(a.failures)).Sub(now)
+		if remaining > wait {
+			wait = remaining
+		}
+	}
+	return wait
+}
+
+// Fail records a failed login and returns the consecutive failures for the user
+func (lt *LoginThrottle) Fail(userName string, ip string) uint {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	if lt.attempts == nil {
+		lt.attempts = map[string]*attempts{}
+	}
+
+	now := time.Now()
+	lt.forgetExpired(now)
+
+	for _, key := range keys(userName, ip) {
+		a, ok := lt.attempts[key]
+		if !ok {
+			a = &attempts{}
+			lt.attempts[key] = a
+		}
+		a.failures++
+		a.lastFailure = now
+	}
+	return lt.attempts[userPrefix+userName].failures
+}
+
+// Succeed clears failures for the user
+// The IP is left alone so one valid account can't reset guessing against others
+func (lt *LoginThrottle) Succeed(userName string, ip string) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	delete(lt.attempts, userPrefix+userName)
+}
+
+// forgetExpired drops stale entries so the map doesn't grow forever
+func (lt *LoginThrottle) forgetExpired(now time.Time) {
+	for key, a := range lt.attempts {
+		if lt.Rules.Expired(now.Sub(a.lastFailure)) {
+			delete(lt.attempts, key)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package loginthrottle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/interfaces/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	user = "admin"
+	ip   = "10.0.0.1"
+)
+
+func TestRetryAfterNoFailures(t *testing.T) {
+	throttle := LoginThrottle{Rules: mocks.NewILoginRules(t)}
+
+	assert.Zero(t, throttle.RetryAfter(user, ip))
+}
+
+func TestFailThenRetryAfter(t *testing.T) {
+	rules := mocks.NewILoginRules(t)
+	rules.EXPECT().Expired(mock.Anything).Return(false).Maybe()
+	rules.EXPECT().Delay(uint(1)).Return(time.Minute)
+
+	throttle := LoginThrottle{Rules: rules}
+
+	assert.Equal(t, uint(1), throttle.Fail(user, ip))
+
+	wait := throttle.RetryAfter(user, ip)
+	assert.Greater(t, wait, 59*time.Second)
+	assert.LessOrEqual(t, wait, time.Minute)
+}
+
+func TestFailByIPThrottlesOtherUsers(t *testing.T) {
+	rules := mocks.NewILoginRules(t)
+	rules.EXPECT().Expired(mock.Anything).Return(false).Maybe()
+	rules.EXPECT().Delay(uint(1)).Return(time.Minute)
+
+	throttle := LoginThrottle{Rules: rules}
+	throttle.Fail(user, ip)
+
+	// Same IP, different user name
+	assert.NotZero(t, throttle.RetryAfter("someone-else", ip))
+	// Same user name, different IP
+	assert.NotZero(t, throttle.RetryAfter(user, "10.0.0.2"))
+}
+
+func TestSucceedClearsUser(t *testing.T) {
+	rules := mocks.NewILoginRules(t)
+	rules.EXPECT().Expired(mock.Anything).Return(false).Maybe()
+
+	throttle := LoginThrottle{Rules: rules}
+	throttle.Fail(user, ip)
+	throttle.Succeed(user, ip)
+
+	// The next failure starts counting from scratch for the user
+	assert.Equal(t, uint(1), throttle.Fail(user, "10.0.0.2"))
+}
+
+func TestExpiredFailuresAreForgotten(t *testing.T) {
+	rules := mocks.NewILoginRules(t)
+	rules.EXPECT().Expired(mock.Anything).Return(true)
+
+	throttle := LoginThrottle{Rules: rules}
+	throttle.Fail(user, ip)
+
+	assert.Equal(t, uint(1), throttle.Fail(user, ip))
+}
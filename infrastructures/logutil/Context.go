@@ -0,0 +1,109 @@
+package logutil
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carries the request ID, a caller's is kept if it's sensible
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestID = 64
+
+type contextKey struct{}
+
+// requestLogger is shared by everything handling a request,
+// so attributes added along the way show up in later logs
+type requestLogger struct {
+	mu     sync.Mutex
+	logger *slog.Logger
+}
+
+// FromContext returns the request's logger, or the default outside a request
+func FromContext(ctx context.Context) *slog.Logger {
+	if rl, ok := ctx.Value(contextKey{}).(*requestLogger); ok {
+		rl.mu.Lock()
+		defer rl.mu.Unlock()
+		return rl.logger
+	}
+	return slog.Default()
+}
+
+// Annotate adds key value pairs to the rest of the request's logs,
+// like the user once they're authorized
+// It does nothing outside a request
+func Annotate(ctx context.Context, args ...any) {
+	if rl, ok := ctx.Value(contextKey{}).(*requestLogger); ok {
+		rl.mu.Lock()
+		defer rl.mu.Unlock()
+		rl.logger = rl.logger.With(args...)
+	}
+}
+
+// Middleware gives each request an ID and a logger carrying it, and its trace ID
+// when it's traced, so use it after tracing.Middleware
+// Requests are logged at debug once handled
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		id := req.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		res.Header().Set(RequestIDHeader, id)
+
+		ctx := req.Context()
+		logger := slog.Default().With("requestID", id)
+		if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+			logger = logger.With("traceID", span.TraceID().String())
+		}
+		rl := &requestLogger{logger: logger}
+		ctx = context.WithValue(ctx, contextKey{}, rl)
+
+		ww := middleware.NewWrapResponseWriter(res, req.ProtoMajor)
+		next.ServeHTTP(ww, req.WithContext(ctx))
+
+		if !logger.Enabled(ctx, slog.LevelDebug) {
+			return
+		}
+		route := ""
+		if rctx := chi.RouteContext(req.Context()); rctx != nil {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		FromContext(ctx).DebugContext(ctx, "Handled request", "method", req.Method, "path", req.URL.Path,
+			"route", route, "status", status, "duration", time.Since(start))
+	})
+}
+
+// validRequestID is true for IDs that are safe to log and echo back
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestID {
+		return false
+	}
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	id := make([]byte, 8)
+	// crypto/rand doesn't fail
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,58 @@
+package logutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// GormLogger sends GORM's logs through slog, with the request's attributes
+// Failed queries are logged as errors, slow ones as warnings and the rest at debug
+// Queries are logged without their values, so no passwords or keys end up in logs
+type GormLogger struct {
+	// SlowThreshold is 0 to never warn
+	SlowThreshold time.Duration
+}
+
+// LogMode is ignored, the level is slog's
+func (l GormLogger) LogMode(logger.LogLevel) logger.Interface {
+	return l
+}
+
+func (l GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	FromContext(ctx).InfoContext(ctx, fmt.Sprintf(msg, data...))
+}
+
+func (l GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	FromContext(ctx).WarnContext(ctx, fmt.Sprintf(msg, data...))
+}
+
+func (l GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	FromContext(ctx).ErrorContext(ctx, fmt.Sprintf(msg, data...))
+}
+
+func (l GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	log := FromContext(ctx)
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		log.ErrorContext(ctx, "Query failed", "sql", sql, "rows", rows, "duration", elapsed, "error", err)
+	case l.SlowThreshold > 0 && elapsed > l.SlowThreshold:
+		sql, rows := fc()
+		log.WarnContext(ctx, "Slow query", "sql", sql, "rows", rows, "duration", elapsed)
+	case log.Enabled(ctx, slog.LevelDebug):
+		sql, rows := fc()
+		log.DebugContext(ctx, "Query", "sql", sql, "rows", rows, "duration", elapsed)
+	}
+}
+
+// ParamsFilter drops the query's values, leaving placeholders
+func (l GormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
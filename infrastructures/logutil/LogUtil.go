@@ -1,46 +1,140 @@
 package logutil
 
 import (
-    "errors"
+    "fmt"
     "io"
-    "log"
+    "log/slog"
+    "math"
     "os"
+    "path/filepath"
+
+    "gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+    FormatText = "text"
+    FormatJSON = "json"
 )
 
-const logFile = "./logs.txt"
+// MinMaxSize is the smallest a log file can be rotated at, sizes are rounded up to whole MiB
+const MinMaxSize = 1 << 20
+
+// DefaultConfig logs info and above as text, keeping 10 files of up to 100 MiB
+var DefaultConfig = Config{
+    File:    "./logs.txt",
+    MaxSize: 100 << 20,
+    Keep:    10,
+    Format:  FormatText,
+    Level:   "info",
+}
+
+type Config struct {
+    File string `yaml:"file" env:"LOG_FILE" usage:"File to log to as well as stdout"`
+    // StdoutOnly suits containers, where whatever runs them keeps the logs
+    StdoutOnly bool   `yaml:"stdoutOnly" env:"LOG_STDOUT_ONLY" usage:"Only log to stdout, not to a file"`
+    MaxSize    int64  `yaml:"maxSize" env:"LOG_MAX_SIZE" usage:"Bytes the log file grows to before it's rotated"`
+    Keep       int    `yaml:"keep" env:"LOG_KEEP" usage:"Rotated log files kept compressed, 0 keeps them all"`
+    Format     string `yaml:"format" env:"LOG_FORMAT" usage:"Log format, text or json"`
+    Level      string `yaml:"level" env:"LOG_LEVEL" usage:"Lowest level logged, debug, info, warn or error"`
+}
 
-func Setup() {
-    // Delete log
-    err := os.Remove(logFile)
+// rotating is the log file, nil when only logging to stdout
+var rotating *lumberjack.Logger
+
+// ParseLevel reads a level name like debug or warn
+func ParseLevel(level string) (slog.Level, error) {
+    var l slog.Level
+    err := l.UnmarshalText([]byte(level))
     if err != nil {
-        if !errors.Is(err, os.ErrNotExist) {
-            Fatal("Failed to delete the log file. Error was %v", err)
+        return l, fmt.Errorf("unknown level %q", level)
+    }
+    return l, nil
+}
+
+// Setup logs to stdout, and to the log file unless it's stdout only
+// An existing log file is appended to, so logs from before a crash are kept
+// Everything logged with the log package goes there too
+func Setup(config Config) error {
+    var w io.Writer = os.Stdout
+    var file *lumberjack.Logger
+    if !config.StdoutOnly {
+        // The file is opened on the first write, check it can be now
+        err := os.MkdirAll(filepath.Dir(config.File), 0755)
+        if err != nil {
+            return fmt.Errorf("failed to create the log directory: %v", err)
+        }
+        f, err := os.OpenFile(config.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+        if err != nil {
+            return fmt.Errorf("failed to open the log file: %v", err)
+        }
+        f.Close()
+
+        file = &lumberjack.Logger{
+            Filename:   config.File,
+            MaxSize:    int(math.Ceil(float64(config.MaxSize) / MinMaxSize)),
+            MaxBackups: config.Keep,
+            LocalTime:  true,
+            Compress:   true,
         }
+        w = io.MultiWriter(os.Stdout, file)
     }
-    // If the file doesn't exist, create it or append to the file
-    file, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+
+    logger, err := newLogger(config, w)
     if err != nil {
-        Fatal("Failed to open log file. Error was %v", err)
+        return err
     }
+    slog.SetDefault(logger)
+    rotating = file
+    return nil
+}
+
+// Rotate moves the log file aside to be compressed and starts a new one
+// Old files beyond Keep are removed
+// It does nothing when only logging to stdout
+func Rotate() error {
+    if rotating == nil {
+        return nil
+    }
+    return rotating.Rotate()
+}
+
+func newLogger(config Config, w io.Writer) (*slog.Logger, error) {
+    level, err := ParseLevel(config.Level)
+    if err != nil {
+        return nil, err
+    }
+    options := &slog.HandlerOptions{Level: level}
+
+    switch config.Format {
+    case FormatText:
+        return slog.New(slog.NewTextHandler(w, options)), nil
+    case FormatJSON:
+        return slog.New(slog.NewJSONHandler(w, options)), nil
+    }
+    return nil, fmt.Errorf("unknown format %q", config.Format)
+}
+
+// Debug, Info, Warn and Error log msg with key value pairs, like slog
+// Use FromContext while handling a request, to include its ID and user
 
-    mw := io.MultiWriter(os.Stdout, file)
-    log.SetOutput(mw)
+func Debug(msg string, args ...any) {
+    slog.Debug(msg, args...)
 }
 
-func Info(msg string) {
-    log.Printf("INFO: %v", msg)
+func Info(msg string, args ...any) {
+    slog.Info(msg, args...)
 }
 
-func Warn(msg string) {
-    log.Printf("WARN: %v", msg)
+func Warn(msg string, args ...any) {
+    slog.Warn(msg, args...)
 }
 
-func Error(msg string, a ...interface{}) {
-    m := "ERROR: " + msg
-    log.Printf(m, a...)
+func Error(msg string, args ...any) {
+    slog.Error(msg, args...)
 }
 
-func Fatal(msg string, a ...interface{}) {
-    m := "ERROR: " + msg
-    log.Fatalf(m, a...)
+// Fatal logs an error and exits
+func Fatal(msg string, args ...any) {
+    slog.Error(msg, args...)
+    os.Exit(1)
 }
@@ -0,0 +1,273 @@
+package logutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// captureLogs sends JSON logs to the returned buffer until the test ends
+func captureLogs(t *testing.T, level string) *bytes.Buffer {
+	var out bytes.Buffer
+	logger, err := newLogger(Config{Format: FormatJSON, Level: level}, &out)
+	require.NoError(t, err)
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &out
+}
+
+// lines decodes each JSON log line
+func lines(t *testing.T, out *bytes.Buffer) []map[string]any {
+	var logs []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var log map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &log))
+		logs = append(logs, log)
+	}
+	return logs
+}
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		expected string
+		err      string
+	}{
+		{name: "text", config: Config{Format: FormatText, Level: "info"}, expected: `level=INFO msg=Listening addr=:8080`},
+		{name: "json", config: Config{Format: FormatJSON, Level: "info"}, expected: `"msg":"Listening","addr":":8080"`},
+		{name: "below level", config: Config{Format: FormatText, Level: "warn"}},
+		{name: "unknown format", config: Config{Format: "logfmt", Level: "info"}, err: `unknown format "logfmt"`},
+		{name: "unknown level", config: Config{Format: FormatText, Level: "verbose"}, err: `unknown level "verbose"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			logger, err := newLogger(tt.config, &out)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+
+			logger.Info("Listening", "addr", ":8080")
+
+			if tt.expected == "" {
+				assert.Empty(t, out.String())
+			} else {
+				assert.Contains(t, out.String(), tt.expected)
+			}
+		})
+	}
+}
+
+// setup calls Setup, putting the previous logger back when the test ends
+func setup(t *testing.T, config Config) error {
+	previous := slog.Default()
+	t.Cleanup(func() {
+		slog.SetDefault(previous)
+		rotating = nil
+	})
+	return Setup(config)
+}
+
+func TestSetupKeepsLogs(t *testing.T) {
+	config := DefaultConfig
+	config.File = filepath.Join(t.TempDir(), "logs", "logs.txt")
+	require.NoError(t, os.MkdirAll(filepath.Dir(config.File), 0755))
+	require.NoError(t, os.WriteFile(config.File, []byte("before the crash\n"), 0644))
+
+	require.NoError(t, setup(t, config))
+	Info("Running...")
+
+	contents, err := os.ReadFile(config.File)
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "before the crash\n")
+	assert.Contains(t, string(contents), "msg=Running...")
+}
+
+func TestSetupStdoutOnly(t *testing.T) {
+	config := DefaultConfig
+	config.File = filepath.Join(t.TempDir(), "logs.txt")
+	config.StdoutOnly = true
+
+	require.NoError(t, setup(t, config))
+	Info("Running...")
+
+	assert.NoFileExists(t, config.File)
+	assert.NoError(t, Rotate())
+}
+
+func TestSetupUnwritable(t *testing.T) {
+	notDir := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(notDir, nil, 0644))
+	config := DefaultConfig
+	config.File = filepath.Join(notDir, "logs.txt")
+
+	err := setup(t, config)
+
+	assert.ErrorContains(t, err, "failed to create the log directory")
+}
+
+func TestRotate(t *testing.T) {
+	dir := t.TempDir()
+	config := DefaultConfig
+	config.File = filepath.Join(dir, "logs.txt")
+	config.Keep = 1
+	require.NoError(t, setup(t, config))
+
+	Info("First")
+	require.NoError(t, Rotate())
+	// Rotated files are named to the millisecond
+	time.Sleep(5 * time.Millisecond)
+	Info("Second")
+	require.NoError(t, Rotate())
+	Info("Third")
+
+	// Compressing and removing old files happens in the background
+	var rotated []string
+	require.Eventually(t, func() bool {
+		rotated, _ = filepath.Glob(filepath.Join(dir, "logs-*.txt*"))
+		return len(rotated) == 1 && strings.HasSuffix(rotated[0], ".gz")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	f, err := os.Open(rotated[0])
+	require.NoError(t, err)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	contents, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "msg=Second")
+	current, err := os.ReadFile(config.File)
+	require.NoError(t, err)
+	assert.Contains(t, string(current), "msg=Third")
+	assert.NotContains(t, string(current), "msg=Second")
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		generated bool
+	}{
+		{name: "caller's ID", requestID: "abc-123"},
+		{name: "no ID", generated: true},
+		{name: "unsafe ID", requestID: "abc\n123", generated: true},
+		{name: "long ID", requestID: strings.Repeat("a", maxRequestID+1), generated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := captureLogs(t, "debug")
+			r := chi.NewRouter()
+			r.Use(Middleware)
+			r.Get("/artist/{artistID}", func(res http.ResponseWriter, req *http.Request) {
+				Annotate(req.Context(), "user", "someone")
+				FromContext(req.Context()).Error("Failed to get artist")
+				res.WriteHeader(http.StatusInternalServerError)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/artist/3", nil)
+			req.Header.Set(RequestIDHeader, tt.requestID)
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(RequestIDHeader)
+			if tt.generated {
+				assert.Len(t, id, 16)
+			} else {
+				assert.Equal(t, tt.requestID, id)
+			}
+			logs := lines(t, out)
+			require.Len(t, logs, 2)
+			assert.Equal(t, "Failed to get artist", logs[0]["msg"])
+			assert.Equal(t, id, logs[0]["requestID"])
+			assert.Equal(t, "someone", logs[0]["user"])
+			// Handled requests carry what was added while handling them
+			assert.Equal(t, "Handled request", logs[1]["msg"])
+			assert.Equal(t, "someone", logs[1]["user"])
+			assert.Equal(t, "/artist/{artistID}", logs[1]["route"])
+			assert.Equal(t, float64(http.StatusInternalServerError), logs[1]["status"])
+		})
+	}
+}
+
+func TestOutsideRequest(t *testing.T) {
+	out := captureLogs(t, "info")
+	ctx := context.Background()
+
+	Annotate(ctx, "user", "someone")
+	FromContext(ctx).Info("Backed up")
+
+	logs := lines(t, out)
+	require.Len(t, logs, 1)
+	assert.NotContains(t, logs[0], "user")
+}
+
+func TestGormLogger(t *testing.T) {
+	type secret struct {
+		ID    uint
+		Value string
+	}
+	out := captureLogs(t, "debug")
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: GormLogger{SlowThreshold: time.Hour}})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&secret{}))
+	out.Reset()
+
+	require.NoError(t, db.Create(&secret{Value: "hunter2"}).Error)
+	// Not found isn't a failure
+	require.ErrorIs(t, db.First(&secret{}, 2).Error, gorm.ErrRecordNotFound)
+	require.Error(t, db.Exec("SELECT * FROM missing").Error)
+
+	logs := lines(t, out)
+	require.Len(t, logs, 3)
+	assert.Equal(t, "DEBUG", logs[0]["level"])
+	assert.Equal(t, "Query", logs[0]["msg"])
+	assert.Contains(t, logs[0]["sql"], "INSERT INTO `secrets`")
+	assert.NotContains(t, out.String(), "hunter2")
+	assert.Equal(t, "DEBUG", logs[1]["level"])
+	assert.Equal(t, "ERROR", logs[2]["level"])
+	assert.Equal(t, "Query failed", logs[2]["msg"])
+	assert.Contains(t, logs[2]["error"], "no such table: missing")
+}
+
+func TestGormLoggerSlowQuery(t *testing.T) {
+	out := captureLogs(t, "warn")
+	l := GormLogger{SlowThreshold: time.Millisecond}
+
+	l.Trace(context.Background(), time.Now().Add(-time.Second), func() (string, int64) {
+		return "SELECT count(*) FROM `artists`", 1
+	}, nil)
+	// Fast queries aren't logged at warn
+	l.Trace(context.Background(), time.Now(), func() (string, int64) {
+		return "SELECT 1", 1
+	}, nil)
+
+	logs := lines(t, out)
+	require.Len(t, logs, 1)
+	assert.Equal(t, "Slow query", logs[0]["msg"])
+	assert.Equal(t, "SELECT count(*) FROM `artists`", logs[0]["sql"])
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const startKey = "metrics:start"
+
+// GormPlugin times every query, use it with gorm.DB.Use
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "metrics"
+}
+
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().Before("*").Register("metrics:before_create", start),
+		callback.Create().After("*").Register("metrics:after_create", observe("create")),
+		callback.Query().Before("*").Register("metrics:before_query", start),
+		callback.Query().After("*").Register("metrics:after_query", observe("query")),
+		callback.Update().Before("*").Register("metrics:before_update", start),
+		callback.Update().After("*").Register("metrics:after_update", observe("update")),
+		callback.Delete().Before("*").Register("metrics:before_delete", start),
+		callback.Delete().After("*").Register("metrics:after_delete", observe("delete")),
+		callback.Row().Before("*").Register("metrics:before_row", start),
+		callback.Row().After("*").Register("metrics:after_row", observe("row")),
+		callback.Raw().Before("*").Register("metrics:before_raw", start),
+		callback.Raw().After("*").Register("metrics:after_raw", observe("raw")),
+	)
+}
+
+func start(db *gorm.DB) {
+	db.InstanceSet(startKey, time.Now())
+}
+
+func observe(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		started, ok := db.InstanceGet(startKey)
+		if !ok {
+			return
+		}
+		dbQueryDuration.WithLabelValues(operation, db.Statement.Table).
+			Observe(time.Since(started.(time.Time)).Seconds())
+	}
+}
@@ -0,0 +1,172 @@
+// Package metrics collects Prometheus metrics and serves them on /metrics
+package metrics
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/infrastructures/logutil"
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "entities"
+
+// unmatchedRoute labels requests that matched no route, so scanners
+// can't create a series per path
+const unmatchedRoute = "unmatched"
+
+// otherMethod labels requests with a method that isn't standard, for the same reason
+const otherMethod = "other"
+
+// Results of logins and backups
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Registry holds every metric, along with Go runtime and process ones
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route pattern and status.",
+	}, []string{"method", "route", "status"})
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route pattern and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Password logins by result.",
+	}, []string{"result"})
+	backups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "backups_total",
+		Help:      "Backups by result.",
+	}, []string{"result"})
+	backupDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "backup_duration_seconds",
+		Help:      "How long backups took, failed ones included.",
+		// 1s to about 17m
+		Buckets: prometheus.ExponentialBuckets(1, 2, 11),
+	})
+	backupSize = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "backup_size_bytes",
+		Help:      "Size of the last successful backup as uploaded.",
+	})
+	backupLastSuccess = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "backup_last_success_timestamp_seconds",
+		Help:      "When the last successful backup started.",
+	})
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Database query latency by operation and table.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration, logins, backups, backupDuration, backupSize, backupLastSuccess,
+		dbQueryDuration,
+	)
+}
+
+func result(succeeded bool) string {
+	if succeeded {
+		return ResultSuccess
+	}
+	return ResultFailure
+}
+
+// Handler serves the metrics in Prometheus' format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Middleware records each request against the chi route pattern it matched
+// It must be used on the chi router, so the pattern is known
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(res, req.ProtoMajor)
+		next.ServeHTTP(ww, req)
+
+		route := unmatchedRoute
+		if rctx := chi.RouteContext(req.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			// Nothing was written
+			status = http.StatusOK
+		}
+		labels := prometheus.Labels{"method": method(req.Method), "route": route, "status": strconv.Itoa(status)}
+		httpRequests.With(labels).Inc()
+		httpDuration.With(labels).Observe(time.Since(start).Seconds())
+	})
+}
+
+func method(m string) string {
+	switch m {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return m
+	}
+	return otherMethod
+}
+
+// ObserveLogin counts a password login
+func ObserveLogin(succeeded bool) {
+	logins.WithLabelValues(result(succeeded)).Inc()
+}
+
+// ObserveBackup records how a backup went
+func ObserveBackup(run *models.BackupRun) {
+	backupDuration.Observe((time.Duration(run.DurationMs) * time.Millisecond).Seconds())
+	backups.WithLabelValues(result(run.Succeeded())).Inc()
+	if run.Succeeded() {
+		SetLastBackup(run)
+	}
+}
+
+// SetLastBackup sets the last successful backup without counting it,
+// for picking up where we left off after a restart
+func SetLastBackup(run *models.BackupRun) {
+	backupSize.Set(float64(run.Size))
+	backupLastSuccess.Set(float64(run.StartedAt.Unix()))
+}
+
+// RegisterArtistCount reports the size of the catalog, counted on each scrape
+func RegisterArtistCount(count func() (uint, error)) error {
+	return Registry.Register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "artists",
+		Help:      "Artists in the catalog.",
+	}, func() float64 {
+		n, err := count()
+		if err != nil {
+			logutil.Error("Failed to count artists for metrics", "error", err)
+			return math.NaN()
+		}
+		return float64(n)
+	}))
+}
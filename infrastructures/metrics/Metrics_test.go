@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMiddleware(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(Middleware)
+	r.Get("/artist/{artistID}", func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusNotFound)
+	})
+	r.Get("/artist/random", func(res http.ResponseWriter, req *http.Request) {})
+
+	for _, path := range []string{"/artist/1", "/artist/2", "/artist/random", "/scan/wp-login.php"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	for _, m := range []string{"SCAN1", "SCAN2", "get"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(m, "/artist/random", nil))
+	}
+
+	// Labelled by pattern, not path
+	assert.Equal(t, 2.0, testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/artist/{artistID}", "404")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/artist/random", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(httpRequests.WithLabelValues("GET", unmatchedRoute, "404")))
+	assert.Equal(t, uint64(1), samples(t, httpDuration.WithLabelValues("GET", "/artist/random", "200")))
+	// Made up methods share a series
+	assert.Equal(t, 3.0, testutil.ToFloat64(httpRequests.WithLabelValues(otherMethod, unmatchedRoute, "405")))
+}
+
+func TestObserveLogin(t *testing.T) {
+	successes := testutil.ToFloat64(logins.WithLabelValues(ResultSuccess))
+	failures := testutil.ToFloat64(logins.WithLabelValues(ResultFailure))
+
+	ObserveLogin(true)
+	ObserveLogin(false)
+	ObserveLogin(false)
+
+	assert.Equal(t, successes+1, testutil.ToFloat64(logins.WithLabelValues(ResultSuccess)))
+	assert.Equal(t, failures+2, testutil.ToFloat64(logins.WithLabelValues(ResultFailure)))
+}
+
+func TestObserveBackup(t *testing.T) {
+	started := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+
+	ObserveBackup(&models.BackupRun{StartedAt: started, DurationMs: 2500, Size: 4096})
+	// A failure doesn't replace the last success
+	ObserveBackup(&models.BackupRun{StartedAt: started.Add(time.Hour), Error: "disk full"})
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(backups.WithLabelValues(ResultSuccess)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(backups.WithLabelValues(ResultFailure)))
+	assert.Equal(t, 4096.0, testutil.ToFloat64(backupSize))
+	assert.Equal(t, float64(started.Unix()), testutil.ToFloat64(backupLastSuccess))
+}
+
+func TestRegisterArtistCount(t *testing.T) {
+	var err error
+	count := uint(12)
+	require.NoError(t, RegisterArtistCount(func() (uint, error) {
+		return count, err
+	}))
+	defer Registry.Unregister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{Namespace: namespace, Name: "artists",
+		Help: "Artists in the catalog."}, nil))
+
+	families, gatherErr := Registry.Gather()
+	require.NoError(t, gatherErr)
+	assert.Equal(t, 12.0, gauge(t, families, "entities_artists"))
+
+	err = errors.New("database is locked")
+	families, gatherErr = Registry.Gather()
+	require.NoError(t, gatherErr)
+	assert.True(t, math.IsNaN(gauge(t, families, "entities_artists")))
+}
+
+func TestGormPlugin(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(GormPlugin{}))
+	require.NoError(t, db.AutoMigrate(&models.Artist{}))
+
+	require.NoError(t, db.Create(&models.Artist{Name: "Autechre"}).Error)
+	var artist models.Artist
+	require.NoError(t, db.First(&artist).Error)
+	// Migrating ran raw queries too
+	raw := samples(t, dbQueryDuration.WithLabelValues("raw", ""))
+	require.NoError(t, db.Exec("SELECT 1").Error)
+
+	assert.Equal(t, uint64(1), samples(t, dbQueryDuration.WithLabelValues("create", "artists")))
+	assert.Equal(t, uint64(1), samples(t, dbQueryDuration.WithLabelValues("query", "artists")))
+	assert.Equal(t, raw+1, samples(t, dbQueryDuration.WithLabelValues("raw", "")))
+}
+
+// samples is how many observations a histogram has
+func samples(t *testing.T, observer prometheus.Observer) uint64 {
+	var metric dto.Metric
+	require.NoError(t, observer.(prometheus.Metric).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+// gauge finds a gauge's value in what was gathered
+func gauge(t *testing.T, families []*dto.MetricFamily, name string) float64 {
+	for _, family := range families {
+		if family.GetName() == name {
+			return family.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	t.Fatalf("%v wasn't gathered", name)
+	return 0
+}
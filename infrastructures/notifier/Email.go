@@ -0,0 +1,108 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/models"
+)
+
+const smtpTimeout = 30 * time.Second
+
+// headerValue stops a value adding headers of its own
+var headerValue = strings.NewReplacer("\r", " ", "\n", " ")
+
+// Email sends notifications over SMTP, using STARTTLS if the server offers it
+// Username and Password are optional
+type Email struct {
+	Addr     string
+	From     string
+	To       []string
+	Username string
+	Password string
+}
+
+func (e *Email) Name() string {
+	return "email"
+}
+
+func (e *Email) message(notification models.Notification) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %v\r\n", e.From)
+	fmt.Fprintf(&b, "To: %v\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&b, "Subject: %v\r\n", headerValue.Replace(notification.Subject))
+	fmt.Fprintf(&b, "Date: %v\r\n", notification.Time.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(text(notification), "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
+
+func (e *Email) Send(ctx context.Context, notification models.Notification) error {
+	host, _, err := net.SplitHostPort(e.Addr)
+	if err != nil {
+		return &permanentError{err}
+	}
+
+	dialer := net.Dialer{Timeout: smtpTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", e.Addr)
+	if err != nil {
+		return err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err = client.StartTLS(&tls.Config{ServerName: host})
+		if err != nil {
+			return err
+		}
+	}
+	if e.Username != "" {
+		// Refuses to send the password unencrypted, except to localhost
+		err = client.Auth(smtp.PlainAuth("", e.Username, e.Password, host))
+		if err != nil {
+			return &permanentError{err}
+		}
+	}
+
+	err = client.Mail(e.From)
+	if err != nil {
+		return err
+	}
+	for _, to := range e.To {
+		err = client.Rcpt(to)
+		if err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(e.message(notification))
+	if err != nil {
+		return err
+	}
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,142 @@
+// Package notifier tells people about failures, over webhooks and email
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/infrastructures/logutil"
+	"github.com/apkatsikas/artist-entities/models"
+)
+
+const (
+	DefaultRetries = 3
+	DefaultBackoff = time.Second
+	// sendTimeout bounds a notification, including its retries
+	sendTimeout = 2 * time.Minute
+)
+
+// Channel delivers a notification somewhere
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, notification models.Notification) error
+}
+
+// permanentError is a failure that retrying won't fix
+type permanentError struct {
+	err error
+}
+
+func (pe *permanentError) Error() string {
+	return pe.err.Error()
+}
+
+func (pe *permanentError) Unwrap() error {
+	return pe.err
+}
+
+// Notifier sends notifications to every channel in the background
+// Successes are only sent if OnSuccess is set
+type Notifier struct {
+	Channels  []Channel
+	OnSuccess bool
+	// Retries is how many times a failed send is retried
+	Retries int
+	// Backoff is the wait before the first retry, it doubles after each
+	Backoff time.Duration
+
+	wg sync.WaitGroup
+}
+
+// Notify sends notification in the background, failures are logged
+func (n *Notifier) Notify(notification models.Notification) {
+	if len(n.Channels) == 0 || (!notification.Failure && !n.OnSuccess) {
+		return
+	}
+	if notification.Time.IsZero() {
+		notification.Time = time.Now()
+	}
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		defer cancel()
+
+		err := n.Send(ctx, notification)
+		if err != nil {
+			logutil.Error("Failed to send notification", "event", notification.Event, "error", err)
+		}
+	}()
+}
+
+// Send sends notification to every channel, retrying failures
+func (n *Notifier) Send(ctx context.Context, notification models.Notification) error {
+	errs := []error{}
+	for _, channel := range n.Channels {
+		err := n.sendWithRetries(ctx, channel, notification)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", channel.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) sendWithRetries(ctx context.Context, channel Channel, notification models.Notification) error {
+	backoff := n.Backoff
+	for attempt := 0; ; attempt++ {
+		err := channel.Send(ctx, notification)
+		var permanent *permanentError
+		if err == nil || attempt >= n.Retries || errors.As(err, &permanent) {
+			return err
+		}
+
+		logutil.Warn("Failed to send notification, retrying", "event", notification.Event,
+			"channel", channel.Name(), "retryIn", backoff, "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return fmt.Errorf("%w, last error was %v", ctx.Err(), err)
+		}
+		backoff *= 2
+	}
+}
+
+// Wait waits for notifications being sent, or for ctx to be done
+func (n *Notifier) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// text renders a notification as plain text, fields sorted by name
+func text(notification models.Notification) string {
+	var b strings.Builder
+	b.WriteString(notification.Message)
+	b.WriteString("\n")
+
+	names := make([]string, 0, len(notification.Fields))
+	for name := range notification.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n%v: %v", name, notification.Fields[name])
+	}
+	fmt.Fprintf(&b, "\ntime: %v", notification.Time.UTC().Format(time.RFC3339))
+	return b.String()
+}
@@ -0,0 +1,169 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/infrastructures/notifytest"
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func backupFailed() models.Notification {
+	return models.Notification{
+		Event:   models.EventBackupFailed,
+		Failure: true,
+		Subject: "Backup failed",
+		Message: "disk full",
+		Time:    time.Date(2026, 10, 14, 2, 0, 0, 0, time.UTC),
+		Fields:  map[string]string{"trigger": "scheduled", "host": "db1"},
+	}
+}
+
+func TestWebhookJSON(t *testing.T) {
+	webhook := notifytest.NewWebhook()
+	defer webhook.Close()
+	notifier := &Notifier{Channels: []Channel{&Webhook{URL: webhook.URL(), Format: FormatJSON}}}
+
+	err := notifier.Send(context.Background(), backupFailed())
+
+	require.Nil(t, err)
+	received := webhook.Received()
+	require.Len(t, received, 1)
+	assert.Equal(t, models.EventBackupFailed, received[0]["event"])
+	assert.Equal(t, true, received[0]["failure"])
+	assert.Equal(t, "disk full", received[0]["message"])
+	assert.Equal(t, "2026-10-14T02:00:00Z", received[0]["time"])
+	assert.Equal(t, map[string]any{"trigger": "scheduled", "host": "db1"}, received[0]["fields"])
+}
+
+func TestWebhookSlack(t *testing.T) {
+	webhook := notifytest.NewWebhook()
+	defer webhook.Close()
+	notifier := &Notifier{Channels: []Channel{&Webhook{URL: webhook.URL(), Format: FormatSlack}}}
+
+	err := notifier.Send(context.Background(), backupFailed())
+
+	require.Nil(t, err)
+	received := webhook.Received()
+	require.Len(t, received, 1)
+	assert.Equal(t, "*Backup failed*\ndisk full\n\nhost: db1\ntrigger: scheduled\ntime: 2026-10-14T02:00:00Z",
+		received[0]["text"])
+}
+
+func TestRetriesWithBackoff(t *testing.T) {
+	webhook := notifytest.NewWebhook()
+	defer webhook.Close()
+	webhook.Failures = 2
+	notifier := &Notifier{
+		Channels: []Channel{&Webhook{URL: webhook.URL()}},
+		Retries:  2,
+		Backoff:  20 * time.Millisecond,
+	}
+
+	start := time.Now()
+	err := notifier.Send(context.Background(), backupFailed())
+
+	require.Nil(t, err)
+	assert.Len(t, webhook.Received(), 1)
+	// Waited 20ms then 40ms
+	assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond)
+}
+
+func TestRetriesRunOut(t *testing.T) {
+	webhook := notifytest.NewWebhook()
+	defer webhook.Close()
+	webhook.Failures = 3
+	notifier := &Notifier{
+		Channels: []Channel{&Webhook{URL: webhook.URL()}},
+		Retries:  2,
+		Backoff:  time.Millisecond,
+	}
+
+	err := notifier.Send(context.Background(), backupFailed())
+
+	assert.ErrorContains(t, err, "503")
+	assert.Empty(t, webhook.Received())
+}
+
+func TestNoRetryOnClientError(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		requests++
+		res.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	notifier := &Notifier{
+		Channels: []Channel{&Webhook{URL: server.URL}},
+		Retries:  3,
+		Backoff:  time.Millisecond,
+	}
+
+	err := notifier.Send(context.Background(), backupFailed())
+
+	assert.ErrorContains(t, err, "404")
+	assert.Equal(t, 1, requests)
+}
+
+func TestEmail(t *testing.T) {
+	smtp := notifytest.NewSMTP()
+	defer smtp.Close()
+	notification := backupFailed()
+	notification.Subject = "Backup failed\r\nBcc: someone@example.com"
+	notifier := &Notifier{Channels: []Channel{&Email{
+		Addr: smtp.Addr(), From: "entities@example.com", To: []string{"ops@example.com", "dev@example.com"},
+		Username: "entities", Password: "secret",
+	}}}
+
+	err := notifier.Send(context.Background(), notification)
+
+	require.Nil(t, err)
+	received := smtp.Received()
+	require.Len(t, received, 1)
+	assert.Equal(t, "entities@example.com", received[0].From)
+	assert.Equal(t, []string{"ops@example.com", "dev@example.com"}, received[0].To)
+	assert.Contains(t, received[0].Data, "Subject: Backup failed  Bcc: someone@example.com\r\n")
+	assert.Contains(t, received[0].Data, "disk full\r\n\r\nhost: db1\r\ntrigger: scheduled")
+}
+
+func TestNotifySkipsSuccesses(t *testing.T) {
+	webhook := notifytest.NewWebhook()
+	defer webhook.Close()
+	notifier := &Notifier{Channels: []Channel{&Webhook{URL: webhook.URL()}}}
+	succeeded := models.Notification{Event: models.EventBackupSucceeded, Subject: "Backup succeeded"}
+
+	notifier.Notify(succeeded)
+	notifier.Notify(backupFailed())
+	require.Nil(t, notifier.Wait(context.Background()))
+	assert.Len(t, webhook.Received(), 1)
+
+	// Unless asked for
+	notifier.OnSuccess = true
+	notifier.Notify(succeeded)
+	require.Nil(t, notifier.Wait(context.Background()))
+	received := webhook.Received()
+	require.Len(t, received, 2)
+	assert.Equal(t, models.EventBackupSucceeded, received[1]["event"])
+	assert.False(t, strings.HasPrefix(received[1]["time"].(string), "0001"))
+}
+
+func TestWaitTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	notifier := &Notifier{Channels: []Channel{&Webhook{URL: server.URL}}}
+
+	notifier.Notify(backupFailed())
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, notifier.Wait(ctx), context.DeadlineExceeded)
+}
@@ -0,0 +1,92 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/models"
+)
+
+// Webhook payload formats
+const (
+	FormatJSON  = "json"
+	FormatSlack = "slack"
+)
+
+// Webhook posts notifications as JSON
+// FormatJSON posts the notification itself, FormatSlack posts a message
+// for a Slack incoming webhook, which many other services accept too
+type Webhook struct {
+	URL    string
+	Format string
+	Client *http.Client
+}
+
+type webhookPayload struct {
+	Event   string            `json:"event"`
+	Failure bool              `json:"failure"`
+	Subject string            `json:"subject"`
+	Message string            `json:"message"`
+	Time    time.Time         `json:"time"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+func (w *Webhook) Name() string {
+	return "webhook"
+}
+
+func (w *Webhook) payload(notification models.Notification) any {
+	if w.Format == FormatSlack {
+		return slackPayload{Text: fmt.Sprintf("*%v*\n%v", notification.Subject, text(notification))}
+	}
+	return webhookPayload{
+		Event:   notification.Event,
+		Failure: notification.Failure,
+		Subject: notification.Subject,
+		Message: notification.Message,
+		Time:    notification.Time.UTC(),
+		Fields:  notification.Fields,
+	}
+}
+
+func (w *Webhook) Send(ctx context.Context, notification models.Notification) error {
+	body, err := json.Marshal(w.payload(notification))
+	if err != nil {
+		return &permanentError{err}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return &permanentError{err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 4096))
+
+	if res.StatusCode >= 300 {
+		err = fmt.Errorf("webhook returned %v", res.Status)
+		// Only worth retrying if the server might recover
+		if res.StatusCode < 500 && res.StatusCode != http.StatusTooManyRequests {
+			return &permanentError{err}
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+// Package notifytest runs stub webhook and SMTP servers in-process, recording
+// what they receive, for testing notifications without sending any
+package notifytest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Webhook records the JSON bodies posted to it
+type Webhook struct {
+	// Failures is how many requests to fail with a 503 before succeeding
+	Failures int
+
+	server   *httptest.Server
+	mu       sync.Mutex
+	received []map[string]any
+}
+
+// NewWebhook starts a webhook, Close it when done
+func NewWebhook() *Webhook {
+	w := &Webhook{}
+	w.server = httptest.NewServer(http.HandlerFunc(w.handle))
+	return w
+}
+
+func (w *Webhook) handle(res http.ResponseWriter, req *http.Request) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.Failures > 0 {
+		w.Failures--
+		res.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	var body map[string]any
+	err := json.NewDecoder(req.Body).Decode(&body)
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	w.received = append(w.received, body)
+}
+
+func (w *Webhook) URL() string {
+	return w.server.URL
+}
+
+// Received returns the bodies posted so far
+func (w *Webhook) Received() []map[string]any {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]map[string]any{}, w.received...)
+}
+
+func (w *Webhook) Close() {
+	w.server.Close()
+}
+
+// Mail is a message received by the SMTP server
+type Mail struct {
+	From string
+	To   []string
+	Data string
+}
+
+// SMTP accepts mail without TLS, and any AUTH PLAIN credentials
+type SMTP struct {
+	listener net.Listener
+	mu       sync.Mutex
+	received []Mail
+	wg       sync.WaitGroup
+}
+
+// NewSMTP starts an SMTP server on localhost, Close it when done
+func NewSMTP() *SMTP {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	s := &SMTP{listener: listener}
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+func (s *SMTP) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *SMTP) handle(conn net.Conn) {
+	// Don't hold up Close for a client that went quiet
+	conn.SetDeadline(time.Now().Add(time.Minute))
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		fmt.Fprintf(conn, "%v\r\n", line)
+	}
+
+	reply("220 notifytest ESMTP")
+	var mail Mail
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"):
+			reply("250-notifytest")
+			reply("250 AUTH PLAIN")
+		case strings.HasPrefix(command, "HELO"):
+			reply("250 notifytest")
+		case strings.HasPrefix(command, "AUTH"):
+			reply("235 Authenticated")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			mail = Mail{From: address(line)}
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			mail.To = append(mail.To, address(line))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			data, err := readData(r)
+			if err != nil {
+				return
+			}
+			mail.Data = data
+			s.mu.Lock()
+			s.received = append(s.received, mail)
+			s.mu.Unlock()
+			reply("250 OK")
+		case command == "RSET" || command == "NOOP":
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// address is what's between the angle brackets of MAIL FROM and RCPT TO
+func address(line string) string {
+	start, end := strings.Index(line, "<"), strings.LastIndex(line, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return line[start+1 : end]
+}
+
+func readData(r *bufio.Reader) (string, error) {
+	var b strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		if line == ".\r\n" {
+			return b.String(), nil
+		}
+		// Undo dot stuffing
+		b.WriteString(strings.TrimPrefix(line, "."))
+	}
+}
+
+func (s *SMTP) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Received returns the mail received so far
+func (s *SMTP) Received() []Mail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Mail{}, s.received...)
+}
+
+func (s *SMTP) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
@@ -0,0 +1,217 @@
+// Package oidctest runs a minimal OpenID Connect provider in-process for tests
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/infrastructures/jwtkeys"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "oidctest"
+
+// Identity is who the issuer logs in as
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+type authRequest struct {
+	redirectURI string
+	nonce       string
+	challenge   string
+	identity    Identity
+}
+
+// Issuer supports discovery, a JWKS, the authorization endpoint and the token
+// endpoint, requiring PKCE with S256
+// The authorization endpoint logs in as Identity without asking
+type Issuer struct {
+	ClientID     string
+	ClientSecret string
+	Identity     Identity
+	// Audience overrides the ID token audience when set
+	Audience string
+	// Nonce overrides the ID token nonce when set
+	Nonce string
+
+	server  *httptest.Server
+	keyring *jwtkeys.Keyring
+	mu      sync.Mutex
+	codes   map[string]authRequest
+}
+
+// NewIssuer starts an issuer, Close it when done
+func NewIssuer(clientID string, clientSecret string) *Issuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+
+	i := &Issuer{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Identity:     Identity{Subject: "1234", Email: "admin@example.com", EmailVerified: true},
+		keyring: jwtkeys.NewKeyring(jwtkeys.NewKey(keyID, jwt.SigningMethodRS256,
+			key, &key.PublicKey, time.Time{}, time.Time{})),
+		codes: map[string]authRequest{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", i.discovery)
+	mux.HandleFunc("/jwks", i.jwks)
+	mux.HandleFunc("/authorize", i.authorize)
+	mux.HandleFunc("/token", i.token)
+	i.server = httptest.NewServer(mux)
+
+	return i
+}
+
+func (i *Issuer) URL() string {
+	return i.server.URL
+}
+
+func (i *Issuer) Close() {
+	i.server.Close()
+}
+
+func writeJSON(res http.ResponseWriter, v any, status int) {
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(status)
+	json.NewEncoder(res).Encode(v)
+}
+
+func oauthError(res http.ResponseWriter, code string) {
+	writeJSON(res, map[string]string{"error": code}, http.StatusBadRequest)
+}
+
+func (i *Issuer) discovery(res http.ResponseWriter, req *http.Request) {
+	writeJSON(res, map[string]any{
+		"issuer":                                i.URL(),
+		"authorization_endpoint":                i.URL() + "/authorize",
+		"token_endpoint":                        i.URL() + "/token",
+		"jwks_uri":                              i.URL() + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	}, http.StatusOK)
+}
+
+func (i *Issuer) jwks(res http.ResponseWriter, req *http.Request) {
+	writeJSON(res, i.keyring.JWKS(), http.StatusOK)
+}
+
+func (i *Issuer) authorize(res http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || query.Get("redirect_uri") == "" {
+		http.Error(res, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if query.Get("client_id") != i.ClientID || query.Get("response_type") != "code" ||
+		query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(res, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+
+	code := make([]byte, 16)
+	rand.Read(code)
+
+	i.mu.Lock()
+	i.codes[hex.EncodeToString(code)] = authRequest{
+		redirectURI: redirectURI.String(),
+		nonce:       query.Get("nonce"),
+		challenge:   query.Get("code_challenge"),
+		identity:    i.Identity,
+	}
+	i.mu.Unlock()
+
+	params := redirectURI.Query()
+	params.Set("code", hex.EncodeToString(code))
+	params.Set("state", query.Get("state"))
+	redirectURI.RawQuery = params.Encode()
+	http.Redirect(res, req, redirectURI.String(), http.StatusFound)
+}
+
+func (i *Issuer) token(res http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost || req.ParseForm() != nil {
+		oauthError(res, "invalid_request")
+		return
+	}
+
+	clientID, clientSecret, ok := req.BasicAuth()
+	if !ok {
+		clientID, clientSecret = req.PostForm.Get("client_id"), req.PostForm.Get("client_secret")
+	}
+	if clientID != i.ClientID || clientSecret != i.ClientSecret {
+		oauthError(res, "invalid_client")
+		return
+	}
+
+	i.mu.Lock()
+	request, found := i.codes[req.PostForm.Get("code")]
+	delete(i.codes, req.PostForm.Get("code"))
+	i.mu.Unlock()
+
+	challenge := sha256.Sum256([]byte(req.PostForm.Get("code_verifier")))
+	if req.PostForm.Get("grant_type") != "authorization_code" || !found ||
+		req.PostForm.Get("redirect_uri") != request.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(challenge[:]) != request.challenge {
+		oauthError(res, "invalid_grant")
+		return
+	}
+
+	idToken, err := i.idToken(request)
+	if err != nil {
+		oauthError(res, "server_error")
+		return
+	}
+
+	writeJSON(res, map[string]any{
+		"access_token": "access",
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	}, http.StatusOK)
+}
+
+func (i *Issuer) idToken(request authRequest) (string, error) {
+	audience, nonce := i.ClientID, request.nonce
+	if i.Audience != "" {
+		audience = i.Audience
+	}
+	if i.Nonce != "" {
+		nonce = i.Nonce
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            i.URL(),
+		"sub":            request.identity.Subject,
+		"aud":            audience,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          nonce,
+		"email":          request.identity.Email,
+		"email_verified": request.identity.EmailVerified,
+	})
+	token.Header["kid"] = keyID
+
+	key, err := i.keyring.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	return token.SignedString(key.SignKey())
+}
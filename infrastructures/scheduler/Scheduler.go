@@ -0,0 +1,164 @@
+// Package scheduler runs named jobs on cron schedules
+//
+// Schedules are standard five field cron expressions or descriptors like
+// @hourly and @every 5m. They're in the scheduler's timezone unless they
+// start with CRON_TZ=, for example "CRON_TZ=Europe/London 0 2 * * *".
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/infrastructures/logutil"
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/robfig/cron/v3"
+)
+
+// Disabled is a schedule that never runs its job
+const Disabled = "off"
+
+type job struct {
+	name     string
+	schedule string
+	run      func(ctx context.Context) error
+	entryID  cron.EntryID
+
+	running      bool
+	lastRun      time.Time
+	lastDuration time.Duration
+	lastError    string
+	skipped      int
+}
+
+type Scheduler struct {
+	// OnFailure is called when a job fails, if set
+	OnFailure func(name string, err error)
+
+	cron *cron.Cron
+	mu   sync.Mutex
+	jobs map[string]*job
+	// ctx is given to jobs, it's cancelled if they don't stop in time
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// New returns a scheduler that runs jobs in location
+func New(location *time.Location) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		cron:   cron.New(cron.WithLocation(location)),
+		jobs:   map[string]*job{},
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Add registers run to be called on schedule
+// A run is skipped if the previous one is still going
+func (s *Scheduler) Add(name string, schedule string, run func(ctx context.Context) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[name]; ok {
+		return fmt.Errorf("job %v is already scheduled", name)
+	}
+	j := &job{name: name, schedule: schedule, run: run}
+	if schedule != Disabled {
+		parsed, err := cron.ParseStandard(schedule)
+		if err != nil {
+			return fmt.Errorf("invalid schedule %q for job %v: %v", schedule, name, err)
+		}
+		j.entryID = s.cron.Schedule(parsed, cron.FuncJob(func() { s.runJob(j) }))
+	}
+	s.jobs[name] = j
+	return nil
+}
+
+func (s *Scheduler) runJob(j *job) {
+	s.mu.Lock()
+	if j.running {
+		j.skipped++
+		s.mu.Unlock()
+		logutil.Warn("Skipped job, the previous run is still going", "job", j.name)
+		return
+	}
+	j.running = true
+	s.mu.Unlock()
+
+	start := time.Now()
+	err := s.call(j)
+
+	s.mu.Lock()
+	j.running = false
+	j.lastRun = start
+	j.lastDuration = time.Since(start)
+	j.lastError = ""
+	if err != nil {
+		j.lastError = err.Error()
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		logutil.Error("Job failed", "job", j.name, "error", err)
+		if s.OnFailure != nil {
+			s.OnFailure(j.name, err)
+		}
+	}
+}
+
+// call runs a job, turning a panic into an error so it's recorded
+func (s *Scheduler) call(j *job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return j.run(s.ctx)
+}
+
+// Jobs returns every job by name
+func (s *Scheduler) Jobs() []models.ScheduledJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := []models.ScheduledJob{}
+	for _, j := range s.jobs {
+		scheduled := models.ScheduledJob{
+			Name:         j.name,
+			Schedule:     j.schedule,
+			Running:      j.running,
+			LastRun:      j.lastRun,
+			LastDuration: j.lastDuration,
+			LastError:    j.lastError,
+			Skipped:      j.skipped,
+		}
+		if j.entryID != 0 {
+			scheduled.NextRun = s.cron.Entry(j.entryID).Next
+		}
+		jobs = append(jobs, scheduled)
+	}
+	sort.Slice(jobs, func(i, k int) bool {
+		return jobs[i].Name < jobs[k].Name
+	})
+	return jobs
+}
+
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop stops scheduling jobs and waits for running ones to finish
+// If ctx is done first the running jobs are cancelled
+func (s *Scheduler) Stop(ctx context.Context) error {
+	select {
+	case <-s.cron.Stop().Done():
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		return ctx.Err()
+	}
+}
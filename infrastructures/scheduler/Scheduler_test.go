@@ -0,0 +1,171 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func jobNamed(t *testing.T, s *Scheduler, name string) *job {
+	j, ok := s.jobs[name]
+	require.True(t, ok)
+	return j
+}
+
+func TestAddInvalid(t *testing.T) {
+	s := New(time.UTC)
+	noop := func(context.Context) error { return nil }
+
+	assert.NotNil(t, s.Add("backup", "every day", noop))
+	assert.NotNil(t, s.Add("backup", "CRON_TZ=Nowhere/Atall 0 2 * * *", noop))
+
+	require.Nil(t, s.Add("backup", "0 2 * * *", noop))
+	assert.NotNil(t, s.Add("backup", "@hourly", noop))
+}
+
+func TestRunRecordsOutcome(t *testing.T) {
+	s := New(time.UTC)
+	fail := true
+	require.Nil(t, s.Add("backup", "0 2 * * *", func(context.Context) error {
+		if fail {
+			return errors.New("disk full")
+		}
+		return nil
+	}))
+	j := jobNamed(t, s, "backup")
+
+	s.runJob(j)
+	jobs := s.Jobs()
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "disk full", jobs[0].LastError)
+	assert.False(t, jobs[0].LastRun.IsZero())
+
+	// A success clears the error
+	fail = false
+	s.runJob(j)
+	assert.Empty(t, s.Jobs()[0].LastError)
+}
+
+func TestRunRecordsPanic(t *testing.T) {
+	s := New(time.UTC)
+	require.Nil(t, s.Add("check", "@daily", func(context.Context) error { panic("boom") }))
+
+	s.runJob(jobNamed(t, s, "check"))
+
+	assert.Equal(t, "panic: boom", s.Jobs()[0].LastError)
+	assert.False(t, s.Jobs()[0].Running)
+}
+
+func TestNoOverlap(t *testing.T) {
+	s := New(time.UTC)
+	started, release := make(chan struct{}), make(chan struct{})
+	runs := 0
+	require.Nil(t, s.Add("backup", "@daily", func(context.Context) error {
+		runs++
+		close(started)
+		<-release
+		return nil
+	}))
+	j := jobNamed(t, s, "backup")
+
+	done := make(chan struct{})
+	go func() {
+		s.runJob(j)
+		close(done)
+	}()
+	<-started
+	assert.True(t, s.Jobs()[0].Running)
+
+	// Fires again while the first run is going
+	s.runJob(j)
+	close(release)
+	<-done
+
+	assert.Equal(t, 1, runs)
+	assert.Equal(t, 1, s.Jobs()[0].Skipped)
+	assert.False(t, s.Jobs()[0].Running)
+}
+
+func TestNextRun(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	require.Nil(t, err)
+	s := New(london)
+	noop := func(context.Context) error { return nil }
+	require.Nil(t, s.Add("backup", "0 2 * * *", noop))
+	require.Nil(t, s.Add("cleanup", "CRON_TZ=UTC 30 4 * * *", noop))
+	require.Nil(t, s.Add("check", Disabled, noop))
+	s.Start()
+	defer s.Stop(context.Background())
+
+	jobs := s.Jobs()
+	require.Len(t, jobs, 3)
+
+	// Sorted by name
+	backup, check, cleanup := jobs[0], jobs[1], jobs[2]
+	assert.Equal(t, 2, backup.NextRun.In(london).Hour())
+	assert.Equal(t, 4, cleanup.NextRun.UTC().Hour())
+	assert.Equal(t, 30, cleanup.NextRun.UTC().Minute())
+	assert.True(t, check.NextRun.IsZero())
+}
+
+func TestStopWaitsForRunningJobs(t *testing.T) {
+	s := New(time.UTC)
+	finished := false
+	require.Nil(t, s.Add("backup", "@every 1s", func(context.Context) error {
+		time.Sleep(200 * time.Millisecond)
+		finished = true
+		return nil
+	}))
+	s.Start()
+
+	// Wait for it to start
+	require.Eventually(t, func() bool { return s.Jobs()[0].Running }, 3*time.Second, 10*time.Millisecond)
+
+	err := s.Stop(context.Background())
+
+	assert.Nil(t, err)
+	assert.True(t, finished)
+}
+
+func TestStopTimesOut(t *testing.T) {
+	s := New(time.UTC)
+	release, cancelled := make(chan struct{}), make(chan error, 1)
+	defer close(release)
+	require.Nil(t, s.Add("backup", "@every 1s", func(ctx context.Context) error {
+		select {
+		case <-release:
+		case <-ctx.Done():
+			cancelled <- ctx.Err()
+		}
+		return nil
+	}))
+	s.Start()
+	require.Eventually(t, func() bool { return s.Jobs()[0].Running }, 3*time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := s.Stop(ctx)
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	// The job is told to give up
+	assert.ErrorIs(t, <-cancelled, context.Canceled)
+}
+
+func TestOnFailure(t *testing.T) {
+	s := New(time.UTC)
+	failed := map[string]string{}
+	s.OnFailure = func(name string, err error) {
+		failed[name] = err.Error()
+	}
+	require.Nil(t, s.Add("check", "@daily", func(context.Context) error { return errors.New("corrupt") }))
+	require.Nil(t, s.Add("cleanup", "@daily", func(context.Context) error { return nil }))
+
+	s.runJob(jobNamed(t, s, "check"))
+	s.runJob(jobNamed(t, s, "cleanup"))
+
+	assert.Equal(t, map[string]string{"check": "corrupt"}, failed)
+}
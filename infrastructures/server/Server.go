@@ -0,0 +1,174 @@
+// Package server serves HTTP until it's told to stop, then drains
+// in-flight requests
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/infrastructures/logutil"
+)
+
+// DefaultConfig leaves enough time to restore a backup in a request
+var DefaultConfig = Config{
+	Addr:              ":8080",
+	MetricsAddr:       "localhost:9090",
+	ReadHeaderTimeout: 10 * time.Second,
+	ReadTimeout:       30 * time.Second,
+	WriteTimeout:      5 * time.Minute,
+	IdleTimeout:       2 * time.Minute,
+	ShutdownTimeout:   30 * time.Second,
+	TLS: TLSConfig{
+		ReloadInterval: time.Minute,
+		HSTSMaxAge:     365 * 24 * time.Hour,
+	},
+}
+
+type Config struct {
+	Addr              string        `yaml:"addr" env:"SERVER_ADDR" usage:"Address to listen on"`
+	MetricsAddr       string        `yaml:"metricsAddr" env:"SERVER_METRICS_ADDR" usage:"Address to serve /metrics on, empty to not serve them"`
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout" env:"SERVER_READ_HEADER_TIMEOUT" usage:"Timeout for reading request headers"`
+	ReadTimeout       time.Duration `yaml:"readTimeout" env:"SERVER_READ_TIMEOUT" usage:"Timeout for reading a whole request"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout" env:"SERVER_WRITE_TIMEOUT" usage:"Timeout for handling a request and writing its response"`
+	IdleTimeout       time.Duration `yaml:"idleTimeout" env:"SERVER_IDLE_TIMEOUT" usage:"How long idle keep-alive connections stay open"`
+	// ShutdownTimeout bounds waiting for in-flight requests, what's left is cut off
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" env:"SERVER_SHUTDOWN_TIMEOUT" usage:"How long in-flight requests get to finish on shutdown"`
+	TLS             TLSConfig     `yaml:"tls"`
+}
+
+type Server struct {
+	config  Config
+	server  *http.Server
+	metrics http.Handler
+}
+
+// New loads the TLS certificate, if there is one
+// metrics is served over plain HTTP on the metrics address
+func New(config Config, handler http.Handler, metrics http.Handler) (*Server, error) {
+	s := &Server{
+		config: config,
+		server: &http.Server{
+			Addr:              config.Addr,
+			Handler:           handler,
+			ReadHeaderTimeout: config.ReadHeaderTimeout,
+			ReadTimeout:       config.ReadTimeout,
+			WriteTimeout:      config.WriteTimeout,
+			IdleTimeout:       config.IdleTimeout,
+		},
+		metrics: metrics,
+	}
+	if config.TLS.Enabled() {
+		cert, err := loadCertificate(config.TLS)
+		if err != nil {
+			return nil, err
+		}
+		s.server.TLSConfig = &tls.Config{GetCertificate: cert.GetCertificate, MinVersion: tls.VersionTLS12}
+		if config.TLS.HSTSMaxAge > 0 {
+			s.server.Handler = hsts(config.TLS.HSTSMaxAge, handler)
+		}
+	}
+	return s, nil
+}
+
+// Run listens on the configured addresses and serves until ctx is done
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		return err
+	}
+	var redirectListener net.Listener
+	if s.config.TLS.Enabled() && s.config.TLS.RedirectAddr != "" {
+		redirectListener, err = net.Listen("tcp", s.config.TLS.RedirectAddr)
+		if err != nil {
+			listener.Close()
+			return err
+		}
+	}
+	var metricsListener net.Listener
+	if s.metrics != nil && s.config.MetricsAddr != "" {
+		metricsListener, err = net.Listen("tcp", s.config.MetricsAddr)
+		if err != nil {
+			listener.Close()
+			if redirectListener != nil {
+				redirectListener.Close()
+			}
+			return err
+		}
+	}
+	return s.Serve(ctx, listener, redirectListener, metricsListener)
+}
+
+// plain serves handler over HTTP with the configured timeouts
+func (s *Server) plain(handler http.Handler) *http.Server {
+	return &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: s.config.ReadHeaderTimeout,
+		ReadTimeout:       s.config.ReadTimeout,
+		WriteTimeout:      s.config.WriteTimeout,
+		IdleTimeout:       s.config.IdleTimeout,
+	}
+}
+
+// Serve serves on listener until ctx is done, then stops accepting connections
+// and waits for in-flight requests
+// Plain HTTP on redirectListener, if it isn't nil, is redirected to listener
+// Metrics are served on metricsListener, if it isn't nil
+// It returns an error if serving failed or requests didn't finish in time
+func (s *Server) Serve(ctx context.Context, listener net.Listener, redirectListener net.Listener,
+	metricsListener net.Listener) error {
+	servers := []*http.Server{s.server}
+	errs := make(chan error, 3)
+	go func() {
+		if s.server.TLSConfig != nil {
+			errs <- s.server.ServeTLS(listener, "", "")
+		} else {
+			errs <- s.server.Serve(listener)
+		}
+	}()
+	logutil.Info("Listening", "addr", listener.Addr().String())
+
+	if redirectListener != nil {
+		_, port, _ := net.SplitHostPort(listener.Addr().String())
+		redirectServer := s.plain(redirect(port))
+		servers = append(servers, redirectServer)
+		go func() {
+			errs <- redirectServer.Serve(redirectListener)
+		}()
+		logutil.Info("Redirecting HTTP to HTTPS", "addr", redirectListener.Addr().String())
+	}
+
+	if metricsListener != nil {
+		metricsServer := s.plain(s.metrics)
+		servers = append(servers, metricsServer)
+		go func() {
+			errs <- metricsServer.Serve(metricsListener)
+		}()
+		logutil.Info("Serving metrics", "addr", metricsListener.Addr().String())
+	}
+
+	var err error
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		logutil.Info("Shutting down, waiting for in-flight requests...")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
+	defer cancel()
+	for _, server := range servers {
+		shutdownErr := server.Shutdown(shutdownCtx)
+		if errors.Is(shutdownErr, context.DeadlineExceeded) {
+			server.Close()
+			shutdownErr = fmt.Errorf("requests were still running after %v", s.config.ShutdownTimeout)
+		}
+		if err == nil {
+			err = shutdownErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// start serves handler on a free port, returning its URL and the result of Serve
+func start(t *testing.T, ctx context.Context, config Config, handler http.Handler) (string, chan error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server, err := New(config, handler, nil)
+	require.NoError(t, err)
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(ctx, listener, nil, nil)
+	}()
+	return "http://" + listener.Addr().String(), done
+}
+
+// slowHandler responds once released, after telling started it has a request
+func slowHandler(started chan struct{}, release chan struct{}) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(res, "done")
+	})
+}
+
+func TestServeDrainsRequests(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	started, release := make(chan struct{}), make(chan struct{})
+	url, done := start(t, ctx, DefaultConfig, slowHandler(started, release))
+
+	type result struct {
+		body string
+		err  error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		res, err := http.Get(url)
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		responses <- result{string(body), err}
+	}()
+	<-started
+
+	cancel()
+	// Still waiting on the request
+	select {
+	case err := <-done:
+		t.Fatalf("Serve returned %v before the request finished", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	// No new connections are accepted
+	_, err := http.Get(url)
+	assert.Error(t, err)
+
+	close(release)
+	response := <-responses
+	require.NoError(t, response.err)
+	assert.Equal(t, "done", response.body)
+	assert.NoError(t, <-done)
+}
+
+func TestServeShutdownTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	config := DefaultConfig
+	config.ShutdownTimeout = 10 * time.Millisecond
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	url, done := start(t, ctx, config, slowHandler(started, release))
+
+	go http.Get(url)
+	<-started
+	cancel()
+
+	assert.ErrorContains(t, <-done, "requests were still running after 10ms")
+}
+
+func TestRunAddressInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	config := DefaultConfig
+	config.Addr = listener.Addr().String()
+
+	server, err := New(config, http.NotFoundHandler(), nil)
+	require.NoError(t, err)
+	err = server.Run(context.Background())
+
+	assert.ErrorContains(t, err, "address already in use")
+}
+
+func TestServeMetrics(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	metricsListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	metrics := http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		io.WriteString(res, "metrics")
+	})
+	server, err := New(DefaultConfig, http.NotFoundHandler(), metrics)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(ctx, listener, nil, metricsListener)
+	}()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-done)
+	}()
+
+	tests := []struct {
+		name     string
+		addr     string
+		expected int
+	}{
+		{name: "metrics address", addr: metricsListener.Addr().String(), expected: http.StatusOK},
+		{name: "public address", addr: listener.Addr().String(), expected: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := http.Get("http://" + tt.addr + "/metrics")
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.expected, res.StatusCode)
+		})
+	}
+}
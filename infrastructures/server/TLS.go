@@ -0,0 +1,126 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/infrastructures/logutil"
+)
+
+// TLS is off unless a certificate and key are set
+type TLSConfig struct {
+	CertFile string `yaml:"certFile" env:"TLS_CERT_FILE" usage:"TLS certificate file, serve HTTPS when set"`
+	KeyFile  string `yaml:"keyFile" env:"TLS_KEY_FILE" usage:"TLS private key file"`
+	// ReloadInterval is how often the files are checked for renewals
+	ReloadInterval time.Duration `yaml:"reloadInterval" env:"TLS_RELOAD_INTERVAL" usage:"How often the certificate files are checked for changes"`
+	// RedirectAddr is empty for no plain HTTP listener
+	RedirectAddr string `yaml:"redirectAddr" env:"TLS_REDIRECT_ADDR" usage:"Address to redirect plain HTTP to HTTPS from, like :80"`
+	// HSTSMaxAge is 0 to not send a Strict-Transport-Security header
+	HSTSMaxAge time.Duration `yaml:"hstsMaxAge" env:"TLS_HSTS_MAX_AGE" usage:"How long browsers should only use HTTPS, 0 turns HSTS off"`
+}
+
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// certificate serves a certificate pair, reloading it when the files change
+// A pair that fails to load is logged and the previous one kept serving
+type certificate struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modified  [2]time.Time
+	checkedAt time.Time
+}
+
+func loadCertificate(config TLSConfig) (*certificate, error) {
+	c := &certificate{certFile: config.CertFile, keyFile: config.KeyFile, interval: config.ReloadInterval}
+	modified, err := c.modTimes()
+	if err != nil {
+		return nil, err
+	}
+	err = c.load(modified)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *certificate) modTimes() ([2]time.Time, error) {
+	var modified [2]time.Time
+	for i, file := range []string{c.certFile, c.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return modified, err
+		}
+		modified[i] = info.ModTime()
+	}
+	return modified, nil
+}
+
+func (c *certificate) load(modified [2]time.Time) error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %v", err)
+	}
+	c.cert, c.modified, c.checkedAt = &cert, modified, time.Now()
+	return nil
+}
+
+// GetCertificate is for tls.Config, it checks the files at most once an interval
+func (c *certificate) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.checkedAt) < c.interval {
+		return c.cert, nil
+	}
+	c.checkedAt = time.Now()
+	modified, err := c.modTimes()
+	if err != nil {
+		logutil.Error("Failed to check the TLS certificate for changes", "error", err)
+		return c.cert, nil
+	}
+	if modified != c.modified {
+		err = c.load(modified)
+		if err != nil {
+			// The files might be half written, try again next interval
+			logutil.Error("Keeping the previous TLS certificate", "error", err)
+		} else {
+			logutil.Info("Reloaded the TLS certificate")
+		}
+	}
+	return c.cert, nil
+}
+
+// hsts tells browsers to only use HTTPS from now on
+func hsts(maxAge time.Duration, next http.Handler) http.Handler {
+	value := fmt.Sprintf("max-age=%d", int64(maxAge.Seconds()))
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Strict-Transport-Security", value)
+		next.ServeHTTP(res, req)
+	})
+}
+
+// redirect sends plain HTTP requests to the same URL over HTTPS on httpsPort
+func redirect(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		host := req.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+		// 308 so clients repeat POSTs rather than turning them into GETs
+		http.Redirect(res, req, "https://"+host+req.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
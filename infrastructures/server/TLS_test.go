@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCert writes a self-signed certificate for 127.0.0.1 named name,
+// modified at modified so reloads notice it
+func writeCert(t *testing.T, config TLSConfig, name string, modified time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(config.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(config.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	require.NoError(t, os.Chtimes(config.CertFile, modified, modified))
+	require.NoError(t, os.Chtimes(config.KeyFile, modified, modified))
+}
+
+func tlsConfig(t *testing.T) Config {
+	dir := t.TempDir()
+	config := DefaultConfig
+	config.TLS.CertFile = filepath.Join(dir, "cert.pem")
+	config.TLS.KeyFile = filepath.Join(dir, "key.pem")
+	config.TLS.ReloadInterval = 0
+	return config
+}
+
+// startTLS serves on free ports, redirecting plain HTTP, and returns both addresses
+func startTLS(t *testing.T, config Config) (string, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	redirectListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server, err := New(config, http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {}), nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(ctx, listener, redirectListener, nil)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+	return listener.Addr().String(), redirectListener.Addr().String()
+}
+
+// served returns the name on the certificate addr serves and its HSTS header
+func served(t *testing.T, addr string) (string, string) {
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		DisableKeepAlives: true,
+	}}
+	res, err := client.Get("https://" + addr + "/artist/1")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	return res.TLS.PeerCertificates[0].Subject.CommonName, res.Header.Get("Strict-Transport-Security")
+}
+
+func TestServeTLS(t *testing.T) {
+	config := tlsConfig(t)
+	writeCert(t, config.TLS, "first", time.Now())
+	addr, _ := startTLS(t, config)
+
+	name, hsts := served(t, addr)
+
+	assert.Equal(t, "first", name)
+	assert.Equal(t, "max-age=31536000", hsts)
+}
+
+func TestServeTLSWithoutHSTS(t *testing.T) {
+	config := tlsConfig(t)
+	config.TLS.HSTSMaxAge = 0
+	writeCert(t, config.TLS, "first", time.Now())
+	addr, _ := startTLS(t, config)
+
+	_, hsts := served(t, addr)
+
+	assert.Empty(t, hsts)
+}
+
+func TestServeTLSReloads(t *testing.T) {
+	config := tlsConfig(t)
+	writeCert(t, config.TLS, "first", time.Now().Add(-time.Minute))
+	addr, _ := startTLS(t, config)
+	name, _ := served(t, addr)
+	require.Equal(t, "first", name)
+
+	writeCert(t, config.TLS, "renewed", time.Now())
+	name, _ = served(t, addr)
+	assert.Equal(t, "renewed", name)
+
+	// A broken renewal keeps the working certificate
+	require.NoError(t, os.WriteFile(config.TLS.KeyFile, []byte("half written"), 0600))
+	name, _ = served(t, addr)
+	assert.Equal(t, "renewed", name)
+}
+
+func TestServeTLSReloadInterval(t *testing.T) {
+	config := tlsConfig(t)
+	config.TLS.ReloadInterval = time.Hour
+	writeCert(t, config.TLS, "first", time.Now().Add(-time.Minute))
+	addr, _ := startTLS(t, config)
+
+	writeCert(t, config.TLS, "renewed", time.Now())
+	name, _ := served(t, addr)
+
+	// Not checked again until the interval is up
+	assert.Equal(t, "first", name)
+}
+
+func TestRedirect(t *testing.T) {
+	config := tlsConfig(t)
+	writeCert(t, config.TLS, "first", time.Now())
+	addr, redirectAddr := startTLS(t, config)
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	res, err := client.Post("http://"+redirectAddr+"/artist?name=a", "application/json", nil)
+
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusPermanentRedirect, res.StatusCode)
+	assert.Equal(t, "https://"+addr+"/artist?name=a", res.Header.Get("Location"))
+}
+
+func TestRedirectDefaultPort(t *testing.T) {
+	res := httptest.NewRecorder()
+
+	redirect("443").ServeHTTP(res, httptest.NewRequest(http.MethodGet, "http://example.com:80/login", nil))
+
+	assert.Equal(t, "https://example.com/login", res.Header().Get("Location"))
+}
+
+func TestNewMissingCertificate(t *testing.T) {
+	config := tlsConfig(t)
+
+	_, err := New(config, http.NotFoundHandler(), nil)
+
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
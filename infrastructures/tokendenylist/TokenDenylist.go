@@ -0,0 +1,78 @@
+package tokendenylist
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/interfaces"
+)
+
+// TokenDenylist keeps revoked token IDs in SQLite with an in-memory copy
+// so checking a token never hits the database
+type TokenDenylist struct {
+	Repository interfaces.IRevokedTokenRepository
+
+	mu      sync.RWMutex
+	revoked map[string]time.Time
+}
+
+// Load fills the cache from the database
+func (td *TokenDenylist) Load() error {
+	tokens, err := td.Repository.ListActive(time.Now())
+	if err != nil {
+		return err
+	}
+
+	revoked := map[string]time.Time{}
+	for _, token := range tokens {
+		revoked[token.JTI] = token.ExpiresAt
+	}
+
+	td.mu.Lock()
+	defer td.mu.Unlock()
+	td.revoked = revoked
+	return nil
+}
+
+// Revoke denies a token ID until it would have expired anyway
+func (td *TokenDenylist) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	err := td.Repository.Create(ctx, jti, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	td.mu.Lock()
+	defer td.mu.Unlock()
+	if td.revoked == nil {
+		td.revoked = map[string]time.Time{}
+	}
+	td.revoked[jti] = expiresAt
+	return nil
+}
+
+func (td *TokenDenylist) IsRevoked(jti string) bool {
+	td.mu.RLock()
+	defer td.mu.RUnlock()
+
+	_, ok := td.revoked[jti]
+	return ok
+}
+
+// Cleanup forgets tokens that have expired, returning how many were removed
+func (td *TokenDenylist) Cleanup() (int64, error) {
+	now := time.Now()
+	deleted, err := td.Repository.DeleteExpired(now)
+	if err != nil {
+		return 0, err
+	}
+
+	td.mu.Lock()
+	defer td.mu.Unlock()
+	for jti, expiresAt := range td.revoked {
+		if !expiresAt.After(now) {
+			delete(td.revoked, jti)
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,67 @@
+package tokendenylist
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/interfaces/mocks"
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	repository := mocks.NewIRevokedTokenRepository(t)
+	repository.EXPECT().ListActive(mock.AnythingOfType("time.Time")).Return([]models.RevokedToken{
+		{JTI: "a", ExpiresAt: time.Now().Add(time.Minute)},
+	}, nil)
+
+	denylist := TokenDenylist{Repository: repository}
+	require.NoError(t, denylist.Load())
+
+	assert.True(t, denylist.IsRevoked("a"))
+	assert.False(t, denylist.IsRevoked("b"))
+}
+
+func TestRevoke(t *testing.T) {
+	expiresAt := time.Now().Add(time.Minute)
+	repository := mocks.NewIRevokedTokenRepository(t)
+	repository.EXPECT().Create(mock.Anything, "a", expiresAt).Return(nil)
+
+	denylist := TokenDenylist{Repository: repository}
+	require.NoError(t, denylist.Revoke(context.Background(), "a", expiresAt))
+
+	assert.True(t, denylist.IsRevoked("a"))
+}
+
+func TestRevokeFails(t *testing.T) {
+	expiresAt := time.Now().Add(time.Minute)
+	repository := mocks.NewIRevokedTokenRepository(t)
+	repository.EXPECT().Create(mock.Anything, "a", expiresAt).Return(fmt.Errorf("locked"))
+
+	denylist := TokenDenylist{Repository: repository}
+	require.Error(t, denylist.Revoke(context.Background(), "a", expiresAt))
+
+	// Not cached unless it was persisted
+	assert.False(t, denylist.IsRevoked("a"))
+}
+
+func TestCleanup(t *testing.T) {
+	repository := mocks.NewIRevokedTokenRepository(t)
+	repository.EXPECT().Create(mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	repository.EXPECT().DeleteExpired(mock.AnythingOfType("time.Time")).Return(1, nil)
+
+	denylist := TokenDenylist{Repository: repository}
+	require.NoError(t, denylist.Revoke(context.Background(), "old", time.Now().Add(-time.Second)))
+	require.NoError(t, denylist.Revoke(context.Background(), "live", time.Now().Add(time.Minute)))
+
+	deleted, err := denylist.Cleanup()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	assert.False(t, denylist.IsRevoked("old"))
+	assert.True(t, denylist.IsRevoked("live"))
+}
@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"errors"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+// GormPlugin starts a span for each query, use it with gorm.DB.Use
+// Queries only get spans inside a trace, so startup and migrations don't
+// each become one
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	return errors.Join(
+		callback.Create().Before("*").Register("tracing:before_create", start("create")),
+		callback.Create().After("*").Register("tracing:after_create", end),
+		callback.Query().Before("*").Register("tracing:before_query", start("query")),
+		callback.Query().After("*").Register("tracing:after_query", end),
+		callback.Update().Before("*").Register("tracing:before_update", start("update")),
+		callback.Update().After("*").Register("tracing:after_update", end),
+		callback.Delete().Before("*").Register("tracing:before_delete", start("delete")),
+		callback.Delete().After("*").Register("tracing:after_delete", end),
+		callback.Row().Before("*").Register("tracing:before_row", start("row")),
+		callback.Row().After("*").Register("tracing:after_row", end),
+		callback.Raw().Before("*").Register("tracing:before_raw", start("raw")),
+		callback.Raw().After("*").Register("tracing:after_raw", end),
+	)
+}
+
+func start(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			return
+		}
+		name := "gorm." + operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		_, span := tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemSqlite, semconv.DBOperation(operation)))
+		if db.Statement.Table != "" {
+			span.SetAttributes(semconv.DBSQLTable(db.Statement.Table))
+		}
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func end(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	span.SetAttributes(semconv.DBStatement(db.Statement.SQL.String()))
+	End(span, db.Error)
+}
@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/apkatsikas/artist-entities/interfaces"
+	"github.com/apkatsikas/artist-entities/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const objectKey = attribute.Key("storage.object")
+
+// StorageClient starts a span for each operation on the client it wraps
+type StorageClient struct {
+	StorageClient interfaces.IStorageClient
+}
+
+func (sc *StorageClient) start(ctx context.Context, name string, object string) (context.Context, trace.Span) {
+	ctx, span := tracer().Start(ctx, "StorageClient."+name, trace.WithSpanKind(trace.SpanKindClient))
+	if object != "" {
+		span.SetAttributes(objectKey.String(object))
+	}
+	return ctx, span
+}
+
+func (sc *StorageClient) DeleteFile(ctx context.Context, object string) (err error) {
+	ctx, span := sc.start(ctx, "DeleteFile", object)
+	defer func() { End(span, err) }()
+	return sc.StorageClient.DeleteFile(ctx, object)
+}
+
+func (sc *StorageClient) UploadFile(ctx context.Context, path string, destObject string) (err error) {
+	ctx, span := sc.start(ctx, "UploadFile", destObject)
+	defer func() { End(span, err) }()
+	return sc.StorageClient.UploadFile(ctx, path, destObject)
+}
+
+func (sc *StorageClient) DownloadFile(ctx context.Context, object string, destPath string) (err error) {
+	ctx, span := sc.start(ctx, "DownloadFile", object)
+	defer func() { End(span, err) }()
+	return sc.StorageClient.DownloadFile(ctx, object, destPath)
+}
+
+func (sc *StorageClient) ListFiles(ctx context.Context) (files []models.BackupFile, err error) {
+	ctx, span := sc.start(ctx, "ListFiles", "")
+	defer func() { End(span, err) }()
+	return sc.StorageClient.ListFiles(ctx)
+}
+
+func (sc *StorageClient) Ping(ctx context.Context) (err error) {
+	ctx, span := sc.start(ctx, "Ping", "")
+	defer func() { End(span, err) }()
+	return sc.StorageClient.Ping(ctx)
+}
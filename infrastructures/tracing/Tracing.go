@@ -0,0 +1,117 @@
+// Package tracing sets up OpenTelemetry and starts spans for requests,
+// services, queries and storage
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	ce "github.com/apkatsikas/artist-entities/customerrors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+const instrumentation = "github.com/apkatsikas/artist-entities"
+
+// DefaultConfig traces nothing
+var DefaultConfig = Config{
+	Exporter:    ExporterNone,
+	ServiceName: "artist-entities",
+	SampleRatio: 1,
+}
+
+type Config struct {
+	Exporter string `yaml:"exporter" env:"TRACING_EXPORTER" usage:"Where traces go, none, otlp or stdout"`
+	// Endpoint is the collector's host:port, empty for the OTLP default of localhost:4318
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT" usage:"OTLP/HTTP collector host:port"`
+	Insecure    bool    `yaml:"insecure" env:"TRACING_INSECURE" usage:"Send traces to the collector over plain HTTP"`
+	ServiceName string  `yaml:"serviceName" env:"TRACING_SERVICE_NAME" usage:"Service name traces are reported under"`
+	SampleRatio float64 `yaml:"sampleRatio" env:"TRACING_SAMPLE_RATIO" usage:"Fraction of new traces to sample, from 0 to 1"`
+}
+
+// Setup installs a tracer provider exporting as configured and W3C trace
+// context propagation
+// The returned function flushes and stops exporting, call it on shutdown
+func Setup(config Config, version string) (func(ctx context.Context) error, error) {
+	return setup(config, version, os.Stdout)
+}
+
+func setup(config Config, version string, stdout io.Writer) (func(ctx context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+	if config.Exporter == ExporterNone {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch config.Exporter {
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if config.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(context.Background(), options...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(stdout), stdouttrace.WithPrettyPrint())
+	default:
+		err = fmt.Errorf("unknown exporter %q", config.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %v", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		// Follow the caller's decision for traces that started elsewhere
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceName(config.ServiceName),
+			semconv.ServiceVersion(version),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentation)
+}
+
+// Start starts a span named after what's being called, like ArtistService.Get
+func Start(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name)
+}
+
+// End records err, if there is one, then ends the span
+// Not finding a record is an answer, not a failure, so it isn't recorded
+func End(span trace.Span, err error) {
+	if errors.Is(err, ce.ErrRecordNotFound) || errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
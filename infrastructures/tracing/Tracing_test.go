@@ -0,0 +1,188 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ce "github.com/apkatsikas/artist-entities/customerrors"
+	"github.com/apkatsikas/artist-entities/interfaces/mocks"
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// recordSpans traces everything into the returned exporter until the test ends
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	_, err := Setup(Config{Exporter: ExporterNone}, "test")
+	require.NoError(t, err)
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return exporter
+}
+
+func named(spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	for _, span := range spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	return tracetest.SpanStub{}
+}
+
+func TestMiddleware(t *testing.T) {
+	exporter := recordSpans(t)
+	r := chi.NewRouter()
+	r.Use(Middleware)
+	r.Get("/artist/{artistID}", func(res http.ResponseWriter, req *http.Request) {
+		_, span := Start(req.Context(), "ArtistService.Get")
+		End(span, nil)
+		res.WriteHeader(http.StatusNotFound)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/artist/3", nil)
+	req.Header.Set("traceparent", traceparent)
+
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	server := named(spans, "GET /artist/{artistID}")
+	service := named(spans, "ArtistService.Get")
+	// Continues the caller's trace, with the service inside the request
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	assert.Equal(t, server.SpanContext.SpanID(), service.Parent.SpanID())
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Contains(t, server.Attributes, semconv.HTTPRoute("/artist/{artistID}"))
+	assert.Contains(t, server.Attributes, semconv.HTTPResponseStatusCode(http.StatusNotFound))
+	// A 404 isn't our error
+	assert.Equal(t, codes.Unset, server.Status.Code)
+}
+
+func TestMiddlewareServerError(t *testing.T) {
+	exporter := recordSpans(t)
+	r := chi.NewRouter()
+	r.Use(Middleware)
+	r.Post("/artist", func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(http.StatusInternalServerError)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/artist", nil))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "POST /artist", spans[0].Name)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+}
+
+func TestEnd(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected codes.Code
+	}{
+		{name: "no error", expected: codes.Unset},
+		{name: "not found", err: fmt.Errorf("artist 3: %w", ce.ErrRecordNotFound), expected: codes.Unset},
+		{name: "failed", err: errors.New("disk I/O error"), expected: codes.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := recordSpans(t)
+
+			_, span := Start(context.Background(), "ArtistService.Get")
+			End(span, tt.err)
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, 1)
+			assert.Equal(t, tt.expected, spans[0].Status.Code)
+		})
+	}
+}
+
+func TestGormPlugin(t *testing.T) {
+	exporter := recordSpans(t)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(GormPlugin{}))
+	require.NoError(t, db.AutoMigrate(&models.Artist{}))
+	// Outside a trace nothing is recorded
+	require.Empty(t, exporter.GetSpans())
+
+	ctx, span := Start(context.Background(), "ArtistService.Get")
+	var artist models.Artist
+	err = db.WithContext(ctx).First(&artist, 1).Error
+	span.End()
+
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	query := named(spans, "gorm.query artists")
+	assert.Equal(t, span.SpanContext().SpanID(), query.Parent.SpanID())
+	assert.Contains(t, query.Attributes, semconv.DBSQLTable("artists"))
+	assert.Contains(t, query.Attributes, semconv.DBOperation("query"))
+	// Not found isn't a failure
+	assert.Equal(t, codes.Unset, query.Status.Code)
+}
+
+func TestStorageClient(t *testing.T) {
+	exporter := recordSpans(t)
+	storageClient := mocks.NewIStorageClient(t)
+	storageClient.EXPECT().UploadFile(mock.Anything, "vacuum.sqlite", "entities-backup1.sqlite").
+		Return(errors.New("bucket not found"))
+	storageClient.EXPECT().ListFiles(mock.Anything).Return([]models.BackupFile{{Name: "a"}}, nil)
+	traced := &StorageClient{StorageClient: storageClient}
+
+	err := traced.UploadFile(context.Background(), "vacuum.sqlite", "entities-backup1.sqlite")
+	require.Error(t, err)
+	files, err := traced.ListFiles(context.Background())
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	upload := named(spans, "StorageClient.UploadFile")
+	assert.Equal(t, codes.Error, upload.Status.Code)
+	assert.Contains(t, upload.Attributes, objectKey.String("entities-backup1.sqlite"))
+	assert.Equal(t, codes.Unset, named(spans, "StorageClient.ListFiles").Status.Code)
+}
+
+func TestSetupStdout(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	var out bytes.Buffer
+
+	stop, err := setup(Config{Exporter: ExporterStdout, ServiceName: "entities", SampleRatio: 1}, "v1.2.0", &out)
+	require.NoError(t, err)
+	_, span := Start(context.Background(), "ArtistService.GetRandom")
+	span.End()
+	require.NoError(t, stop(context.Background()))
+
+	assert.Contains(t, out.String(), "ArtistService.GetRandom")
+	assert.Contains(t, out.String(), "v1.2.0")
+}
+
+func TestSetupUnknownExporter(t *testing.T) {
+	_, err := Setup(Config{Exporter: "jaeger"}, "v1.2.0")
+
+	assert.ErrorContains(t, err, `unknown exporter "jaeger"`)
+}
@@ -0,0 +1,466 @@
+// Package walship continuously replicates a SQLite database to storage by
+// shipping its WAL, so it can be restored to any point in time
+//
+// Replication happens in generations. Each starts with a snapshot of the
+// database file, followed by numbered segments of WAL frames. Only the
+// replicator checkpoints the WAL, with writers blocked, so frames are always
+// shipped before they can be overwritten. A new generation starts on a
+// schedule, and whenever the WAL can't be followed, for example after the
+// database is replaced.
+package walship
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/infrastructures/logutil"
+	"github.com/apkatsikas/artist-entities/interfaces"
+	"github.com/apkatsikas/artist-entities/models"
+)
+
+const (
+	DefaultSnapshotInterval = 24 * time.Hour
+	DefaultRetention        = 7 * 24 * time.Hour
+	DefaultCheckpointSize   = 4 << 20
+
+	snapshotFileName = "wal-snapshot.sqlite"
+	segmentFileName  = "wal-segment"
+	restoreSegment   = "wal-restore-segment"
+)
+
+// ErrNoReplica is returned when nothing in storage reaches back to a time
+var ErrNoReplica = errors.New("no replica covers that time")
+
+type Replicator struct {
+	Database      interfaces.IWALDatabase
+	StorageClient interfaces.IStorageClient
+	Compressor    interfaces.ICompressor
+	// Compression for uploaded objects, empty means none
+	Compression string
+	// Encryptor encrypts uploaded objects, nil means they're uploaded in plaintext
+	Encryptor interfaces.IEncryptor
+	// SnapshotInterval is how often a new generation starts
+	SnapshotInterval time.Duration
+	// Retention is how far back the database can be restored to
+	Retention time.Duration
+	// CheckpointSize is how large the WAL grows before it's checkpointed
+	CheckpointSize int64
+	// Dir holds files on their way to and from storage
+	Dir string
+
+	mu         sync.Mutex
+	generation string
+	started    time.Time
+	index      int
+	pos        position
+	// checkpointed is true when everything shipped is in the database file,
+	// so the WAL may restart
+	checkpointed bool
+	database     os.FileInfo
+}
+
+func (r *Replicator) walPath() string {
+	return r.Database.Path() + "-wal"
+}
+
+func (r *Replicator) path(name string) string {
+	return filepath.Join(r.Dir, name)
+}
+
+func (r *Replicator) sameDatabase() bool {
+	info, err := os.Stat(r.Database.Path())
+	return err == nil && os.SameFile(info, r.database)
+}
+
+// Sync ships WAL frames committed since the last sync
+// It checkpoints the WAL once it's grown, and starts new generations as needed
+func (r *Replicator) Sync(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.generation == "" || time.Since(r.started) >= r.SnapshotInterval || !r.sameDatabase() {
+		return r.snapshot(ctx)
+	}
+
+	h, err := readHeader(r.walPath())
+	if errors.Is(err, errNoWAL) {
+		if r.pos.offset == 0 {
+			return nil
+		}
+		logutil.Warn("The WAL was removed before it was shipped, starting a new generation")
+		return r.snapshot(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	if h.salt != r.pos.salt {
+		// Only a restart after our own checkpoint keeps continuity
+		restarted := r.checkpointed && h.sequence == r.pos.sequence+1
+		if r.pos.offset != 0 && !restarted {
+			logutil.Warn("The WAL restarted before it was shipped, starting a new generation")
+			return r.snapshot(ctx)
+		}
+		r.pos = h.start()
+	}
+
+	next, err := r.capture(h)
+	if err != nil {
+		return err
+	}
+	// The database may have been replaced while it was read
+	if !r.sameDatabase() {
+		return r.snapshot(ctx)
+	}
+	err = r.send(ctx, next)
+	if err != nil {
+		return err
+	}
+
+	if r.pos.offset < r.CheckpointSize {
+		return nil
+	}
+	return r.checkpoint(ctx)
+}
+
+// capture writes the frames after r.pos to the segment file
+func (r *Replicator) capture(h walHeader) (position, error) {
+	f, err := os.Create(r.path(segmentFileName))
+	if err != nil {
+		return r.pos, err
+	}
+	defer f.Close()
+	return readFrames(r.walPath(), h, r.pos, f)
+}
+
+// send uploads the captured segment, which ends at next
+func (r *Replicator) send(ctx context.Context, next position) error {
+	defer os.Remove(r.path(segmentFileName))
+	if next == r.pos {
+		return nil
+	}
+
+	name := models.WALSegmentName(r.generation, r.index, time.Now())
+	err := r.upload(ctx, r.path(segmentFileName), name)
+	if err != nil {
+		return err
+	}
+	r.pos, r.index, r.checkpointed = next, r.index+1, false
+	return nil
+}
+
+// checkpoint ships the rest of the WAL and checkpoints it, with writers blocked
+func (r *Replicator) checkpoint(ctx context.Context) error {
+	var next position
+	var full bool
+	err := r.Database.WriteLocked(func() error {
+		h, err := readHeader(r.walPath())
+		if err != nil {
+			return err
+		}
+		if h.salt != r.pos.salt {
+			return errors.New("the WAL restarted without a checkpoint")
+		}
+		next, err = r.capture(h)
+		if err != nil {
+			return err
+		}
+		full, err = r.Database.Checkpoint()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = r.send(ctx, next)
+	if err != nil {
+		// The WAL may restart before another try, so the frames would be lost
+		if full {
+			r.generation = ""
+		}
+		return err
+	}
+	r.checkpointed = full
+	return nil
+}
+
+// snapshot starts a new generation, then removes those past retention
+func (r *Replicator) snapshot(ctx context.Context) error {
+	r.generation = ""
+	start := time.Now()
+	file := r.path(snapshotFileName)
+	defer os.Remove(file)
+
+	var pos position
+	var info os.FileInfo
+	err := r.Database.WriteLocked(func() error {
+		full, err := r.Database.Checkpoint()
+		if err != nil {
+			return err
+		}
+		if !full {
+			return errors.New("reads held back the checkpoint, the snapshot will be retried")
+		}
+
+		info, err = os.Stat(r.Database.Path())
+		if err != nil {
+			return err
+		}
+		err = copyFile(r.Database.Path(), file)
+		if err != nil {
+			return err
+		}
+
+		// Everything in the WAL is now in the snapshot
+		h, err := readHeader(r.walPath())
+		if errors.Is(err, errNoWAL) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		pos, err = readFrames(r.walPath(), h, h.start(), io.Discard)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	generation := models.NewWALGeneration(start)
+	err = r.upload(ctx, file, models.WALSnapshotName(generation))
+	if err != nil {
+		return err
+	}
+	r.generation, r.started, r.index = generation, start, 0
+	r.pos, r.checkpointed, r.database = pos, true, info
+	logutil.Info("Started WAL replication generation", "generation", generation)
+
+	return r.prune(ctx)
+}
+
+// generations lists replication objects by generation, oldest first
+func (r *Replicator) generations(ctx context.Context) ([][]models.WALObject, error) {
+	files, err := r.StorageClient.ListFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byGeneration := map[string][]models.WALObject{}
+	for _, file := range files {
+		if object, ok := models.ParseWALObject(file.Name); ok {
+			byGeneration[object.Generation] = append(byGeneration[object.Generation], object)
+		}
+	}
+
+	generations := make([][]models.WALObject, 0, len(byGeneration))
+	for _, objects := range byGeneration {
+		generations = append(generations, objects)
+	}
+	sort.Slice(generations, func(i, j int) bool {
+		return generations[i][0].GenerationTime().Before(generations[j][0].GenerationTime())
+	})
+	return generations, nil
+}
+
+// prune deletes generations that ended before the retention window
+func (r *Replicator) prune(ctx context.Context) error {
+	generations, err := r.generations(ctx)
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-r.Retention)
+	for i := 0; i+1 < len(generations); i++ {
+		// A generation is needed until the next one starts
+		if !generations[i+1][0].GenerationTime().Before(cutoff) {
+			break
+		}
+		for _, object := range generations[i] {
+			err = r.StorageClient.DeleteFile(ctx, object.Name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Reconstruct rebuilds the database as it was at a point in time into dest
+// Returns when the last change restored was shipped, at most at
+// Returns ErrNoReplica if no generation started by then
+func (r *Replicator) Reconstruct(ctx context.Context, at time.Time, dest string) (time.Time, error) {
+	generations, err := r.generations(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// The latest generation that started by then
+	var snapshot *models.WALObject
+	segments, last := map[int][]models.WALObject{}, -1
+	for _, generation := range generations {
+		if generation[0].GenerationTime().After(at) {
+			break
+		}
+		for i, object := range generation {
+			if object.Snapshot {
+				snapshot = &generation[i]
+			}
+		}
+		if snapshot == nil || snapshot.Generation != generation[0].Generation {
+			continue
+		}
+		segments, last = map[int][]models.WALObject{}, -1
+		for _, object := range generation {
+			if !object.Snapshot {
+				segments[object.Index] = append(segments[object.Index], object)
+				last = max(last, object.Index)
+			}
+		}
+	}
+	if snapshot == nil {
+		return time.Time{}, ErrNoReplica
+	}
+
+	err = r.download(ctx, snapshot.Name, dest)
+	if err != nil {
+		return time.Time{}, err
+	}
+	db, err := os.OpenFile(dest, os.O_RDWR, 0)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer db.Close()
+
+	restored := snapshot.GenerationTime()
+	for index := 0; ; index++ {
+		// A failed upload can be retried with more frames, take the latest in time
+		var segment *models.WALObject
+		for i, candidate := range segments[index] {
+			if !candidate.Time.After(at) && (segment == nil || candidate.Time.After(segment.Time)) {
+				segment = &segments[index][i]
+			}
+		}
+		if segment == nil {
+			if len(segments[index]) == 0 && index < last {
+				logutil.Warn("WAL segment is missing, restoring to before it", "segment", index,
+					"generation", snapshot.Generation, "restoredTo", restored.UTC().Format(time.RFC3339))
+			}
+			break
+		}
+
+		err = r.applySegment(ctx, db, segment.Name)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to apply %v: %v", segment.Name, err)
+		}
+		restored = segment.Time
+	}
+
+	return restored, db.Close()
+}
+
+func (r *Replicator) applySegment(ctx context.Context, db *os.File, object string) error {
+	file := r.path(restoreSegment)
+	defer os.Remove(file)
+	err := r.download(ctx, object, file)
+	if err != nil {
+		return err
+	}
+
+	segment, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer segment.Close()
+	return apply(db, segment)
+}
+
+func (r *Replicator) compression() string {
+	if r.Compression == "" {
+		return models.CompressionNone
+	}
+	return r.Compression
+}
+
+// upload compresses and encrypts file as configured, naming the object to match
+func (r *Replicator) upload(ctx context.Context, file string, name string) error {
+	compression := r.compression()
+	if compression != models.CompressionNone {
+		compressed := file + ".compressed"
+		defer os.Remove(compressed)
+		err := r.Compressor.Compress(file, compressed, compression)
+		if err != nil {
+			return err
+		}
+		file, name = compressed, name+models.CompressionExtension(compression)
+	}
+	if r.Encryptor != nil {
+		encrypted := file + ".encrypted"
+		defer os.Remove(encrypted)
+		_, err := r.Encryptor.Encrypt(file, encrypted)
+		if err != nil {
+			return err
+		}
+		file, name = encrypted, name+models.EncryptionExtension
+	}
+	return r.StorageClient.UploadFile(ctx, file, name)
+}
+
+// download reverses upload, going by the object's name
+func (r *Replicator) download(ctx context.Context, object string, dest string) error {
+	compression := models.CompressionFor(object)
+	compressed, encrypted := compression != models.CompressionNone, models.IsEncrypted(object)
+
+	downloaded := dest
+	if compressed || encrypted {
+		downloaded = dest + ".download"
+		defer os.Remove(downloaded)
+	}
+	err := r.StorageClient.DownloadFile(ctx, object, downloaded)
+	if err != nil {
+		return err
+	}
+
+	if encrypted {
+		if r.Encryptor == nil {
+			return fmt.Errorf("%v is encrypted but no backup encryption keys are configured", object)
+		}
+		decrypted := dest
+		if compressed {
+			decrypted = dest + ".decrypted"
+			defer os.Remove(decrypted)
+		}
+		err = r.Encryptor.Decrypt(downloaded, decrypted)
+		if err != nil {
+			return err
+		}
+		downloaded = decrypted
+	}
+
+	if compressed {
+		return r.Compressor.Decompress(downloaded, dest, compression)
+	}
+	return nil
+}
+
+func copyFile(src string, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,239 @@
+package walship
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/infrastructures"
+	"github.com/apkatsikas/artist-entities/infrastructures/backupcrypt"
+	"github.com/apkatsikas/artist-entities/infrastructures/compressor"
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/apkatsikas/artist-entities/storageclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var ctx = context.Background()
+
+func setup(t *testing.T) (*infrastructures.SQLiteHandler, *Replicator, *storageclient.LocalClient) {
+	dir := t.TempDir()
+	handler := &infrastructures.SQLiteHandler{WAL: true}
+	require.NoError(t, handler.ConnectSQLite(filepath.Join(dir, "entities.db")))
+	t.Cleanup(func() {
+		sqlDB, _ := handler.Connection().DB()
+		sqlDB.Close()
+	})
+	require.NoError(t, handler.Connection().Exec("CREATE TABLE artists (name TEXT)").Error)
+
+	storage, err := storageclient.NewLocal(filepath.Join(dir, "storage"))
+	require.NoError(t, err)
+	replicator := &Replicator{
+		Database:         handler,
+		StorageClient:    storage,
+		Compressor:       &compressor.Compressor{},
+		SnapshotInterval: DefaultSnapshotInterval,
+		Retention:        DefaultRetention,
+		CheckpointSize:   DefaultCheckpointSize,
+		Dir:              dir,
+	}
+	return handler, replicator, storage
+}
+
+func insert(t *testing.T, handler *infrastructures.SQLiteHandler, names ...string) {
+	for _, name := range names {
+		require.NoError(t, handler.Connection().Exec("INSERT INTO artists VALUES (?)", name).Error)
+	}
+}
+
+// syncAt syncs and returns a time after everything synced, but before anything later
+func syncAt(t *testing.T, replicator *Replicator) time.Time {
+	require.NoError(t, replicator.Sync(ctx))
+	at := time.Now()
+	time.Sleep(2 * time.Millisecond)
+	return at
+}
+
+// artists reconstructs the database at a time and lists its artists
+func artists(t *testing.T, replicator *Replicator, at time.Time) []string {
+	dest := filepath.Join(t.TempDir(), "restore.sqlite")
+	_, err := replicator.Reconstruct(ctx, at, dest)
+	require.NoError(t, err)
+
+	restored := &infrastructures.SQLiteHandler{}
+	require.NoError(t, restored.ConnectSQLite(dest))
+	defer func() {
+		sqlDB, _ := restored.Connection().DB()
+		sqlDB.Close()
+	}()
+
+	var results []string
+	require.NoError(t, restored.Connection().Raw("PRAGMA integrity_check").Scan(&results).Error)
+	assert.Equal(t, []string{"ok"}, results)
+
+	names := []string{}
+	require.NoError(t, restored.Connection().Raw("SELECT name FROM artists ORDER BY rowid").Scan(&names).Error)
+	return names
+}
+
+func objects(t *testing.T, storage *storageclient.LocalClient) (snapshots int, segments int) {
+	files, err := storage.ListFiles(ctx)
+	require.NoError(t, err)
+	for _, file := range files {
+		object, ok := models.ParseWALObject(file.Name)
+		require.True(t, ok, file.Name)
+		if object.Snapshot {
+			snapshots++
+		} else {
+			segments++
+		}
+	}
+	return snapshots, segments
+}
+
+func TestReconstruct(t *testing.T) {
+	handler, replicator, storage := setup(t)
+
+	insert(t, handler, "a")
+	atSnapshot := syncAt(t, replicator)
+	insert(t, handler, "b", "c")
+	atC := syncAt(t, replicator)
+	insert(t, handler, "d")
+	atD := syncAt(t, replicator)
+	// Nothing new to ship
+	syncAt(t, replicator)
+
+	assert.Equal(t, []string{"a"}, artists(t, replicator, atSnapshot))
+	assert.Equal(t, []string{"a", "b", "c"}, artists(t, replicator, atC))
+	assert.Equal(t, []string{"a", "b", "c", "d"}, artists(t, replicator, atD))
+	assert.Equal(t, []string{"a", "b", "c", "d"}, artists(t, replicator, time.Now().Add(time.Hour)))
+
+	snapshots, segments := objects(t, storage)
+	assert.Equal(t, 1, snapshots)
+	assert.Equal(t, 2, segments)
+}
+
+func TestReconstructBeforeReplication(t *testing.T) {
+	handler, replicator, _ := setup(t)
+	insert(t, handler, "a")
+	before := time.Now().Add(-time.Second)
+	syncAt(t, replicator)
+
+	restoredTo, err := replicator.Reconstruct(ctx, before, filepath.Join(t.TempDir(), "restore.sqlite"))
+
+	assert.ErrorIs(t, err, ErrNoReplica)
+	assert.True(t, restoredTo.IsZero())
+}
+
+func TestReconstructAcrossCheckpoints(t *testing.T) {
+	handler, replicator, storage := setup(t)
+	// Checkpoint on every sync so the WAL keeps restarting
+	replicator.CheckpointSize = 1
+
+	insert(t, handler, "a")
+	syncAt(t, replicator)
+	var times []time.Time
+	for _, name := range []string{"b", "c", "d", "e"} {
+		insert(t, handler, name)
+		times = append(times, syncAt(t, replicator))
+	}
+
+	assert.Equal(t, []string{"a", "b", "c"}, artists(t, replicator, times[1]))
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, artists(t, replicator, times[3]))
+
+	// The restarts were followed within one generation
+	assert.Greater(t, replicator.pos.sequence, uint32(1))
+	snapshots, segments := objects(t, storage)
+	assert.Equal(t, 1, snapshots)
+	assert.Equal(t, 4, segments)
+}
+
+func TestReconstructEncrypted(t *testing.T) {
+	handler, replicator, storage := setup(t)
+	encryptor, err := backupcrypt.NewEncryptor("k1", map[string][]byte{"k1": make([]byte, 32)})
+	require.NoError(t, err)
+	replicator.Compression, replicator.Encryptor = models.CompressionZstd, encryptor
+
+	insert(t, handler, "a")
+	syncAt(t, replicator)
+	insert(t, handler, "b")
+	at := syncAt(t, replicator)
+
+	files, err := storage.ListFiles(ctx)
+	require.NoError(t, err)
+	for _, file := range files {
+		assert.True(t, models.IsEncrypted(file.Name), file.Name)
+		assert.Equal(t, models.CompressionZstd, models.CompressionFor(file.Name))
+	}
+	assert.Equal(t, []string{"a", "b"}, artists(t, replicator, at))
+
+	// Without the keys there's nothing to restore from
+	replicator.Encryptor = nil
+	_, err = replicator.Reconstruct(ctx, at, filepath.Join(t.TempDir(), "restore.sqlite"))
+	assert.ErrorContains(t, err, "encrypted")
+}
+
+func TestReconstructMissingSegment(t *testing.T) {
+	handler, replicator, storage := setup(t)
+
+	insert(t, handler, "a")
+	syncAt(t, replicator)
+	insert(t, handler, "b")
+	syncAt(t, replicator)
+	insert(t, handler, "c")
+	syncAt(t, replicator)
+
+	files, err := storage.ListFiles(ctx)
+	require.NoError(t, err)
+	for _, file := range files {
+		if object, _ := models.ParseWALObject(file.Name); !object.Snapshot && object.Index == 0 {
+			require.NoError(t, storage.DeleteFile(ctx, file.Name))
+		}
+	}
+
+	// Later segments can't be applied without the missing one
+	assert.Equal(t, []string{"a"}, artists(t, replicator, time.Now()))
+}
+
+func TestSyncAfterReplace(t *testing.T) {
+	handler, replicator, storage := setup(t)
+
+	insert(t, handler, "a")
+	syncAt(t, replicator)
+
+	// Swap in a copy of the database from before "b"
+	copy := filepath.Join(t.TempDir(), "copy.sqlite")
+	require.NoError(t, handler.Connection().Exec("VACUUM INTO ?", copy).Error)
+	insert(t, handler, "b")
+	syncAt(t, replicator)
+	replaced := filepath.Join(filepath.Dir(handler.Path()), "replaced.sqlite")
+	require.NoError(t, os.Rename(copy, replaced))
+	require.NoError(t, handler.Replace(replaced))
+
+	insert(t, handler, "c")
+	at := syncAt(t, replicator)
+	insert(t, handler, "d")
+	syncAt(t, replicator)
+
+	assert.Equal(t, []string{"a", "c"}, artists(t, replicator, at))
+	snapshots, _ := objects(t, storage)
+	assert.Equal(t, 2, snapshots)
+}
+
+func TestSyncPrunesGenerations(t *testing.T) {
+	handler, replicator, storage := setup(t)
+	replicator.SnapshotInterval = 0
+	replicator.Retention = 0
+
+	for _, name := range []string{"a", "b", "c"} {
+		insert(t, handler, name)
+		syncAt(t, replicator)
+	}
+
+	snapshots, segments := objects(t, storage)
+	assert.Equal(t, 1, snapshots)
+	assert.Equal(t, 0, segments)
+	assert.Equal(t, []string{"a", "b", "c"}, artists(t, replicator, time.Now()))
+}
@@ -0,0 +1,192 @@
+package walship
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// https://www.sqlite.org/fileformat.html#the_write_ahead_log
+const (
+	walHeaderSize   = 32
+	frameHeaderSize = 24
+	walMagic        = 0x377f0682
+)
+
+// errNoWAL is returned for a WAL file that hasn't been written yet
+var errNoWAL = errors.New("no WAL")
+
+type walHeader struct {
+	pageSize  int
+	bigEndian bool
+	// sequence goes up by one each time the WAL restarts
+	sequence uint32
+	salt     [8]byte
+	checksum [2]uint32
+}
+
+// position is how much of a WAL has been shipped
+// checksum is the running checksum up to offset, the next frame continues it
+type position struct {
+	salt     [8]byte
+	sequence uint32
+	offset   int64
+	checksum [2]uint32
+}
+
+func walChecksum(bigEndian bool, data []byte, s [2]uint32) [2]uint32 {
+	order := binary.ByteOrder(binary.LittleEndian)
+	if bigEndian {
+		order = binary.BigEndian
+	}
+	for i := 0; i+8 <= len(data); i += 8 {
+		s[0] += order.Uint32(data[i:]) + s[1]
+		s[1] += order.Uint32(data[i+4:]) + s[0]
+	}
+	return s
+}
+
+// readHeader returns errNoWAL when there isn't a valid WAL at path
+func readHeader(path string) (walHeader, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return walHeader{}, errNoWAL
+	}
+	if err != nil {
+		return walHeader{}, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, walHeaderSize)
+	_, err = io.ReadFull(f, buf)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return walHeader{}, errNoWAL
+	}
+	if err != nil {
+		return walHeader{}, err
+	}
+
+	magic := binary.BigEndian.Uint32(buf)
+	if magic&^1 != walMagic {
+		return walHeader{}, errNoWAL
+	}
+	h := walHeader{
+		pageSize:  int(binary.BigEndian.Uint32(buf[8:])),
+		bigEndian: magic&1 == 1,
+		sequence:  binary.BigEndian.Uint32(buf[12:]),
+	}
+	if h.pageSize == 1 {
+		h.pageSize = 65536
+	}
+	copy(h.salt[:], buf[16:24])
+	h.checksum = [2]uint32{binary.BigEndian.Uint32(buf[24:]), binary.BigEndian.Uint32(buf[28:])}
+	if walChecksum(h.bigEndian, buf[:24], [2]uint32{}) != h.checksum {
+		return walHeader{}, errNoWAL
+	}
+	return h, nil
+}
+
+// start is the position of the first frame
+func (h walHeader) start() position {
+	return position{salt: h.salt, sequence: h.sequence, offset: walHeaderSize, checksum: h.checksum}
+}
+
+// readFrames copies whole transactions after pos to w and returns where they end
+// Frames are checked against the header's salt and the running checksum,
+// so leftovers from before the WAL restarted are never copied
+func readFrames(path string, h walHeader, pos position, w io.Writer) (position, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return pos, err
+	}
+	defer f.Close()
+
+	_, err = f.Seek(pos.offset, io.SeekStart)
+	if err != nil {
+		return pos, err
+	}
+	r := bufio.NewReader(f)
+
+	frame := make([]byte, frameHeaderSize+h.pageSize)
+	var transaction bytes.Buffer
+	next := pos
+	for {
+		_, err = io.ReadFull(r, frame)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return pos, nil
+		}
+		if err != nil {
+			return pos, err
+		}
+		if !bytes.Equal(frame[8:16], h.salt[:]) {
+			return pos, nil
+		}
+		sum := walChecksum(h.bigEndian, frame[:8], next.checksum)
+		sum = walChecksum(h.bigEndian, frame[frameHeaderSize:], sum)
+		if sum != [2]uint32{binary.BigEndian.Uint32(frame[16:]), binary.BigEndian.Uint32(frame[20:])} {
+			return pos, nil
+		}
+		transaction.Write(frame)
+		next.offset += int64(len(frame))
+		next.checksum = sum
+
+		// A commit frame holds the database size in pages
+		if binary.BigEndian.Uint32(frame[4:]) != 0 {
+			_, err = transaction.WriteTo(w)
+			if err != nil {
+				return pos, err
+			}
+			pos = next
+		}
+	}
+}
+
+// pageSize reads a database file's page size from its header
+func pageSize(db *os.File) (int, error) {
+	buf := make([]byte, 2)
+	_, err := db.ReadAt(buf, 16)
+	if err != nil {
+		return 0, fmt.Errorf("not a database: %v", err)
+	}
+	size := int(binary.BigEndian.Uint16(buf))
+	if size == 1 {
+		size = 65536
+	}
+	return size, nil
+}
+
+// apply writes a segment's frames into a database file, as a checkpoint would
+func apply(db *os.File, segment io.Reader) error {
+	size, err := pageSize(db)
+	if err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(segment)
+	frame := make([]byte, frameHeaderSize+size)
+	for {
+		_, err = io.ReadFull(r, frame)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("truncated segment: %v", err)
+		}
+
+		page := int64(binary.BigEndian.Uint32(frame))
+		_, err = db.WriteAt(frame[frameHeaderSize:], (page-1)*int64(size))
+		if err != nil {
+			return err
+		}
+		if pages := int64(binary.BigEndian.Uint32(frame[4:])); pages != 0 {
+			err = db.Truncate(pages * int64(size))
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -2,4 +2,8 @@ package interfaces
 
 type IAdminRepository interface {
     CreateBackup(file string) error
+    CheckIntegrity(file string) error
+    CheckDatabase() error
+    Counts(file string) (int64, int64, error)
+    Restore(file string) error
 }
@@ -1,7 +1,7 @@
 package interfaces
 
-import "github.com/apkatsikas/artist-entities/storageclient"
+import "github.com/apkatsikas/artist-entities/models"
 
 type IAdminRules interface {
-    FileToDelete(files []storageclient.BackupFile) string
+    FilesToDelete(files []models.BackupFile) []string
 }
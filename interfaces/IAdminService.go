@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/apkatsikas/artist-entities/models"
+)
+
+type IAdminService interface {
+	Backup(ctx context.Context, trigger string) (*models.BackupRun, error)
+	ListBackups(ctx context.Context) ([]models.Backup, error)
+	LastBackupRun() (*models.BackupRun, error)
+	VerifyBackup(ctx context.Context, name string) error
+	CheckDatabase() error
+	Restore(ctx context.Context, name string) (string, error)
+}
@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"time"
+
+	"github.com/apkatsikas/artist-entities/models"
+)
+
+type IApiKeyRepository interface {
+	Create(apiKey *models.ApiKey) error
+	GetByHash(hash string) (*models.ApiKey, error)
+	List() ([]models.ApiKey, error)
+	Revoke(id uint, at time.Time) error
+	Touch(id uint, at time.Time) error
+	Migrate() error
+}
@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"time"
+
+	"github.com/apkatsikas/artist-entities/models"
+)
+
+type IApiKeyService interface {
+	Create(name string, scopes []string, expiresAt *time.Time) (string, *models.ApiKey, error)
+	List() ([]models.ApiKey, error)
+	Revoke(id uint) error
+	IsAuthorized(key string, scope string) bool
+}
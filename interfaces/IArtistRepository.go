@@ -1,13 +1,15 @@
 package interfaces
 
 import (
+    "context"
+
     "github.com/apkatsikas/artist-entities/models"
 )
 
 type IArtistRepository interface {
-    Get(id uint) (*models.Artist, error)
-    Create(name string) (*models.Artist, error)
-    GetCount() (uint, error)
-    GetByOffset(offset uint) (*models.Artist, error)
+    Get(ctx context.Context, id uint) (*models.Artist, error)
+    Create(ctx context.Context, name string) (*models.Artist, error)
+    GetCount(ctx context.Context) (uint, error)
+    GetByOffset(ctx context.Context, offset uint) (*models.Artist, error)
     Migrate() error
 }
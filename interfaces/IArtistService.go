@@ -1,11 +1,13 @@
 package interfaces
 
 import (
+	"context"
+
 	"github.com/apkatsikas/artist-entities/models"
 )
 
 type IArtistService interface {
-	Get(id uint) (*models.Artist, error)
-	Create(artistName string) (*models.Artist, error)
-	GetRandom() (*models.Artist, error)
+	Get(ctx context.Context, id uint) (*models.Artist, error)
+	Create(ctx context.Context, artistName string) (*models.Artist, error)
+	GetRandom(ctx context.Context) (*models.Artist, error)
 }
@@ -1,6 +1,17 @@
 package interfaces
 
+import (
+	"context"
+
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/apkatsikas/artist-entities/viewmodels"
+)
+
 type IAuthService interface {
-	IsAuthorized(token string) bool
-	GenerateJWT(name string, password string) (string, error)
+	Authorize(ctx context.Context, token string, role string) error
+	GenerateJWT(ctx context.Context, name string, password string) (string, error)
+	IssueJWT(ctx context.Context, user *models.User) (string, error)
+	JWKS(ctx context.Context) viewmodels.JwksVM
+	Logout(ctx context.Context, token string) error
+	RevokeSessions(ctx context.Context, name string) error
 }
@@ -0,0 +1,9 @@
+package interfaces
+
+import "github.com/apkatsikas/artist-entities/models"
+
+type IBackupRunRepository interface {
+	Create(run *models.BackupRun) error
+	Last() (*models.BackupRun, error)
+	Migrate() error
+}
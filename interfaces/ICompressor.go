@@ -0,0 +1,6 @@
+package interfaces
+
+type ICompressor interface {
+	Compress(src string, dest string, compression string) error
+	Decompress(src string, dest string, compression string) error
+}
@@ -1,7 +1,13 @@
 package interfaces
 
-import "gorm.io/gorm"
+import (
+    "context"
+
+    "gorm.io/gorm"
+)
 
 type IDbHandler interface {
     Connection() *gorm.DB
+    Replace(file string) error
+    Ping(ctx context.Context) error
 }
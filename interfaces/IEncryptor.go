@@ -0,0 +1,6 @@
+package interfaces
+
+type IEncryptor interface {
+	Encrypt(src string, dest string) (string, error)
+	Decrypt(src string, dest string) error
+}
@@ -2,4 +2,7 @@ package interfaces
 
 type IFileUtil interface {
     DeleteIfExists(file string) error
+    Checksum(file string) (string, int64, error)
+    ReadFile(file string) ([]byte, error)
+    WriteFile(file string, data []byte) error
 }
@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/apkatsikas/artist-entities/models"
+)
+
+type IHealthService interface {
+	Ready(ctx context.Context) models.Readiness
+	Version() models.BuildInfo
+}
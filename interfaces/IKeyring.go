@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+	"github.com/apkatsikas/artist-entities/infrastructures/jwtkeys"
+	"github.com/apkatsikas/artist-entities/viewmodels"
+)
+
+type IKeyring interface {
+	SigningKey() (*jwtkeys.Key, error)
+	VerificationKey(kid string) (*jwtkeys.Key, error)
+	Methods() []string
+	Len() int
+	JWKS() viewmodels.JwksVM
+}
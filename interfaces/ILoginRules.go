@@ -0,0 +1,8 @@
+package interfaces
+
+import "time"
+
+type ILoginRules interface {
+	Delay(failures uint) time.Duration
+	Expired(sinceLastFailure time.Duration) bool
+}
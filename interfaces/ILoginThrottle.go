@@ -0,0 +1,9 @@
+package interfaces
+
+import "time"
+
+type ILoginThrottle interface {
+	RetryAfter(userName string, ip string) time.Duration
+	Fail(userName string, ip string) uint
+	Succeed(userName string, ip string)
+}
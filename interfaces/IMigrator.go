@@ -0,0 +1,7 @@
+package interfaces
+
+type IMigrator interface {
+	Migrate() error
+	// CheckMigrated returns an error if Migrate hasn't brought the schema up to date
+	CheckMigrated() error
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+import "context"
+
+type IOIDCService interface {
+	AuthCodeURL(ctx context.Context) (string, error)
+	Exchange(ctx context.Context, state string, code string) (string, error)
+}
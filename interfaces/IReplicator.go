@@ -0,0 +1,10 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+)
+
+type IReplicator interface {
+	Reconstruct(ctx context.Context, at time.Time, dest string) (time.Time, error)
+}
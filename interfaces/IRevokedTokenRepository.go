@@ -0,0 +1,15 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/models"
+)
+
+type IRevokedTokenRepository interface {
+	Create(ctx context.Context, jti string, expiresAt time.Time) error
+	ListActive(now time.Time) ([]models.RevokedToken, error)
+	DeleteExpired(now time.Time) (int64, error)
+	Migrate() error
+}
@@ -0,0 +1,7 @@
+package interfaces
+
+import "github.com/apkatsikas/artist-entities/models"
+
+type IScheduler interface {
+	Jobs() []models.ScheduledJob
+}
@@ -1,9 +1,16 @@
 package interfaces
 
-import "github.com/apkatsikas/artist-entities/storageclient"
+import (
+    "context"
+
+    "github.com/apkatsikas/artist-entities/models"
+)
 
 type IStorageClient interface {
-    DeleteFile(object string) error
-    UploadFile(path string, destObject string) error
-    ListFiles() ([]storageclient.BackupFile, error)
+    DeleteFile(ctx context.Context, object string) error
+    UploadFile(ctx context.Context, path string, destObject string) error
+    DownloadFile(ctx context.Context, object string, destPath string) error
+    ListFiles(ctx context.Context) ([]models.BackupFile, error)
+    // Ping checks storage can be reached, without listing it
+    Ping(ctx context.Context) error
 }
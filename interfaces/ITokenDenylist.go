@@ -0,0 +1,11 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+)
+
+type ITokenDenylist interface {
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	IsRevoked(jti string) bool
+}
@@ -1,10 +1,17 @@
 package interfaces
 
 import (
+	"context"
+
 	"github.com/apkatsikas/artist-entities/models"
 )
 
 type IUserRepository interface {
-	Get(name string) (*models.User, error)
-	Create(name string, password string) (*models.User, error)
+	Get(ctx context.Context, name string) (*models.User, error)
+	Create(ctx context.Context, name string, password string) (*models.User, error)
+	UpdatePassword(ctx context.Context, name string, password string) error
+	IncrementTokenVersion(ctx context.Context, name string) error
+	UpdateRole(ctx context.Context, name string, role string) error
+	GetByIdentity(ctx context.Context, issuer string, subject string) (*models.User, error)
+	CreateWithIdentity(ctx context.Context, name string, password string, role string, issuer string, subject string) (*models.User, error)
 }
@@ -0,0 +1,8 @@
+package interfaces
+
+// IWALDatabase is a database in WAL mode that can be replicated
+type IWALDatabase interface {
+	Path() string
+	WriteLocked(fc func() error) error
+	Checkpoint() (bool, error)
+}
@@ -5,10 +5,13 @@
 package mocks
 
 import (
+	"context"
+	"github.com/apkatsikas/artist-entities/infrastructures/jwtkeys"
 	"github.com/apkatsikas/artist-entities/models"
-	"github.com/apkatsikas/artist-entities/storageclient"
+	"github.com/apkatsikas/artist-entities/viewmodels"
 	mock "github.com/stretchr/testify/mock"
 	"gorm.io/gorm"
+	"time"
 )
 
 // NewIAdminRepository creates a new instance of IAdminRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
@@ -38,6 +41,155 @@ func (_m *IAdminRepository) EXPECT() *IAdminRepository_Expecter {
 	return &IAdminRepository_Expecter{mock: &_m.Mock}
 }
 
+// CheckDatabase provides a mock function for the type IAdminRepository
+func (_mock *IAdminRepository) CheckDatabase() error {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckDatabase")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func() error); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IAdminRepository_CheckDatabase_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CheckDatabase'
+type IAdminRepository_CheckDatabase_Call struct {
+	*mock.Call
+}
+
+// CheckDatabase is a helper method to define mock.On call
+func (_e *IAdminRepository_Expecter) CheckDatabase() *IAdminRepository_CheckDatabase_Call {
+	return &IAdminRepository_CheckDatabase_Call{Call: _e.mock.On("CheckDatabase")}
+}
+
+func (_c *IAdminRepository_CheckDatabase_Call) Run(run func()) *IAdminRepository_CheckDatabase_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *IAdminRepository_CheckDatabase_Call) Return(err error) *IAdminRepository_CheckDatabase_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IAdminRepository_CheckDatabase_Call) RunAndReturn(run func() error) *IAdminRepository_CheckDatabase_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CheckIntegrity provides a mock function for the type IAdminRepository
+func (_mock *IAdminRepository) CheckIntegrity(file string) error {
+	ret := _mock.Called(file)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckIntegrity")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(file)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IAdminRepository_CheckIntegrity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CheckIntegrity'
+type IAdminRepository_CheckIntegrity_Call struct {
+	*mock.Call
+}
+
+// CheckIntegrity is a helper method to define mock.On call
+//   - file
+func (_e *IAdminRepository_Expecter) CheckIntegrity(file interface{}) *IAdminRepository_CheckIntegrity_Call {
+	return &IAdminRepository_CheckIntegrity_Call{Call: _e.mock.On("CheckIntegrity", file)}
+}
+
+func (_c *IAdminRepository_CheckIntegrity_Call) Run(run func(file string)) *IAdminRepository_CheckIntegrity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *IAdminRepository_CheckIntegrity_Call) Return(err error) *IAdminRepository_CheckIntegrity_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IAdminRepository_CheckIntegrity_Call) RunAndReturn(run func(file string) error) *IAdminRepository_CheckIntegrity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Counts provides a mock function for the type IAdminRepository
+func (_mock *IAdminRepository) Counts(file string) (int64, int64, error) {
+	ret := _mock.Called(file)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Counts")
+	}
+
+	var r0 int64
+	var r1 int64
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(string) (int64, int64, error)); ok {
+		return returnFunc(file)
+	}
+	if returnFunc, ok := ret.Get(0).(func(string) int64); ok {
+		r0 = returnFunc(file)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(string) int64); ok {
+		r1 = returnFunc(file)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+	if returnFunc, ok := ret.Get(2).(func(string) error); ok {
+		r2 = returnFunc(file)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// IAdminRepository_Counts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Counts'
+type IAdminRepository_Counts_Call struct {
+	*mock.Call
+}
+
+// Counts is a helper method to define mock.On call
+//   - file
+func (_e *IAdminRepository_Expecter) Counts(file interface{}) *IAdminRepository_Counts_Call {
+	return &IAdminRepository_Counts_Call{Call: _e.mock.On("Counts", file)}
+}
+
+func (_c *IAdminRepository_Counts_Call) Run(run func(file string)) *IAdminRepository_Counts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *IAdminRepository_Counts_Call) Return(v int64, v1 int64, err error) *IAdminRepository_Counts_Call {
+	_c.Call.Return(v, v1, err)
+	return _c
+}
+
+func (_c *IAdminRepository_Counts_Call) RunAndReturn(run func(file string) (int64, int64, error)) *IAdminRepository_Counts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CreateBackup provides a mock function for the type IAdminRepository
 func (_mock *IAdminRepository) CreateBackup(file string) error {
 	ret := _mock.Called(file)
@@ -83,6 +235,51 @@ func (_c *IAdminRepository_CreateBackup_Call) RunAndReturn(run func(file string)
 	return _c
 }
 
+// Restore provides a mock function for the type IAdminRepository
+func (_mock *IAdminRepository) Restore(file string) error {
+	ret := _mock.Called(file)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Restore")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(file)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IAdminRepository_Restore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Restore'
+type IAdminRepository_Restore_Call struct {
+	*mock.Call
+}
+
+// Restore is a helper method to define mock.On call
+//   - file
+func (_e *IAdminRepository_Expecter) Restore(file interface{}) *IAdminRepository_Restore_Call {
+	return &IAdminRepository_Restore_Call{Call: _e.mock.On("Restore", file)}
+}
+
+func (_c *IAdminRepository_Restore_Call) Run(run func(file string)) *IAdminRepository_Restore_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *IAdminRepository_Restore_Call) Return(err error) *IAdminRepository_Restore_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IAdminRepository_Restore_Call) RunAndReturn(run func(file string) error) *IAdminRepository_Restore_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewIAdminRules creates a new instance of IAdminRules. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewIAdminRules(t interface {
@@ -110,58 +307,60 @@ func (_m *IAdminRules) EXPECT() *IAdminRules_Expecter {
 	return &IAdminRules_Expecter{mock: &_m.Mock}
 }
 
-// FileToDelete provides a mock function for the type IAdminRules
-func (_mock *IAdminRules) FileToDelete(files []storageclient.BackupFile) string {
+// FilesToDelete provides a mock function for the type IAdminRules
+func (_mock *IAdminRules) FilesToDelete(files []models.BackupFile) []string {
 	ret := _mock.Called(files)
 
 	if len(ret) == 0 {
-		panic("no return value specified for FileToDelete")
+		panic("no return value specified for FilesToDelete")
 	}
 
-	var r0 string
-	if returnFunc, ok := ret.Get(0).(func([]storageclient.BackupFile) string); ok {
+	var r0 []string
+	if returnFunc, ok := ret.Get(0).(func([]models.BackupFile) []string); ok {
 		r0 = returnFunc(files)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
 	}
 	return r0
 }
 
-// IAdminRules_FileToDelete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FileToDelete'
-type IAdminRules_FileToDelete_Call struct {
+// IAdminRules_FilesToDelete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FilesToDelete'
+type IAdminRules_FilesToDelete_Call struct {
 	*mock.Call
 }
 
-// FileToDelete is a helper method to define mock.On call
+// FilesToDelete is a helper method to define mock.On call
 //   - files
-func (_e *IAdminRules_Expecter) FileToDelete(files interface{}) *IAdminRules_FileToDelete_Call {
-	return &IAdminRules_FileToDelete_Call{Call: _e.mock.On("FileToDelete", files)}
+func (_e *IAdminRules_Expecter) FilesToDelete(files interface{}) *IAdminRules_FilesToDelete_Call {
+	return &IAdminRules_FilesToDelete_Call{Call: _e.mock.On("FilesToDelete", files)}
 }
 
-func (_c *IAdminRules_FileToDelete_Call) Run(run func(files []storageclient.BackupFile)) *IAdminRules_FileToDelete_Call {
+func (_c *IAdminRules_FilesToDelete_Call) Run(run func(files []models.BackupFile)) *IAdminRules_FilesToDelete_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].([]storageclient.BackupFile))
+		run(args[0].([]models.BackupFile))
 	})
 	return _c
 }
 
-func (_c *IAdminRules_FileToDelete_Call) Return(s string) *IAdminRules_FileToDelete_Call {
-	_c.Call.Return(s)
+func (_c *IAdminRules_FilesToDelete_Call) Return(strings []string) *IAdminRules_FilesToDelete_Call {
+	_c.Call.Return(strings)
 	return _c
 }
 
-func (_c *IAdminRules_FileToDelete_Call) RunAndReturn(run func(files []storageclient.BackupFile) string) *IAdminRules_FileToDelete_Call {
+func (_c *IAdminRules_FilesToDelete_Call) RunAndReturn(run func(files []models.BackupFile) []string) *IAdminRules_FilesToDelete_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// NewIArtistRepository creates a new instance of IArtistRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// NewIAdminService creates a new instance of IAdminService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
-func NewIArtistRepository(t interface {
+func NewIAdminService(t interface {
 	mock.TestingT
 	Cleanup(func())
-}) *IArtistRepository {
-	mock := &IArtistRepository{}
+}) *IAdminService {
+	mock := &IAdminService{}
 	mock.Mock.Test(t)
 
 	t.Cleanup(func() { mock.AssertExpectations(t) })
@@ -169,417 +368,339 @@ func NewIArtistRepository(t interface {
 	return mock
 }
 
-// IArtistRepository is an autogenerated mock type for the IArtistRepository type
-type IArtistRepository struct {
+// IAdminService is an autogenerated mock type for the IAdminService type
+type IAdminService struct {
 	mock.Mock
 }
 
-type IArtistRepository_Expecter struct {
+type IAdminService_Expecter struct {
 	mock *mock.Mock
 }
 
-func (_m *IArtistRepository) EXPECT() *IArtistRepository_Expecter {
-	return &IArtistRepository_Expecter{mock: &_m.Mock}
+func (_m *IAdminService) EXPECT() *IAdminService_Expecter {
+	return &IAdminService_Expecter{mock: &_m.Mock}
 }
 
-// Create provides a mock function for the type IArtistRepository
-func (_mock *IArtistRepository) Create(name string) (*models.Artist, error) {
-	ret := _mock.Called(name)
+// Backup provides a mock function for the type IAdminService
+func (_mock *IAdminService) Backup(ctx context.Context, trigger string) (*models.BackupRun, error) {
+	ret := _mock.Called(ctx, trigger)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Create")
+		panic("no return value specified for Backup")
 	}
 
-	var r0 *models.Artist
+	var r0 *models.BackupRun
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(string) (*models.Artist, error)); ok {
-		return returnFunc(name)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*models.BackupRun, error)); ok {
+		return returnFunc(ctx, trigger)
 	}
-	if returnFunc, ok := ret.Get(0).(func(string) *models.Artist); ok {
-		r0 = returnFunc(name)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *models.BackupRun); ok {
+		r0 = returnFunc(ctx, trigger)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Artist)
+			r0 = ret.Get(0).(*models.BackupRun)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(string) error); ok {
-		r1 = returnFunc(name)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, trigger)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// IArtistRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
-type IArtistRepository_Create_Call struct {
+// IAdminService_Backup_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Backup'
+type IAdminService_Backup_Call struct {
 	*mock.Call
 }
 
-// Create is a helper method to define mock.On call
-//   - name
-func (_e *IArtistRepository_Expecter) Create(name interface{}) *IArtistRepository_Create_Call {
-	return &IArtistRepository_Create_Call{Call: _e.mock.On("Create", name)}
+// Backup is a helper method to define mock.On call
+//   - ctx
+//   - trigger
+func (_e *IAdminService_Expecter) Backup(ctx interface{}, trigger interface{}) *IAdminService_Backup_Call {
+	return &IAdminService_Backup_Call{Call: _e.mock.On("Backup", ctx, trigger)}
 }
 
-func (_c *IArtistRepository_Create_Call) Run(run func(name string)) *IArtistRepository_Create_Call {
+func (_c *IAdminService_Backup_Call) Run(run func(ctx context.Context, trigger string)) *IAdminService_Backup_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *IArtistRepository_Create_Call) Return(artist *models.Artist, err error) *IArtistRepository_Create_Call {
-	_c.Call.Return(artist, err)
+func (_c *IAdminService_Backup_Call) Return(backupRun *models.BackupRun, err error) *IAdminService_Backup_Call {
+	_c.Call.Return(backupRun, err)
 	return _c
 }
 
-func (_c *IArtistRepository_Create_Call) RunAndReturn(run func(name string) (*models.Artist, error)) *IArtistRepository_Create_Call {
+func (_c *IAdminService_Backup_Call) RunAndReturn(run func(ctx context.Context, trigger string) (*models.BackupRun, error)) *IAdminService_Backup_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Get provides a mock function for the type IArtistRepository
-func (_mock *IArtistRepository) Get(id uint) (*models.Artist, error) {
-	ret := _mock.Called(id)
+// CheckDatabase provides a mock function for the type IAdminService
+func (_mock *IAdminService) CheckDatabase() error {
+	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for Get")
+		panic("no return value specified for CheckDatabase")
 	}
 
-	var r0 *models.Artist
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(uint) (*models.Artist, error)); ok {
-		return returnFunc(id)
-	}
-	if returnFunc, ok := ret.Get(0).(func(uint) *models.Artist); ok {
-		r0 = returnFunc(id)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Artist)
-		}
-	}
-	if returnFunc, ok := ret.Get(1).(func(uint) error); ok {
-		r1 = returnFunc(id)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func() error); ok {
+		r0 = returnFunc()
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// IArtistRepository_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
-type IArtistRepository_Get_Call struct {
+// IAdminService_CheckDatabase_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CheckDatabase'
+type IAdminService_CheckDatabase_Call struct {
 	*mock.Call
 }
 
-// Get is a helper method to define mock.On call
-//   - id
-func (_e *IArtistRepository_Expecter) Get(id interface{}) *IArtistRepository_Get_Call {
-	return &IArtistRepository_Get_Call{Call: _e.mock.On("Get", id)}
+// CheckDatabase is a helper method to define mock.On call
+func (_e *IAdminService_Expecter) CheckDatabase() *IAdminService_CheckDatabase_Call {
+	return &IAdminService_CheckDatabase_Call{Call: _e.mock.On("CheckDatabase")}
 }
 
-func (_c *IArtistRepository_Get_Call) Run(run func(id uint)) *IArtistRepository_Get_Call {
+func (_c *IAdminService_CheckDatabase_Call) Run(run func()) *IAdminService_CheckDatabase_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(uint))
+		run()
 	})
 	return _c
 }
 
-func (_c *IArtistRepository_Get_Call) Return(artist *models.Artist, err error) *IArtistRepository_Get_Call {
-	_c.Call.Return(artist, err)
+func (_c *IAdminService_CheckDatabase_Call) Return(err error) *IAdminService_CheckDatabase_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *IArtistRepository_Get_Call) RunAndReturn(run func(id uint) (*models.Artist, error)) *IArtistRepository_Get_Call {
+func (_c *IAdminService_CheckDatabase_Call) RunAndReturn(run func() error) *IAdminService_CheckDatabase_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetByOffset provides a mock function for the type IArtistRepository
-func (_mock *IArtistRepository) GetByOffset(offset uint) (*models.Artist, error) {
-	ret := _mock.Called(offset)
+// LastBackupRun provides a mock function for the type IAdminService
+func (_mock *IAdminService) LastBackupRun() (*models.BackupRun, error) {
+	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetByOffset")
+		panic("no return value specified for LastBackupRun")
 	}
 
-	var r0 *models.Artist
+	var r0 *models.BackupRun
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(uint) (*models.Artist, error)); ok {
-		return returnFunc(offset)
+	if returnFunc, ok := ret.Get(0).(func() (*models.BackupRun, error)); ok {
+		return returnFunc()
 	}
-	if returnFunc, ok := ret.Get(0).(func(uint) *models.Artist); ok {
-		r0 = returnFunc(offset)
+	if returnFunc, ok := ret.Get(0).(func() *models.BackupRun); ok {
+		r0 = returnFunc()
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Artist)
+			r0 = ret.Get(0).(*models.BackupRun)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(uint) error); ok {
-		r1 = returnFunc(offset)
+	if returnFunc, ok := ret.Get(1).(func() error); ok {
+		r1 = returnFunc()
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// IArtistRepository_GetByOffset_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByOffset'
-type IArtistRepository_GetByOffset_Call struct {
+// IAdminService_LastBackupRun_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LastBackupRun'
+type IAdminService_LastBackupRun_Call struct {
 	*mock.Call
 }
 
-// GetByOffset is a helper method to define mock.On call
-//   - offset
-func (_e *IArtistRepository_Expecter) GetByOffset(offset interface{}) *IArtistRepository_GetByOffset_Call {
-	return &IArtistRepository_GetByOffset_Call{Call: _e.mock.On("GetByOffset", offset)}
+// LastBackupRun is a helper method to define mock.On call
+func (_e *IAdminService_Expecter) LastBackupRun() *IAdminService_LastBackupRun_Call {
+	return &IAdminService_LastBackupRun_Call{Call: _e.mock.On("LastBackupRun")}
 }
 
-func (_c *IArtistRepository_GetByOffset_Call) Run(run func(offset uint)) *IArtistRepository_GetByOffset_Call {
+func (_c *IAdminService_LastBackupRun_Call) Run(run func()) *IAdminService_LastBackupRun_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(uint))
+		run()
 	})
 	return _c
 }
 
-func (_c *IArtistRepository_GetByOffset_Call) Return(artist *models.Artist, err error) *IArtistRepository_GetByOffset_Call {
-	_c.Call.Return(artist, err)
+func (_c *IAdminService_LastBackupRun_Call) Return(backupRun *models.BackupRun, err error) *IAdminService_LastBackupRun_Call {
+	_c.Call.Return(backupRun, err)
 	return _c
 }
 
-func (_c *IArtistRepository_GetByOffset_Call) RunAndReturn(run func(offset uint) (*models.Artist, error)) *IArtistRepository_GetByOffset_Call {
+func (_c *IAdminService_LastBackupRun_Call) RunAndReturn(run func() (*models.BackupRun, error)) *IAdminService_LastBackupRun_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetCount provides a mock function for the type IArtistRepository
-func (_mock *IArtistRepository) GetCount() (uint, error) {
-	ret := _mock.Called()
+// ListBackups provides a mock function for the type IAdminService
+func (_mock *IAdminService) ListBackups(ctx context.Context) ([]models.Backup, error) {
+	ret := _mock.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetCount")
+		panic("no return value specified for ListBackups")
 	}
 
-	var r0 uint
+	var r0 []models.Backup
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func() (uint, error)); ok {
-		return returnFunc()
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]models.Backup, error)); ok {
+		return returnFunc(ctx)
 	}
-	if returnFunc, ok := ret.Get(0).(func() uint); ok {
-		r0 = returnFunc()
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []models.Backup); ok {
+		r0 = returnFunc(ctx)
 	} else {
-		r0 = ret.Get(0).(uint)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Backup)
+		}
 	}
-	if returnFunc, ok := ret.Get(1).(func() error); ok {
-		r1 = returnFunc()
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// IArtistRepository_GetCount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCount'
-type IArtistRepository_GetCount_Call struct {
+// IAdminService_ListBackups_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListBackups'
+type IAdminService_ListBackups_Call struct {
 	*mock.Call
 }
 
-// GetCount is a helper method to define mock.On call
-func (_e *IArtistRepository_Expecter) GetCount() *IArtistRepository_GetCount_Call {
-	return &IArtistRepository_GetCount_Call{Call: _e.mock.On("GetCount")}
+// ListBackups is a helper method to define mock.On call
+//   - ctx
+func (_e *IAdminService_Expecter) ListBackups(ctx interface{}) *IAdminService_ListBackups_Call {
+	return &IAdminService_ListBackups_Call{Call: _e.mock.On("ListBackups", ctx)}
 }
 
-func (_c *IArtistRepository_GetCount_Call) Run(run func()) *IArtistRepository_GetCount_Call {
+func (_c *IAdminService_ListBackups_Call) Run(run func(ctx context.Context)) *IAdminService_ListBackups_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(context.Context))
 	})
 	return _c
 }
 
-func (_c *IArtistRepository_GetCount_Call) Return(v uint, err error) *IArtistRepository_GetCount_Call {
-	_c.Call.Return(v, err)
+func (_c *IAdminService_ListBackups_Call) Return(backups []models.Backup, err error) *IAdminService_ListBackups_Call {
+	_c.Call.Return(backups, err)
 	return _c
 }
 
-func (_c *IArtistRepository_GetCount_Call) RunAndReturn(run func() (uint, error)) *IArtistRepository_GetCount_Call {
+func (_c *IAdminService_ListBackups_Call) RunAndReturn(run func(ctx context.Context) ([]models.Backup, error)) *IAdminService_ListBackups_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Migrate provides a mock function for the type IArtistRepository
-func (_mock *IArtistRepository) Migrate() error {
-	ret := _mock.Called()
+// Restore provides a mock function for the type IAdminService
+func (_mock *IAdminService) Restore(ctx context.Context, name string) (string, error) {
+	ret := _mock.Called(ctx, name)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Migrate")
+		panic("no return value specified for Restore")
 	}
 
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func() error); ok {
-		r0 = returnFunc()
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+		return returnFunc(ctx, name)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = returnFunc(ctx, name)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(string)
 	}
-	return r0
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
 }
 
-// IArtistRepository_Migrate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Migrate'
-type IArtistRepository_Migrate_Call struct {
+// IAdminService_Restore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Restore'
+type IAdminService_Restore_Call struct {
 	*mock.Call
 }
 
-// Migrate is a helper method to define mock.On call
-func (_e *IArtistRepository_Expecter) Migrate() *IArtistRepository_Migrate_Call {
-	return &IArtistRepository_Migrate_Call{Call: _e.mock.On("Migrate")}
+// Restore is a helper method to define mock.On call
+//   - ctx
+//   - name
+func (_e *IAdminService_Expecter) Restore(ctx interface{}, name interface{}) *IAdminService_Restore_Call {
+	return &IAdminService_Restore_Call{Call: _e.mock.On("Restore", ctx, name)}
 }
 
-func (_c *IArtistRepository_Migrate_Call) Run(run func()) *IArtistRepository_Migrate_Call {
+func (_c *IAdminService_Restore_Call) Run(run func(ctx context.Context, name string)) *IAdminService_Restore_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *IArtistRepository_Migrate_Call) Return(err error) *IArtistRepository_Migrate_Call {
-	_c.Call.Return(err)
+func (_c *IAdminService_Restore_Call) Return(s string, err error) *IAdminService_Restore_Call {
+	_c.Call.Return(s, err)
 	return _c
 }
 
-func (_c *IArtistRepository_Migrate_Call) RunAndReturn(run func() error) *IArtistRepository_Migrate_Call {
+func (_c *IAdminService_Restore_Call) RunAndReturn(run func(ctx context.Context, name string) (string, error)) *IAdminService_Restore_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// NewIArtistRules creates a new instance of IArtistRules. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
-// The first argument is typically a *testing.T value.
-func NewIArtistRules(t interface {
-	mock.TestingT
-	Cleanup(func())
-}) *IArtistRules {
-	mock := &IArtistRules{}
-	mock.Mock.Test(t)
-
-	t.Cleanup(func() { mock.AssertExpectations(t) })
-
-	return mock
-}
-
-// IArtistRules is an autogenerated mock type for the IArtistRules type
-type IArtistRules struct {
-	mock.Mock
-}
-
-type IArtistRules_Expecter struct {
-	mock *mock.Mock
-}
-
-func (_m *IArtistRules) EXPECT() *IArtistRules_Expecter {
-	return &IArtistRules_Expecter{mock: &_m.Mock}
-}
-
-// CleanArtistName provides a mock function for the type IArtistRules
-func (_mock *IArtistRules) CleanArtistName(s string) (string, error) {
-	ret := _mock.Called(s)
-
-	if len(ret) == 0 {
-		panic("no return value specified for CleanArtistName")
-	}
-
-	var r0 string
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(string) (string, error)); ok {
-		return returnFunc(s)
-	}
-	if returnFunc, ok := ret.Get(0).(func(string) string); ok {
-		r0 = returnFunc(s)
-	} else {
-		r0 = ret.Get(0).(string)
-	}
-	if returnFunc, ok := ret.Get(1).(func(string) error); ok {
-		r1 = returnFunc(s)
-	} else {
-		r1 = ret.Error(1)
-	}
-	return r0, r1
-}
-
-// IArtistRules_CleanArtistName_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CleanArtistName'
-type IArtistRules_CleanArtistName_Call struct {
-	*mock.Call
-}
-
-// CleanArtistName is a helper method to define mock.On call
-//   - s
-func (_e *IArtistRules_Expecter) CleanArtistName(s interface{}) *IArtistRules_CleanArtistName_Call {
-	return &IArtistRules_CleanArtistName_Call{Call: _e.mock.On("CleanArtistName", s)}
-}
-
-func (_c *IArtistRules_CleanArtistName_Call) Run(run func(s string)) *IArtistRules_CleanArtistName_Call {
-	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
-	})
-	return _c
-}
-
-func (_c *IArtistRules_CleanArtistName_Call) Return(s1 string, err error) *IArtistRules_CleanArtistName_Call {
-	_c.Call.Return(s1, err)
-	return _c
-}
-
-func (_c *IArtistRules_CleanArtistName_Call) RunAndReturn(run func(s string) (string, error)) *IArtistRules_CleanArtistName_Call {
-	_c.Call.Return(run)
-	return _c
-}
-
-// RandomOffset provides a mock function for the type IArtistRules
-func (_mock *IArtistRules) RandomOffset(count uint) uint {
-	ret := _mock.Called(count)
+// VerifyBackup provides a mock function for the type IAdminService
+func (_mock *IAdminService) VerifyBackup(ctx context.Context, name string) error {
+	ret := _mock.Called(ctx, name)
 
 	if len(ret) == 0 {
-		panic("no return value specified for RandomOffset")
+		panic("no return value specified for VerifyBackup")
 	}
 
-	var r0 uint
-	if returnFunc, ok := ret.Get(0).(func(uint) uint); ok {
-		r0 = returnFunc(count)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, name)
 	} else {
-		r0 = ret.Get(0).(uint)
+		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// IArtistRules_RandomOffset_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RandomOffset'
-type IArtistRules_RandomOffset_Call struct {
+// IAdminService_VerifyBackup_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifyBackup'
+type IAdminService_VerifyBackup_Call struct {
 	*mock.Call
 }
 
-// RandomOffset is a helper method to define mock.On call
-//   - count
-func (_e *IArtistRules_Expecter) RandomOffset(count interface{}) *IArtistRules_RandomOffset_Call {
-	return &IArtistRules_RandomOffset_Call{Call: _e.mock.On("RandomOffset", count)}
+// VerifyBackup is a helper method to define mock.On call
+//   - ctx
+//   - name
+func (_e *IAdminService_Expecter) VerifyBackup(ctx interface{}, name interface{}) *IAdminService_VerifyBackup_Call {
+	return &IAdminService_VerifyBackup_Call{Call: _e.mock.On("VerifyBackup", ctx, name)}
 }
 
-func (_c *IArtistRules_RandomOffset_Call) Run(run func(count uint)) *IArtistRules_RandomOffset_Call {
+func (_c *IAdminService_VerifyBackup_Call) Run(run func(ctx context.Context, name string)) *IAdminService_VerifyBackup_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(uint))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *IArtistRules_RandomOffset_Call) Return(v uint) *IArtistRules_RandomOffset_Call {
-	_c.Call.Return(v)
+func (_c *IAdminService_VerifyBackup_Call) Return(err error) *IAdminService_VerifyBackup_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *IArtistRules_RandomOffset_Call) RunAndReturn(run func(count uint) uint) *IArtistRules_RandomOffset_Call {
+func (_c *IAdminService_VerifyBackup_Call) RunAndReturn(run func(ctx context.Context, name string) error) *IAdminService_VerifyBackup_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// NewIArtistService creates a new instance of IArtistService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// NewIApiKeyRepository creates a new instance of IApiKeyRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
-func NewIArtistService(t interface {
+func NewIApiKeyRepository(t interface {
 	mock.TestingT
 	Cleanup(func())
-}) *IArtistService {
-	mock := &IArtistService{}
+}) *IApiKeyRepository {
+	mock := &IApiKeyRepository{}
 	mock.Mock.Test(t)
 
 	t.Cleanup(func() { mock.AssertExpectations(t) })
@@ -587,149 +708,138 @@ func NewIArtistService(t interface {
 	return mock
 }
 
-// IArtistService is an autogenerated mock type for the IArtistService type
-type IArtistService struct {
+// IApiKeyRepository is an autogenerated mock type for the IApiKeyRepository type
+type IApiKeyRepository struct {
 	mock.Mock
 }
 
-type IArtistService_Expecter struct {
+type IApiKeyRepository_Expecter struct {
 	mock *mock.Mock
 }
 
-func (_m *IArtistService) EXPECT() *IArtistService_Expecter {
-	return &IArtistService_Expecter{mock: &_m.Mock}
+func (_m *IApiKeyRepository) EXPECT() *IApiKeyRepository_Expecter {
+	return &IApiKeyRepository_Expecter{mock: &_m.Mock}
 }
 
-// Create provides a mock function for the type IArtistService
-func (_mock *IArtistService) Create(artistName string) (*models.Artist, error) {
-	ret := _mock.Called(artistName)
+// Create provides a mock function for the type IApiKeyRepository
+func (_mock *IApiKeyRepository) Create(apiKey *models.ApiKey) error {
+	ret := _mock.Called(apiKey)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Create")
 	}
 
-	var r0 *models.Artist
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(string) (*models.Artist, error)); ok {
-		return returnFunc(artistName)
-	}
-	if returnFunc, ok := ret.Get(0).(func(string) *models.Artist); ok {
-		r0 = returnFunc(artistName)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Artist)
-		}
-	}
-	if returnFunc, ok := ret.Get(1).(func(string) error); ok {
-		r1 = returnFunc(artistName)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(*models.ApiKey) error); ok {
+		r0 = returnFunc(apiKey)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// IArtistService_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
-type IArtistService_Create_Call struct {
+// IApiKeyRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type IApiKeyRepository_Create_Call struct {
 	*mock.Call
 }
 
 // Create is a helper method to define mock.On call
-//   - artistName
-func (_e *IArtistService_Expecter) Create(artistName interface{}) *IArtistService_Create_Call {
-	return &IArtistService_Create_Call{Call: _e.mock.On("Create", artistName)}
+//   - apiKey
+func (_e *IApiKeyRepository_Expecter) Create(apiKey interface{}) *IApiKeyRepository_Create_Call {
+	return &IApiKeyRepository_Create_Call{Call: _e.mock.On("Create", apiKey)}
 }
 
-func (_c *IArtistService_Create_Call) Run(run func(artistName string)) *IArtistService_Create_Call {
+func (_c *IApiKeyRepository_Create_Call) Run(run func(apiKey *models.ApiKey)) *IApiKeyRepository_Create_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(*models.ApiKey))
 	})
 	return _c
 }
 
-func (_c *IArtistService_Create_Call) Return(artist *models.Artist, err error) *IArtistService_Create_Call {
-	_c.Call.Return(artist, err)
+func (_c *IApiKeyRepository_Create_Call) Return(err error) *IApiKeyRepository_Create_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *IArtistService_Create_Call) RunAndReturn(run func(artistName string) (*models.Artist, error)) *IArtistService_Create_Call {
+func (_c *IApiKeyRepository_Create_Call) RunAndReturn(run func(apiKey *models.ApiKey) error) *IApiKeyRepository_Create_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Get provides a mock function for the type IArtistService
-func (_mock *IArtistService) Get(id uint) (*models.Artist, error) {
-	ret := _mock.Called(id)
+// GetByHash provides a mock function for the type IApiKeyRepository
+func (_mock *IApiKeyRepository) GetByHash(hash string) (*models.ApiKey, error) {
+	ret := _mock.Called(hash)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Get")
+		panic("no return value specified for GetByHash")
 	}
 
-	var r0 *models.Artist
+	var r0 *models.ApiKey
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(uint) (*models.Artist, error)); ok {
-		return returnFunc(id)
+	if returnFunc, ok := ret.Get(0).(func(string) (*models.ApiKey, error)); ok {
+		return returnFunc(hash)
 	}
-	if returnFunc, ok := ret.Get(0).(func(uint) *models.Artist); ok {
-		r0 = returnFunc(id)
+	if returnFunc, ok := ret.Get(0).(func(string) *models.ApiKey); ok {
+		r0 = returnFunc(hash)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Artist)
+			r0 = ret.Get(0).(*models.ApiKey)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(uint) error); ok {
-		r1 = returnFunc(id)
+	if returnFunc, ok := ret.Get(1).(func(string) error); ok {
+		r1 = returnFunc(hash)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// IArtistService_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
-type IArtistService_Get_Call struct {
+// IApiKeyRepository_GetByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByHash'
+type IApiKeyRepository_GetByHash_Call struct {
 	*mock.Call
 }
 
-// Get is a helper method to define mock.On call
-//   - id
-func (_e *IArtistService_Expecter) Get(id interface{}) *IArtistService_Get_Call {
-	return &IArtistService_Get_Call{Call: _e.mock.On("Get", id)}
+// GetByHash is a helper method to define mock.On call
+//   - hash
+func (_e *IApiKeyRepository_Expecter) GetByHash(hash interface{}) *IApiKeyRepository_GetByHash_Call {
+	return &IApiKeyRepository_GetByHash_Call{Call: _e.mock.On("GetByHash", hash)}
 }
 
-func (_c *IArtistService_Get_Call) Run(run func(id uint)) *IArtistService_Get_Call {
+func (_c *IApiKeyRepository_GetByHash_Call) Run(run func(hash string)) *IApiKeyRepository_GetByHash_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(uint))
+		run(args[0].(string))
 	})
 	return _c
 }
 
-func (_c *IArtistService_Get_Call) Return(artist *models.Artist, err error) *IArtistService_Get_Call {
-	_c.Call.Return(artist, err)
+func (_c *IApiKeyRepository_GetByHash_Call) Return(apiKey *models.ApiKey, err error) *IApiKeyRepository_GetByHash_Call {
+	_c.Call.Return(apiKey, err)
 	return _c
 }
 
-func (_c *IArtistService_Get_Call) RunAndReturn(run func(id uint) (*models.Artist, error)) *IArtistService_Get_Call {
+func (_c *IApiKeyRepository_GetByHash_Call) RunAndReturn(run func(hash string) (*models.ApiKey, error)) *IApiKeyRepository_GetByHash_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetRandom provides a mock function for the type IArtistService
-func (_mock *IArtistService) GetRandom() (*models.Artist, error) {
+// List provides a mock function for the type IApiKeyRepository
+func (_mock *IApiKeyRepository) List() ([]models.ApiKey, error) {
 	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetRandom")
+		panic("no return value specified for List")
 	}
 
-	var r0 *models.Artist
+	var r0 []models.ApiKey
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func() (*models.Artist, error)); ok {
+	if returnFunc, ok := ret.Get(0).(func() ([]models.ApiKey, error)); ok {
 		return returnFunc()
 	}
-	if returnFunc, ok := ret.Get(0).(func() *models.Artist); ok {
+	if returnFunc, ok := ret.Get(0).(func() []models.ApiKey); ok {
 		r0 = returnFunc()
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.Artist)
+			r0 = ret.Get(0).([]models.ApiKey)
 		}
 	}
 	if returnFunc, ok := ret.Get(1).(func() error); ok {
@@ -740,167 +850,176 @@ func (_mock *IArtistService) GetRandom() (*models.Artist, error) {
 	return r0, r1
 }
 
-// IArtistService_GetRandom_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRandom'
-type IArtistService_GetRandom_Call struct {
+// IApiKeyRepository_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type IApiKeyRepository_List_Call struct {
 	*mock.Call
 }
 
-// GetRandom is a helper method to define mock.On call
-func (_e *IArtistService_Expecter) GetRandom() *IArtistService_GetRandom_Call {
-	return &IArtistService_GetRandom_Call{Call: _e.mock.On("GetRandom")}
+// List is a helper method to define mock.On call
+func (_e *IApiKeyRepository_Expecter) List() *IApiKeyRepository_List_Call {
+	return &IApiKeyRepository_List_Call{Call: _e.mock.On("List")}
 }
 
-func (_c *IArtistService_GetRandom_Call) Run(run func()) *IArtistService_GetRandom_Call {
+func (_c *IApiKeyRepository_List_Call) Run(run func()) *IApiKeyRepository_List_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run()
 	})
 	return _c
 }
 
-func (_c *IArtistService_GetRandom_Call) Return(artist *models.Artist, err error) *IArtistService_GetRandom_Call {
-	_c.Call.Return(artist, err)
+func (_c *IApiKeyRepository_List_Call) Return(apiKeys []models.ApiKey, err error) *IApiKeyRepository_List_Call {
+	_c.Call.Return(apiKeys, err)
 	return _c
 }
 
-func (_c *IArtistService_GetRandom_Call) RunAndReturn(run func() (*models.Artist, error)) *IArtistService_GetRandom_Call {
+func (_c *IApiKeyRepository_List_Call) RunAndReturn(run func() ([]models.ApiKey, error)) *IApiKeyRepository_List_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// NewIAuthService creates a new instance of IAuthService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
-// The first argument is typically a *testing.T value.
-func NewIAuthService(t interface {
-	mock.TestingT
-	Cleanup(func())
-}) *IAuthService {
-	mock := &IAuthService{}
-	mock.Mock.Test(t)
+// Migrate provides a mock function for the type IApiKeyRepository
+func (_mock *IApiKeyRepository) Migrate() error {
+	ret := _mock.Called()
 
-	t.Cleanup(func() { mock.AssertExpectations(t) })
+	if len(ret) == 0 {
+		panic("no return value specified for Migrate")
+	}
 
-	return mock
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func() error); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
 }
 
-// IAuthService is an autogenerated mock type for the IAuthService type
-type IAuthService struct {
-	mock.Mock
+// IApiKeyRepository_Migrate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Migrate'
+type IApiKeyRepository_Migrate_Call struct {
+	*mock.Call
 }
 
-type IAuthService_Expecter struct {
-	mock *mock.Mock
+// Migrate is a helper method to define mock.On call
+func (_e *IApiKeyRepository_Expecter) Migrate() *IApiKeyRepository_Migrate_Call {
+	return &IApiKeyRepository_Migrate_Call{Call: _e.mock.On("Migrate")}
 }
 
-func (_m *IAuthService) EXPECT() *IAuthService_Expecter {
-	return &IAuthService_Expecter{mock: &_m.Mock}
+func (_c *IApiKeyRepository_Migrate_Call) Run(run func()) *IApiKeyRepository_Migrate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
 }
 
-// GenerateJWT provides a mock function for the type IAuthService
-func (_mock *IAuthService) GenerateJWT(name string, password string) (string, error) {
-	ret := _mock.Called(name, password)
+func (_c *IApiKeyRepository_Migrate_Call) Return(err error) *IApiKeyRepository_Migrate_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IApiKeyRepository_Migrate_Call) RunAndReturn(run func() error) *IApiKeyRepository_Migrate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Revoke provides a mock function for the type IApiKeyRepository
+func (_mock *IApiKeyRepository) Revoke(id uint, at time.Time) error {
+	ret := _mock.Called(id, at)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GenerateJWT")
+		panic("no return value specified for Revoke")
 	}
 
-	var r0 string
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(string, string) (string, error)); ok {
-		return returnFunc(name, password)
-	}
-	if returnFunc, ok := ret.Get(0).(func(string, string) string); ok {
-		r0 = returnFunc(name, password)
-	} else {
-		r0 = ret.Get(0).(string)
-	}
-	if returnFunc, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = returnFunc(name, password)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(uint, time.Time) error); ok {
+		r0 = returnFunc(id, at)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// IAuthService_GenerateJWT_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GenerateJWT'
-type IAuthService_GenerateJWT_Call struct {
+// IApiKeyRepository_Revoke_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Revoke'
+type IApiKeyRepository_Revoke_Call struct {
 	*mock.Call
 }
 
-// GenerateJWT is a helper method to define mock.On call
-//   - name
-//   - password
-func (_e *IAuthService_Expecter) GenerateJWT(name interface{}, password interface{}) *IAuthService_GenerateJWT_Call {
-	return &IAuthService_GenerateJWT_Call{Call: _e.mock.On("GenerateJWT", name, password)}
+// Revoke is a helper method to define mock.On call
+//   - id
+//   - at
+func (_e *IApiKeyRepository_Expecter) Revoke(id interface{}, at interface{}) *IApiKeyRepository_Revoke_Call {
+	return &IApiKeyRepository_Revoke_Call{Call: _e.mock.On("Revoke", id, at)}
 }
 
-func (_c *IAuthService_GenerateJWT_Call) Run(run func(name string, password string)) *IAuthService_GenerateJWT_Call {
+func (_c *IApiKeyRepository_Revoke_Call) Run(run func(id uint, at time.Time)) *IApiKeyRepository_Revoke_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		run(args[0].(uint), args[1].(time.Time))
 	})
 	return _c
 }
 
-func (_c *IAuthService_GenerateJWT_Call) Return(s string, err error) *IAuthService_GenerateJWT_Call {
-	_c.Call.Return(s, err)
+func (_c *IApiKeyRepository_Revoke_Call) Return(err error) *IApiKeyRepository_Revoke_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *IAuthService_GenerateJWT_Call) RunAndReturn(run func(name string, password string) (string, error)) *IAuthService_GenerateJWT_Call {
+func (_c *IApiKeyRepository_Revoke_Call) RunAndReturn(run func(id uint, at time.Time) error) *IApiKeyRepository_Revoke_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// IsAuthorized provides a mock function for the type IAuthService
-func (_mock *IAuthService) IsAuthorized(token string) bool {
-	ret := _mock.Called(token)
+// Touch provides a mock function for the type IApiKeyRepository
+func (_mock *IApiKeyRepository) Touch(id uint, at time.Time) error {
+	ret := _mock.Called(id, at)
 
 	if len(ret) == 0 {
-		panic("no return value specified for IsAuthorized")
+		panic("no return value specified for Touch")
 	}
 
-	var r0 bool
-	if returnFunc, ok := ret.Get(0).(func(string) bool); ok {
-		r0 = returnFunc(token)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(uint, time.Time) error); ok {
+		r0 = returnFunc(id, at)
 	} else {
-		r0 = ret.Get(0).(bool)
+		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// IAuthService_IsAuthorized_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsAuthorized'
-type IAuthService_IsAuthorized_Call struct {
+// IApiKeyRepository_Touch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Touch'
+type IApiKeyRepository_Touch_Call struct {
 	*mock.Call
 }
 
-// IsAuthorized is a helper method to define mock.On call
-//   - token
-func (_e *IAuthService_Expecter) IsAuthorized(token interface{}) *IAuthService_IsAuthorized_Call {
-	return &IAuthService_IsAuthorized_Call{Call: _e.mock.On("IsAuthorized", token)}
+// Touch is a helper method to define mock.On call
+//   - id
+//   - at
+func (_e *IApiKeyRepository_Expecter) Touch(id interface{}, at interface{}) *IApiKeyRepository_Touch_Call {
+	return &IApiKeyRepository_Touch_Call{Call: _e.mock.On("Touch", id, at)}
 }
 
-func (_c *IAuthService_IsAuthorized_Call) Run(run func(token string)) *IAuthService_IsAuthorized_Call {
+func (_c *IApiKeyRepository_Touch_Call) Run(run func(id uint, at time.Time)) *IApiKeyRepository_Touch_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(uint), args[1].(time.Time))
 	})
 	return _c
 }
 
-func (_c *IAuthService_IsAuthorized_Call) Return(b bool) *IAuthService_IsAuthorized_Call {
-	_c.Call.Return(b)
+func (_c *IApiKeyRepository_Touch_Call) Return(err error) *IApiKeyRepository_Touch_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *IAuthService_IsAuthorized_Call) RunAndReturn(run func(token string) bool) *IAuthService_IsAuthorized_Call {
+func (_c *IApiKeyRepository_Touch_Call) RunAndReturn(run func(id uint, at time.Time) error) *IApiKeyRepository_Touch_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// NewIDbHandler creates a new instance of IDbHandler. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// NewIApiKeyService creates a new instance of IApiKeyService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
-func NewIDbHandler(t interface {
+func NewIApiKeyService(t interface {
 	mock.TestingT
 	Cleanup(func())
-}) *IDbHandler {
-	mock := &IDbHandler{}
+}) *IApiKeyService {
+	mock := &IApiKeyService{}
 	mock.Mock.Test(t)
 
 	t.Cleanup(func() { mock.AssertExpectations(t) })
@@ -908,317 +1027,4138 @@ func NewIDbHandler(t interface {
 	return mock
 }
 
-// IDbHandler is an autogenerated mock type for the IDbHandler type
-type IDbHandler struct {
+// IApiKeyService is an autogenerated mock type for the IApiKeyService type
+type IApiKeyService struct {
 	mock.Mock
 }
 
-type IDbHandler_Expecter struct {
+type IApiKeyService_Expecter struct {
 	mock *mock.Mock
 }
 
-func (_m *IDbHandler) EXPECT() *IDbHandler_Expecter {
-	return &IDbHandler_Expecter{mock: &_m.Mock}
+func (_m *IApiKeyService) EXPECT() *IApiKeyService_Expecter {
+	return &IApiKeyService_Expecter{mock: &_m.Mock}
 }
 
-// Connection provides a mock function for the type IDbHandler
-func (_mock *IDbHandler) Connection() *gorm.DB {
-	ret := _mock.Called()
+// Create provides a mock function for the type IApiKeyService
+func (_mock *IApiKeyService) Create(name string, scopes []string, expiresAt *time.Time) (string, *models.ApiKey, error) {
+	ret := _mock.Called(name, scopes, expiresAt)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Connection")
+		panic("no return value specified for Create")
 	}
 
-	var r0 *gorm.DB
-	if returnFunc, ok := ret.Get(0).(func() *gorm.DB); ok {
-		r0 = returnFunc()
+	var r0 string
+	var r1 *models.ApiKey
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(string, []string, *time.Time) (string, *models.ApiKey, error)); ok {
+		return returnFunc(name, scopes, expiresAt)
+	}
+	if returnFunc, ok := ret.Get(0).(func(string, []string, *time.Time) string); ok {
+		r0 = returnFunc(name, scopes, expiresAt)
 	} else {
-		if ret.Get(0) != nil {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(string, []string, *time.Time) *models.ApiKey); ok {
+		r1 = returnFunc(name, scopes, expiresAt)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*models.ApiKey)
+		}
+	}
+	if returnFunc, ok := ret.Get(2).(func(string, []string, *time.Time) error); ok {
+		r2 = returnFunc(name, scopes, expiresAt)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// IApiKeyService_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type IApiKeyService_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - name
+//   - scopes
+//   - expiresAt
+func (_e *IApiKeyService_Expecter) Create(name interface{}, scopes interface{}, expiresAt interface{}) *IApiKeyService_Create_Call {
+	return &IApiKeyService_Create_Call{Call: _e.mock.On("Create", name, scopes, expiresAt)}
+}
+
+func (_c *IApiKeyService_Create_Call) Run(run func(name string, scopes []string, expiresAt *time.Time)) *IApiKeyService_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].([]string), args[2].(*time.Time))
+	})
+	return _c
+}
+
+func (_c *IApiKeyService_Create_Call) Return(s string, apiKey *models.ApiKey, err error) *IApiKeyService_Create_Call {
+	_c.Call.Return(s, apiKey, err)
+	return _c
+}
+
+func (_c *IApiKeyService_Create_Call) RunAndReturn(run func(name string, scopes []string, expiresAt *time.Time) (string, *models.ApiKey, error)) *IApiKeyService_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IsAuthorized provides a mock function for the type IApiKeyService
+func (_mock *IApiKeyService) IsAuthorized(key string, scope string) bool {
+	ret := _mock.Called(key, scope)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsAuthorized")
+	}
+
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func(string, string) bool); ok {
+		r0 = returnFunc(key, scope)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	return r0
+}
+
+// IApiKeyService_IsAuthorized_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsAuthorized'
+type IApiKeyService_IsAuthorized_Call struct {
+	*mock.Call
+}
+
+// IsAuthorized is a helper method to define mock.On call
+//   - key
+//   - scope
+func (_e *IApiKeyService_Expecter) IsAuthorized(key interface{}, scope interface{}) *IApiKeyService_IsAuthorized_Call {
+	return &IApiKeyService_IsAuthorized_Call{Call: _e.mock.On("IsAuthorized", key, scope)}
+}
+
+func (_c *IApiKeyService_IsAuthorized_Call) Run(run func(key string, scope string)) *IApiKeyService_IsAuthorized_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *IApiKeyService_IsAuthorized_Call) Return(b bool) *IApiKeyService_IsAuthorized_Call {
+	_c.Call.Return(b)
+	return _c
+}
+
+func (_c *IApiKeyService_IsAuthorized_Call) RunAndReturn(run func(key string, scope string) bool) *IApiKeyService_IsAuthorized_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function for the type IApiKeyService
+func (_mock *IApiKeyService) List() ([]models.ApiKey, error) {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []models.ApiKey
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func() ([]models.ApiKey, error)); ok {
+		return returnFunc()
+	}
+	if returnFunc, ok := ret.Get(0).(func() []models.ApiKey); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.ApiKey)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func() error); ok {
+		r1 = returnFunc()
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IApiKeyService_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type IApiKeyService_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+func (_e *IApiKeyService_Expecter) List() *IApiKeyService_List_Call {
+	return &IApiKeyService_List_Call{Call: _e.mock.On("List")}
+}
+
+func (_c *IApiKeyService_List_Call) Run(run func()) *IApiKeyService_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *IApiKeyService_List_Call) Return(apiKeys []models.ApiKey, err error) *IApiKeyService_List_Call {
+	_c.Call.Return(apiKeys, err)
+	return _c
+}
+
+func (_c *IApiKeyService_List_Call) RunAndReturn(run func() ([]models.ApiKey, error)) *IApiKeyService_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Revoke provides a mock function for the type IApiKeyService
+func (_mock *IApiKeyService) Revoke(id uint) error {
+	ret := _mock.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revoke")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(uint) error); ok {
+		r0 = returnFunc(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IApiKeyService_Revoke_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Revoke'
+type IApiKeyService_Revoke_Call struct {
+	*mock.Call
+}
+
+// Revoke is a helper method to define mock.On call
+//   - id
+func (_e *IApiKeyService_Expecter) Revoke(id interface{}) *IApiKeyService_Revoke_Call {
+	return &IApiKeyService_Revoke_Call{Call: _e.mock.On("Revoke", id)}
+}
+
+func (_c *IApiKeyService_Revoke_Call) Run(run func(id uint)) *IApiKeyService_Revoke_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint))
+	})
+	return _c
+}
+
+func (_c *IApiKeyService_Revoke_Call) Return(err error) *IApiKeyService_Revoke_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IApiKeyService_Revoke_Call) RunAndReturn(run func(id uint) error) *IApiKeyService_Revoke_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIArtistRepository creates a new instance of IArtistRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIArtistRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IArtistRepository {
+	mock := &IArtistRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// IArtistRepository is an autogenerated mock type for the IArtistRepository type
+type IArtistRepository struct {
+	mock.Mock
+}
+
+type IArtistRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IArtistRepository) EXPECT() *IArtistRepository_Expecter {
+	return &IArtistRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function for the type IArtistRepository
+func (_mock *IArtistRepository) Create(ctx context.Context, name string) (*models.Artist, error) {
+	ret := _mock.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *models.Artist
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*models.Artist, error)); ok {
+		return returnFunc(ctx, name)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *models.Artist); ok {
+		r0 = returnFunc(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Artist)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IArtistRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type IArtistRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx
+//   - name
+func (_e *IArtistRepository_Expecter) Create(ctx interface{}, name interface{}) *IArtistRepository_Create_Call {
+	return &IArtistRepository_Create_Call{Call: _e.mock.On("Create", ctx, name)}
+}
+
+func (_c *IArtistRepository_Create_Call) Run(run func(ctx context.Context, name string)) *IArtistRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *IArtistRepository_Create_Call) Return(artist *models.Artist, err error) *IArtistRepository_Create_Call {
+	_c.Call.Return(artist, err)
+	return _c
+}
+
+func (_c *IArtistRepository_Create_Call) RunAndReturn(run func(ctx context.Context, name string) (*models.Artist, error)) *IArtistRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function for the type IArtistRepository
+func (_mock *IArtistRepository) Get(ctx context.Context, id uint) (*models.Artist, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *models.Artist
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) (*models.Artist, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) *models.Artist); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Artist)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IArtistRepository_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type IArtistRepository_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *IArtistRepository_Expecter) Get(ctx interface{}, id interface{}) *IArtistRepository_Get_Call {
+	return &IArtistRepository_Get_Call{Call: _e.mock.On("Get", ctx, id)}
+}
+
+func (_c *IArtistRepository_Get_Call) Run(run func(ctx context.Context, id uint)) *IArtistRepository_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint))
+	})
+	return _c
+}
+
+func (_c *IArtistRepository_Get_Call) Return(artist *models.Artist, err error) *IArtistRepository_Get_Call {
+	_c.Call.Return(artist, err)
+	return _c
+}
+
+func (_c *IArtistRepository_Get_Call) RunAndReturn(run func(ctx context.Context, id uint) (*models.Artist, error)) *IArtistRepository_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByOffset provides a mock function for the type IArtistRepository
+func (_mock *IArtistRepository) GetByOffset(ctx context.Context, offset uint) (*models.Artist, error) {
+	ret := _mock.Called(ctx, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByOffset")
+	}
+
+	var r0 *models.Artist
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) (*models.Artist, error)); ok {
+		return returnFunc(ctx, offset)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) *models.Artist); ok {
+		r0 = returnFunc(ctx, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Artist)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = returnFunc(ctx, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IArtistRepository_GetByOffset_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByOffset'
+type IArtistRepository_GetByOffset_Call struct {
+	*mock.Call
+}
+
+// GetByOffset is a helper method to define mock.On call
+//   - ctx
+//   - offset
+func (_e *IArtistRepository_Expecter) GetByOffset(ctx interface{}, offset interface{}) *IArtistRepository_GetByOffset_Call {
+	return &IArtistRepository_GetByOffset_Call{Call: _e.mock.On("GetByOffset", ctx, offset)}
+}
+
+func (_c *IArtistRepository_GetByOffset_Call) Run(run func(ctx context.Context, offset uint)) *IArtistRepository_GetByOffset_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint))
+	})
+	return _c
+}
+
+func (_c *IArtistRepository_GetByOffset_Call) Return(artist *models.Artist, err error) *IArtistRepository_GetByOffset_Call {
+	_c.Call.Return(artist, err)
+	return _c
+}
+
+func (_c *IArtistRepository_GetByOffset_Call) RunAndReturn(run func(ctx context.Context, offset uint) (*models.Artist, error)) *IArtistRepository_GetByOffset_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCount provides a mock function for the type IArtistRepository
+func (_mock *IArtistRepository) GetCount(ctx context.Context) (uint, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCount")
+	}
+
+	var r0 uint
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (uint, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) uint); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Get(0).(uint)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IArtistRepository_GetCount_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCount'
+type IArtistRepository_GetCount_Call struct {
+	*mock.Call
+}
+
+// GetCount is a helper method to define mock.On call
+//   - ctx
+func (_e *IArtistRepository_Expecter) GetCount(ctx interface{}) *IArtistRepository_GetCount_Call {
+	return &IArtistRepository_GetCount_Call{Call: _e.mock.On("GetCount", ctx)}
+}
+
+func (_c *IArtistRepository_GetCount_Call) Run(run func(ctx context.Context)) *IArtistRepository_GetCount_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *IArtistRepository_GetCount_Call) Return(v uint, err error) *IArtistRepository_GetCount_Call {
+	_c.Call.Return(v, err)
+	return _c
+}
+
+func (_c *IArtistRepository_GetCount_Call) RunAndReturn(run func(ctx context.Context) (uint, error)) *IArtistRepository_GetCount_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Migrate provides a mock function for the type IArtistRepository
+func (_mock *IArtistRepository) Migrate() error {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Migrate")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func() error); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IArtistRepository_Migrate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Migrate'
+type IArtistRepository_Migrate_Call struct {
+	*mock.Call
+}
+
+// Migrate is a helper method to define mock.On call
+func (_e *IArtistRepository_Expecter) Migrate() *IArtistRepository_Migrate_Call {
+	return &IArtistRepository_Migrate_Call{Call: _e.mock.On("Migrate")}
+}
+
+func (_c *IArtistRepository_Migrate_Call) Run(run func()) *IArtistRepository_Migrate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *IArtistRepository_Migrate_Call) Return(err error) *IArtistRepository_Migrate_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IArtistRepository_Migrate_Call) RunAndReturn(run func() error) *IArtistRepository_Migrate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIArtistRules creates a new instance of IArtistRules. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIArtistRules(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IArtistRules {
+	mock := &IArtistRules{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// IArtistRules is an autogenerated mock type for the IArtistRules type
+type IArtistRules struct {
+	mock.Mock
+}
+
+type IArtistRules_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IArtistRules) EXPECT() *IArtistRules_Expecter {
+	return &IArtistRules_Expecter{mock: &_m.Mock}
+}
+
+// CleanArtistName provides a mock function for the type IArtistRules
+func (_mock *IArtistRules) CleanArtistName(s string) (string, error) {
+	ret := _mock.Called(s)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CleanArtistName")
+	}
+
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(string) (string, error)); ok {
+		return returnFunc(s)
+	}
+	if returnFunc, ok := ret.Get(0).(func(string) string); ok {
+		r0 = returnFunc(s)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(string) error); ok {
+		r1 = returnFunc(s)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IArtistRules_CleanArtistName_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CleanArtistName'
+type IArtistRules_CleanArtistName_Call struct {
+	*mock.Call
+}
+
+// CleanArtistName is a helper method to define mock.On call
+//   - s
+func (_e *IArtistRules_Expecter) CleanArtistName(s interface{}) *IArtistRules_CleanArtistName_Call {
+	return &IArtistRules_CleanArtistName_Call{Call: _e.mock.On("CleanArtistName", s)}
+}
+
+func (_c *IArtistRules_CleanArtistName_Call) Run(run func(s string)) *IArtistRules_CleanArtistName_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *IArtistRules_CleanArtistName_Call) Return(s1 string, err error) *IArtistRules_CleanArtistName_Call {
+	_c.Call.Return(s1, err)
+	return _c
+}
+
+func (_c *IArtistRules_CleanArtistName_Call) RunAndReturn(run func(s string) (string, error)) *IArtistRules_CleanArtistName_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RandomOffset provides a mock function for the type IArtistRules
+func (_mock *IArtistRules) RandomOffset(count uint) uint {
+	ret := _mock.Called(count)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RandomOffset")
+	}
+
+	var r0 uint
+	if returnFunc, ok := ret.Get(0).(func(uint) uint); ok {
+		r0 = returnFunc(count)
+	} else {
+		r0 = ret.Get(0).(uint)
+	}
+	return r0
+}
+
+// IArtistRules_RandomOffset_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RandomOffset'
+type IArtistRules_RandomOffset_Call struct {
+	*mock.Call
+}
+
+// RandomOffset is a helper method to define mock.On call
+//   - count
+func (_e *IArtistRules_Expecter) RandomOffset(count interface{}) *IArtistRules_RandomOffset_Call {
+	return &IArtistRules_RandomOffset_Call{Call: _e.mock.On("RandomOffset", count)}
+}
+
+func (_c *IArtistRules_RandomOffset_Call) Run(run func(count uint)) *IArtistRules_RandomOffset_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint))
+	})
+	return _c
+}
+
+func (_c *IArtistRules_RandomOffset_Call) Return(v uint) *IArtistRules_RandomOffset_Call {
+	_c.Call.Return(v)
+	return _c
+}
+
+func (_c *IArtistRules_RandomOffset_Call) RunAndReturn(run func(count uint) uint) *IArtistRules_RandomOffset_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIArtistService creates a new instance of IArtistService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIArtistService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IArtistService {
+	mock := &IArtistService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// IArtistService is an autogenerated mock type for the IArtistService type
+type IArtistService struct {
+	mock.Mock
+}
+
+type IArtistService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IArtistService) EXPECT() *IArtistService_Expecter {
+	return &IArtistService_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function for the type IArtistService
+func (_mock *IArtistService) Create(ctx context.Context, artistName string) (*models.Artist, error) {
+	ret := _mock.Called(ctx, artistName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *models.Artist
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*models.Artist, error)); ok {
+		return returnFunc(ctx, artistName)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *models.Artist); ok {
+		r0 = returnFunc(ctx, artistName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Artist)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, artistName)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IArtistService_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type IArtistService_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx
+//   - artistName
+func (_e *IArtistService_Expecter) Create(ctx interface{}, artistName interface{}) *IArtistService_Create_Call {
+	return &IArtistService_Create_Call{Call: _e.mock.On("Create", ctx, artistName)}
+}
+
+func (_c *IArtistService_Create_Call) Run(run func(ctx context.Context, artistName string)) *IArtistService_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *IArtistService_Create_Call) Return(artist *models.Artist, err error) *IArtistService_Create_Call {
+	_c.Call.Return(artist, err)
+	return _c
+}
+
+func (_c *IArtistService_Create_Call) RunAndReturn(run func(ctx context.Context, artistName string) (*models.Artist, error)) *IArtistService_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function for the type IArtistService
+func (_mock *IArtistService) Get(ctx context.Context, id uint) (*models.Artist, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *models.Artist
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) (*models.Artist, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, uint) *models.Artist); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Artist)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IArtistService_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type IArtistService_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *IArtistService_Expecter) Get(ctx interface{}, id interface{}) *IArtistService_Get_Call {
+	return &IArtistService_Get_Call{Call: _e.mock.On("Get", ctx, id)}
+}
+
+func (_c *IArtistService_Get_Call) Run(run func(ctx context.Context, id uint)) *IArtistService_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uint))
+	})
+	return _c
+}
+
+func (_c *IArtistService_Get_Call) Return(artist *models.Artist, err error) *IArtistService_Get_Call {
+	_c.Call.Return(artist, err)
+	return _c
+}
+
+func (_c *IArtistService_Get_Call) RunAndReturn(run func(ctx context.Context, id uint) (*models.Artist, error)) *IArtistService_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRandom provides a mock function for the type IArtistService
+func (_mock *IArtistService) GetRandom(ctx context.Context) (*models.Artist, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRandom")
+	}
+
+	var r0 *models.Artist
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (*models.Artist, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) *models.Artist); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Artist)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IArtistService_GetRandom_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRandom'
+type IArtistService_GetRandom_Call struct {
+	*mock.Call
+}
+
+// GetRandom is a helper method to define mock.On call
+//   - ctx
+func (_e *IArtistService_Expecter) GetRandom(ctx interface{}) *IArtistService_GetRandom_Call {
+	return &IArtistService_GetRandom_Call{Call: _e.mock.On("GetRandom", ctx)}
+}
+
+func (_c *IArtistService_GetRandom_Call) Run(run func(ctx context.Context)) *IArtistService_GetRandom_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *IArtistService_GetRandom_Call) Return(artist *models.Artist, err error) *IArtistService_GetRandom_Call {
+	_c.Call.Return(artist, err)
+	return _c
+}
+
+func (_c *IArtistService_GetRandom_Call) RunAndReturn(run func(ctx context.Context) (*models.Artist, error)) *IArtistService_GetRandom_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIAuthService creates a new instance of IAuthService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIAuthService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IAuthService {
+	mock := &IAuthService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// IAuthService is an autogenerated mock type for the IAuthService type
+type IAuthService struct {
+	mock.Mock
+}
+
+type IAuthService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IAuthService) EXPECT() *IAuthService_Expecter {
+	return &IAuthService_Expecter{mock: &_m.Mock}
+}
+
+// Authorize provides a mock function for the type IAuthService
+func (_mock *IAuthService) Authorize(ctx context.Context, token string, role string) error {
+	ret := _mock.Called(ctx, token, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Authorize")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, token, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IAuthService_Authorize_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Authorize'
+type IAuthService_Authorize_Call struct {
+	*mock.Call
+}
+
+// Authorize is a helper method to define mock.On call
+//   - ctx
+//   - token
+//   - role
+func (_e *IAuthService_Expecter) Authorize(ctx interface{}, token interface{}, role interface{}) *IAuthService_Authorize_Call {
+	return &IAuthService_Authorize_Call{Call: _e.mock.On("Authorize", ctx, token, role)}
+}
+
+func (_c *IAuthService_Authorize_Call) Run(run func(ctx context.Context, token string, role string)) *IAuthService_Authorize_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *IAuthService_Authorize_Call) Return(err error) *IAuthService_Authorize_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IAuthService_Authorize_Call) RunAndReturn(run func(ctx context.Context, token string, role string) error) *IAuthService_Authorize_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GenerateJWT provides a mock function for the type IAuthService
+func (_mock *IAuthService) GenerateJWT(ctx context.Context, name string, password string) (string, error) {
+	ret := _mock.Called(ctx, name, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GenerateJWT")
+	}
+
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (string, error)); ok {
+		return returnFunc(ctx, name, password)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
+		r0 = returnFunc(ctx, name, password)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(ctx, name, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IAuthService_GenerateJWT_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GenerateJWT'
+type IAuthService_GenerateJWT_Call struct {
+	*mock.Call
+}
+
+// GenerateJWT is a helper method to define mock.On call
+//   - ctx
+//   - name
+//   - password
+func (_e *IAuthService_Expecter) GenerateJWT(ctx interface{}, name interface{}, password interface{}) *IAuthService_GenerateJWT_Call {
+	return &IAuthService_GenerateJWT_Call{Call: _e.mock.On("GenerateJWT", ctx, name, password)}
+}
+
+func (_c *IAuthService_GenerateJWT_Call) Run(run func(ctx context.Context, name string, password string)) *IAuthService_GenerateJWT_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *IAuthService_GenerateJWT_Call) Return(s string, err error) *IAuthService_GenerateJWT_Call {
+	_c.Call.Return(s, err)
+	return _c
+}
+
+func (_c *IAuthService_GenerateJWT_Call) RunAndReturn(run func(ctx context.Context, name string, password string) (string, error)) *IAuthService_GenerateJWT_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IssueJWT provides a mock function for the type IAuthService
+func (_mock *IAuthService) IssueJWT(ctx context.Context, user *models.User) (string, error) {
+	ret := _mock.Called(ctx, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IssueJWT")
+	}
+
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *models.User) (string, error)); ok {
+		return returnFunc(ctx, user)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *models.User) string); ok {
+		r0 = returnFunc(ctx, user)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *models.User) error); ok {
+		r1 = returnFunc(ctx, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IAuthService_IssueJWT_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IssueJWT'
+type IAuthService_IssueJWT_Call struct {
+	*mock.Call
+}
+
+// IssueJWT is a helper method to define mock.On call
+//   - ctx
+//   - user
+func (_e *IAuthService_Expecter) IssueJWT(ctx interface{}, user interface{}) *IAuthService_IssueJWT_Call {
+	return &IAuthService_IssueJWT_Call{Call: _e.mock.On("IssueJWT", ctx, user)}
+}
+
+func (_c *IAuthService_IssueJWT_Call) Run(run func(ctx context.Context, user *models.User)) *IAuthService_IssueJWT_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*models.User))
+	})
+	return _c
+}
+
+func (_c *IAuthService_IssueJWT_Call) Return(s string, err error) *IAuthService_IssueJWT_Call {
+	_c.Call.Return(s, err)
+	return _c
+}
+
+func (_c *IAuthService_IssueJWT_Call) RunAndReturn(run func(ctx context.Context, user *models.User) (string, error)) *IAuthService_IssueJWT_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// JWKS provides a mock function for the type IAuthService
+func (_mock *IAuthService) JWKS(ctx context.Context) viewmodels.JwksVM {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for JWKS")
+	}
+
+	var r0 viewmodels.JwksVM
+	if returnFunc, ok := ret.Get(0).(func(context.Context) viewmodels.JwksVM); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Get(0).(viewmodels.JwksVM)
+	}
+	return r0
+}
+
+// IAuthService_JWKS_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'JWKS'
+type IAuthService_JWKS_Call struct {
+	*mock.Call
+}
+
+// JWKS is a helper method to define mock.On call
+//   - ctx
+func (_e *IAuthService_Expecter) JWKS(ctx interface{}) *IAuthService_JWKS_Call {
+	return &IAuthService_JWKS_Call{Call: _e.mock.On("JWKS", ctx)}
+}
+
+func (_c *IAuthService_JWKS_Call) Run(run func(ctx context.Context)) *IAuthService_JWKS_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *IAuthService_JWKS_Call) Return(jwksVM viewmodels.JwksVM) *IAuthService_JWKS_Call {
+	_c.Call.Return(jwksVM)
+	return _c
+}
+
+func (_c *IAuthService_JWKS_Call) RunAndReturn(run func(ctx context.Context) viewmodels.JwksVM) *IAuthService_JWKS_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Logout provides a mock function for the type IAuthService
+func (_mock *IAuthService) Logout(ctx context.Context, token string) error {
+	ret := _mock.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Logout")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IAuthService_Logout_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Logout'
+type IAuthService_Logout_Call struct {
+	*mock.Call
+}
+
+// Logout is a helper method to define mock.On call
+//   - ctx
+//   - token
+func (_e *IAuthService_Expecter) Logout(ctx interface{}, token interface{}) *IAuthService_Logout_Call {
+	return &IAuthService_Logout_Call{Call: _e.mock.On("Logout", ctx, token)}
+}
+
+func (_c *IAuthService_Logout_Call) Run(run func(ctx context.Context, token string)) *IAuthService_Logout_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *IAuthService_Logout_Call) Return(err error) *IAuthService_Logout_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IAuthService_Logout_Call) RunAndReturn(run func(ctx context.Context, token string) error) *IAuthService_Logout_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeSessions provides a mock function for the type IAuthService
+func (_mock *IAuthService) RevokeSessions(ctx context.Context, name string) error {
+	ret := _mock.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSessions")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IAuthService_RevokeSessions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeSessions'
+type IAuthService_RevokeSessions_Call struct {
+	*mock.Call
+}
+
+// RevokeSessions is a helper method to define mock.On call
+//   - ctx
+//   - name
+func (_e *IAuthService_Expecter) RevokeSessions(ctx interface{}, name interface{}) *IAuthService_RevokeSessions_Call {
+	return &IAuthService_RevokeSessions_Call{Call: _e.mock.On("RevokeSessions", ctx, name)}
+}
+
+func (_c *IAuthService_RevokeSessions_Call) Run(run func(ctx context.Context, name string)) *IAuthService_RevokeSessions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *IAuthService_RevokeSessions_Call) Return(err error) *IAuthService_RevokeSessions_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IAuthService_RevokeSessions_Call) RunAndReturn(run func(ctx context.Context, name string) error) *IAuthService_RevokeSessions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIBackupRunRepository creates a new instance of IBackupRunRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIBackupRunRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IBackupRunRepository {
+	mock := &IBackupRunRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// IBackupRunRepository is an autogenerated mock type for the IBackupRunRepository type
+type IBackupRunRepository struct {
+	mock.Mock
+}
+
+type IBackupRunRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IBackupRunRepository) EXPECT() *IBackupRunRepository_Expecter {
+	return &IBackupRunRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function for the type IBackupRunRepository
+func (_mock *IBackupRunRepository) Create(run *models.BackupRun) error {
+	ret := _mock.Called(run)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(*models.BackupRun) error); ok {
+		r0 = returnFunc(run)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IBackupRunRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type IBackupRunRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - run
+func (_e *IBackupRunRepository_Expecter) Create(run interface{}) *IBackupRunRepository_Create_Call {
+	return &IBackupRunRepository_Create_Call{Call: _e.mock.On("Create", run)}
+}
+
+func (_c *IBackupRunRepository_Create_Call) Run(run func(run *models.BackupRun)) *IBackupRunRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*models.BackupRun))
+	})
+	return _c
+}
+
+func (_c *IBackupRunRepository_Create_Call) Return(err error) *IBackupRunRepository_Create_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IBackupRunRepository_Create_Call) RunAndReturn(run func(run *models.BackupRun) error) *IBackupRunRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Last provides a mock function for the type IBackupRunRepository
+func (_mock *IBackupRunRepository) Last() (*models.BackupRun, error) {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Last")
+	}
+
+	var r0 *models.BackupRun
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func() (*models.BackupRun, error)); ok {
+		return returnFunc()
+	}
+	if returnFunc, ok := ret.Get(0).(func() *models.BackupRun); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.BackupRun)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func() error); ok {
+		r1 = returnFunc()
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IBackupRunRepository_Last_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Last'
+type IBackupRunRepository_Last_Call struct {
+	*mock.Call
+}
+
+// Last is a helper method to define mock.On call
+func (_e *IBackupRunRepository_Expecter) Last() *IBackupRunRepository_Last_Call {
+	return &IBackupRunRepository_Last_Call{Call: _e.mock.On("Last")}
+}
+
+func (_c *IBackupRunRepository_Last_Call) Run(run func()) *IBackupRunRepository_Last_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *IBackupRunRepository_Last_Call) Return(backupRun *models.BackupRun, err error) *IBackupRunRepository_Last_Call {
+	_c.Call.Return(backupRun, err)
+	return _c
+}
+
+func (_c *IBackupRunRepository_Last_Call) RunAndReturn(run func() (*models.BackupRun, error)) *IBackupRunRepository_Last_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Migrate provides a mock function for the type IBackupRunRepository
+func (_mock *IBackupRunRepository) Migrate() error {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Migrate")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func() error); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IBackupRunRepository_Migrate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Migrate'
+type IBackupRunRepository_Migrate_Call struct {
+	*mock.Call
+}
+
+// Migrate is a helper method to define mock.On call
+func (_e *IBackupRunRepository_Expecter) Migrate() *IBackupRunRepository_Migrate_Call {
+	return &IBackupRunRepository_Migrate_Call{Call: _e.mock.On("Migrate")}
+}
+
+func (_c *IBackupRunRepository_Migrate_Call) Run(run func()) *IBackupRunRepository_Migrate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *IBackupRunRepository_Migrate_Call) Return(err error) *IBackupRunRepository_Migrate_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IBackupRunRepository_Migrate_Call) RunAndReturn(run func() error) *IBackupRunRepository_Migrate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewICompressor creates a new instance of ICompressor. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewICompressor(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ICompressor {
+	mock := &ICompressor{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// ICompressor is an autogenerated mock type for the ICompressor type
+type ICompressor struct {
+	mock.Mock
+}
+
+type ICompressor_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *ICompressor) EXPECT() *ICompressor_Expecter {
+	return &ICompressor_Expecter{mock: &_m.Mock}
+}
+
+// Compress provides a mock function for the type ICompressor
+func (_mock *ICompressor) Compress(src string, dest string, compression string) error {
+	ret := _mock.Called(src, dest, compression)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Compress")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = returnFunc(src, dest, compression)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// ICompressor_Compress_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Compress'
+type ICompressor_Compress_Call struct {
+	*mock.Call
+}
+
+// Compress is a helper method to define mock.On call
+//   - src
+//   - dest
+//   - compression
+func (_e *ICompressor_Expecter) Compress(src interface{}, dest interface{}, compression interface{}) *ICompressor_Compress_Call {
+	return &ICompressor_Compress_Call{Call: _e.mock.On("Compress", src, dest, compression)}
+}
+
+func (_c *ICompressor_Compress_Call) Run(run func(src string, dest string, compression string)) *ICompressor_Compress_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *ICompressor_Compress_Call) Return(err error) *ICompressor_Compress_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *ICompressor_Compress_Call) RunAndReturn(run func(src string, dest string, compression string) error) *ICompressor_Compress_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Decompress provides a mock function for the type ICompressor
+func (_mock *ICompressor) Decompress(src string, dest string, compression string) error {
+	ret := _mock.Called(src, dest, compression)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Decompress")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = returnFunc(src, dest, compression)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// ICompressor_Decompress_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Decompress'
+type ICompressor_Decompress_Call struct {
+	*mock.Call
+}
+
+// Decompress is a helper method to define mock.On call
+//   - src
+//   - dest
+//   - compression
+func (_e *ICompressor_Expecter) Decompress(src interface{}, dest interface{}, compression interface{}) *ICompressor_Decompress_Call {
+	return &ICompressor_Decompress_Call{Call: _e.mock.On("Decompress", src, dest, compression)}
+}
+
+func (_c *ICompressor_Decompress_Call) Run(run func(src string, dest string, compression string)) *ICompressor_Decompress_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *ICompressor_Decompress_Call) Return(err error) *ICompressor_Decompress_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *ICompressor_Decompress_Call) RunAndReturn(run func(src string, dest string, compression string) error) *ICompressor_Decompress_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIDbHandler creates a new instance of IDbHandler. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIDbHandler(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IDbHandler {
+	mock := &IDbHandler{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// IDbHandler is an autogenerated mock type for the IDbHandler type
+type IDbHandler struct {
+	mock.Mock
+}
+
+type IDbHandler_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IDbHandler) EXPECT() *IDbHandler_Expecter {
+	return &IDbHandler_Expecter{mock: &_m.Mock}
+}
+
+// Connection provides a mock function for the type IDbHandler
+func (_mock *IDbHandler) Connection() *gorm.DB {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Connection")
+	}
+
+	var r0 *gorm.DB
+	if returnFunc, ok := ret.Get(0).(func() *gorm.DB); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*gorm.DB)
 		}
 	}
-	return r0
+	return r0
+}
+
+// IDbHandler_Connection_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Connection'
+type IDbHandler_Connection_Call struct {
+	*mock.Call
+}
+
+// Connection is a helper method to define mock.On call
+func (_e *IDbHandler_Expecter) Connection() *IDbHandler_Connection_Call {
+	return &IDbHandler_Connection_Call{Call: _e.mock.On("Connection")}
+}
+
+func (_c *IDbHandler_Connection_Call) Run(run func()) *IDbHandler_Connection_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *IDbHandler_Connection_Call) Return(dB *gorm.DB) *IDbHandler_Connection_Call {
+	_c.Call.Return(dB)
+	return _c
+}
+
+func (_c *IDbHandler_Connection_Call) RunAndReturn(run func() *gorm.DB) *IDbHandler_Connection_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Ping provides a mock function for the type IDbHandler
+func (_mock *IDbHandler) Ping(ctx context.Context) error {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Ping")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IDbHandler_Ping_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Ping'
+type IDbHandler_Ping_Call struct {
+	*mock.Call
+}
+
+// Ping is a helper method to define mock.On call
+//   - ctx
+func (_e *IDbHandler_Expecter) Ping(ctx interface{}) *IDbHandler_Ping_Call {
+	return &IDbHandler_Ping_Call{Call: _e.mock.On("Ping", ctx)}
+}
+
+func (_c *IDbHandler_Ping_Call) Run(run func(ctx context.Context)) *IDbHandler_Ping_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *IDbHandler_Ping_Call) Return(err error) *IDbHandler_Ping_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IDbHandler_Ping_Call) RunAndReturn(run func(ctx context.Context) error) *IDbHandler_Ping_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Replace provides a mock function for the type IDbHandler
+func (_mock *IDbHandler) Replace(file string) error {
+	ret := _mock.Called(file)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Replace")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(file)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IDbHandler_Replace_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Replace'
+type IDbHandler_Replace_Call struct {
+	*mock.Call
+}
+
+// Replace is a helper method to define mock.On call
+//   - file
+func (_e *IDbHandler_Expecter) Replace(file interface{}) *IDbHandler_Replace_Call {
+	return &IDbHandler_Replace_Call{Call: _e.mock.On("Replace", file)}
+}
+
+func (_c *IDbHandler_Replace_Call) Run(run func(file string)) *IDbHandler_Replace_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *IDbHandler_Replace_Call) Return(err error) *IDbHandler_Replace_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IDbHandler_Replace_Call) RunAndReturn(run func(file string) error) *IDbHandler_Replace_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIEncryptor creates a new instance of IEncryptor. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIEncryptor(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IEncryptor {
+	mock := &IEncryptor{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// IEncryptor is an autogenerated mock type for the IEncryptor type
+type IEncryptor struct {
+	mock.Mock
+}
+
+type IEncryptor_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IEncryptor) EXPECT() *IEncryptor_Expecter {
+	return &IEncryptor_Expecter{mock: &_m.Mock}
+}
+
+// Decrypt provides a mock function for the type IEncryptor
+func (_mock *IEncryptor) Decrypt(src string, dest string) error {
+	ret := _mock.Called(src, dest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Decrypt")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = returnFunc(src, dest)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IEncryptor_Decrypt_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Decrypt'
+type IEncryptor_Decrypt_Call struct {
+	*mock.Call
+}
+
+// Decrypt is a helper method to define mock.On call
+//   - src
+//   - dest
+func (_e *IEncryptor_Expecter) Decrypt(src interface{}, dest interface{}) *IEncryptor_Decrypt_Call {
+	return &IEncryptor_Decrypt_Call{Call: _e.mock.On("Decrypt", src, dest)}
+}
+
+func (_c *IEncryptor_Decrypt_Call) Run(run func(src string, dest string)) *IEncryptor_Decrypt_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *IEncryptor_Decrypt_Call) Return(err error) *IEncryptor_Decrypt_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IEncryptor_Decrypt_Call) RunAndReturn(run func(src string, dest string) error) *IEncryptor_Decrypt_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Encrypt provides a mock function for the type IEncryptor
+func (_mock *IEncryptor) Encrypt(src string, dest string) (string, error) {
+	ret := _mock.Called(src, dest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Encrypt")
+	}
+
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(string, string) (string, error)); ok {
+		return returnFunc(src, dest)
+	}
+	if returnFunc, ok := ret.Get(0).(func(string, string) string); ok {
+		r0 = returnFunc(src, dest)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = returnFunc(src, dest)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IEncryptor_Encrypt_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Encrypt'
+type IEncryptor_Encrypt_Call struct {
+	*mock.Call
+}
+
+// Encrypt is a helper method to define mock.On call
+//   - src
+//   - dest
+func (_e *IEncryptor_Expecter) Encrypt(src interface{}, dest interface{}) *IEncryptor_Encrypt_Call {
+	return &IEncryptor_Encrypt_Call{Call: _e.mock.On("Encrypt", src, dest)}
+}
+
+func (_c *IEncryptor_Encrypt_Call) Run(run func(src string, dest string)) *IEncryptor_Encrypt_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *IEncryptor_Encrypt_Call) Return(s string, err error) *IEncryptor_Encrypt_Call {
+	_c.Call.Return(s, err)
+	return _c
+}
+
+func (_c *IEncryptor_Encrypt_Call) RunAndReturn(run func(src string, dest string) (string, error)) *IEncryptor_Encrypt_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIFileUtil creates a new instance of IFileUtil. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIFileUtil(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IFileUtil {
+	mock := &IFileUtil{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// IFileUtil is an autogenerated mock type for the IFileUtil type
+type IFileUtil struct {
+	mock.Mock
+}
+
+type IFileUtil_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IFileUtil) EXPECT() *IFileUtil_Expecter {
+	return &IFileUtil_Expecter{mock: &_m.Mock}
+}
+
+// Checksum provides a mock function for the type IFileUtil
+func (_mock *IFileUtil) Checksum(file string) (string, int64, error) {
+	ret := _mock.Called(file)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Checksum")
+	}
+
+	var r0 string
+	var r1 int64
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(string) (string, int64, error)); ok {
+		return returnFunc(file)
+	}
+	if returnFunc, ok := ret.Get(0).(func(string) string); ok {
+		r0 = returnFunc(file)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(string) int64); ok {
+		r1 = returnFunc(file)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+	if returnFunc, ok := ret.Get(2).(func(string) error); ok {
+		r2 = returnFunc(file)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// IFileUtil_Checksum_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Checksum'
+type IFileUtil_Checksum_Call struct {
+	*mock.Call
+}
+
+// Checksum is a helper method to define mock.On call
+//   - file
+func (_e *IFileUtil_Expecter) Checksum(file interface{}) *IFileUtil_Checksum_Call {
+	return &IFileUtil_Checksum_Call{Call: _e.mock.On("Checksum", file)}
+}
+
+func (_c *IFileUtil_Checksum_Call) Run(run func(file string)) *IFileUtil_Checksum_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *IFileUtil_Checksum_Call) Return(s string, v int64, err error) *IFileUtil_Checksum_Call {
+	_c.Call.Return(s, v, err)
+	return _c
+}
+
+func (_c *IFileUtil_Checksum_Call) RunAndReturn(run func(file string) (string, int64, error)) *IFileUtil_Checksum_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteIfExists provides a mock function for the type IFileUtil
+func (_mock *IFileUtil) DeleteIfExists(file string) error {
+	ret := _mock.Called(file)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteIfExists")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(file)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IFileUtil_DeleteIfExists_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteIfExists'
+type IFileUtil_DeleteIfExists_Call struct {
+	*mock.Call
+}
+
+// DeleteIfExists is a helper method to define mock.On call
+//   - file
+func (_e *IFileUtil_Expecter) DeleteIfExists(file interface{}) *IFileUtil_DeleteIfExists_Call {
+	return &IFileUtil_DeleteIfExists_Call{Call: _e.mock.On("DeleteIfExists", file)}
+}
+
+func (_c *IFileUtil_DeleteIfExists_Call) Run(run func(file string)) *IFileUtil_DeleteIfExists_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *IFileUtil_DeleteIfExists_Call) Return(err error) *IFileUtil_DeleteIfExists_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IFileUtil_DeleteIfExists_Call) RunAndReturn(run func(file string) error) *IFileUtil_DeleteIfExists_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReadFile provides a mock function for the type IFileUtil
+func (_mock *IFileUtil) ReadFile(file string) ([]byte, error) {
+	ret := _mock.Called(file)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReadFile")
+	}
+
+	var r0 []byte
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(string) ([]byte, error)); ok {
+		return returnFunc(file)
+	}
+	if returnFunc, ok := ret.Get(0).(func(string) []byte); ok {
+		r0 = returnFunc(file)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(string) error); ok {
+		r1 = returnFunc(file)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IFileUtil_ReadFile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReadFile'
+type IFileUtil_ReadFile_Call struct {
+	*mock.Call
+}
+
+// ReadFile is a helper method to define mock.On call
+//   - file
+func (_e *IFileUtil_Expecter) ReadFile(file interface{}) *IFileUtil_ReadFile_Call {
+	return &IFileUtil_ReadFile_Call{Call: _e.mock.On("ReadFile", file)}
+}
+
+func (_c *IFileUtil_ReadFile_Call) Run(run func(file string)) *IFileUtil_ReadFile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *IFileUtil_ReadFile_Call) Return(bytes []byte, err error) *IFileUtil_ReadFile_Call {
+	_c.Call.Return(bytes, err)
+	return _c
+}
+
+func (_c *IFileUtil_ReadFile_Call) RunAndReturn(run func(file string) ([]byte, error)) *IFileUtil_ReadFile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WriteFile provides a mock function for the type IFileUtil
+func (_mock *IFileUtil) WriteFile(file string, data []byte) error {
+	ret := _mock.Called(file, data)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WriteFile")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string, []byte) error); ok {
+		r0 = returnFunc(file, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IFileUtil_WriteFile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WriteFile'
+type IFileUtil_WriteFile_Call struct {
+	*mock.Call
+}
+
+// WriteFile is a helper method to define mock.On call
+//   - file
+//   - data
+func (_e *IFileUtil_Expecter) WriteFile(file interface{}, data interface{}) *IFileUtil_WriteFile_Call {
+	return &IFileUtil_WriteFile_Call{Call: _e.mock.On("WriteFile", file, data)}
+}
+
+func (_c *IFileUtil_WriteFile_Call) Run(run func(file string, data []byte)) *IFileUtil_WriteFile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].([]byte))
+	})
+	return _c
+}
+
+func (_c *IFileUtil_WriteFile_Call) Return(err error) *IFileUtil_WriteFile_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IFileUtil_WriteFile_Call) RunAndReturn(run func(file string, data []byte) error) *IFileUtil_WriteFile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIHealthService creates a new instance of IHealthService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIHealthService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IHealthService {
+	mock := &IHealthService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// IHealthService is an autogenerated mock type for the IHealthService type
+type IHealthService struct {
+	mock.Mock
+}
+
+type IHealthService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IHealthService) EXPECT() *IHealthService_Expecter {
+	return &IHealthService_Expecter{mock: &_m.Mock}
+}
+
+// Ready provides a mock function for the type IHealthService
+func (_mock *IHealthService) Ready(ctx context.Context) models.Readiness {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Ready")
+	}
+
+	var r0 models.Readiness
+	if returnFunc, ok := ret.Get(0).(func(context.Context) models.Readiness); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Get(0).(models.Readiness)
+	}
+	return r0
+}
+
+// IHealthService_Ready_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Ready'
+type IHealthService_Ready_Call struct {
+	*mock.Call
+}
+
+// Ready is a helper method to define mock.On call
+//   - ctx
+func (_e *IHealthService_Expecter) Ready(ctx interface{}) *IHealthService_Ready_Call {
+	return &IHealthService_Ready_Call{Call: _e.mock.On("Ready", ctx)}
+}
+
+func (_c *IHealthService_Ready_Call) Run(run func(ctx context.Context)) *IHealthService_Ready_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *IHealthService_Ready_Call) Return(readiness models.Readiness) *IHealthService_Ready_Call {
+	_c.Call.Return(readiness)
+	return _c
+}
+
+func (_c *IHealthService_Ready_Call) RunAndReturn(run func(ctx context.Context) models.Readiness) *IHealthService_Ready_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Version provides a mock function for the type IHealthService
+func (_mock *IHealthService) Version() models.BuildInfo {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Version")
+	}
+
+	var r0 models.BuildInfo
+	if returnFunc, ok := ret.Get(0).(func() models.BuildInfo); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(models.BuildInfo)
+	}
+	return r0
+}
+
+// IHealthService_Version_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Version'
+type IHealthService_Version_Call struct {
+	*mock.Call
+}
+
+// Version is a helper method to define mock.On call
+func (_e *IHealthService_Expecter) Version() *IHealthService_Version_Call {
+	return &IHealthService_Version_Call{Call: _e.mock.On("Version")}
+}
+
+func (_c *IHealthService_Version_Call) Run(run func()) *IHealthService_Version_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *IHealthService_Version_Call) Return(buildInfo models.BuildInfo) *IHealthService_Version_Call {
+	_c.Call.Return(buildInfo)
+	return _c
+}
+
+func (_c *IHealthService_Version_Call) RunAndReturn(run func() models.BuildInfo) *IHealthService_Version_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIKeyring creates a new instance of IKeyring. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIKeyring(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IKeyring {
+	mock := &IKeyring{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// IKeyring is an autogenerated mock type for the IKeyring type
+type IKeyring struct {
+	mock.Mock
+}
+
+type IKeyring_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IKeyring) EXPECT() *IKeyring_Expecter {
+	return &IKeyring_Expecter{mock: &_m.Mock}
+}
+
+// JWKS provides a mock function for the type IKeyring
+func (_mock *IKeyring) JWKS() viewmodels.JwksVM {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for JWKS")
+	}
+
+	var r0 viewmodels.JwksVM
+	if returnFunc, ok := ret.Get(0).(func() viewmodels.JwksVM); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(viewmodels.JwksVM)
+	}
+	return r0
+}
+
+// IKeyring_JWKS_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'JWKS'
+type IKeyring_JWKS_Call struct {
+	*mock.Call
+}
+
+// JWKS is a helper method to define mock.On call
+func (_e *IKeyring_Expecter) JWKS() *IKeyring_JWKS_Call {
+	return &IKeyring_JWKS_Call{Call: _e.mock.On("JWKS")}
+}
+
+func (_c *IKeyring_JWKS_Call) Run(run func()) *IKeyring_JWKS_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *IKeyring_JWKS_Call) Return(jwksVM viewmodels.JwksVM) *IKeyring_JWKS_Call {
+	_c.Call.Return(jwksVM)
+	return _c
+}
+
+func (_c *IKeyring_JWKS_Call) RunAndReturn(run func() viewmodels.JwksVM) *IKeyring_JWKS_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Len provides a mock function for the type IKeyring
+func (_mock *IKeyring) Len() int {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Len")
+	}
+
+	var r0 int
+	if returnFunc, ok := ret.Get(0).(func() int); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	return r0
+}
+
+// IKeyring_Len_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Len'
+type IKeyring_Len_Call struct {
+	*mock.Call
+}
+
+// Len is a helper method to define mock.On call
+func (_e *IKeyring_Expecter) Len() *IKeyring_Len_Call {
+	return &IKeyring_Len_Call{Call: _e.mock.On("Len")}
+}
+
+func (_c *IKeyring_Len_Call) Run(run func()) *IKeyring_Len_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *IKeyring_Len_Call) Return(v int) *IKeyring_Len_Call {
+	_c.Call.Return(v)
+	return _c
+}
+
+func (_c *IKeyring_Len_Call) RunAndReturn(run func() int) *IKeyring_Len_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Methods provides a mock function for the type IKeyring
+func (_mock *IKeyring) Methods() []string {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Methods")
+	}
+
+	var r0 []string
+	if returnFunc, ok := ret.Get(0).(func() []string); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+	return r0
+}
+
+// IKeyring_Methods_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Methods'
+type IKeyring_Methods_Call struct {
+	*mock.Call
+}
+
+// Methods is a helper method to define mock.On call
+func (_e *IKeyring_Expecter) Methods() *IKeyring_Methods_Call {
+	return &IKeyring_Methods_Call{Call: _e.mock.On("Methods")}
+}
+
+func (_c *IKeyring_Methods_Call) Run(run func()) *IKeyring_Methods_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *IKeyring_Methods_Call) Return(strings []string) *IKeyring_Methods_Call {
+	_c.Call.Return(strings)
+	return _c
+}
+
+func (_c *IKeyring_Methods_Call) RunAndReturn(run func() []string) *IKeyring_Methods_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SigningKey provides a mock function for the type IKeyring
+func (_mock *IKeyring) SigningKey() (*jwtkeys.Key, error) {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for SigningKey")
+	}
+
+	var r0 *jwtkeys.Key
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func() (*jwtkeys.Key, error)); ok {
+		return returnFunc()
+	}
+	if returnFunc, ok := ret.Get(0).(func() *jwtkeys.Key); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*jwtkeys.Key)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func() error); ok {
+		r1 = returnFunc()
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IKeyring_SigningKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SigningKey'
+type IKeyring_SigningKey_Call struct {
+	*mock.Call
+}
+
+// SigningKey is a helper method to define mock.On call
+func (_e *IKeyring_Expecter) SigningKey() *IKeyring_SigningKey_Call {
+	return &IKeyring_SigningKey_Call{Call: _e.mock.On("SigningKey")}
+}
+
+func (_c *IKeyring_SigningKey_Call) Run(run func()) *IKeyring_SigningKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *IKeyring_SigningKey_Call) Return(key *jwtkeys.Key, err error) *IKeyring_SigningKey_Call {
+	_c.Call.Return(key, err)
+	return _c
+}
+
+func (_c *IKeyring_SigningKey_Call) RunAndReturn(run func() (*jwtkeys.Key, error)) *IKeyring_SigningKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// VerificationKey provides a mock function for the type IKeyring
+func (_mock *IKeyring) VerificationKey(kid string) (*jwtkeys.Key, error) {
+	ret := _mock.Called(kid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerificationKey")
+	}
+
+	var r0 *jwtkeys.Key
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(string) (*jwtkeys.Key, error)); ok {
+		return returnFunc(kid)
+	}
+	if returnFunc, ok := ret.Get(0).(func(string) *jwtkeys.Key); ok {
+		r0 = returnFunc(kid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*jwtkeys.Key)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(string) error); ok {
+		r1 = returnFunc(kid)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IKeyring_VerificationKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerificationKey'
+type IKeyring_VerificationKey_Call struct {
+	*mock.Call
+}
+
+// VerificationKey is a helper method to define mock.On call
+//   - kid
+func (_e *IKeyring_Expecter) VerificationKey(kid interface{}) *IKeyring_VerificationKey_Call {
+	return &IKeyring_VerificationKey_Call{Call: _e.mock.On("VerificationKey", kid)}
+}
+
+func (_c *IKeyring_VerificationKey_Call) Run(run func(kid string)) *IKeyring_VerificationKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *IKeyring_VerificationKey_Call) Return(key *jwtkeys.Key, err error) *IKeyring_VerificationKey_Call {
+	_c.Call.Return(key, err)
+	return _c
+}
+
+func (_c *IKeyring_VerificationKey_Call) RunAndReturn(run func(kid string) (*jwtkeys.Key, error)) *IKeyring_VerificationKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewILoginRules creates a new instance of ILoginRules. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewILoginRules(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ILoginRules {
+	mock := &ILoginRules{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// ILoginRules is an autogenerated mock type for the ILoginRules type
+type ILoginRules struct {
+	mock.Mock
+}
+
+type ILoginRules_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *ILoginRules) EXPECT() *ILoginRules_Expecter {
+	return &ILoginRules_Expecter{mock: &_m.Mock}
+}
+
+// Delay provides a mock function for the type ILoginRules
+func (_mock *ILoginRules) Delay(failures uint) time.Duration {
+	ret := _mock.Called(failures)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delay")
+	}
+
+	var r0 time.Duration
+	if returnFunc, ok := ret.Get(0).(func(uint) time.Duration); ok {
+		r0 = returnFunc(failures)
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+	return r0
+}
+
+// ILoginRules_Delay_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delay'
+type ILoginRules_Delay_Call struct {
+	*mock.Call
+}
+
+// Delay is a helper method to define mock.On call
+//   - failures
+func (_e *ILoginRules_Expecter) Delay(failures interface{}) *ILoginRules_Delay_Call {
+	return &ILoginRules_Delay_Call{Call: _e.mock.On("Delay", failures)}
+}
+
+func (_c *ILoginRules_Delay_Call) Run(run func(failures uint)) *ILoginRules_Delay_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uint))
+	})
+	return _c
+}
+
+func (_c *ILoginRules_Delay_Call) Return(v time.Duration) *ILoginRules_Delay_Call {
+	_c.Call.Return(v)
+	return _c
+}
+
+func (_c *ILoginRules_Delay_Call) RunAndReturn(run func(failures uint) time.Duration) *ILoginRules_Delay_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Expired provides a mock function for the type ILoginRules
+func (_mock *ILoginRules) Expired(sinceLastFailure time.Duration) bool {
+	ret := _mock.Called(sinceLastFailure)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Expired")
+	}
+
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func(time.Duration) bool); ok {
+		r0 = returnFunc(sinceLastFailure)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	return r0
+}
+
+// ILoginRules_Expired_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Expired'
+type ILoginRules_Expired_Call struct {
+	*mock.Call
+}
+
+// Expired is a helper method to define mock.On call
+//   - sinceLastFailure
+func (_e *ILoginRules_Expecter) Expired(sinceLastFailure interface{}) *ILoginRules_Expired_Call {
+	return &ILoginRules_Expired_Call{Call: _e.mock.On("Expired", sinceLastFailure)}
+}
+
+func (_c *ILoginRules_Expired_Call) Run(run func(sinceLastFailure time.Duration)) *ILoginRules_Expired_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *ILoginRules_Expired_Call) Return(b bool) *ILoginRules_Expired_Call {
+	_c.Call.Return(b)
+	return _c
+}
+
+func (_c *ILoginRules_Expired_Call) RunAndReturn(run func(sinceLastFailure time.Duration) bool) *ILoginRules_Expired_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewILoginThrottle creates a new instance of ILoginThrottle. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewILoginThrottle(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ILoginThrottle {
+	mock := &ILoginThrottle{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// ILoginThrottle is an autogenerated mock type for the ILoginThrottle type
+type ILoginThrottle struct {
+	mock.Mock
+}
+
+type ILoginThrottle_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *ILoginThrottle) EXPECT() *ILoginThrottle_Expecter {
+	return &ILoginThrottle_Expecter{mock: &_m.Mock}
+}
+
+// Fail provides a mock function for the type ILoginThrottle
+func (_mock *ILoginThrottle) Fail(userName string, ip string) uint {
+	ret := _mock.Called(userName, ip)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Fail")
+	}
+
+	var r0 uint
+	if returnFunc, ok := ret.Get(0).(func(string, string) uint); ok {
+		r0 = returnFunc(userName, ip)
+	} else {
+		r0 = ret.Get(0).(uint)
+	}
+	return r0
+}
+
+// ILoginThrottle_Fail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Fail'
+type ILoginThrottle_Fail_Call struct {
+	*mock.Call
+}
+
+// Fail is a helper method to define mock.On call
+//   - userName
+//   - ip
+func (_e *ILoginThrottle_Expecter) Fail(userName interface{}, ip interface{}) *ILoginThrottle_Fail_Call {
+	return &ILoginThrottle_Fail_Call{Call: _e.mock.On("Fail", userName, ip)}
+}
+
+func (_c *ILoginThrottle_Fail_Call) Run(run func(userName string, ip string)) *ILoginThrottle_Fail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *ILoginThrottle_Fail_Call) Return(v uint) *ILoginThrottle_Fail_Call {
+	_c.Call.Return(v)
+	return _c
+}
+
+func (_c *ILoginThrottle_Fail_Call) RunAndReturn(run func(userName string, ip string) uint) *ILoginThrottle_Fail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RetryAfter provides a mock function for the type ILoginThrottle
+func (_mock *ILoginThrottle) RetryAfter(userName string, ip string) time.Duration {
+	ret := _mock.Called(userName, ip)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetryAfter")
+	}
+
+	var r0 time.Duration
+	if returnFunc, ok := ret.Get(0).(func(string, string) time.Duration); ok {
+		r0 = returnFunc(userName, ip)
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+	return r0
+}
+
+// ILoginThrottle_RetryAfter_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RetryAfter'
+type ILoginThrottle_RetryAfter_Call struct {
+	*mock.Call
+}
+
+// RetryAfter is a helper method to define mock.On call
+//   - userName
+//   - ip
+func (_e *ILoginThrottle_Expecter) RetryAfter(userName interface{}, ip interface{}) *ILoginThrottle_RetryAfter_Call {
+	return &ILoginThrottle_RetryAfter_Call{Call: _e.mock.On("RetryAfter", userName, ip)}
+}
+
+func (_c *ILoginThrottle_RetryAfter_Call) Run(run func(userName string, ip string)) *ILoginThrottle_RetryAfter_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *ILoginThrottle_RetryAfter_Call) Return(v time.Duration) *ILoginThrottle_RetryAfter_Call {
+	_c.Call.Return(v)
+	return _c
+}
+
+func (_c *ILoginThrottle_RetryAfter_Call) RunAndReturn(run func(userName string, ip string) time.Duration) *ILoginThrottle_RetryAfter_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Succeed provides a mock function for the type ILoginThrottle
+func (_mock *ILoginThrottle) Succeed(userName string, ip string) {
+	_mock.Called(userName, ip)
+	return
+}
+
+// ILoginThrottle_Succeed_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Succeed'
+type ILoginThrottle_Succeed_Call struct {
+	*mock.Call
+}
+
+// Succeed is a helper method to define mock.On call
+//   - userName
+//   - ip
+func (_e *ILoginThrottle_Expecter) Succeed(userName interface{}, ip interface{}) *ILoginThrottle_Succeed_Call {
+	return &ILoginThrottle_Succeed_Call{Call: _e.mock.On("Succeed", userName, ip)}
+}
+
+func (_c *ILoginThrottle_Succeed_Call) Run(run func(userName string, ip string)) *ILoginThrottle_Succeed_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *ILoginThrottle_Succeed_Call) Return() *ILoginThrottle_Succeed_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *ILoginThrottle_Succeed_Call) RunAndReturn(run func(userName string, ip string)) *ILoginThrottle_Succeed_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIMigrator creates a new instance of IMigrator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIMigrator(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IMigrator {
+	mock := &IMigrator{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// IMigrator is an autogenerated mock type for the IMigrator type
+type IMigrator struct {
+	mock.Mock
+}
+
+type IMigrator_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IMigrator) EXPECT() *IMigrator_Expecter {
+	return &IMigrator_Expecter{mock: &_m.Mock}
+}
+
+// CheckMigrated provides a mock function for the type IMigrator
+func (_mock *IMigrator) CheckMigrated() error {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckMigrated")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func() error); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IMigrator_CheckMigrated_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CheckMigrated'
+type IMigrator_CheckMigrated_Call struct {
+	*mock.Call
+}
+
+// CheckMigrated is a helper method to define mock.On call
+func (_e *IMigrator_Expecter) CheckMigrated() *IMigrator_CheckMigrated_Call {
+	return &IMigrator_CheckMigrated_Call{Call: _e.mock.On("CheckMigrated")}
+}
+
+func (_c *IMigrator_CheckMigrated_Call) Run(run func()) *IMigrator_CheckMigrated_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *IMigrator_CheckMigrated_Call) Return(err error) *IMigrator_CheckMigrated_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IMigrator_CheckMigrated_Call) RunAndReturn(run func() error) *IMigrator_CheckMigrated_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Migrate provides a mock function for the type IMigrator
+func (_mock *IMigrator) Migrate() error {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Migrate")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func() error); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IMigrator_Migrate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Migrate'
+type IMigrator_Migrate_Call struct {
+	*mock.Call
+}
+
+// Migrate is a helper method to define mock.On call
+func (_e *IMigrator_Expecter) Migrate() *IMigrator_Migrate_Call {
+	return &IMigrator_Migrate_Call{Call: _e.mock.On("Migrate")}
+}
+
+func (_c *IMigrator_Migrate_Call) Run(run func()) *IMigrator_Migrate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *IMigrator_Migrate_Call) Return(err error) *IMigrator_Migrate_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IMigrator_Migrate_Call) RunAndReturn(run func() error) *IMigrator_Migrate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewINotifier creates a new instance of INotifier. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewINotifier(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *INotifier {
+	mock := &INotifier{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// INotifier is an autogenerated mock type for the INotifier type
+type INotifier struct {
+	mock.Mock
+}
+
+type INotifier_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *INotifier) EXPECT() *INotifier_Expecter {
+	return &INotifier_Expecter{mock: &_m.Mock}
+}
+
+// Notify provides a mock function for the type INotifier
+func (_mock *INotifier) Notify(notification models.Notification) {
+	_mock.Called(notification)
+	return
+}
+
+// INotifier_Notify_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Notify'
+type INotifier_Notify_Call struct {
+	*mock.Call
+}
+
+// Notify is a helper method to define mock.On call
+//   - notification
+func (_e *INotifier_Expecter) Notify(notification interface{}) *INotifier_Notify_Call {
+	return &INotifier_Notify_Call{Call: _e.mock.On("Notify", notification)}
+}
+
+func (_c *INotifier_Notify_Call) Run(run func(notification models.Notification)) *INotifier_Notify_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(models.Notification))
+	})
+	return _c
+}
+
+func (_c *INotifier_Notify_Call) Return() *INotifier_Notify_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *INotifier_Notify_Call) RunAndReturn(run func(notification models.Notification)) *INotifier_Notify_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIOIDCService creates a new instance of IOIDCService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIOIDCService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IOIDCService {
+	mock := &IOIDCService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// IOIDCService is an autogenerated mock type for the IOIDCService type
+type IOIDCService struct {
+	mock.Mock
+}
+
+type IOIDCService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IOIDCService) EXPECT() *IOIDCService_Expecter {
+	return &IOIDCService_Expecter{mock: &_m.Mock}
+}
+
+// AuthCodeURL provides a mock function for the type IOIDCService
+func (_mock *IOIDCService) AuthCodeURL(ctx context.Context) (string, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AuthCodeURL")
+	}
+
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (string, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) string); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IOIDCService_AuthCodeURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AuthCodeURL'
+type IOIDCService_AuthCodeURL_Call struct {
+	*mock.Call
+}
+
+// AuthCodeURL is a helper method to define mock.On call
+//   - ctx
+func (_e *IOIDCService_Expecter) AuthCodeURL(ctx interface{}) *IOIDCService_AuthCodeURL_Call {
+	return &IOIDCService_AuthCodeURL_Call{Call: _e.mock.On("AuthCodeURL", ctx)}
+}
+
+func (_c *IOIDCService_AuthCodeURL_Call) Run(run func(ctx context.Context)) *IOIDCService_AuthCodeURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *IOIDCService_AuthCodeURL_Call) Return(s string, err error) *IOIDCService_AuthCodeURL_Call {
+	_c.Call.Return(s, err)
+	return _c
+}
+
+func (_c *IOIDCService_AuthCodeURL_Call) RunAndReturn(run func(ctx context.Context) (string, error)) *IOIDCService_AuthCodeURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Exchange provides a mock function for the type IOIDCService
+func (_mock *IOIDCService) Exchange(ctx context.Context, state string, code string) (string, error) {
+	ret := _mock.Called(ctx, state, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Exchange")
+	}
+
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (string, error)); ok {
+		return returnFunc(ctx, state, code)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
+		r0 = returnFunc(ctx, state, code)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(ctx, state, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IOIDCService_Exchange_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Exchange'
+type IOIDCService_Exchange_Call struct {
+	*mock.Call
+}
+
+// Exchange is a helper method to define mock.On call
+//   - ctx
+//   - state
+//   - code
+func (_e *IOIDCService_Expecter) Exchange(ctx interface{}, state interface{}, code interface{}) *IOIDCService_Exchange_Call {
+	return &IOIDCService_Exchange_Call{Call: _e.mock.On("Exchange", ctx, state, code)}
+}
+
+func (_c *IOIDCService_Exchange_Call) Run(run func(ctx context.Context, state string, code string)) *IOIDCService_Exchange_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *IOIDCService_Exchange_Call) Return(s string, err error) *IOIDCService_Exchange_Call {
+	_c.Call.Return(s, err)
+	return _c
+}
+
+func (_c *IOIDCService_Exchange_Call) RunAndReturn(run func(ctx context.Context, state string, code string) (string, error)) *IOIDCService_Exchange_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIReplicator creates a new instance of IReplicator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIReplicator(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IReplicator {
+	mock := &IReplicator{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// IReplicator is an autogenerated mock type for the IReplicator type
+type IReplicator struct {
+	mock.Mock
+}
+
+type IReplicator_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IReplicator) EXPECT() *IReplicator_Expecter {
+	return &IReplicator_Expecter{mock: &_m.Mock}
+}
+
+// Reconstruct provides a mock function for the type IReplicator
+func (_mock *IReplicator) Reconstruct(ctx context.Context, at time.Time, dest string) (time.Time, error) {
+	ret := _mock.Called(ctx, at, dest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reconstruct")
+	}
+
+	var r0 time.Time
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time, string) (time.Time, error)); ok {
+		return returnFunc(ctx, at, dest)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time, string) time.Time); ok {
+		r0 = returnFunc(ctx, at, dest)
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, time.Time, string) error); ok {
+		r1 = returnFunc(ctx, at, dest)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IReplicator_Reconstruct_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Reconstruct'
+type IReplicator_Reconstruct_Call struct {
+	*mock.Call
+}
+
+// Reconstruct is a helper method to define mock.On call
+//   - ctx
+//   - at
+//   - dest
+func (_e *IReplicator_Expecter) Reconstruct(ctx interface{}, at interface{}, dest interface{}) *IReplicator_Reconstruct_Call {
+	return &IReplicator_Reconstruct_Call{Call: _e.mock.On("Reconstruct", ctx, at, dest)}
+}
+
+func (_c *IReplicator_Reconstruct_Call) Run(run func(ctx context.Context, at time.Time, dest string)) *IReplicator_Reconstruct_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *IReplicator_Reconstruct_Call) Return(time1 time.Time, err error) *IReplicator_Reconstruct_Call {
+	_c.Call.Return(time1, err)
+	return _c
+}
+
+func (_c *IReplicator_Reconstruct_Call) RunAndReturn(run func(ctx context.Context, at time.Time, dest string) (time.Time, error)) *IReplicator_Reconstruct_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIRevokedTokenRepository creates a new instance of IRevokedTokenRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIRevokedTokenRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IRevokedTokenRepository {
+	mock := &IRevokedTokenRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// IRevokedTokenRepository is an autogenerated mock type for the IRevokedTokenRepository type
+type IRevokedTokenRepository struct {
+	mock.Mock
+}
+
+type IRevokedTokenRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IRevokedTokenRepository) EXPECT() *IRevokedTokenRepository_Expecter {
+	return &IRevokedTokenRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function for the type IRevokedTokenRepository
+func (_mock *IRevokedTokenRepository) Create(ctx context.Context, jti string, expiresAt time.Time) error {
+	ret := _mock.Called(ctx, jti, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = returnFunc(ctx, jti, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IRevokedTokenRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type IRevokedTokenRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx
+//   - jti
+//   - expiresAt
+func (_e *IRevokedTokenRepository_Expecter) Create(ctx interface{}, jti interface{}, expiresAt interface{}) *IRevokedTokenRepository_Create_Call {
+	return &IRevokedTokenRepository_Create_Call{Call: _e.mock.On("Create", ctx, jti, expiresAt)}
+}
+
+func (_c *IRevokedTokenRepository_Create_Call) Run(run func(ctx context.Context, jti string, expiresAt time.Time)) *IRevokedTokenRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *IRevokedTokenRepository_Create_Call) Return(err error) *IRevokedTokenRepository_Create_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IRevokedTokenRepository_Create_Call) RunAndReturn(run func(ctx context.Context, jti string, expiresAt time.Time) error) *IRevokedTokenRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteExpired provides a mock function for the type IRevokedTokenRepository
+func (_mock *IRevokedTokenRepository) DeleteExpired(now time.Time) (int64, error) {
+	ret := _mock.Called(now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpired")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(time.Time) (int64, error)); ok {
+		return returnFunc(now)
+	}
+	if returnFunc, ok := ret.Get(0).(func(time.Time) int64); ok {
+		r0 = returnFunc(now)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = returnFunc(now)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IRevokedTokenRepository_DeleteExpired_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteExpired'
+type IRevokedTokenRepository_DeleteExpired_Call struct {
+	*mock.Call
+}
+
+// DeleteExpired is a helper method to define mock.On call
+//   - now
+func (_e *IRevokedTokenRepository_Expecter) DeleteExpired(now interface{}) *IRevokedTokenRepository_DeleteExpired_Call {
+	return &IRevokedTokenRepository_DeleteExpired_Call{Call: _e.mock.On("DeleteExpired", now)}
+}
+
+func (_c *IRevokedTokenRepository_DeleteExpired_Call) Run(run func(now time.Time)) *IRevokedTokenRepository_DeleteExpired_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Time))
+	})
+	return _c
+}
+
+func (_c *IRevokedTokenRepository_DeleteExpired_Call) Return(v int64, err error) *IRevokedTokenRepository_DeleteExpired_Call {
+	_c.Call.Return(v, err)
+	return _c
+}
+
+func (_c *IRevokedTokenRepository_DeleteExpired_Call) RunAndReturn(run func(now time.Time) (int64, error)) *IRevokedTokenRepository_DeleteExpired_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListActive provides a mock function for the type IRevokedTokenRepository
+func (_mock *IRevokedTokenRepository) ListActive(now time.Time) ([]models.RevokedToken, error) {
+	ret := _mock.Called(now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListActive")
+	}
+
+	var r0 []models.RevokedToken
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(time.Time) ([]models.RevokedToken, error)); ok {
+		return returnFunc(now)
+	}
+	if returnFunc, ok := ret.Get(0).(func(time.Time) []models.RevokedToken); ok {
+		r0 = returnFunc(now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.RevokedToken)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = returnFunc(now)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IRevokedTokenRepository_ListActive_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListActive'
+type IRevokedTokenRepository_ListActive_Call struct {
+	*mock.Call
+}
+
+// ListActive is a helper method to define mock.On call
+//   - now
+func (_e *IRevokedTokenRepository_Expecter) ListActive(now interface{}) *IRevokedTokenRepository_ListActive_Call {
+	return &IRevokedTokenRepository_ListActive_Call{Call: _e.mock.On("ListActive", now)}
+}
+
+func (_c *IRevokedTokenRepository_ListActive_Call) Run(run func(now time.Time)) *IRevokedTokenRepository_ListActive_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Time))
+	})
+	return _c
+}
+
+func (_c *IRevokedTokenRepository_ListActive_Call) Return(revokedTokens []models.RevokedToken, err error) *IRevokedTokenRepository_ListActive_Call {
+	_c.Call.Return(revokedTokens, err)
+	return _c
+}
+
+func (_c *IRevokedTokenRepository_ListActive_Call) RunAndReturn(run func(now time.Time) ([]models.RevokedToken, error)) *IRevokedTokenRepository_ListActive_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Migrate provides a mock function for the type IRevokedTokenRepository
+func (_mock *IRevokedTokenRepository) Migrate() error {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Migrate")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func() error); ok {
+		r0 = returnFunc()
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IRevokedTokenRepository_Migrate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Migrate'
+type IRevokedTokenRepository_Migrate_Call struct {
+	*mock.Call
+}
+
+// Migrate is a helper method to define mock.On call
+func (_e *IRevokedTokenRepository_Expecter) Migrate() *IRevokedTokenRepository_Migrate_Call {
+	return &IRevokedTokenRepository_Migrate_Call{Call: _e.mock.On("Migrate")}
+}
+
+func (_c *IRevokedTokenRepository_Migrate_Call) Run(run func()) *IRevokedTokenRepository_Migrate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *IRevokedTokenRepository_Migrate_Call) Return(err error) *IRevokedTokenRepository_Migrate_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IRevokedTokenRepository_Migrate_Call) RunAndReturn(run func() error) *IRevokedTokenRepository_Migrate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIScheduler creates a new instance of IScheduler. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIScheduler(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IScheduler {
+	mock := &IScheduler{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// IScheduler is an autogenerated mock type for the IScheduler type
+type IScheduler struct {
+	mock.Mock
+}
+
+type IScheduler_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IScheduler) EXPECT() *IScheduler_Expecter {
+	return &IScheduler_Expecter{mock: &_m.Mock}
+}
+
+// Jobs provides a mock function for the type IScheduler
+func (_mock *IScheduler) Jobs() []models.ScheduledJob {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Jobs")
+	}
+
+	var r0 []models.ScheduledJob
+	if returnFunc, ok := ret.Get(0).(func() []models.ScheduledJob); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.ScheduledJob)
+		}
+	}
+	return r0
+}
+
+// IScheduler_Jobs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Jobs'
+type IScheduler_Jobs_Call struct {
+	*mock.Call
+}
+
+// Jobs is a helper method to define mock.On call
+func (_e *IScheduler_Expecter) Jobs() *IScheduler_Jobs_Call {
+	return &IScheduler_Jobs_Call{Call: _e.mock.On("Jobs")}
+}
+
+func (_c *IScheduler_Jobs_Call) Run(run func()) *IScheduler_Jobs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *IScheduler_Jobs_Call) Return(scheduledJobs []models.ScheduledJob) *IScheduler_Jobs_Call {
+	_c.Call.Return(scheduledJobs)
+	return _c
+}
+
+func (_c *IScheduler_Jobs_Call) RunAndReturn(run func() []models.ScheduledJob) *IScheduler_Jobs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIStorageClient creates a new instance of IStorageClient. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIStorageClient(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IStorageClient {
+	mock := &IStorageClient{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// IStorageClient is an autogenerated mock type for the IStorageClient type
+type IStorageClient struct {
+	mock.Mock
+}
+
+type IStorageClient_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IStorageClient) EXPECT() *IStorageClient_Expecter {
+	return &IStorageClient_Expecter{mock: &_m.Mock}
+}
+
+// DeleteFile provides a mock function for the type IStorageClient
+func (_mock *IStorageClient) DeleteFile(ctx context.Context, object string) error {
+	ret := _mock.Called(ctx, object)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteFile")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, object)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IStorageClient_DeleteFile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteFile'
+type IStorageClient_DeleteFile_Call struct {
+	*mock.Call
+}
+
+// DeleteFile is a helper method to define mock.On call
+//   - ctx
+//   - object
+func (_e *IStorageClient_Expecter) DeleteFile(ctx interface{}, object interface{}) *IStorageClient_DeleteFile_Call {
+	return &IStorageClient_DeleteFile_Call{Call: _e.mock.On("DeleteFile", ctx, object)}
+}
+
+func (_c *IStorageClient_DeleteFile_Call) Run(run func(ctx context.Context, object string)) *IStorageClient_DeleteFile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *IStorageClient_DeleteFile_Call) Return(err error) *IStorageClient_DeleteFile_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IStorageClient_DeleteFile_Call) RunAndReturn(run func(ctx context.Context, object string) error) *IStorageClient_DeleteFile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DownloadFile provides a mock function for the type IStorageClient
+func (_mock *IStorageClient) DownloadFile(ctx context.Context, object string, destPath string) error {
+	ret := _mock.Called(ctx, object, destPath)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DownloadFile")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, object, destPath)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IStorageClient_DownloadFile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DownloadFile'
+type IStorageClient_DownloadFile_Call struct {
+	*mock.Call
+}
+
+// DownloadFile is a helper method to define mock.On call
+//   - ctx
+//   - object
+//   - destPath
+func (_e *IStorageClient_Expecter) DownloadFile(ctx interface{}, object interface{}, destPath interface{}) *IStorageClient_DownloadFile_Call {
+	return &IStorageClient_DownloadFile_Call{Call: _e.mock.On("DownloadFile", ctx, object, destPath)}
+}
+
+func (_c *IStorageClient_DownloadFile_Call) Run(run func(ctx context.Context, object string, destPath string)) *IStorageClient_DownloadFile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *IStorageClient_DownloadFile_Call) Return(err error) *IStorageClient_DownloadFile_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IStorageClient_DownloadFile_Call) RunAndReturn(run func(ctx context.Context, object string, destPath string) error) *IStorageClient_DownloadFile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListFiles provides a mock function for the type IStorageClient
+func (_mock *IStorageClient) ListFiles(ctx context.Context) ([]models.BackupFile, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListFiles")
+	}
+
+	var r0 []models.BackupFile
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]models.BackupFile, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []models.BackupFile); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.BackupFile)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IStorageClient_ListFiles_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListFiles'
+type IStorageClient_ListFiles_Call struct {
+	*mock.Call
+}
+
+// ListFiles is a helper method to define mock.On call
+//   - ctx
+func (_e *IStorageClient_Expecter) ListFiles(ctx interface{}) *IStorageClient_ListFiles_Call {
+	return &IStorageClient_ListFiles_Call{Call: _e.mock.On("ListFiles", ctx)}
+}
+
+func (_c *IStorageClient_ListFiles_Call) Run(run func(ctx context.Context)) *IStorageClient_ListFiles_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *IStorageClient_ListFiles_Call) Return(backupFiles []models.BackupFile, err error) *IStorageClient_ListFiles_Call {
+	_c.Call.Return(backupFiles, err)
+	return _c
+}
+
+func (_c *IStorageClient_ListFiles_Call) RunAndReturn(run func(ctx context.Context) ([]models.BackupFile, error)) *IStorageClient_ListFiles_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Ping provides a mock function for the type IStorageClient
+func (_mock *IStorageClient) Ping(ctx context.Context) error {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Ping")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IStorageClient_Ping_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Ping'
+type IStorageClient_Ping_Call struct {
+	*mock.Call
+}
+
+// Ping is a helper method to define mock.On call
+//   - ctx
+func (_e *IStorageClient_Expecter) Ping(ctx interface{}) *IStorageClient_Ping_Call {
+	return &IStorageClient_Ping_Call{Call: _e.mock.On("Ping", ctx)}
+}
+
+func (_c *IStorageClient_Ping_Call) Run(run func(ctx context.Context)) *IStorageClient_Ping_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *IStorageClient_Ping_Call) Return(err error) *IStorageClient_Ping_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IStorageClient_Ping_Call) RunAndReturn(run func(ctx context.Context) error) *IStorageClient_Ping_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UploadFile provides a mock function for the type IStorageClient
+func (_mock *IStorageClient) UploadFile(ctx context.Context, path string, destObject string) error {
+	ret := _mock.Called(ctx, path, destObject)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UploadFile")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, path, destObject)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// IStorageClient_UploadFile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UploadFile'
+type IStorageClient_UploadFile_Call struct {
+	*mock.Call
+}
+
+// UploadFile is a helper method to define mock.On call
+//   - ctx
+//   - path
+//   - destObject
+func (_e *IStorageClient_Expecter) UploadFile(ctx interface{}, path interface{}, destObject interface{}) *IStorageClient_UploadFile_Call {
+	return &IStorageClient_UploadFile_Call{Call: _e.mock.On("UploadFile", ctx, path, destObject)}
+}
+
+func (_c *IStorageClient_UploadFile_Call) Run(run func(ctx context.Context, path string, destObject string)) *IStorageClient_UploadFile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *IStorageClient_UploadFile_Call) Return(err error) *IStorageClient_UploadFile_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *IStorageClient_UploadFile_Call) RunAndReturn(run func(ctx context.Context, path string, destObject string) error) *IStorageClient_UploadFile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewITokenDenylist creates a new instance of ITokenDenylist. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewITokenDenylist(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ITokenDenylist {
+	mock := &ITokenDenylist{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// ITokenDenylist is an autogenerated mock type for the ITokenDenylist type
+type ITokenDenylist struct {
+	mock.Mock
+}
+
+type ITokenDenylist_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *ITokenDenylist) EXPECT() *ITokenDenylist_Expecter {
+	return &ITokenDenylist_Expecter{mock: &_m.Mock}
+}
+
+// IsRevoked provides a mock function for the type ITokenDenylist
+func (_mock *ITokenDenylist) IsRevoked(jti string) bool {
+	ret := _mock.Called(jti)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsRevoked")
+	}
+
+	var r0 bool
+	if returnFunc, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = returnFunc(jti)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	return r0
+}
+
+// ITokenDenylist_IsRevoked_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsRevoked'
+type ITokenDenylist_IsRevoked_Call struct {
+	*mock.Call
+}
+
+// IsRevoked is a helper method to define mock.On call
+//   - jti
+func (_e *ITokenDenylist_Expecter) IsRevoked(jti interface{}) *ITokenDenylist_IsRevoked_Call {
+	return &ITokenDenylist_IsRevoked_Call{Call: _e.mock.On("IsRevoked", jti)}
+}
+
+func (_c *ITokenDenylist_IsRevoked_Call) Run(run func(jti string)) *ITokenDenylist_IsRevoked_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *ITokenDenylist_IsRevoked_Call) Return(b bool) *ITokenDenylist_IsRevoked_Call {
+	_c.Call.Return(b)
+	return _c
+}
+
+func (_c *ITokenDenylist_IsRevoked_Call) RunAndReturn(run func(jti string) bool) *ITokenDenylist_IsRevoked_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Revoke provides a mock function for the type ITokenDenylist
+func (_mock *ITokenDenylist) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	ret := _mock.Called(ctx, jti, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revoke")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = returnFunc(ctx, jti, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// ITokenDenylist_Revoke_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Revoke'
+type ITokenDenylist_Revoke_Call struct {
+	*mock.Call
+}
+
+// Revoke is a helper method to define mock.On call
+//   - ctx
+//   - jti
+//   - expiresAt
+func (_e *ITokenDenylist_Expecter) Revoke(ctx interface{}, jti interface{}, expiresAt interface{}) *ITokenDenylist_Revoke_Call {
+	return &ITokenDenylist_Revoke_Call{Call: _e.mock.On("Revoke", ctx, jti, expiresAt)}
+}
+
+func (_c *ITokenDenylist_Revoke_Call) Run(run func(ctx context.Context, jti string, expiresAt time.Time)) *ITokenDenylist_Revoke_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *ITokenDenylist_Revoke_Call) Return(err error) *ITokenDenylist_Revoke_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *ITokenDenylist_Revoke_Call) RunAndReturn(run func(ctx context.Context, jti string, expiresAt time.Time) error) *ITokenDenylist_Revoke_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIUserRepository creates a new instance of IUserRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIUserRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IUserRepository {
+	mock := &IUserRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// IUserRepository is an autogenerated mock type for the IUserRepository type
+type IUserRepository struct {
+	mock.Mock
+}
+
+type IUserRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IUserRepository) EXPECT() *IUserRepository_Expecter {
+	return &IUserRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function for the type IUserRepository
+func (_mock *IUserRepository) Create(ctx context.Context, name string, password string) (*models.User, error) {
+	ret := _mock.Called(ctx, name, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (*models.User, error)); ok {
+		return returnFunc(ctx, name, password)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) *models.User); ok {
+		r0 = returnFunc(ctx, name, password)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(ctx, name, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// IUserRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type IUserRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx
+//   - name
+//   - password
+func (_e *IUserRepository_Expecter) Create(ctx interface{}, name interface{}, password interface{}) *IUserRepository_Create_Call {
+	return &IUserRepository_Create_Call{Call: _e.mock.On("Create", ctx, name, password)}
+}
+
+func (_c *IUserRepository_Create_Call) Run(run func(ctx context.Context, name string, password string)) *IUserRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *IUserRepository_Create_Call) Return(user *models.User, err error) *IUserRepository_Create_Call {
+	_c.Call.Return(user, err)
+	return _c
+}
+
+func (_c *IUserRepository_Create_Call) RunAndReturn(run func(ctx context.Context, name string, password string) (*models.User, error)) *IUserRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateWithIdentity provides a mock function for the type IUserRepository
+func (_mock *IUserRepository) CreateWithIdentity(ctx context.Context, name string, password string, role string, issuer string, subject string) (*models.User, error) {
+	ret := _mock.Called(ctx, name, password, role, issuer, subject)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateWithIdentity")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, string, string, string) (*models.User, error)); ok {
+		return returnFunc(ctx, name, password, role, issuer, subject)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, string, string, string) *models.User); ok {
+		r0 = returnFunc(ctx, name, password, role, issuer, subject)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, string, string, string) error); ok {
+		r1 = returnFunc(ctx, name, password, role, issuer, subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
 }
 
-// IDbHandler_Connection_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Connection'
-type IDbHandler_Connection_Call struct {
+// IUserRepository_CreateWithIdentity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateWithIdentity'
+type IUserRepository_CreateWithIdentity_Call struct {
 	*mock.Call
 }
 
-// Connection is a helper method to define mock.On call
-func (_e *IDbHandler_Expecter) Connection() *IDbHandler_Connection_Call {
-	return &IDbHandler_Connection_Call{Call: _e.mock.On("Connection")}
+// CreateWithIdentity is a helper method to define mock.On call
+//   - ctx
+//   - name
+//   - password
+//   - role
+//   - issuer
+//   - subject
+func (_e *IUserRepository_Expecter) CreateWithIdentity(ctx interface{}, name interface{}, password interface{}, role interface{}, issuer interface{}, subject interface{}) *IUserRepository_CreateWithIdentity_Call {
+	return &IUserRepository_CreateWithIdentity_Call{Call: _e.mock.On("CreateWithIdentity", ctx, name, password, role, issuer, subject)}
 }
 
-func (_c *IDbHandler_Connection_Call) Run(run func()) *IDbHandler_Connection_Call {
+func (_c *IUserRepository_CreateWithIdentity_Call) Run(run func(ctx context.Context, name string, password string, role string, issuer string, subject string)) *IUserRepository_CreateWithIdentity_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string), args[4].(string), args[5].(string))
 	})
 	return _c
 }
 
-func (_c *IDbHandler_Connection_Call) Return(dB *gorm.DB) *IDbHandler_Connection_Call {
-	_c.Call.Return(dB)
+func (_c *IUserRepository_CreateWithIdentity_Call) Return(user *models.User, err error) *IUserRepository_CreateWithIdentity_Call {
+	_c.Call.Return(user, err)
 	return _c
 }
 
-func (_c *IDbHandler_Connection_Call) RunAndReturn(run func() *gorm.DB) *IDbHandler_Connection_Call {
+func (_c *IUserRepository_CreateWithIdentity_Call) RunAndReturn(run func(ctx context.Context, name string, password string, role string, issuer string, subject string) (*models.User, error)) *IUserRepository_CreateWithIdentity_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// NewIFileUtil creates a new instance of IFileUtil. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
-// The first argument is typically a *testing.T value.
-func NewIFileUtil(t interface {
-	mock.TestingT
-	Cleanup(func())
-}) *IFileUtil {
-	mock := &IFileUtil{}
-	mock.Mock.Test(t)
-
-	t.Cleanup(func() { mock.AssertExpectations(t) })
-
-	return mock
-}
-
-// IFileUtil is an autogenerated mock type for the IFileUtil type
-type IFileUtil struct {
-	mock.Mock
-}
-
-type IFileUtil_Expecter struct {
-	mock *mock.Mock
-}
-
-func (_m *IFileUtil) EXPECT() *IFileUtil_Expecter {
-	return &IFileUtil_Expecter{mock: &_m.Mock}
-}
-
-// DeleteIfExists provides a mock function for the type IFileUtil
-func (_mock *IFileUtil) DeleteIfExists(file string) error {
-	ret := _mock.Called(file)
+// Get provides a mock function for the type IUserRepository
+func (_mock *IUserRepository) Get(ctx context.Context, name string) (*models.User, error) {
+	ret := _mock.Called(ctx, name)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteIfExists")
+		panic("no return value specified for Get")
 	}
 
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
-		r0 = returnFunc(file)
+	var r0 *models.User
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*models.User, error)); ok {
+		return returnFunc(ctx, name)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *models.User); ok {
+		r0 = returnFunc(ctx, name)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
 	}
-	return r0
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
 }
 
-// IFileUtil_DeleteIfExists_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteIfExists'
-type IFileUtil_DeleteIfExists_Call struct {
+// IUserRepository_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type IUserRepository_Get_Call struct {
 	*mock.Call
 }
 
-// DeleteIfExists is a helper method to define mock.On call
-//   - file
-func (_e *IFileUtil_Expecter) DeleteIfExists(file interface{}) *IFileUtil_DeleteIfExists_Call {
-	return &IFileUtil_DeleteIfExists_Call{Call: _e.mock.On("DeleteIfExists", file)}
+// Get is a helper method to define mock.On call
+//   - ctx
+//   - name
+func (_e *IUserRepository_Expecter) Get(ctx interface{}, name interface{}) *IUserRepository_Get_Call {
+	return &IUserRepository_Get_Call{Call: _e.mock.On("Get", ctx, name)}
 }
 
-func (_c *IFileUtil_DeleteIfExists_Call) Run(run func(file string)) *IFileUtil_DeleteIfExists_Call {
+func (_c *IUserRepository_Get_Call) Run(run func(ctx context.Context, name string)) *IUserRepository_Get_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *IFileUtil_DeleteIfExists_Call) Return(err error) *IFileUtil_DeleteIfExists_Call {
-	_c.Call.Return(err)
+func (_c *IUserRepository_Get_Call) Return(user *models.User, err error) *IUserRepository_Get_Call {
+	_c.Call.Return(user, err)
 	return _c
 }
 
-func (_c *IFileUtil_DeleteIfExists_Call) RunAndReturn(run func(file string) error) *IFileUtil_DeleteIfExists_Call {
+func (_c *IUserRepository_Get_Call) RunAndReturn(run func(ctx context.Context, name string) (*models.User, error)) *IUserRepository_Get_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// NewIStorageClient creates a new instance of IStorageClient. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
-// The first argument is typically a *testing.T value.
-func NewIStorageClient(t interface {
-	mock.TestingT
-	Cleanup(func())
-}) *IStorageClient {
-	mock := &IStorageClient{}
-	mock.Mock.Test(t)
+// GetByIdentity provides a mock function for the type IUserRepository
+func (_mock *IUserRepository) GetByIdentity(ctx context.Context, issuer string, subject string) (*models.User, error) {
+	ret := _mock.Called(ctx, issuer, subject)
 
-	t.Cleanup(func() { mock.AssertExpectations(t) })
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIdentity")
+	}
 
-	return mock
+	var r0 *models.User
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (*models.User, error)); ok {
+		return returnFunc(ctx, issuer, subject)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) *models.User); ok {
+		r0 = returnFunc(ctx, issuer, subject)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(ctx, issuer, subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
 }
 
-// IStorageClient is an autogenerated mock type for the IStorageClient type
-type IStorageClient struct {
-	mock.Mock
+// IUserRepository_GetByIdentity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByIdentity'
+type IUserRepository_GetByIdentity_Call struct {
+	*mock.Call
 }
 
-type IStorageClient_Expecter struct {
-	mock *mock.Mock
+// GetByIdentity is a helper method to define mock.On call
+//   - ctx
+//   - issuer
+//   - subject
+func (_e *IUserRepository_Expecter) GetByIdentity(ctx interface{}, issuer interface{}, subject interface{}) *IUserRepository_GetByIdentity_Call {
+	return &IUserRepository_GetByIdentity_Call{Call: _e.mock.On("GetByIdentity", ctx, issuer, subject)}
 }
 
-func (_m *IStorageClient) EXPECT() *IStorageClient_Expecter {
-	return &IStorageClient_Expecter{mock: &_m.Mock}
+func (_c *IUserRepository_GetByIdentity_Call) Run(run func(ctx context.Context, issuer string, subject string)) *IUserRepository_GetByIdentity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
 }
 
-// DeleteFile provides a mock function for the type IStorageClient
-func (_mock *IStorageClient) DeleteFile(object string) error {
-	ret := _mock.Called(object)
+func (_c *IUserRepository_GetByIdentity_Call) Return(user *models.User, err error) *IUserRepository_GetByIdentity_Call {
+	_c.Call.Return(user, err)
+	return _c
+}
+
+func (_c *IUserRepository_GetByIdentity_Call) RunAndReturn(run func(ctx context.Context, issuer string, subject string) (*models.User, error)) *IUserRepository_GetByIdentity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IncrementTokenVersion provides a mock function for the type IUserRepository
+func (_mock *IUserRepository) IncrementTokenVersion(ctx context.Context, name string) error {
+	ret := _mock.Called(ctx, name)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteFile")
+		panic("no return value specified for IncrementTokenVersion")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
-		r0 = returnFunc(object)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, name)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// IStorageClient_DeleteFile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteFile'
-type IStorageClient_DeleteFile_Call struct {
+// IUserRepository_IncrementTokenVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IncrementTokenVersion'
+type IUserRepository_IncrementTokenVersion_Call struct {
 	*mock.Call
 }
 
-// DeleteFile is a helper method to define mock.On call
-//   - object
-func (_e *IStorageClient_Expecter) DeleteFile(object interface{}) *IStorageClient_DeleteFile_Call {
-	return &IStorageClient_DeleteFile_Call{Call: _e.mock.On("DeleteFile", object)}
+// IncrementTokenVersion is a helper method to define mock.On call
+//   - ctx
+//   - name
+func (_e *IUserRepository_Expecter) IncrementTokenVersion(ctx interface{}, name interface{}) *IUserRepository_IncrementTokenVersion_Call {
+	return &IUserRepository_IncrementTokenVersion_Call{Call: _e.mock.On("IncrementTokenVersion", ctx, name)}
 }
 
-func (_c *IStorageClient_DeleteFile_Call) Run(run func(object string)) *IStorageClient_DeleteFile_Call {
+func (_c *IUserRepository_IncrementTokenVersion_Call) Run(run func(ctx context.Context, name string)) *IUserRepository_IncrementTokenVersion_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *IStorageClient_DeleteFile_Call) Return(err error) *IStorageClient_DeleteFile_Call {
+func (_c *IUserRepository_IncrementTokenVersion_Call) Return(err error) *IUserRepository_IncrementTokenVersion_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *IStorageClient_DeleteFile_Call) RunAndReturn(run func(object string) error) *IStorageClient_DeleteFile_Call {
+func (_c *IUserRepository_IncrementTokenVersion_Call) RunAndReturn(run func(ctx context.Context, name string) error) *IUserRepository_IncrementTokenVersion_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ListFiles provides a mock function for the type IStorageClient
-func (_mock *IStorageClient) ListFiles() ([]storageclient.BackupFile, error) {
-	ret := _mock.Called()
+// UpdatePassword provides a mock function for the type IUserRepository
+func (_mock *IUserRepository) UpdatePassword(ctx context.Context, name string, password string) error {
+	ret := _mock.Called(ctx, name, password)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ListFiles")
+		panic("no return value specified for UpdatePassword")
 	}
 
-	var r0 []storageclient.BackupFile
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func() ([]storageclient.BackupFile, error)); ok {
-		return returnFunc()
-	}
-	if returnFunc, ok := ret.Get(0).(func() []storageclient.BackupFile); ok {
-		r0 = returnFunc()
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]storageclient.BackupFile)
-		}
-	}
-	if returnFunc, ok := ret.Get(1).(func() error); ok {
-		r1 = returnFunc()
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, name, password)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// IStorageClient_ListFiles_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListFiles'
-type IStorageClient_ListFiles_Call struct {
+// IUserRepository_UpdatePassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdatePassword'
+type IUserRepository_UpdatePassword_Call struct {
 	*mock.Call
 }
 
-// ListFiles is a helper method to define mock.On call
-func (_e *IStorageClient_Expecter) ListFiles() *IStorageClient_ListFiles_Call {
-	return &IStorageClient_ListFiles_Call{Call: _e.mock.On("ListFiles")}
+// UpdatePassword is a helper method to define mock.On call
+//   - ctx
+//   - name
+//   - password
+func (_e *IUserRepository_Expecter) UpdatePassword(ctx interface{}, name interface{}, password interface{}) *IUserRepository_UpdatePassword_Call {
+	return &IUserRepository_UpdatePassword_Call{Call: _e.mock.On("UpdatePassword", ctx, name, password)}
 }
 
-func (_c *IStorageClient_ListFiles_Call) Run(run func()) *IStorageClient_ListFiles_Call {
+func (_c *IUserRepository_UpdatePassword_Call) Run(run func(ctx context.Context, name string, password string)) *IUserRepository_UpdatePassword_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
 	})
 	return _c
 }
 
-func (_c *IStorageClient_ListFiles_Call) Return(backupFiles []storageclient.BackupFile, err error) *IStorageClient_ListFiles_Call {
-	_c.Call.Return(backupFiles, err)
+func (_c *IUserRepository_UpdatePassword_Call) Return(err error) *IUserRepository_UpdatePassword_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *IStorageClient_ListFiles_Call) RunAndReturn(run func() ([]storageclient.BackupFile, error)) *IStorageClient_ListFiles_Call {
+func (_c *IUserRepository_UpdatePassword_Call) RunAndReturn(run func(ctx context.Context, name string, password string) error) *IUserRepository_UpdatePassword_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// UploadFile provides a mock function for the type IStorageClient
-func (_mock *IStorageClient) UploadFile(path string, destObject string) error {
-	ret := _mock.Called(path, destObject)
+// UpdateRole provides a mock function for the type IUserRepository
+func (_mock *IUserRepository) UpdateRole(ctx context.Context, name string, role string) error {
+	ret := _mock.Called(ctx, name, role)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UploadFile")
+		panic("no return value specified for UpdateRole")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(string, string) error); ok {
-		r0 = returnFunc(path, destObject)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, name, role)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// IStorageClient_UploadFile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UploadFile'
-type IStorageClient_UploadFile_Call struct {
+// IUserRepository_UpdateRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateRole'
+type IUserRepository_UpdateRole_Call struct {
 	*mock.Call
 }
 
-// UploadFile is a helper method to define mock.On call
-//   - path
-//   - destObject
-func (_e *IStorageClient_Expecter) UploadFile(path interface{}, destObject interface{}) *IStorageClient_UploadFile_Call {
-	return &IStorageClient_UploadFile_Call{Call: _e.mock.On("UploadFile", path, destObject)}
+// UpdateRole is a helper method to define mock.On call
+//   - ctx
+//   - name
+//   - role
+func (_e *IUserRepository_Expecter) UpdateRole(ctx interface{}, name interface{}, role interface{}) *IUserRepository_UpdateRole_Call {
+	return &IUserRepository_UpdateRole_Call{Call: _e.mock.On("UpdateRole", ctx, name, role)}
 }
 
-func (_c *IStorageClient_UploadFile_Call) Run(run func(path string, destObject string)) *IStorageClient_UploadFile_Call {
+func (_c *IUserRepository_UpdateRole_Call) Run(run func(ctx context.Context, name string, role string)) *IUserRepository_UpdateRole_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
 	})
 	return _c
 }
 
-func (_c *IStorageClient_UploadFile_Call) Return(err error) *IStorageClient_UploadFile_Call {
+func (_c *IUserRepository_UpdateRole_Call) Return(err error) *IUserRepository_UpdateRole_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *IStorageClient_UploadFile_Call) RunAndReturn(run func(path string, destObject string) error) *IStorageClient_UploadFile_Call {
+func (_c *IUserRepository_UpdateRole_Call) RunAndReturn(run func(ctx context.Context, name string, role string) error) *IUserRepository_UpdateRole_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// NewIUserRepository creates a new instance of IUserRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// NewIWALDatabase creates a new instance of IWALDatabase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
-func NewIUserRepository(t interface {
+func NewIWALDatabase(t interface {
 	mock.TestingT
 	Cleanup(func())
-}) *IUserRepository {
-	mock := &IUserRepository{}
+}) *IWALDatabase {
+	mock := &IWALDatabase{}
 	mock.Mock.Test(t)
 
 	t.Cleanup(func() { mock.AssertExpectations(t) })
@@ -1226,128 +5166,157 @@ func NewIUserRepository(t interface {
 	return mock
 }
 
-// IUserRepository is an autogenerated mock type for the IUserRepository type
-type IUserRepository struct {
+// IWALDatabase is an autogenerated mock type for the IWALDatabase type
+type IWALDatabase struct {
 	mock.Mock
 }
 
-type IUserRepository_Expecter struct {
+type IWALDatabase_Expecter struct {
 	mock *mock.Mock
 }
 
-func (_m *IUserRepository) EXPECT() *IUserRepository_Expecter {
-	return &IUserRepository_Expecter{mock: &_m.Mock}
+func (_m *IWALDatabase) EXPECT() *IWALDatabase_Expecter {
+	return &IWALDatabase_Expecter{mock: &_m.Mock}
 }
 
-// Create provides a mock function for the type IUserRepository
-func (_mock *IUserRepository) Create(name string, password string) (*models.User, error) {
-	ret := _mock.Called(name, password)
+// Checkpoint provides a mock function for the type IWALDatabase
+func (_mock *IWALDatabase) Checkpoint() (bool, error) {
+	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for Create")
+		panic("no return value specified for Checkpoint")
 	}
 
-	var r0 *models.User
+	var r0 bool
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(string, string) (*models.User, error)); ok {
-		return returnFunc(name, password)
+	if returnFunc, ok := ret.Get(0).(func() (bool, error)); ok {
+		return returnFunc()
 	}
-	if returnFunc, ok := ret.Get(0).(func(string, string) *models.User); ok {
-		r0 = returnFunc(name, password)
+	if returnFunc, ok := ret.Get(0).(func() bool); ok {
+		r0 = returnFunc()
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.User)
-		}
+		r0 = ret.Get(0).(bool)
 	}
-	if returnFunc, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = returnFunc(name, password)
+	if returnFunc, ok := ret.Get(1).(func() error); ok {
+		r1 = returnFunc()
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// IUserRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
-type IUserRepository_Create_Call struct {
+// IWALDatabase_Checkpoint_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Checkpoint'
+type IWALDatabase_Checkpoint_Call struct {
 	*mock.Call
 }
 
-// Create is a helper method to define mock.On call
-//   - name
-//   - password
-func (_e *IUserRepository_Expecter) Create(name interface{}, password interface{}) *IUserRepository_Create_Call {
-	return &IUserRepository_Create_Call{Call: _e.mock.On("Create", name, password)}
+// Checkpoint is a helper method to define mock.On call
+func (_e *IWALDatabase_Expecter) Checkpoint() *IWALDatabase_Checkpoint_Call {
+	return &IWALDatabase_Checkpoint_Call{Call: _e.mock.On("Checkpoint")}
 }
 
-func (_c *IUserRepository_Create_Call) Run(run func(name string, password string)) *IUserRepository_Create_Call {
+func (_c *IWALDatabase_Checkpoint_Call) Run(run func()) *IWALDatabase_Checkpoint_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		run()
 	})
 	return _c
 }
 
-func (_c *IUserRepository_Create_Call) Return(user *models.User, err error) *IUserRepository_Create_Call {
-	_c.Call.Return(user, err)
+func (_c *IWALDatabase_Checkpoint_Call) Return(b bool, err error) *IWALDatabase_Checkpoint_Call {
+	_c.Call.Return(b, err)
 	return _c
 }
 
-func (_c *IUserRepository_Create_Call) RunAndReturn(run func(name string, password string) (*models.User, error)) *IUserRepository_Create_Call {
+func (_c *IWALDatabase_Checkpoint_Call) RunAndReturn(run func() (bool, error)) *IWALDatabase_Checkpoint_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Get provides a mock function for the type IUserRepository
-func (_mock *IUserRepository) Get(name string) (*models.User, error) {
-	ret := _mock.Called(name)
+// Path provides a mock function for the type IWALDatabase
+func (_mock *IWALDatabase) Path() string {
+	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for Get")
+		panic("no return value specified for Path")
 	}
 
-	var r0 *models.User
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(string) (*models.User, error)); ok {
-		return returnFunc(name)
-	}
-	if returnFunc, ok := ret.Get(0).(func(string) *models.User); ok {
-		r0 = returnFunc(name)
+	var r0 string
+	if returnFunc, ok := ret.Get(0).(func() string); ok {
+		r0 = returnFunc()
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.User)
-		}
+		r0 = ret.Get(0).(string)
 	}
-	if returnFunc, ok := ret.Get(1).(func(string) error); ok {
-		r1 = returnFunc(name)
+	return r0
+}
+
+// IWALDatabase_Path_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Path'
+type IWALDatabase_Path_Call struct {
+	*mock.Call
+}
+
+// Path is a helper method to define mock.On call
+func (_e *IWALDatabase_Expecter) Path() *IWALDatabase_Path_Call {
+	return &IWALDatabase_Path_Call{Call: _e.mock.On("Path")}
+}
+
+func (_c *IWALDatabase_Path_Call) Run(run func()) *IWALDatabase_Path_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *IWALDatabase_Path_Call) Return(s string) *IWALDatabase_Path_Call {
+	_c.Call.Return(s)
+	return _c
+}
+
+func (_c *IWALDatabase_Path_Call) RunAndReturn(run func() string) *IWALDatabase_Path_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WriteLocked provides a mock function for the type IWALDatabase
+func (_mock *IWALDatabase) WriteLocked(fc func() error) error {
+	ret := _mock.Called(fc)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WriteLocked")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(func() error) error); ok {
+		r0 = returnFunc(fc)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// IUserRepository_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
-type IUserRepository_Get_Call struct {
+// IWALDatabase_WriteLocked_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WriteLocked'
+type IWALDatabase_WriteLocked_Call struct {
 	*mock.Call
 }
 
-// Get is a helper method to define mock.On call
-//   - name
-func (_e *IUserRepository_Expecter) Get(name interface{}) *IUserRepository_Get_Call {
-	return &IUserRepository_Get_Call{Call: _e.mock.On("Get", name)}
+// WriteLocked is a helper method to define mock.On call
+//   - fc
+func (_e *IWALDatabase_Expecter) WriteLocked(fc interface{}) *IWALDatabase_WriteLocked_Call {
+	return &IWALDatabase_WriteLocked_Call{Call: _e.mock.On("WriteLocked", fc)}
 }
 
-func (_c *IUserRepository_Get_Call) Run(run func(name string)) *IUserRepository_Get_Call {
+func (_c *IWALDatabase_WriteLocked_Call) Run(run func(fc func() error)) *IWALDatabase_WriteLocked_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(func() error))
 	})
 	return _c
 }
 
-func (_c *IUserRepository_Get_Call) Return(user *models.User, err error) *IUserRepository_Get_Call {
-	_c.Call.Return(user, err)
+func (_c *IWALDatabase_WriteLocked_Call) Return(err error) *IWALDatabase_WriteLocked_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *IUserRepository_Get_Call) RunAndReturn(run func(name string) (*models.User, error)) *IUserRepository_Get_Call {
+func (_c *IWALDatabase_WriteLocked_Call) RunAndReturn(run func(fc func() error) error) *IWALDatabase_WriteLocked_Call {
 	_c.Call.Return(run)
 	return _c
 }
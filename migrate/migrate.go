@@ -1,6 +1,7 @@
 package migrate
 
 import (
+	"context"
 	"encoding/csv"
 	"errors"
 	"fmt"
@@ -11,48 +12,52 @@ import (
 	"github.com/apkatsikas/artist-entities/interfaces"
 )
 
-const filePath = "artists.csv"
-
-func readCsvFile() [][]string {
-	f, err := os.Open(filePath)
+func readCsvFile(file string) ([][]string, error) {
+	f, err := os.Open(file)
 	if err != nil {
-		logutil.Error("Unable to read input file, error was %v", err)
+		return nil, fmt.Errorf("unable to read input file: %v", err)
 	}
 	defer f.Close()
 
 	csvReader := csv.NewReader(f)
 	records, err := csvReader.ReadAll()
 	if err != nil {
-		logutil.Error("Unable to parse input file, error was %v", err)
+		return nil, fmt.Errorf("unable to parse input file: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("input file is empty")
 	}
 
-	return records
+	return records, nil
 }
 
-func Migrate(ar interfaces.IArtistRepository, as interfaces.IArtistService, secret string) {
+// Migrate imports the artists in the first row of a CSV file
+func Migrate(ctx context.Context, ar interfaces.IArtistRepository, as interfaces.IArtistService, file string) error {
 	// Setup table
 	err := ar.Migrate()
 	if err != nil {
-		logutil.Error("Got an unexpected error during artist migration: %v", err)
+		logutil.Error("Failed to migrate artists", "error", err)
 	}
 
-	artists := readCsvFile()
+	artists, err := readCsvFile(file)
+	if err != nil {
+		return err
+	}
 
 	// Insert records
 	for _, a := range artists[0] {
-		result, err := as.Create(a)
+		result, err := as.Create(ctx, a)
 		if err != nil {
 			// Log if record exists, or something unexpected
 			if errors.Is(err, customerrors.ErrRecordExists) {
-				log := fmt.Sprintf("Artist '%v' already exists", a)
-				logutil.Info(log)
+				logutil.Info("Artist already exists", "artist", a)
 			} else {
-				logutil.Error("Got an unexpected error on record %v, error was: %v", a, err)
+				logutil.Error("Failed to import artist", "artist", a, "error", err)
 			}
 
 		} else {
-			log := fmt.Sprintf("Inserted artist: %v", result)
-			logutil.Info(log)
+			logutil.Info("Inserted artist", "artistID", result.ID, "artist", result.Name)
 		}
 	}
+	return nil
 }
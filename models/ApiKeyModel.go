@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ApiKeyScopeArtistWrite allows creating artists
+const ApiKeyScopeArtistWrite = "artist:write"
+
+type ApiKey struct {
+	gorm.Model
+	Name string `gorm:"type:varchar(75);not null"`
+	// Prefix is the start of the key, kept so keys can be told apart
+	Prefix string `gorm:"type:varchar(11);not null"`
+	// Hash is the SHA-256 of the key, the key itself is never stored
+	Hash string `gorm:"type:varchar(64);uniqueIndex;not null"`
+	// Scopes is a comma separated list of scopes
+	Scopes     string `gorm:"type:varchar(255);not null"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// BackupPrefix starts the name of every backup we upload
+const BackupPrefix = "entities-backup"
+
+// BackupFile is a backup object in storage
+type BackupFile struct {
+	Name    string
+	Updated time.Time
+	Size    int64
+}
+
+// IsBackup is true for backup objects, but not their manifests
+func IsBackup(name string) bool {
+	return strings.HasPrefix(name, BackupPrefix) && !strings.HasSuffix(name, ManifestSuffix)
+}
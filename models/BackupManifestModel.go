@@ -0,0 +1,86 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// SchemaVersion is recorded in backup manifests, bump it when tables change
+const SchemaVersion = 1
+
+// ManifestSuffix is added to a backup's name for its manifest
+const ManifestSuffix = ".manifest.json"
+
+// Compression algorithms for backups
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// CompressionExtension is added to a backup's name for its compression
+func CompressionExtension(compression string) string {
+	switch compression {
+	case CompressionGzip:
+		return ".gz"
+	case CompressionZstd:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+// EncryptionExtension is added to the name of encrypted backups
+const EncryptionExtension = ".enc"
+
+// IsEncrypted guesses whether a backup is encrypted from its name
+func IsEncrypted(name string) bool {
+	return strings.HasSuffix(name, EncryptionExtension)
+}
+
+// CompressionFor guesses a backup's compression from its name
+// Used for backups from before manifests
+func CompressionFor(name string) string {
+	name = strings.TrimSuffix(name, EncryptionExtension)
+	for _, compression := range []string{CompressionGzip, CompressionZstd} {
+		if strings.HasSuffix(name, CompressionExtension(compression)) {
+			return compression
+		}
+	}
+	return CompressionNone
+}
+
+// BackupManifest is uploaded next to each backup to describe it
+type BackupManifest struct {
+	Object        string `json:"object"`
+	SchemaVersion int    `json:"schemaVersion"`
+	Compression   string `json:"compression"`
+	// Encryption and KeyID are empty for unencrypted backups
+	Encryption string `json:"encryption,omitempty"`
+	KeyID      string `json:"keyId,omitempty"`
+	// SHA256 and Size are of the object as uploaded
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+	// DatabaseSHA256 and DatabaseSize are of the uncompressed database
+	DatabaseSHA256 string    `json:"databaseSha256"`
+	DatabaseSize   int64     `json:"databaseSize"`
+	Artists        int64     `json:"artists"`
+	Users          int64     `json:"users"`
+	SourceHost     string    `json:"sourceHost"`
+	CreatedAt      time.Time `json:"createdAt"`
+	DurationMs     int64     `json:"durationMs"`
+}
+
+// Backup statuses from checking a backup against its manifest
+const (
+	BackupOK         = "ok"
+	BackupUnverified = "unverified"
+	BackupCorrupt    = "corrupt"
+)
+
+// Backup is a stored backup with its manifest, if it has one
+type Backup struct {
+	BackupFile
+	Manifest *BackupManifest
+	Status   string
+}
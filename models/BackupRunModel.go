@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// What started a backup
+const (
+	BackupTriggerScheduled = "scheduled"
+	BackupTriggerManual    = "manual"
+)
+
+// BackupRun records the outcome of a backup, Error is empty if it succeeded
+type BackupRun struct {
+	gorm.Model
+	Trigger    string
+	Object     string
+	StartedAt  time.Time
+	DurationMs int64
+	// Size is of the uploaded object
+	Size  int64
+	Error string
+}
+
+func (br *BackupRun) Succeeded() bool {
+	return br.Error == ""
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// HealthCheck is the outcome of checking one dependency, Error is empty if it passed
+type HealthCheck struct {
+	Name     string
+	Error    string
+	Duration time.Duration
+}
+
+func (hc *HealthCheck) Passed() bool {
+	return hc.Error == ""
+}
+
+// Readiness is ready when every check passed
+type Readiness struct {
+	Checks []HealthCheck
+}
+
+func (r *Readiness) Ready() bool {
+	for _, check := range r.Checks {
+		if !check.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// BuildInfo describes the running binary
+type BuildInfo struct {
+	Version   string
+	Commit    string
+	BuildTime string
+	GoVersion string
+}
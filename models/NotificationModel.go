@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Notification events
+const (
+	EventBackupFailed    = "backup.failed"
+	EventBackupSucceeded = "backup.succeeded"
+	EventJobFailed       = "job.failed"
+	EventTest            = "test"
+)
+
+// Notification is sent to the configured channels
+// Fields are extra details, like the backup's name
+type Notification struct {
+	Event   string
+	Failure bool
+	Subject string
+	Message string
+	Time    time.Time
+	Fields  map[string]string
+}
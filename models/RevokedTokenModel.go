@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+// RevokedToken is a JWT that was logged out before it expired
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey;type:varchar(64)"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+}
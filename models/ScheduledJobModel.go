@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// ScheduledJob is a job run on a schedule and how it last went
+// Times are zero if it hasn't run, LastError is empty if it succeeded
+type ScheduledJob struct {
+	Name         string
+	Schedule     string
+	Running      bool
+	LastRun      time.Time
+	LastDuration time.Duration
+	LastError    string
+	// Skipped counts runs missed because the previous one was still going
+	Skipped int
+	NextRun time.Time
+}
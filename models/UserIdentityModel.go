@@ -0,0 +1,11 @@
+package models
+
+import "gorm.io/gorm"
+
+// UserIdentity links an OpenID Connect identity to a local user
+type UserIdentity struct {
+	gorm.Model
+	Issuer  string `gorm:"type:varchar(255);uniqueIndex:idx_user_identity;not null"`
+	Subject string `gorm:"type:varchar(255);uniqueIndex:idx_user_identity;not null"`
+	UserID  uint   `gorm:"index;not null"`
+}
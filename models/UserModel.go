@@ -2,8 +2,22 @@ package models
 
 import "gorm.io/gorm"
 
+// Roles a user can have
+const (
+    RoleAdmin = "admin"
+    RoleEditor = "editor"
+)
+
 type User struct {
     gorm.Model
     Name string `gorm:"type:varchar(75);unique_index;not null"`
     Password string `gorm:"type:varchar(75);not null"`
+    // TokenVersion is bumped to invalidate every token issued to the user
+    TokenVersion uint `gorm:"not null;default:0"`
+    Role string `gorm:"type:varchar(20);not null;default:admin"`
+}
+
+// HasRole is true if the user can do what role can, admins can do anything
+func (u *User) HasRole(role string) bool {
+    return u.Role == RoleAdmin || u.Role == role
 }
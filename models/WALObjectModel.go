@@ -0,0 +1,75 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WALPrefix starts the name of every replication object we upload
+const WALPrefix = "entities-wal-"
+
+// WALObject is a replication object in storage
+// Each generation is a snapshot of the database followed by numbered WAL segments
+type WALObject struct {
+	Name       string
+	Generation string
+	Snapshot   bool
+	// Index and Time are only set for segments
+	Index int
+	// Time is when the segment was shipped, everything in it was committed by then
+	Time time.Time
+}
+
+// GenerationTime is when a generation's snapshot was taken
+func (o WALObject) GenerationTime() time.Time {
+	ms, _ := strconv.ParseInt(o.Generation, 10, 64)
+	return time.UnixMilli(ms)
+}
+
+// NewWALGeneration names a generation after its snapshot time
+func NewWALGeneration(t time.Time) string {
+	return strconv.FormatInt(t.UnixMilli(), 10)
+}
+
+// WALSnapshotName is the object name of a generation's snapshot, before any extensions
+func WALSnapshotName(generation string) string {
+	return fmt.Sprintf("%v%v-snapshot.sqlite", WALPrefix, generation)
+}
+
+// WALSegmentName is the object name of a segment, before any extensions
+func WALSegmentName(generation string, index int, t time.Time) string {
+	return fmt.Sprintf("%v%v-%08d-%v.wal", WALPrefix, generation, index, t.UnixMilli())
+}
+
+// ParseWALObject is false for anything that isn't a replication object
+func ParseWALObject(name string) (WALObject, bool) {
+	if !strings.HasPrefix(name, WALPrefix) {
+		return WALObject{}, false
+	}
+	base, _, _ := strings.Cut(strings.TrimPrefix(name, WALPrefix), ".")
+	parts := strings.Split(base, "-")
+	if _, err := strconv.ParseInt(parts[0], 10, 64); err != nil {
+		return WALObject{}, false
+	}
+	object := WALObject{Name: name, Generation: parts[0]}
+
+	switch {
+	case len(parts) == 2 && parts[1] == "snapshot":
+		object.Snapshot = true
+	case len(parts) == 3:
+		index, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return WALObject{}, false
+		}
+		ms, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			return WALObject{}, false
+		}
+		object.Index, object.Time = index, time.UnixMilli(ms)
+	default:
+		return WALObject{}, false
+	}
+	return object, true
+}
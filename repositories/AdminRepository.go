@@ -3,7 +3,9 @@ package repositories
 import (
     "fmt"
 
+    ce "github.com/apkatsikas/artist-entities/customerrors"
     "github.com/apkatsikas/artist-entities/interfaces"
+    "gorm.io/gorm"
 )
 
 type AdminRepository struct {
@@ -21,3 +23,75 @@ func (adR *AdminRepository) CreateBackup(file string) error {
     }
     return nil
 }
+
+// withAttached attaches a database file as "candidate" while fc runs
+func (adR *AdminRepository) withAttached(file string, fc func(conn *gorm.DB) error) error {
+    // ATTACH only applies to one connection, so hold on to it
+    return adR.IDB.Connection().Connection(func(conn *gorm.DB) error {
+        err := conn.Exec("ATTACH DATABASE ? AS candidate", file).Error
+        if err != nil {
+            return fmt.Errorf("%w: %v", ce.ErrDataInvalid, err)
+        }
+        defer conn.Exec("DETACH DATABASE candidate")
+
+        return fc(conn)
+    })
+}
+
+// CheckIntegrity attaches a database file and checks it's an intact copy of ours
+// Returns ErrDataInvalid if it isn't
+func (adR *AdminRepository) CheckIntegrity(file string) error {
+    return adR.withAttached(file, func(conn *gorm.DB) error {
+        var results []string
+        err := conn.Raw("PRAGMA candidate.integrity_check").Scan(&results).Error
+        if err != nil {
+            return fmt.Errorf("%w: %v", ce.ErrDataInvalid, err)
+        }
+        if len(results) != 1 || results[0] != "ok" {
+            return fmt.Errorf("%w: integrity check failed: %v", ce.ErrDataInvalid, results)
+        }
+
+        var tables int64
+        err = conn.Raw("SELECT count(*) FROM candidate.sqlite_master WHERE type = 'table' AND name = 'artists'").
+            Scan(&tables).Error
+        if err != nil {
+            return err
+        }
+        if tables == 0 {
+            return fmt.Errorf("%w: no artists table", ce.ErrDataInvalid)
+        }
+        return nil
+    })
+}
+
+// CheckDatabase checks the live database for corruption
+// Returns ErrDataInvalid if it's corrupt
+func (adR *AdminRepository) CheckDatabase() error {
+    var results []string
+    err := adR.IDB.Connection().Raw("PRAGMA main.integrity_check").Scan(&results).Error
+    if err != nil {
+        return err
+    }
+    if len(results) != 1 || results[0] != "ok" {
+        return fmt.Errorf("%w: integrity check failed: %v", ce.ErrDataInvalid, results)
+    }
+    return nil
+}
+
+// Counts returns how many artists and users a database file holds
+func (adR *AdminRepository) Counts(file string) (int64, int64, error) {
+    var artists, users int64
+    err := adR.withAttached(file, func(conn *gorm.DB) error {
+        err := conn.Raw("SELECT count(*) FROM candidate.artists WHERE deleted_at IS NULL").Scan(&artists).Error
+        if err != nil {
+            return err
+        }
+        return conn.Raw("SELECT count(*) FROM candidate.users WHERE deleted_at IS NULL").Scan(&users).Error
+    })
+    return artists, users, err
+}
+
+// Restore replaces the database with file
+func (adR *AdminRepository) Restore(file string) error {
+    return adR.IDB.Replace(file)
+}
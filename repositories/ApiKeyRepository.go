@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	ce "github.com/apkatsikas/artist-entities/customerrors"
+	"github.com/apkatsikas/artist-entities/interfaces"
+	"github.com/apkatsikas/artist-entities/models"
+	"gorm.io/gorm"
+)
+
+type ApiKeyRepository struct {
+	IDB interfaces.IDbHandler
+}
+
+func (ar *ApiKeyRepository) Create(apiKey *models.ApiKey) error {
+	result := ar.IDB.Connection().Create(apiKey)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (ar *ApiKeyRepository) GetByHash(hash string) (*models.ApiKey, error) {
+	var apiKey = models.ApiKey{}
+	result := ar.IDB.Connection().Where("hash = ?", hash).First(&apiKey)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ce.ErrRecordNotFound
+		}
+		return nil, result.Error
+	}
+	return &apiKey, nil
+}
+
+func (ar *ApiKeyRepository) List() ([]models.ApiKey, error) {
+	var apiKeys []models.ApiKey
+	result := ar.IDB.Connection().Order("id").Find(&apiKeys)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return apiKeys, nil
+}
+
+func (ar *ApiKeyRepository) Revoke(id uint, at time.Time) error {
+	result := ar.IDB.Connection().Model(&models.ApiKey{}).
+		Where("id = ?", id).Update("revoked_at", at)
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ce.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (ar *ApiKeyRepository) Touch(id uint, at time.Time) error {
+	result := ar.IDB.Connection().Model(&models.ApiKey{}).
+		Where("id = ?", id).Update("last_used_at", at)
+
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (ar *ApiKeyRepository) Migrate() error {
+	err := ar.IDB.Connection().AutoMigrate(&models.ApiKey{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (ar *ApiKeyRepository) CheckMigrated() error {
+	return checkMigrated(ar.IDB.Connection(), &models.ApiKey{})
+}
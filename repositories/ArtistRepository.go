@@ -1,6 +1,7 @@
 package repositories
 
 import (
+    "context"
     "errors"
 
     ce "github.com/apkatsikas/artist-entities/customerrors"
@@ -15,8 +16,8 @@ type ArtistRepository struct {
     IDB interfaces.IDbHandler
 }
 
-func (ar *ArtistRepository) GetCount() (uint, error) {
-    gormConn := ar.IDB.Connection()
+func (ar *ArtistRepository) GetCount(ctx context.Context) (uint, error) {
+    gormConn := ar.IDB.Connection().WithContext(ctx)
 
     var count int64
 
@@ -28,8 +29,8 @@ func (ar *ArtistRepository) GetCount() (uint, error) {
     return uint(count), nil
 }
 
-func (ar *ArtistRepository) GetByOffset(offset uint) (*models.Artist, error) {
-    gormConn := ar.IDB.Connection()
+func (ar *ArtistRepository) GetByOffset(ctx context.Context, offset uint) (*models.Artist, error) {
+    gormConn := ar.IDB.Connection().WithContext(ctx)
 
     var artist = models.Artist{}
 
@@ -42,10 +43,10 @@ func (ar *ArtistRepository) GetByOffset(offset uint) (*models.Artist, error) {
     return &artist, nil
 }
 
-func (ar *ArtistRepository) Get(id uint) (*models.Artist, error) {
+func (ar *ArtistRepository) Get(ctx context.Context, id uint) (*models.Artist, error) {
     var artist = models.Artist{}
     artist.ID = id
-    result := ar.IDB.Connection().First(&artist)
+    result := ar.IDB.Connection().WithContext(ctx).First(&artist)
 
     if result.Error != nil {
         if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -56,10 +57,10 @@ func (ar *ArtistRepository) Get(id uint) (*models.Artist, error) {
     return &artist, nil
 }
 
-func (ar *ArtistRepository) Create(name string) (*models.Artist, error) {
+func (ar *ArtistRepository) Create(ctx context.Context, name string) (*models.Artist, error) {
     var a models.Artist
     // If record can't be found, insert it
-    result := ar.IDB.Connection().Where(
+    result := ar.IDB.Connection().WithContext(ctx).Where(
         models.Artist{Name: name}).FirstOrCreate(&a)
 
     if result.Error != nil {
@@ -80,3 +81,7 @@ func (ar *ArtistRepository) Migrate() error {
 
     return nil
 }
+
+func (ar *ArtistRepository) CheckMigrated() error {
+    return checkMigrated(ar.IDB.Connection(), &models.Artist{})
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"errors"
+
+	ce "github.com/apkatsikas/artist-entities/customerrors"
+	"github.com/apkatsikas/artist-entities/interfaces"
+	"github.com/apkatsikas/artist-entities/models"
+	"gorm.io/gorm"
+)
+
+// backupRunsKept stops the history growing forever
+const backupRunsKept = 100
+
+type BackupRunRepository struct {
+	IDB interfaces.IDbHandler
+}
+
+func (br *BackupRunRepository) Create(run *models.BackupRun) error {
+	result := br.IDB.Connection().Create(run)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// Prune older runs
+	if run.ID <= backupRunsKept {
+		return nil
+	}
+	result = br.IDB.Connection().Unscoped().
+		Where("id <= ?", run.ID-backupRunsKept).Delete(&models.BackupRun{})
+	return result.Error
+}
+
+func (br *BackupRunRepository) Last() (*models.BackupRun, error) {
+	var run models.BackupRun
+	result := br.IDB.Connection().Order("id desc").First(&run)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ce.ErrRecordNotFound
+	} else if result.Error != nil {
+		return nil, result.Error
+	}
+	return &run, nil
+}
+
+func (br *BackupRunRepository) Migrate() error {
+	err := br.IDB.Connection().AutoMigrate(&models.BackupRun{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (br *BackupRunRepository) CheckMigrated() error {
+	return checkMigrated(br.IDB.Connection(), &models.BackupRun{})
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// checkMigrated returns an error naming the first table or column of models
+// that's missing from the database
+func checkMigrated(db *gorm.DB, models ...interface{}) error {
+	migrator := db.Migrator()
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		err := stmt.Parse(model)
+		if err != nil {
+			return err
+		}
+		if !migrator.HasTable(model) {
+			return fmt.Errorf("table %v is missing", stmt.Schema.Table)
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" && !migrator.HasColumn(model, field.DBName) {
+				return fmt.Errorf("column %v.%v is missing", stmt.Schema.Table, field.DBName)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/interfaces"
+	"github.com/apkatsikas/artist-entities/models"
+	"gorm.io/gorm/clause"
+)
+
+type RevokedTokenRepository struct {
+	IDB interfaces.IDbHandler
+}
+
+func (rr *RevokedTokenRepository) Create(ctx context.Context, jti string, expiresAt time.Time) error {
+	// Logging out twice is fine
+	result := rr.IDB.Connection().WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.RevokedToken{JTI: jti, ExpiresAt: expiresAt})
+
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
+func (rr *RevokedTokenRepository) ListActive(now time.Time) ([]models.RevokedToken, error) {
+	var tokens []models.RevokedToken
+	result := rr.IDB.Connection().Where("expires_at > ?", now).Find(&tokens)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return tokens, nil
+}
+
+func (rr *RevokedTokenRepository) DeleteExpired(now time.Time) (int64, error) {
+	result := rr.IDB.Connection().Where("expires_at <= ?", now).Delete(&models.RevokedToken{})
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+func (rr *RevokedTokenRepository) Migrate() error {
+	err := rr.IDB.Connection().AutoMigrate(&models.RevokedToken{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (rr *RevokedTokenRepository) CheckMigrated() error {
+	return checkMigrated(rr.IDB.Connection(), &models.RevokedToken{})
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 
 	ce "github.com/apkatsikas/artist-entities/customerrors"
@@ -15,10 +16,9 @@ type UserRepository struct {
 	IDB interfaces.IDbHandler
 }
 
-func (ur *UserRepository) Get(name string) (*models.User, error) {
+func (ur *UserRepository) Get(ctx context.Context, name string) (*models.User, error) {
 	var user = models.User{}
-	user.Name = name
-	result := ur.IDB.Connection().First(&user)
+	result := ur.IDB.Connection().WithContext(ctx).Where("name = ?", name).First(&user)
 
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -29,11 +29,12 @@ func (ur *UserRepository) Get(name string) (*models.User, error) {
 	return &user, nil
 }
 
-func (ur *UserRepository) Create(name string, password string) (*models.User, error) {
+func (ur *UserRepository) Create(ctx context.Context, name string, password string) (*models.User, error) {
 	var user models.User
 
-	result := ur.IDB.Connection().Where(
-		models.User{Name: name, Password: password}).FirstOrCreate(&user)
+	// Match on name only so a second password can't create a duplicate user
+	result := ur.IDB.Connection().WithContext(ctx).Where(
+		models.User{Name: name}).Attrs(models.User{Password: password}).FirstOrCreate(&user)
 
 	if result.Error != nil {
 		return nil, result.Error
@@ -44,11 +45,99 @@ func (ur *UserRepository) Create(name string, password string) (*models.User, er
 	return &user, nil
 }
 
+// UpdatePassword sets a new password hash and invalidates existing tokens
+func (ur *UserRepository) UpdatePassword(ctx context.Context, name string, password string) error {
+	result := ur.IDB.Connection().WithContext(ctx).Model(&models.User{}).Where("name = ?", name).
+		Updates(map[string]interface{}{
+			"password":      password,
+			"token_version": gorm.Expr("token_version + 1"),
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ce.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpdateRole changes what the user can do, including with tokens already issued
+func (ur *UserRepository) UpdateRole(ctx context.Context, name string, role string) error {
+	result := ur.IDB.Connection().WithContext(ctx).Model(&models.User{}).Where("name = ?", name).
+		Update("role", role)
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ce.ErrRecordNotFound
+	}
+	return nil
+}
+
+// IncrementTokenVersion invalidates every token issued to the user
+func (ur *UserRepository) IncrementTokenVersion(ctx context.Context, name string) error {
+	result := ur.IDB.Connection().WithContext(ctx).Model(&models.User{}).Where("name = ?", name).
+		Update("token_version", gorm.Expr("token_version + 1"))
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ce.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetByIdentity finds the user an OpenID Connect identity is linked to
+func (ur *UserRepository) GetByIdentity(ctx context.Context, issuer string, subject string) (*models.User, error) {
+	var user = models.User{}
+	result := ur.IDB.Connection().WithContext(ctx).
+		Joins("JOIN user_identities ON user_identities.user_id = users.id AND user_identities.deleted_at IS NULL").
+		Where("user_identities.issuer = ? AND user_identities.subject = ?", issuer, subject).
+		First(&user)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ce.ErrRecordNotFound
+		}
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+// CreateWithIdentity creates a user linked to an OpenID Connect identity,
+// or links the identity to the user if they already exist, keeping their role
+func (ur *UserRepository) CreateWithIdentity(ctx context.Context, name string, password string, role string,
+	issuer string, subject string) (*models.User, error) {
+	var user models.User
+
+	err := ur.IDB.Connection().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where(models.User{Name: name}).
+			Attrs(models.User{Password: password, Role: role}).FirstOrCreate(&user)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		return tx.Create(&models.UserIdentity{Issuer: issuer, Subject: subject, UserID: user.ID}).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 func (ur *UserRepository) Migrate() error {
-	err := ur.IDB.Connection().AutoMigrate(&models.User{})
+	err := ur.IDB.Connection().AutoMigrate(&models.User{}, &models.UserIdentity{})
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+func (ur *UserRepository) CheckMigrated() error {
+	return checkMigrated(ur.IDB.Connection(), &models.User{}, &models.UserIdentity{})
+}
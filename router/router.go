@@ -5,30 +5,66 @@ import (
 
 	"github.com/apkatsikas/artist-entities/controllers"
 	"github.com/apkatsikas/artist-entities/infrastructures/logutil"
+	"github.com/apkatsikas/artist-entities/infrastructures/metrics"
+	"github.com/apkatsikas/artist-entities/infrastructures/tracing"
 	"github.com/go-chi/chi/v5"
 )
 
 type IChiRouter interface {
-	InitRouter(ac *controllers.ArtistController, authController *controllers.AuthController) *chi.Mux
+	InitRouter(ac *controllers.ArtistController, authController *controllers.AuthController,
+		apiKeyController *controllers.ApiKeyController,
+		adminController *controllers.AdminController,
+		healthController *controllers.HealthController) *chi.Mux
 }
 
 type router struct{}
 
 func (router *router) InitRouter(ac *controllers.ArtistController,
-	authController *controllers.AuthController) *chi.Mux {
+	authController *controllers.AuthController,
+	apiKeyController *controllers.ApiKeyController,
+	adminController *controllers.AdminController,
+	healthController *controllers.HealthController) *chi.Mux {
 	// Create router
 	r := chi.NewRouter()
+	r.Use(tracing.Middleware)
+	r.Use(logutil.Middleware)
+	r.Use(metrics.Middleware)
+	r.Get(controllers.HEALTHZ_RP, healthController.Healthz)
+	r.Get(controllers.READYZ_RP, healthController.Readyz)
+	r.Get(controllers.VERSION_RP, healthController.Version)
+
 	r.HandleFunc(controllers.ARTIST_RP, ac.Get)
 	r.HandleFunc(controllers.POST_ARTIST_RP, ac.Create)
 	r.HandleFunc(controllers.RANDOM_ARTIST_RP, ac.GetRandom)
 
 	r.HandleFunc(controllers.LOGIN, authController.Login)
+	r.Get(controllers.OIDC_LOGIN, authController.OIDCLogin)
+	r.Get(controllers.OIDC_CALLBACK, authController.OIDCCallback)
+	r.Get(controllers.JWKS_RP, authController.JWKS)
+	r.Post(controllers.LOGOUT, authController.Logout)
+	r.Post(controllers.REVOKE_SESSIONS_RP, authController.RevokeSessions)
+
+	r.Post(controllers.API_KEY_RP, apiKeyController.Create)
+	r.Get(controllers.API_KEY_RP, apiKeyController.List)
+	r.Delete(controllers.API_KEY_ID_RP, apiKeyController.Revoke)
+
+	r.Post(controllers.ADMIN_BACKUP_RP, adminController.Backup)
+	r.Get(controllers.ADMIN_BACKUPS_RP, adminController.List)
+	r.Post(controllers.ADMIN_RESTORE_RP, adminController.Restore)
+	r.Get(controllers.ADMIN_JOBS_RP, adminController.Jobs)
 
 	logutil.Info("Router initialized")
 
 	return r
 }
 
+// MetricsRouter serves /metrics, which is kept off the public router
+func MetricsRouter() *chi.Mux {
+	r := chi.NewRouter()
+	r.Handle(controllers.METRICS_RP, metrics.Handler())
+	return r
+}
+
 // Setup singleton
 var (
 	m          *router
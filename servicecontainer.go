@@ -1,135 +1,406 @@
 package entities
 
 import (
+	"context"
+	"encoding/base64"
+	"fmt"
 	"os"
-	"sync"
+	"path/filepath"
+	"time"
 
+	"github.com/apkatsikas/artist-entities/config"
 	"github.com/apkatsikas/artist-entities/controllers"
 	"github.com/apkatsikas/artist-entities/infrastructures"
+	"github.com/apkatsikas/artist-entities/infrastructures/backupcrypt"
+	"github.com/apkatsikas/artist-entities/infrastructures/buildinfo"
+	"github.com/apkatsikas/artist-entities/infrastructures/compressor"
 	"github.com/apkatsikas/artist-entities/infrastructures/fileutil"
-	"github.com/apkatsikas/artist-entities/infrastructures/flagutil"
+	"github.com/apkatsikas/artist-entities/infrastructures/jwtkeys"
+	"github.com/apkatsikas/artist-entities/infrastructures/loginthrottle"
 	"github.com/apkatsikas/artist-entities/infrastructures/logutil"
-	"github.com/apkatsikas/artist-entities/migrate"
+	"github.com/apkatsikas/artist-entities/infrastructures/metrics"
+	"github.com/apkatsikas/artist-entities/infrastructures/notifier"
+	"github.com/apkatsikas/artist-entities/infrastructures/scheduler"
+	"github.com/apkatsikas/artist-entities/infrastructures/tokendenylist"
+	"github.com/apkatsikas/artist-entities/infrastructures/tracing"
+	"github.com/apkatsikas/artist-entities/infrastructures/walship"
+	"github.com/apkatsikas/artist-entities/interfaces"
+	"github.com/apkatsikas/artist-entities/models"
 	"github.com/apkatsikas/artist-entities/repositories"
 	"github.com/apkatsikas/artist-entities/router"
 	"github.com/apkatsikas/artist-entities/services"
 	"github.com/apkatsikas/artist-entities/services/rules"
 	"github.com/apkatsikas/artist-entities/storageclient"
 	"github.com/go-chi/chi/v5"
-	"github.com/robfig/cron/v3"
 )
 
-const (
-	dbFile = "entities.db"
+const backupJob = "backup"
 
-	// 2am every day
-	schedule = "0 2 * * *"
-)
+// kernel builds dependencies the first time they're needed,
+// so each command only builds what it uses
+type kernel struct {
+	config        *config.Config
+	sqliteHandler *infrastructures.SQLiteHandler
+	repositories  *repositorySet
+	storage       interfaces.IStorageClient
+	notifier      *notifier.Notifier
+	keyring       *jwtkeys.Keyring
+	tokenDenylist *tokendenylist.TokenDenylist
+	authService   *services.AuthService
+	adminService  *services.AdminService
+	scheduler     *scheduler.Scheduler
+	// replicator is only set when WAL replication is on
+	replicator *walship.Replicator
+	// stopTracing flushes spans, it's set once tracing is setup
+	stopTracing func(ctx context.Context) error
+}
 
-type IServiceContainer interface {
-	Setup() *chi.Mux
+type repositorySet struct {
+	admin        *repositories.AdminRepository
+	artist       *repositories.ArtistRepository
+	user         *repositories.UserRepository
+	revokedToken *repositories.RevokedTokenRepository
+	apiKey       *repositories.ApiKeyRepository
+	backupRun    *repositories.BackupRunRepository
 }
 
-type kernel struct {
-	sqliteHandler *infrastructures.SQLiteHandler
+// migrators are migrated when the database is opened, and after a restore
+func (rs *repositorySet) migrators() []interfaces.IMigrator {
+	return []interfaces.IMigrator{rs.artist, rs.user, rs.revokedToken, rs.apiKey, rs.backupRun}
 }
 
-func (k *kernel) Setup() *chi.Mux {
-	// Setup logs
-	logutil.Setup()
-	logutil.Info("Running...")
+func newKernel(cfg *config.Config) *kernel {
+	return &kernel{config: cfg}
+}
 
-	// Setup flags
-	fu := flagutil.Get()
-	fu.Setup()
+// Stop waits for running jobs to finish, closes the database and waits for
+// notifications to be sent, or for ctx to be done
+func (k *kernel) Stop(ctx context.Context) error {
+	if k.scheduler != nil {
+		err := k.scheduler.Stop(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	if k.replicator != nil {
+		// Ship whatever was written since the last sync
+		err := k.replicator.Sync(ctx)
+		if err != nil {
+			logutil.Error("Failed to sync the WAL replica", "error", err)
+		}
+	}
+	if k.sqliteHandler != nil {
+		err := k.sqliteHandler.Close()
+		if err != nil {
+			logutil.Error("Failed to close the database", "error", err)
+		}
+	}
+	if k.stopTracing != nil {
+		err := k.stopTracing(ctx)
+		if err != nil {
+			logutil.Error("Failed to flush traces", "error", err)
+		}
+	}
+	if k.notifier != nil {
+		return k.notifier.Wait(ctx)
+	}
+	return nil
+}
 
-	// Setup sqlite
-	k.sqliteHandler = &infrastructures.SQLiteHandler{}
+// repos opens the database and brings its tables up to date
+func (k *kernel) repos() *repositorySet {
+	if k.repositories != nil {
+		return k.repositories
+	}
 
-	// Connect to SQLite
-	err := k.sqliteHandler.ConnectSQLite(dbFile)
+	// Setup sqlite, in WAL mode if it's replicated
+	k.sqliteHandler = &infrastructures.SQLiteHandler{WAL: k.config.WAL.Replication}
+	err := k.sqliteHandler.ConnectSQLite(k.config.Database.Path)
 	if err != nil {
-		logutil.Fatal("Failed to connect to SQLite. Error was %v", err)
+		logutil.Fatal("Failed to connect to SQLite", "error", err)
 	}
 
-	// Bring everything online
-	storage := storageclient.New()
-	artistRules := &rules.ArtistRules{}
-	adminRules := &rules.AdminRules{}
-	fileUtil := &fileutil.FileUtil{}
+	k.repositories = &repositorySet{
+		admin:        &repositories.AdminRepository{IDB: k.sqliteHandler},
+		artist:       &repositories.ArtistRepository{IDB: k.sqliteHandler},
+		user:         &repositories.UserRepository{IDB: k.sqliteHandler},
+		revokedToken: &repositories.RevokedTokenRepository{IDB: k.sqliteHandler},
+		apiKey:       &repositories.ApiKeyRepository{IDB: k.sqliteHandler},
+		backupRun:    &repositories.BackupRunRepository{IDB: k.sqliteHandler},
+	}
+	for _, migrator := range k.repositories.migrators() {
+		err = migrator.Migrate()
+		if err != nil {
+			logutil.Fatal("Failed to migrate the database", "error", err)
+		}
+	}
+	return k.repositories
+}
 
-	// Inject dependencies
+func (k *kernel) storageClient() interfaces.IStorageClient {
+	if k.storage == nil {
+		storage, err := storageclient.New(k.config.Storage)
+		if err != nil {
+			logutil.Fatal("Failed to create backup storage", "error", err)
+		}
+		k.storage = &tracing.StorageClient{StorageClient: storage}
+	}
+	return k.storage
+}
 
-	// Admin
-	adminRepository := &repositories.AdminRepository{IDB: k.sqliteHandler}
-	adminService := &services.AdminService{
-		AdminRepository: adminRepository,
-		FileUtil:        fileUtil,
-		StorageClient:   storage, Rules: adminRules,
+func (k *kernel) notifications() *notifier.Notifier {
+	if k.notifier == nil {
+		k.notifier = newNotifier(k.config.Notify)
 	}
-	userRepository := &repositories.UserRepository{IDB: k.sqliteHandler}
-	authService := &services.AuthService{UserRepository: userRepository}
+	return k.notifier
+}
 
-	signingKey := os.Getenv("JWT_SIGNING_KEY")
-	if signingKey == "" {
-		panic("JWT_SIGNING_KEY must be set")
+func (k *kernel) artistService() *services.ArtistService {
+	return &services.ArtistService{
+		ArtistRepository: k.repos().artist,
+		Rules:            &rules.ArtistRules{},
 	}
-	authService.SetJwtSigningKey(signingKey)
+}
+
+func (k *kernel) apiKeyService() *services.ApiKeyService {
+	return &services.ApiKeyService{ApiKeyRepository: k.repos().apiKey}
+}
 
-	// Web
-	artistRepository := &repositories.ArtistRepository{IDB: k.sqliteHandler}
-	artistService := &services.ArtistService{
-		ArtistRepository: artistRepository,
-		Rules:            artistRules,
+func (k *kernel) auth() *services.AuthService {
+	if k.authService != nil {
+		return k.authService
 	}
 
-	artistController := &controllers.ArtistController{ArtistService: artistService,
-		AuthService: authService}
-	authController := &controllers.AuthController{AuthService: authService}
+	// Logged out tokens
+	k.tokenDenylist = &tokendenylist.TokenDenylist{Repository: k.repos().revokedToken}
+	err := k.tokenDenylist.Load()
+	if err != nil {
+		logutil.Fatal("Failed to load revoked tokens", "error", err)
+	}
+	k.authService = &services.AuthService{UserRepository: k.repos().user,
+		TokenDenylist: k.tokenDenylist}
 
-	if fu.MigrateUser != "" && fu.MigratePassword != "" {
-		err := userRepository.Migrate()
+	// A keyring file allows rotation and asymmetric keys,
+	// otherwise fall back to a single shared secret
+	if k.config.Auth.JWTKeyringFile != "" {
+		k.keyring, err = jwtkeys.Load(k.config.Auth.JWTKeyringFile)
 		if err != nil {
-			logutil.Error("Failed to migrate user table, error was %v", err)
+			logutil.Fatal("Failed to load JWT keyring", "error", err)
 		}
-		_, err = authService.CreateUser(fu.MigrateUser, fu.MigratePassword)
+		k.authService.SetKeyring(k.keyring)
+	} else {
+		k.authService.SetJwtSigningKey(k.config.Auth.JWTSigningKey)
+	}
+	return k.authService
+}
+
+func (k *kernel) admin() *services.AdminService {
+	if k.adminService != nil {
+		return k.adminService
+	}
+
+	cfg := k.config
+	k.adminService = &services.AdminService{
+		AdminRepository: k.repos().admin,
+		FileUtil:        &fileutil.FileUtil{},
+		StorageClient:   k.storageClient(),
+		Rules:           &rules.AdminRules{Retention: rules.Retention(cfg.Backup.Keep)},
+		Compressor:      &compressor.Compressor{},
+		Compression:     cfg.Backup.Compression,
+		Encryptor:       backupEncryptor(cfg.Backup),
+		Notifier:        k.notifications(),
+		// Restored backups are migrated to the current schema
+		BackupRunRepository: k.repos().backupRun,
+		Migrators:           k.repos().migrators(),
+		// Restores are renamed over the database
+		Dir: filepath.Dir(cfg.Database.Path),
+	}
+	k.adminService.Hostname, _ = os.Hostname()
+	// Restoring to a point in time works whether or not we're replicating
+	replicator := newReplicator(cfg.WAL, k.sqliteHandler, k.adminService)
+	k.adminService.Replicator = replicator
+	if cfg.WAL.Replication {
+		k.replicator = replicator
+	}
+	return k.adminService
+}
+
+// serve starts the scheduled jobs and returns the router
+func (k *kernel) serve() *chi.Mux {
+	cfg := k.config
+	stopTracing, err := tracing.Setup(cfg.Tracing, buildinfo.Get().Version)
+	if err != nil {
+		logutil.Fatal("Failed to setup tracing", "error", err)
+	}
+	k.stopTracing = stopTracing
+	authService := k.auth()
+	adminService := k.admin()
+	apiKeyService := k.apiKeyService()
+
+	artistController := &controllers.ArtistController{ArtistService: k.artistService(),
+		AuthService: authService, ApiKeyService: apiKeyService}
+	authController := &controllers.AuthController{AuthService: authService,
+		LoginThrottle: &loginthrottle.LoginThrottle{Rules: &rules.LoginRules{}}}
+	// OpenID Connect login is optional
+	if cfg.OIDC.IssuerURL != "" {
+		roleMap, err := services.ParseRoleMap(cfg.OIDC.RoleMap)
 		if err != nil {
-			logutil.Error("Failed to create user %v, error was %v", err)
+			logutil.Fatal("Failed to parse OIDC_ROLE_MAP", "error", err)
 		}
-		return nil
+		authController.OIDCService = &services.OIDCService{
+			AuthService:    authService,
+			UserRepository: k.repos().user,
+			IssuerURL:      cfg.OIDC.IssuerURL,
+			ClientID:       cfg.OIDC.ClientID,
+			ClientSecret:   cfg.OIDC.ClientSecret,
+			RedirectURL:    cfg.OIDC.RedirectURL,
+			RoleMap:        roleMap,
+		}
+	}
+	apiKeyController := &controllers.ApiKeyController{ApiKeyService: apiKeyService,
+		AuthService: authService}
+	adminController := &controllers.AdminController{AdminService: adminService,
+		AuthService: authService}
+	healthService := &services.HealthService{
+		DbHandler:     k.sqliteHandler,
+		Migrators:     k.repos().migrators(),
+		StorageClient: k.storageClient(),
+		CheckTimeout:  cfg.Health.CheckTimeout,
+		CacheFor:      cfg.Health.CacheFor,
+		Started:       time.Now(),
+		BuildInfo:     buildinfo.Get(),
+	}
+	// Without scheduled backups there's nothing to expect
+	if cfg.Schedule.Backup != scheduler.Disabled {
+		healthService.MaxBackupAge = cfg.Health.MaxBackupAge
 	}
+	healthController := &controllers.HealthController{HealthService: healthService}
 
-	// Setup cron
-	c := cron.New()
+	// Setup metrics, carrying on from the last backup before a restart
+	err = metrics.RegisterArtistCount(func() (uint, error) {
+		return k.repos().artist.GetCount(context.Background())
+	})
+	if err != nil {
+		logutil.Fatal("Failed to register metrics", "error", err)
+	}
+	lastRun, err := adminService.LastBackupRun()
+	if err == nil && lastRun.Succeeded() {
+		metrics.SetLastBackup(lastRun)
+	}
 
-	c.AddFunc(schedule, func() {
-		err = adminService.Backup()
+	// Setup scheduled jobs
+	k.scheduler = scheduler.New(cfg.Schedule.Location())
+	k.scheduler.OnFailure = func(name string, err error) {
+		// Backups send their own, more detailed, notifications
+		if name == backupJob {
+			return
+		}
+		k.notifier.Notify(models.Notification{
+			Event:   models.EventJobFailed,
+			Failure: true,
+			Subject: fmt.Sprintf("Job %v failed on %v", name, adminService.Hostname),
+			Message: err.Error(),
+			Fields:  map[string]string{"job": name},
+		})
+	}
+	addJob := func(name string, schedule string, run func(ctx context.Context) error) {
+		err := k.scheduler.Add(name, schedule, run)
 		if err != nil {
-			logutil.Error("Failed to backup entities DB, error was %v", err)
+			logutil.Fatal("Failed to schedule job", "job", name, "error", err)
 		}
-	})
-	c.Start()
+	}
 
-	// Migrate
-	if fu.MigrateDB {
-		migrate.Migrate(artistRepository, artistService, fu.Secret)
+	addJob(backupJob, cfg.Schedule.Backup, func(ctx context.Context) error {
+		_, err := adminService.Backup(ctx, models.BackupTriggerScheduled)
+		return err
+	})
+	if k.replicator != nil {
+		addJob("wal-replication", cfg.Schedule.WALSync, k.replicator.Sync)
+	}
+	addJob("integrity-check", cfg.Schedule.IntegrityCheck, func(context.Context) error {
+		return adminService.CheckDatabase()
+	})
+	if k.keyring != nil {
+		// Pick up newly added keys for rotation
+		addJob("keyring-reload", cfg.Schedule.KeyringReload, func(context.Context) error {
+			return k.keyring.Reload()
+		})
 	}
+	addJob("token-cleanup", cfg.Schedule.TokenCleanup, func(context.Context) error {
+		deleted, err := k.tokenDenylist.Cleanup()
+		if err != nil {
+			return err
+		}
+		logutil.Info("Cleaned up expired revoked tokens", "deleted", deleted)
+		return nil
+	})
+	if !cfg.Log.StdoutOnly {
+		addJob("log-rotate", cfg.Schedule.LogRotate, func(context.Context) error {
+			return logutil.Rotate()
+		})
+	}
+	k.scheduler.Start()
+	adminController.Scheduler = k.scheduler
 
 	// Setup router
-	return router.ChiRouter().InitRouter(artistController, authController)
+	return router.ChiRouter().InitRouter(artistController, authController, apiKeyController,
+		adminController, healthController)
 }
 
-// Setup singleton
-var (
-	k             *kernel
-	containerOnce sync.Once
-)
+// newReplicator replicates to backup storage, compressed and encrypted like backups
+// Its files are staged with the backups, next to the database
+func newReplicator(cfg config.WAL, database interfaces.IWALDatabase, adminService *services.AdminService) *walship.Replicator {
+	return &walship.Replicator{
+		Database:         database,
+		StorageClient:    adminService.StorageClient,
+		Compressor:       adminService.Compressor,
+		Compression:      adminService.Compression,
+		Encryptor:        adminService.Encryptor,
+		SnapshotInterval: cfg.SnapshotInterval,
+		Retention:        cfg.Retention,
+		CheckpointSize:   walship.DefaultCheckpointSize,
+		Dir:              adminService.Dir,
+	}
+}
 
-func ServiceContainer() IServiceContainer {
-	if k == nil {
-		containerOnce.Do(func() {
-			k = &kernel{}
+// newNotifier sends to whichever channels are configured, with none nothing is sent
+func newNotifier(cfg config.Notify) *notifier.Notifier {
+	n := &notifier.Notifier{Retries: cfg.Retries, Backoff: notifier.DefaultBackoff, OnSuccess: cfg.OnSuccess}
+	if cfg.WebhookURL != "" {
+		n.Channels = append(n.Channels, &notifier.Webhook{URL: cfg.WebhookURL, Format: cfg.WebhookFormat})
+	}
+	if cfg.SMTPAddr != "" {
+		n.Channels = append(n.Channels, &notifier.Email{
+			Addr:     cfg.SMTPAddr,
+			From:     cfg.EmailFrom,
+			To:       cfg.EmailTo,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
 		})
 	}
-	return k
+	return n
+}
+
+// backupEncryptor loads the backup encryption keys, if any
+// A key file holds several keys so old backups can be restored after rotation,
+// otherwise the key is a single base64 key
+func backupEncryptor(cfg config.Backup) interfaces.IEncryptor {
+	if cfg.EncryptionKeyFile != "" {
+		encryptor, err := backupcrypt.Load(cfg.EncryptionKeyFile)
+		if err != nil {
+			logutil.Fatal("Failed to load backup encryption keys", "error", err)
+		}
+		return encryptor
+	}
+
+	if cfg.EncryptionKey == "" {
+		return nil
+	}
+	// Validated as base64 when the config was loaded
+	key, _ := base64.StdEncoding.DecodeString(cfg.EncryptionKey)
+	encryptor, err := backupcrypt.NewEncryptor(cfg.EncryptionKeyID, map[string][]byte{cfg.EncryptionKeyID: key})
+	if err != nil {
+		logutil.Fatal("Invalid BACKUP_ENCRYPTION_KEY", "error", err)
+	}
+	return encryptor
 }
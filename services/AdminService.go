@@ -1,15 +1,34 @@
 package services
 
 import (
+    "context"
+    "encoding/json"
+    "errors"
     "fmt"
+    "path/filepath"
+    "sort"
+    "sync"
     "time"
 
+    ce "github.com/apkatsikas/artist-entities/customerrors"
+    "github.com/apkatsikas/artist-entities/infrastructures/backupcrypt"
+    "github.com/apkatsikas/artist-entities/infrastructures/logutil"
+    "github.com/apkatsikas/artist-entities/infrastructures/metrics"
+    "github.com/apkatsikas/artist-entities/infrastructures/walship"
     "github.com/apkatsikas/artist-entities/interfaces"
+    "github.com/apkatsikas/artist-entities/models"
 )
 
 const (
     vacuumFileName = "vacuum.sqlite"
-    entitiesBackup = "entities-backup"
+    compressedFileName = "vacuum.sqlite.compressed"
+    encryptedFileName = "vacuum.sqlite.encrypted"
+    manifestFileName = "manifest.json"
+    listedManifestFileName = "listed-manifest.json"
+    downloadFileName = "restore.download"
+    decryptedFileName = "restore.decrypted"
+    restoreFileName = "restore.sqlite"
+    preRestoreBackup = "entities-pre-restore"
 )
 
 type AdminService struct {
@@ -17,46 +36,460 @@ type AdminService struct {
     FileUtil        interfaces.IFileUtil
     StorageClient   interfaces.IStorageClient
     Rules           interfaces.IAdminRules
+    BackupRunRepository interfaces.IBackupRunRepository
+    // Notifier is told how backups went, nil means nobody is
+    Notifier interfaces.INotifier
+    Compressor      interfaces.ICompressor
+    // Encryptor encrypts new backups, nil means they're uploaded in plaintext
+    Encryptor interfaces.IEncryptor
+    // Replicator rebuilds the database at a point in time from its WAL replica
+    Replicator interfaces.IReplicator
+    // Compression for new backups, empty means none
+    Compression string
+    // Hostname is recorded in manifests
+    Hostname string
+    // Migrators bring a restored database up to the current schema
+    Migrators []interfaces.IMigrator
+    // Dir is where backups and restores are staged, empty is the working directory
+    // It must be the database's directory, so a restore can be renamed over it atomically
+    Dir string
+
+    // Backups and restores must not overlap
+    mu sync.Mutex
+    // Listing can happen during a backup, but not alongside another listing
+    listMu sync.Mutex
+}
+
+// path is where a staged file goes
+func (as *AdminService) path(name string) string {
+    return filepath.Join(as.Dir, name)
+}
+
+func (as *AdminService) compression() string {
+    if as.Compression == "" {
+        return models.CompressionNone
+    }
+    return as.Compression
+}
+
+// Backup uploads a backup of the database and records how it went
+// Returns ErrBackupInProgress rather than waiting for another backup or restore
+func (as *AdminService) Backup(ctx context.Context, trigger string) (*models.BackupRun, error) {
+    if !as.mu.TryLock() {
+        return nil, ce.ErrBackupInProgress
+    }
+    defer as.mu.Unlock()
+
+    start := time.Now()
+    object, size, err := as.backup(ctx, start)
+
+    run := &models.BackupRun{
+        Trigger:    trigger,
+        Object:     object,
+        StartedAt:  start.UTC(),
+        DurationMs: time.Since(start).Milliseconds(),
+        Size:       size,
+    }
+    if err != nil {
+        run.Error = err.Error()
+    }
+    recordErr := as.BackupRunRepository.Create(run)
+    if recordErr != nil {
+        logutil.Error("Failed to record backup run", "error", recordErr)
+    }
+    as.notify(run)
+    metrics.ObserveBackup(run)
+
+    return run, err
+}
+
+func (as *AdminService) notify(run *models.BackupRun) {
+    if as.Notifier == nil {
+        return
+    }
+
+    notification := models.Notification{
+        Event:   models.EventBackupSucceeded,
+        Subject: fmt.Sprintf("Backup succeeded on %v", as.Hostname),
+        Message: fmt.Sprintf("Backed up to %v", run.Object),
+        Time:    run.StartedAt,
+        Fields: map[string]string{
+            "trigger":  run.Trigger,
+            "duration": (time.Duration(run.DurationMs) * time.Millisecond).String(),
+        },
+    }
+    if !run.Succeeded() {
+        notification.Event = models.EventBackupFailed
+        notification.Failure = true
+        notification.Subject = fmt.Sprintf("Backup failed on %v", as.Hostname)
+        notification.Message = run.Error
+    }
+    if run.Object != "" {
+        notification.Fields["object"] = run.Object
+    }
+    as.Notifier.Notify(notification)
+}
+
+// LastBackupRun returns the most recent backup's outcome
+// Returns ErrRecordNotFound if there hasn't been one
+func (as *AdminService) LastBackupRun() (*models.BackupRun, error) {
+    return as.BackupRunRepository.Last()
 }
 
-func (as *AdminService) Backup() error {
+// backup returns the name and size of the object it uploaded
+func (as *AdminService) backup(ctx context.Context, start time.Time) (string, int64, error) {
     // Remove existing vacuum file first
-    err := as.FileUtil.DeleteIfExists(vacuumFileName)
+    err := as.FileUtil.DeleteIfExists(as.path(vacuumFileName))
     if err != nil {
-        return err
+        return "", 0, err
     }
 
     // Back up the DB
-    err = as.AdminRepository.CreateBackup(vacuumFileName)
+    err = as.AdminRepository.CreateBackup(as.path(vacuumFileName))
     if err != nil {
-        return err
+        return "", 0, err
+    }
+
+    // Describe it
+    artists, users, err := as.AdminRepository.Counts(as.path(vacuumFileName))
+    if err != nil {
+        return "", 0, err
+    }
+    databaseSum, databaseSize, err := as.FileUtil.Checksum(as.path(vacuumFileName))
+    if err != nil {
+        return "", 0, err
+    }
+
+    // Compress
+    compression := as.compression()
+    uploadFileName := as.path(vacuumFileName)
+    if compression != models.CompressionNone {
+        err = as.Compressor.Compress(as.path(vacuumFileName), as.path(compressedFileName), compression)
+        if err != nil {
+            return "", 0, err
+        }
+        uploadFileName = as.path(compressedFileName)
+    }
+
+    // Encrypt
+    encryption, keyID, extension := "", "", ""
+    if as.Encryptor != nil {
+        keyID, err = as.Encryptor.Encrypt(uploadFileName, as.path(encryptedFileName))
+        if err != nil {
+            return "", 0, err
+        }
+        uploadFileName = as.path(encryptedFileName)
+        encryption, extension = backupcrypt.Algorithm, models.EncryptionExtension
+    }
+    sum, size, err := as.FileUtil.Checksum(uploadFileName)
+    if err != nil {
+        return "", 0, err
     }
 
     // Get timestamped file name
-    timestamp := time.Now().Unix()
-    fileName := fmt.Sprintf("%v%v.sqlite", entitiesBackup, timestamp)
+    timestamp := start.Unix()
+    fileName := fmt.Sprintf("%v%v.sqlite%v%v", models.BackupPrefix, timestamp,
+        models.CompressionExtension(compression), extension)
 
-    // Upload file
-    err = as.StorageClient.UploadFile(vacuumFileName, fileName)
+    manifest, err := json.MarshalIndent(models.BackupManifest{
+        Object:         fileName,
+        SchemaVersion:  models.SchemaVersion,
+        Compression:    compression,
+        Encryption:     encryption,
+        KeyID:          keyID,
+        SHA256:         sum,
+        Size:           size,
+        DatabaseSHA256: databaseSum,
+        DatabaseSize:   databaseSize,
+        Artists:        artists,
+        Users:          users,
+        SourceHost:     as.Hostname,
+        CreatedAt:      start.UTC(),
+        DurationMs:     time.Since(start).Milliseconds(),
+    }, "", "  ")
     if err != nil {
-        return err
+        return "", 0, err
+    }
+    err = as.FileUtil.WriteFile(as.path(manifestFileName), manifest)
+    if err != nil {
+        return "", 0, err
+    }
+
+    // Upload file, then its manifest
+    err = as.StorageClient.UploadFile(ctx, uploadFileName, fileName)
+    if err != nil {
+        return "", 0, err
+    }
+    err = as.StorageClient.UploadFile(ctx, as.path(manifestFileName), fileName+models.ManifestSuffix)
+    if err != nil {
+        return fileName, size, err
     }
 
     // List files
-    files, err := as.StorageClient.ListFiles()
+    files, err := as.StorageClient.ListFiles(ctx)
+    if err != nil {
+        return fileName, size, err
+    }
+    objects := map[string]bool{}
+    for _, file := range files {
+        objects[file.Name] = true
+    }
+
+    // Delete whatever the retention policy doesn't keep, with its manifest
+    for _, fileToDelete := range as.Rules.FilesToDelete(files) {
+        err = as.StorageClient.DeleteFile(ctx, fileToDelete)
+        if err != nil {
+            return fileName, size, err
+        }
+        if objects[fileToDelete+models.ManifestSuffix] {
+            err = as.StorageClient.DeleteFile(ctx, fileToDelete + models.ManifestSuffix)
+            if err != nil {
+                return fileName, size, err
+            }
+        }
+    }
+
+    return fileName, size, nil
+}
+
+// readManifest downloads a backup's manifest
+func (as *AdminService) readManifest(ctx context.Context, name string) (*models.BackupManifest, error) {
+    err := as.StorageClient.DownloadFile(ctx, name+models.ManifestSuffix, as.path(listedManifestFileName))
+    if err != nil {
+        return nil, err
+    }
+    data, err := as.FileUtil.ReadFile(as.path(listedManifestFileName))
+    if err != nil {
+        return nil, err
+    }
+
+    var manifest models.BackupManifest
+    err = json.Unmarshal(data, &manifest)
+    if err != nil {
+        return nil, err
+    }
+    return &manifest, nil
+}
+
+func (as *AdminService) listBackups(ctx context.Context) ([]models.Backup, error) {
+    as.listMu.Lock()
+    defer as.listMu.Unlock()
+
+    files, err := as.StorageClient.ListFiles(ctx)
+    if err != nil {
+        return nil, err
+    }
+    objects := map[string]bool{}
+    for _, file := range files {
+        objects[file.Name] = true
+    }
+
+    // Ignore anything else in the bucket
+    backups := []models.Backup{}
+    for _, file := range files {
+        if !models.IsBackup(file.Name) {
+            continue
+        }
+
+        backup := models.Backup{BackupFile: file, Status: models.BackupUnverified}
+        // Backups from before manifests can't be checked
+        if objects[file.Name+models.ManifestSuffix] {
+            manifest, err := as.readManifest(ctx, file.Name)
+            if err != nil || manifest.Object != file.Name || manifest.Size != file.Size {
+                backup.Status = models.BackupCorrupt
+            } else {
+                backup.Status = models.BackupOK
+            }
+            backup.Manifest = manifest
+        }
+        backups = append(backups, backup)
+    }
+
+    sort.Slice(backups, func(i, j int) bool {
+        return backups[i].Updated.After(backups[j].Updated)
+    })
+    return backups, nil
+}
+
+// ListBackups lists the backups in storage, newest first
+// Each is checked against its manifest's size, VerifyBackup checks the contents
+func (as *AdminService) ListBackups(ctx context.Context) ([]models.Backup, error) {
+    return as.listBackups(ctx)
+}
+
+// fetch downloads a backup to restoreFileName, checking it along the way
+// Returns ErrRecordNotFound for an unknown backup and ErrDataInvalid for a corrupt one
+func (as *AdminService) fetch(ctx context.Context, name string) error {
+    // Only fetch something we listed
+    backups, err := as.listBackups(ctx)
+    if err != nil {
+        return err
+    }
+    var backup *models.Backup
+    for i := range backups {
+        if backups[i].Name == name {
+            backup = &backups[i]
+            break
+        }
+    }
+    if backup == nil {
+        return ce.ErrRecordNotFound
+    }
+    if backup.Status == models.BackupCorrupt {
+        return fmt.Errorf("%w: %v doesn't match its manifest", ce.ErrDataInvalid, name)
+    }
+
+    compression := models.CompressionFor(name)
+    encrypted := models.IsEncrypted(name)
+    if backup.Manifest != nil {
+        compression = backup.Manifest.Compression
+        encrypted = backup.Manifest.Encryption != ""
+    }
+    compressed := compression != models.CompressionNone
+
+    // Download next to the database so it can be swapped in atomically
+    err = as.FileUtil.DeleteIfExists(as.path(restoreFileName))
     if err != nil {
         return err
     }
+    downloaded := as.path(restoreFileName)
+    if compressed || encrypted {
+        downloaded = as.path(downloadFileName)
+        defer as.FileUtil.DeleteIfExists(as.path(downloadFileName))
+    }
+    err = as.StorageClient.DownloadFile(ctx, name, downloaded)
+    if err != nil {
+        return err
+    }
+
+    if backup.Manifest != nil {
+        sum, _, err := as.FileUtil.Checksum(downloaded)
+        if err != nil {
+            return err
+        }
+        if sum != backup.Manifest.SHA256 {
+            return fmt.Errorf("%w: %v checksum doesn't match its manifest", ce.ErrDataInvalid, name)
+        }
+    }
+
+    // The key is picked from the backup's header, so rotated keys still work
+    decrypted := downloaded
+    if encrypted {
+        if as.Encryptor == nil {
+            return fmt.Errorf("%v is encrypted but no backup encryption keys are configured", name)
+        }
+        decrypted = as.path(restoreFileName)
+        if compressed {
+            decrypted = as.path(decryptedFileName)
+            defer as.FileUtil.DeleteIfExists(as.path(decryptedFileName))
+        }
+        err = as.Encryptor.Decrypt(downloaded, decrypted)
+        if errors.Is(err, backupcrypt.ErrCorrupt) {
+            return fmt.Errorf("%w: %v", ce.ErrDataInvalid, err)
+        }
+        if err != nil {
+            return err
+        }
+    }
 
-    // Determine if we need to delete
-    fileToDelete := as.Rules.FileToDelete(files)
-    if fileToDelete != "" {
-        // Delete
-        err = as.StorageClient.DeleteFile(fileToDelete)
+    if compressed {
+        err = as.Compressor.Decompress(decrypted, as.path(restoreFileName), compression)
+        if err != nil {
+            return fmt.Errorf("%w: %v", ce.ErrDataInvalid, err)
+        }
+    }
+
+    if backup.Manifest != nil && downloaded != as.path(restoreFileName) {
+        sum, _, err := as.FileUtil.Checksum(as.path(restoreFileName))
         if err != nil {
             return err
         }
+        if sum != backup.Manifest.DatabaseSHA256 {
+            return fmt.Errorf("%w: %v database checksum doesn't match its manifest", ce.ErrDataInvalid, name)
+        }
+    }
+
+    return as.AdminRepository.CheckIntegrity(as.path(restoreFileName))
+}
+
+// VerifyBackup downloads a backup and checks it fully
+func (as *AdminService) VerifyBackup(ctx context.Context, name string) error {
+    as.mu.Lock()
+    defer as.mu.Unlock()
+
+    defer as.FileUtil.DeleteIfExists(as.path(restoreFileName))
+    return as.fetch(ctx, name)
+}
+
+// CheckDatabase checks the live database for corruption
+func (as *AdminService) CheckDatabase() error {
+    return as.AdminRepository.CheckDatabase()
+}
+
+// Restore replaces the database with a backup from storage
+// The current database is first copied to a local file, whose name is returned
+// Returns ErrRecordNotFound for an unknown backup and ErrDataInvalid for a corrupt one
+func (as *AdminService) Restore(ctx context.Context, name string) (string, error) {
+    as.mu.Lock()
+    defer as.mu.Unlock()
+
+    err := as.fetch(ctx, name)
+    if err != nil {
+        as.FileUtil.DeleteIfExists(as.path(restoreFileName))
+        return "", err
+    }
+    return as.swapIn()
+}
+
+// RestoreToTime replaces the database with its WAL replica as it was at a point in time
+// Returns the safety copy and when the last change restored was shipped
+// Returns ErrRecordNotFound if replication hadn't started by then
+func (as *AdminService) RestoreToTime(ctx context.Context, at time.Time) (string, time.Time, error) {
+    as.mu.Lock()
+    defer as.mu.Unlock()
+
+    err := as.FileUtil.DeleteIfExists(as.path(restoreFileName))
+    if err != nil {
+        return "", time.Time{}, err
+    }
+    restoredTo, err := as.Replicator.Reconstruct(ctx, at, as.path(restoreFileName))
+    if err == nil {
+        err = as.AdminRepository.CheckIntegrity(as.path(restoreFileName))
+    }
+    if errors.Is(err, walship.ErrNoReplica) {
+        err = ce.ErrRecordNotFound
+    }
+    if err != nil {
+        as.FileUtil.DeleteIfExists(as.path(restoreFileName))
+        return "", time.Time{}, err
+    }
+
+    safetyCopy, err := as.swapIn()
+    return safetyCopy, restoredTo, err
+}
+
+// swapIn replaces the database with restoreFileName, returning the safety copy
+func (as *AdminService) swapIn() (string, error) {
+    // Keep the current database in case the backup isn't what was wanted
+    safetyCopy := as.path(fmt.Sprintf("%v%v.sqlite", preRestoreBackup, time.Now().Unix()))
+    err := as.AdminRepository.CreateBackup(safetyCopy)
+    if err != nil {
+        as.FileUtil.DeleteIfExists(as.path(restoreFileName))
+        return "", err
+    }
+
+    err = as.AdminRepository.Restore(as.path(restoreFileName))
+    if err != nil {
+        return "", err
+    }
+
+    // The backup may predate newer tables and columns
+    for _, migrator := range as.Migrators {
+        err = migrator.Migrate()
+        if err != nil {
+            return safetyCopy, err
+        }
     }
 
-    return nil
+    return safetyCopy, nil
 }
@@ -1,33 +1,41 @@
 package services
 
 import (
+    "context"
+    "encoding/json"
     "errors"
     "fmt"
+    "strings"
     "testing"
     "time"
 
+    ce "github.com/apkatsikas/artist-entities/customerrors"
+    "github.com/apkatsikas/artist-entities/infrastructures/backupcrypt"
+    "github.com/apkatsikas/artist-entities/infrastructures/walship"
     "github.com/apkatsikas/artist-entities/interfaces/mocks"
-    sc "github.com/apkatsikas/artist-entities/storageclient"
+    "github.com/apkatsikas/artist-entities/models"
     "github.com/stretchr/testify/assert"
     "github.com/stretchr/testify/mock"
 )
 
 const ss = "string"
 
-func happyPathFiles() []sc.BackupFile {
+var ctx = context.Background()
+
+func happyPathFiles() []models.BackupFile {
     // Data
     now := time.Now()
     yesterday := now.AddDate(0, 0, -1)
     twoDaysAgo := now.AddDate(0, 0, -2)
-    oldestName := "twodaysago.sqlite"
+    oldestName := "entities-backup-twodaysago.sqlite"
 
-    return []sc.BackupFile{
+    return []models.BackupFile{
         {
-            Name:    "yesterday.sqlite",
+            Name:    "entities-backup-yesterday.sqlite",
             Updated: yesterday,
         },
         {
-            Name:    "coolfile.sqlite",
+            Name:    "entities-backup-coolfile.sqlite",
             Updated: now,
         },
         {
@@ -42,6 +50,8 @@ type adminServiceTestMocks struct {
     *mocks.IStorageClient
     *mocks.IFileUtil
     *mocks.IAdminRules
+    *mocks.ICompressor
+    *mocks.IBackupRunRepository
 }
 
 func adminServiceReqMocks(t *testing.T) adminServiceTestMocks {
@@ -50,6 +60,8 @@ func adminServiceReqMocks(t *testing.T) adminServiceTestMocks {
         IStorageClient:   mocks.NewIStorageClient(t),
         IFileUtil:        mocks.NewIFileUtil(t),
         IAdminRules:      mocks.NewIAdminRules(t),
+        ICompressor:      mocks.NewICompressor(t),
+        IBackupRunRepository: mocks.NewIBackupRunRepository(t),
     }
 }
 
@@ -59,9 +71,19 @@ func injectedAdminService(mocks adminServiceTestMocks) AdminService {
         StorageClient:   mocks.IStorageClient,
         FileUtil:        mocks.IFileUtil,
         Rules:           mocks.IAdminRules,
+        Compressor:      mocks.ICompressor,
+        BackupRunRepository: mocks.IBackupRunRepository,
     }
 }
 
+// expectDescribed expects the vacuum file to be counted, checksummed and
+// have its manifest written
+func expectDescribed(mocks adminServiceTestMocks) {
+    mocks.IAdminRepository.EXPECT().Counts(vacuumFileName).Return(10, 1, nil)
+    mocks.IFileUtil.EXPECT().Checksum(vacuumFileName).Return("abc", 100, nil)
+    mocks.IFileUtil.EXPECT().WriteFile(manifestFileName, mock.Anything).Return(nil)
+}
+
 func TestBackup(t *testing.T) {
     // Data
     bucketFiles := happyPathFiles()
@@ -81,16 +103,20 @@ func TestBackup(t *testing.T) {
     // We delete the oldest one successfully
     mocks.IFileUtil.EXPECT().DeleteIfExists(file).Return(nil)
     mocks.IAdminRepository.EXPECT().CreateBackup(vacuumFileName).Return(nil)
-    mocks.IStorageClient.EXPECT().UploadFile(vacuumFileName, mock.AnythingOfType(ss)).Return(nil)
-    mocks.IStorageClient.EXPECT().ListFiles().Return(bucketFiles, nil)
-    mocks.IAdminRules.EXPECT().FileToDelete(bucketFiles).Return(oldestName)
-    mocks.IStorageClient.EXPECT().DeleteFile(oldestName).Return(nil)
+    expectDescribed(mocks)
+    mocks.IStorageClient.EXPECT().UploadFile(mock.Anything, vacuumFileName, mock.AnythingOfType(ss)).Return(nil)
+    mocks.IStorageClient.EXPECT().UploadFile(mock.Anything, manifestFileName, mock.AnythingOfType(ss)).Return(nil)
+    mocks.IStorageClient.EXPECT().ListFiles(mock.Anything).Return(bucketFiles, nil)
+    mocks.IAdminRules.EXPECT().FilesToDelete(bucketFiles).Return([]string{oldestName})
+    mocks.IStorageClient.EXPECT().DeleteFile(mock.Anything, oldestName).Return(nil)
+
+    mocks.IBackupRunRepository.EXPECT().Create(mock.Anything).Return(nil)
 
     // Inject service
     adminService := injectedAdminService(mocks)
 
     // Backup
-    err := adminService.Backup()
+    _, err := adminService.Backup(ctx, models.BackupTriggerScheduled)
 
     // Check that there is no error
     assert.Nil(t, err)
@@ -99,7 +125,6 @@ func TestBackup(t *testing.T) {
 func TestBackupNoDelete(t *testing.T) {
     // Data
     bucketFiles := happyPathFiles()
-    fileToDelete := ""
 
     file := vacuumFileName
 
@@ -114,15 +139,48 @@ func TestBackupNoDelete(t *testing.T) {
     // We delete the oldest one successfully
     mocks.IFileUtil.EXPECT().DeleteIfExists(file).Return(nil)
     mocks.IAdminRepository.EXPECT().CreateBackup(vacuumFileName).Return(nil)
-    mocks.IStorageClient.EXPECT().UploadFile(vacuumFileName, mock.AnythingOfType(ss)).Return(nil)
-    mocks.IStorageClient.EXPECT().ListFiles().Return(bucketFiles, nil)
-    mocks.IAdminRules.EXPECT().FileToDelete(bucketFiles).Return(fileToDelete)
+    expectDescribed(mocks)
+    mocks.IStorageClient.EXPECT().UploadFile(mock.Anything, vacuumFileName, mock.AnythingOfType(ss)).Return(nil)
+    mocks.IStorageClient.EXPECT().UploadFile(mock.Anything, manifestFileName, mock.AnythingOfType(ss)).Return(nil)
+    mocks.IStorageClient.EXPECT().ListFiles(mock.Anything).Return(bucketFiles, nil)
+    mocks.IAdminRules.EXPECT().FilesToDelete(bucketFiles).Return(nil)
+
+    mocks.IBackupRunRepository.EXPECT().Create(mock.Anything).Return(nil)
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+
+    // Backup
+    _, err := adminService.Backup(ctx, models.BackupTriggerScheduled)
+
+    // Check that there is no error
+    assert.Nil(t, err)
+}
+
+func TestBackupDeletesEveryExpiredFile(t *testing.T) {
+    // Data
+    bucketFiles := happyPathFiles()
+    expired := []string{"entities-backup-twodaysago.sqlite", "entities-backup-yesterday.sqlite"}
+
+    // Setup mocks
+    mocks := adminServiceReqMocks(t)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(vacuumFileName).Return(nil)
+    mocks.IAdminRepository.EXPECT().CreateBackup(vacuumFileName).Return(nil)
+    expectDescribed(mocks)
+    mocks.IStorageClient.EXPECT().UploadFile(mock.Anything, vacuumFileName, mock.AnythingOfType(ss)).Return(nil)
+    mocks.IStorageClient.EXPECT().UploadFile(mock.Anything, manifestFileName, mock.AnythingOfType(ss)).Return(nil)
+    mocks.IStorageClient.EXPECT().ListFiles(mock.Anything).Return(bucketFiles, nil)
+    mocks.IAdminRules.EXPECT().FilesToDelete(bucketFiles).Return(expired)
+    mocks.IStorageClient.EXPECT().DeleteFile(mock.Anything, expired[0]).Return(nil)
+    mocks.IStorageClient.EXPECT().DeleteFile(mock.Anything, expired[1]).Return(nil)
+
+    mocks.IBackupRunRepository.EXPECT().Create(mock.Anything).Return(nil)
 
     // Inject service
     adminService := injectedAdminService(mocks)
 
     // Backup
-    err := adminService.Backup()
+    _, err := adminService.Backup(ctx, models.BackupTriggerScheduled)
 
     // Check that there is no error
     assert.Nil(t, err)
@@ -137,11 +195,13 @@ func TestBackupFileUtilFails(t *testing.T) {
     mocks := adminServiceReqMocks(t)
     mocks.IFileUtil.EXPECT().DeleteIfExists(file).Return(expectedError)
 
+    mocks.IBackupRunRepository.EXPECT().Create(mock.Anything).Return(nil)
+
     // Inject service
     adminService := injectedAdminService(mocks)
 
     // Backup
-    err := adminService.Backup()
+    _, err := adminService.Backup(ctx, models.BackupTriggerScheduled)
 
     // Check error
     assert.True(t, errors.Is(err, expectedError))
@@ -158,11 +218,13 @@ func TestBackupAdminFails(t *testing.T) {
     // Fail
     mocks.IAdminRepository.EXPECT().CreateBackup(vacuumFileName).Return(expectedError)
 
+    mocks.IBackupRunRepository.EXPECT().Create(mock.Anything).Return(nil)
+
     // Inject service
     adminService := injectedAdminService(mocks)
 
     // Backup
-    err := adminService.Backup()
+    _, err := adminService.Backup(ctx, models.BackupTriggerScheduled)
 
     // Check error
     assert.True(t, errors.Is(err, expectedError))
@@ -177,14 +239,17 @@ func TestBackupUploadStorageFails(t *testing.T) {
     mocks := adminServiceReqMocks(t)
     mocks.IFileUtil.EXPECT().DeleteIfExists(file).Return(nil)
     mocks.IAdminRepository.EXPECT().CreateBackup(vacuumFileName).Return(nil)
+    expectDescribed(mocks)
     // Fail
-    mocks.IStorageClient.EXPECT().UploadFile(vacuumFileName, mock.AnythingOfType(ss)).Return(expectedError)
+    mocks.IStorageClient.EXPECT().UploadFile(mock.Anything, vacuumFileName, mock.AnythingOfType(ss)).Return(expectedError)
+
+    mocks.IBackupRunRepository.EXPECT().Create(mock.Anything).Return(nil)
 
     // Inject service
     adminService := injectedAdminService(mocks)
 
     // Backup
-    err := adminService.Backup()
+    _, err := adminService.Backup(ctx, models.BackupTriggerScheduled)
 
     // Check error
     assert.True(t, errors.Is(err, expectedError))
@@ -199,15 +264,19 @@ func TestBackupListStorageFails(t *testing.T) {
     mocks := adminServiceReqMocks(t)
     mocks.IFileUtil.EXPECT().DeleteIfExists(file).Return(nil)
     mocks.IAdminRepository.EXPECT().CreateBackup(vacuumFileName).Return(nil)
-    mocks.IStorageClient.EXPECT().UploadFile(vacuumFileName, mock.AnythingOfType(ss)).Return(nil)
+    expectDescribed(mocks)
+    mocks.IStorageClient.EXPECT().UploadFile(mock.Anything, vacuumFileName, mock.AnythingOfType(ss)).Return(nil)
+    mocks.IStorageClient.EXPECT().UploadFile(mock.Anything, manifestFileName, mock.AnythingOfType(ss)).Return(nil)
     // Fail
-    mocks.IStorageClient.EXPECT().ListFiles().Return(nil, expectedError)
+    mocks.IStorageClient.EXPECT().ListFiles(mock.Anything).Return(nil, expectedError)
+
+    mocks.IBackupRunRepository.EXPECT().Create(mock.Anything).Return(nil)
 
     // Inject service
     adminService := injectedAdminService(mocks)
 
     // Backup
-    err := adminService.Backup()
+    _, err := adminService.Backup(ctx, models.BackupTriggerScheduled)
 
     // Check error
     assert.True(t, errors.Is(err, expectedError))
@@ -224,18 +293,729 @@ func TestBackupDeleteStorageFails(t *testing.T) {
     mocks := adminServiceReqMocks(t)
     mocks.IFileUtil.EXPECT().DeleteIfExists(file).Return(nil)
     mocks.IAdminRepository.EXPECT().CreateBackup(vacuumFileName).Return(nil)
-    mocks.IStorageClient.EXPECT().UploadFile(vacuumFileName, mock.AnythingOfType(ss)).Return(nil)
-    mocks.IStorageClient.EXPECT().ListFiles().Return(bucketFiles, nil)
-    mocks.IAdminRules.EXPECT().FileToDelete(bucketFiles).Return(oldest)
+    expectDescribed(mocks)
+    mocks.IStorageClient.EXPECT().UploadFile(mock.Anything, vacuumFileName, mock.AnythingOfType(ss)).Return(nil)
+    mocks.IStorageClient.EXPECT().UploadFile(mock.Anything, manifestFileName, mock.AnythingOfType(ss)).Return(nil)
+    mocks.IStorageClient.EXPECT().ListFiles(mock.Anything).Return(bucketFiles, nil)
+    mocks.IAdminRules.EXPECT().FilesToDelete(bucketFiles).Return([]string{oldest})
     // Fail
-    mocks.IStorageClient.EXPECT().DeleteFile(oldest).Return(expectedError)
+    mocks.IStorageClient.EXPECT().DeleteFile(mock.Anything, oldest).Return(expectedError)
+
+    mocks.IBackupRunRepository.EXPECT().Create(mock.Anything).Return(nil)
 
     // Inject service
     adminService := injectedAdminService(mocks)
 
     // Backup
-    err := adminService.Backup()
+    _, err := adminService.Backup(ctx, models.BackupTriggerScheduled)
 
     // Check error
     assert.True(t, errors.Is(err, expectedError))
 }
+
+func TestBackupCompressed(t *testing.T) {
+    // Data
+    bucketFiles := happyPathFiles()
+    var manifest models.BackupManifest
+
+    // Setup mocks
+    mocks := adminServiceReqMocks(t)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(vacuumFileName).Return(nil)
+    mocks.IAdminRepository.EXPECT().CreateBackup(vacuumFileName).Return(nil)
+    mocks.IAdminRepository.EXPECT().Counts(vacuumFileName).Return(10, 1, nil)
+    mocks.IFileUtil.EXPECT().Checksum(vacuumFileName).Return("abc", 100, nil)
+    mocks.ICompressor.EXPECT().Compress(vacuumFileName, compressedFileName, models.CompressionZstd).Return(nil)
+    mocks.IFileUtil.EXPECT().Checksum(compressedFileName).Return("def", 40, nil)
+    mocks.IFileUtil.EXPECT().WriteFile(manifestFileName, mock.Anything).
+        Run(func(file string, data []byte) {
+            assert.Nil(t, json.Unmarshal(data, &manifest))
+        }).Return(nil)
+    mocks.IStorageClient.EXPECT().UploadFile(mock.Anything, compressedFileName, mock.MatchedBy(func(object string) bool {
+        return strings.HasPrefix(object, models.BackupPrefix) && strings.HasSuffix(object, ".sqlite.zst")
+    })).Return(nil)
+    mocks.IStorageClient.EXPECT().UploadFile(mock.Anything, manifestFileName, mock.MatchedBy(func(object string) bool {
+        return strings.HasSuffix(object, ".sqlite.zst"+models.ManifestSuffix)
+    })).Return(nil)
+    mocks.IStorageClient.EXPECT().ListFiles(mock.Anything).Return(bucketFiles, nil)
+    mocks.IAdminRules.EXPECT().FilesToDelete(bucketFiles).Return(nil)
+
+    mocks.IBackupRunRepository.EXPECT().Create(mock.Anything).Return(nil)
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+    adminService.Compression = models.CompressionZstd
+    adminService.Hostname = "host"
+
+    // Backup
+    run, err := adminService.Backup(ctx, models.BackupTriggerScheduled)
+
+    // Check the manifest describes the backup
+    assert.Nil(t, err)
+    assert.Equal(t, int64(40), run.Size)
+    assert.Equal(t, models.CompressionZstd, manifest.Compression)
+    assert.Equal(t, "def", manifest.SHA256)
+    assert.Equal(t, int64(40), manifest.Size)
+    assert.Equal(t, "abc", manifest.DatabaseSHA256)
+    assert.Equal(t, int64(100), manifest.DatabaseSize)
+    assert.Equal(t, int64(10), manifest.Artists)
+    assert.Equal(t, int64(1), manifest.Users)
+    assert.Equal(t, "host", manifest.SourceHost)
+    assert.Equal(t, models.SchemaVersion, manifest.SchemaVersion)
+    assert.True(t, strings.HasSuffix(manifest.Object, ".sqlite.zst"))
+}
+
+func TestBackupDeletesManifests(t *testing.T) {
+    // Data
+    oldest := "entities-backup1.sqlite.gz"
+    legacy := "entities-backup0.sqlite"
+    bucketFiles := []models.BackupFile{
+        {Name: oldest}, {Name: oldest + models.ManifestSuffix}, {Name: legacy},
+    }
+
+    // Setup mocks
+    mocks := adminServiceReqMocks(t)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(vacuumFileName).Return(nil)
+    mocks.IAdminRepository.EXPECT().CreateBackup(vacuumFileName).Return(nil)
+    expectDescribed(mocks)
+    mocks.IStorageClient.EXPECT().UploadFile(mock.Anything, vacuumFileName, mock.AnythingOfType(ss)).Return(nil)
+    mocks.IStorageClient.EXPECT().UploadFile(mock.Anything, manifestFileName, mock.AnythingOfType(ss)).Return(nil)
+    mocks.IStorageClient.EXPECT().ListFiles(mock.Anything).Return(bucketFiles, nil)
+    mocks.IAdminRules.EXPECT().FilesToDelete(bucketFiles).Return([]string{legacy, oldest})
+    // Backups from before manifests don't have one to delete
+    mocks.IStorageClient.EXPECT().DeleteFile(mock.Anything, legacy).Return(nil)
+    mocks.IStorageClient.EXPECT().DeleteFile(mock.Anything, oldest).Return(nil)
+    mocks.IStorageClient.EXPECT().DeleteFile(mock.Anything, oldest + models.ManifestSuffix).Return(nil)
+
+    mocks.IBackupRunRepository.EXPECT().Create(mock.Anything).Return(nil)
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+
+    // Backup
+    _, err := adminService.Backup(ctx, models.BackupTriggerScheduled)
+
+    // Check that there is no error
+    assert.Nil(t, err)
+}
+
+// manifestFor returns a manifest as uploaded with a backup
+func manifestFor(file models.BackupFile, compression string) []byte {
+    data, _ := json.Marshal(models.BackupManifest{
+        Object:         file.Name,
+        Compression:    compression,
+        SHA256:         "object-sum",
+        Size:           file.Size,
+        DatabaseSHA256: "database-sum",
+    })
+    return data
+}
+
+// expectManifest expects a backup's manifest to be downloaded and read
+func expectManifest(mocks adminServiceTestMocks, name string, manifest []byte) {
+    mocks.IStorageClient.EXPECT().DownloadFile(mock.Anything, name+models.ManifestSuffix, listedManifestFileName).Return(nil)
+    mocks.IFileUtil.EXPECT().ReadFile(listedManifestFileName).Return(manifest, nil).Once()
+}
+
+// verifiedBackup is a gzipped backup with a manifest
+func verifiedBackup(mocks adminServiceTestMocks) models.BackupFile {
+    backup := models.BackupFile{Name: "entities-backup2.sqlite.gz", Updated: time.Now(), Size: 40}
+    mocks.IStorageClient.EXPECT().ListFiles(mock.Anything).Return([]models.BackupFile{
+        backup, {Name: backup.Name + models.ManifestSuffix, Updated: time.Now()},
+    }, nil)
+    expectManifest(mocks, backup.Name, manifestFor(backup, models.CompressionGzip))
+    return backup
+}
+
+func TestListBackups(t *testing.T) {
+    // Data
+    now := time.Now()
+    ok := models.BackupFile{Name: "entities-backup3.sqlite.gz", Updated: now, Size: 40}
+    truncated := models.BackupFile{Name: "entities-backup2.sqlite.gz", Updated: now.Add(-time.Hour), Size: 39}
+    legacy := models.BackupFile{Name: "entities-backup1.sqlite", Updated: now.Add(-2 * time.Hour), Size: 100}
+    unreadable := models.BackupFile{Name: "entities-backup0.sqlite.gz", Updated: now.Add(-3 * time.Hour), Size: 40}
+
+    // Setup mocks
+    mocks := adminServiceReqMocks(t)
+    mocks.IStorageClient.EXPECT().ListFiles(mock.Anything).Return([]models.BackupFile{
+        legacy, truncated, ok, unreadable,
+        {Name: ok.Name + models.ManifestSuffix},
+        {Name: truncated.Name + models.ManifestSuffix},
+        {Name: unreadable.Name + models.ManifestSuffix},
+        {Name: "unrelated.txt", Updated: now},
+    }, nil)
+    // Manifests are read in listing order
+    expectManifest(mocks, truncated.Name, manifestFor(models.BackupFile{Name: truncated.Name, Size: 40},
+        models.CompressionGzip))
+    expectManifest(mocks, ok.Name, manifestFor(ok, models.CompressionGzip))
+    expectManifest(mocks, unreadable.Name, []byte("{"))
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+
+    backups, err := adminService.ListBackups(ctx)
+
+    // Newest first, only backups are listed
+    assert.Nil(t, err)
+    assert.Len(t, backups, 4)
+    assert.Equal(t, ok.Name, backups[0].Name)
+    assert.Equal(t, models.BackupOK, backups[0].Status)
+    assert.Equal(t, "object-sum", backups[0].Manifest.SHA256)
+    assert.Equal(t, truncated.Name, backups[1].Name)
+    assert.Equal(t, models.BackupCorrupt, backups[1].Status)
+    assert.Equal(t, legacy.Name, backups[2].Name)
+    assert.Equal(t, models.BackupUnverified, backups[2].Status)
+    assert.Nil(t, backups[2].Manifest)
+    assert.Equal(t, unreadable.Name, backups[3].Name)
+    assert.Equal(t, models.BackupCorrupt, backups[3].Status)
+}
+
+func TestRestore(t *testing.T) {
+    // Setup mocks
+    migrator := mocks.NewIMigrator(t)
+    mocks := adminServiceReqMocks(t)
+    backup := verifiedBackup(mocks)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(restoreFileName).Return(nil)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(downloadFileName).Return(nil)
+    mocks.IStorageClient.EXPECT().DownloadFile(mock.Anything, backup.Name, downloadFileName).Return(nil)
+    mocks.IFileUtil.EXPECT().Checksum(downloadFileName).Return("object-sum", 40, nil)
+    mocks.ICompressor.EXPECT().Decompress(downloadFileName, restoreFileName, models.CompressionGzip).Return(nil)
+    mocks.IFileUtil.EXPECT().Checksum(restoreFileName).Return("database-sum", 100, nil)
+    mocks.IAdminRepository.EXPECT().CheckIntegrity(restoreFileName).Return(nil)
+    mocks.IAdminRepository.EXPECT().CreateBackup(mock.AnythingOfType(ss)).Return(nil)
+    mocks.IAdminRepository.EXPECT().Restore(restoreFileName).Return(nil)
+    migrator.EXPECT().Migrate().Return(nil)
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+    adminService.Migrators = append(adminService.Migrators, migrator)
+
+    safetyCopy, err := adminService.Restore(ctx, backup.Name)
+
+    assert.Nil(t, err)
+    assert.Regexp(t, `^entities-pre-restore\d+\.sqlite$`, safetyCopy)
+    mocks.IAdminRepository.AssertCalled(t, "CreateBackup", safetyCopy)
+}
+
+func TestRestoreStagesNextToDatabase(t *testing.T) {
+    // The database isn't in the working directory
+    dir := "/var/lib/entities"
+    staged := func(name string) string { return dir + "/" + name }
+
+    // Setup mocks
+    mocks := adminServiceReqMocks(t)
+    backup := models.BackupFile{Name: "entities-backup2.sqlite.gz", Updated: time.Now(), Size: 40}
+    mocks.IStorageClient.EXPECT().ListFiles(mock.Anything).Return([]models.BackupFile{
+        backup, {Name: backup.Name + models.ManifestSuffix, Updated: time.Now()},
+    }, nil)
+    mocks.IStorageClient.EXPECT().DownloadFile(mock.Anything, backup.Name+models.ManifestSuffix,
+        staged(listedManifestFileName)).Return(nil)
+    mocks.IFileUtil.EXPECT().ReadFile(staged(listedManifestFileName)).
+        Return(manifestFor(backup, models.CompressionGzip), nil)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(staged(restoreFileName)).Return(nil)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(staged(downloadFileName)).Return(nil)
+    mocks.IStorageClient.EXPECT().DownloadFile(mock.Anything, backup.Name, staged(downloadFileName)).Return(nil)
+    mocks.IFileUtil.EXPECT().Checksum(staged(downloadFileName)).Return("object-sum", 40, nil)
+    mocks.ICompressor.EXPECT().Decompress(staged(downloadFileName), staged(restoreFileName),
+        models.CompressionGzip).Return(nil)
+    mocks.IFileUtil.EXPECT().Checksum(staged(restoreFileName)).Return("database-sum", 100, nil)
+    mocks.IAdminRepository.EXPECT().CheckIntegrity(staged(restoreFileName)).Return(nil)
+    mocks.IAdminRepository.EXPECT().CreateBackup(mock.AnythingOfType(ss)).Return(nil)
+    mocks.IAdminRepository.EXPECT().Restore(staged(restoreFileName)).Return(nil)
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+    adminService.Dir = dir
+
+    safetyCopy, err := adminService.Restore(ctx, backup.Name)
+
+    // So the restore can be renamed over the database, and the safety copy is beside it
+    assert.Nil(t, err)
+    assert.Regexp(t, `^/var/lib/entities/entities-pre-restore\d+\.sqlite$`, safetyCopy)
+}
+
+func TestRestoreWithoutManifest(t *testing.T) {
+    // Data
+    name := "entities-backup-yesterday.sqlite"
+
+    // Setup mocks
+    mocks := adminServiceReqMocks(t)
+    mocks.IStorageClient.EXPECT().ListFiles(mock.Anything).Return(happyPathFiles(), nil)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(restoreFileName).Return(nil)
+    // Uncompressed, so downloaded straight to where it's restored from
+    mocks.IStorageClient.EXPECT().DownloadFile(mock.Anything, name, restoreFileName).Return(nil)
+    mocks.IAdminRepository.EXPECT().CheckIntegrity(restoreFileName).Return(nil)
+    mocks.IAdminRepository.EXPECT().CreateBackup(mock.AnythingOfType(ss)).Return(nil)
+    mocks.IAdminRepository.EXPECT().Restore(restoreFileName).Return(nil)
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+
+    _, err := adminService.Restore(ctx, name)
+
+    assert.Nil(t, err)
+}
+
+func TestRestoreUnknownBackup(t *testing.T) {
+    // Setup mocks
+    mocks := adminServiceReqMocks(t)
+    mocks.IStorageClient.EXPECT().ListFiles(mock.Anything).Return(happyPathFiles(), nil)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(restoreFileName).Return(nil)
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+
+    _, err := adminService.Restore(ctx, "../entities.db")
+
+    assert.True(t, errors.Is(err, ce.ErrRecordNotFound))
+}
+
+func TestRestoreChecksumMismatch(t *testing.T) {
+    // Setup mocks
+    mocks := adminServiceReqMocks(t)
+    backup := verifiedBackup(mocks)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(restoreFileName).Return(nil)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(downloadFileName).Return(nil)
+    mocks.IStorageClient.EXPECT().DownloadFile(mock.Anything, backup.Name, downloadFileName).Return(nil)
+    // Fail, the database is never touched
+    mocks.IFileUtil.EXPECT().Checksum(downloadFileName).Return("tampered", 40, nil)
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+
+    _, err := adminService.Restore(ctx, backup.Name)
+
+    assert.True(t, errors.Is(err, ce.ErrDataInvalid))
+}
+
+func TestRestoreCorruptBackup(t *testing.T) {
+    // Test data
+    name := "entities-backup-yesterday.sqlite"
+    expectedError := fmt.Errorf("%w: integrity check failed", ce.ErrDataInvalid)
+
+    // Setup mocks
+    mocks := adminServiceReqMocks(t)
+    mocks.IStorageClient.EXPECT().ListFiles(mock.Anything).Return(happyPathFiles(), nil)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(restoreFileName).Return(nil)
+    mocks.IStorageClient.EXPECT().DownloadFile(mock.Anything, name, restoreFileName).Return(nil)
+    // Fail, the database is never touched
+    mocks.IAdminRepository.EXPECT().CheckIntegrity(restoreFileName).Return(expectedError)
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+
+    _, err := adminService.Restore(ctx, name)
+
+    assert.True(t, errors.Is(err, ce.ErrDataInvalid))
+    mocks.IFileUtil.AssertNumberOfCalls(t, "DeleteIfExists", 2)
+}
+
+func TestRestoreDownloadFails(t *testing.T) {
+    // Test data
+    name := "entities-backup-yesterday.sqlite"
+    expectedError := fmt.Errorf("??")
+
+    // Setup mocks
+    mocks := adminServiceReqMocks(t)
+    mocks.IStorageClient.EXPECT().ListFiles(mock.Anything).Return(happyPathFiles(), nil)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(restoreFileName).Return(nil)
+    // Fail
+    mocks.IStorageClient.EXPECT().DownloadFile(mock.Anything, name, restoreFileName).Return(expectedError)
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+
+    _, err := adminService.Restore(ctx, name)
+
+    assert.True(t, errors.Is(err, expectedError))
+}
+
+func TestVerifyBackup(t *testing.T) {
+    // Setup mocks
+    mocks := adminServiceReqMocks(t)
+    backup := verifiedBackup(mocks)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(restoreFileName).Return(nil)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(downloadFileName).Return(nil)
+    mocks.IStorageClient.EXPECT().DownloadFile(mock.Anything, backup.Name, downloadFileName).Return(nil)
+    mocks.IFileUtil.EXPECT().Checksum(downloadFileName).Return("object-sum", 40, nil)
+    mocks.ICompressor.EXPECT().Decompress(downloadFileName, restoreFileName, models.CompressionGzip).Return(nil)
+    // Fail
+    mocks.IFileUtil.EXPECT().Checksum(restoreFileName).Return("tampered", 100, nil)
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+
+    err := adminService.VerifyBackup(ctx, backup.Name)
+
+    assert.True(t, errors.Is(err, ce.ErrDataInvalid))
+}
+
+func TestBackupEncrypted(t *testing.T) {
+    // Data
+    bucketFiles := happyPathFiles()
+    var manifest models.BackupManifest
+
+    // Setup mocks
+    encryptor := mocks.NewIEncryptor(t)
+    mocks := adminServiceReqMocks(t)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(vacuumFileName).Return(nil)
+    mocks.IAdminRepository.EXPECT().CreateBackup(vacuumFileName).Return(nil)
+    mocks.IAdminRepository.EXPECT().Counts(vacuumFileName).Return(10, 1, nil)
+    mocks.IFileUtil.EXPECT().Checksum(vacuumFileName).Return("abc", 100, nil)
+    mocks.ICompressor.EXPECT().Compress(vacuumFileName, compressedFileName, models.CompressionGzip).Return(nil)
+    // Compressed first, encrypted data doesn't compress
+    encryptor.EXPECT().Encrypt(compressedFileName, encryptedFileName).Return("2026-10", nil)
+    mocks.IFileUtil.EXPECT().Checksum(encryptedFileName).Return("def", 60, nil)
+    mocks.IFileUtil.EXPECT().WriteFile(manifestFileName, mock.Anything).
+        Run(func(file string, data []byte) {
+            assert.Nil(t, json.Unmarshal(data, &manifest))
+        }).Return(nil)
+    mocks.IStorageClient.EXPECT().UploadFile(mock.Anything, encryptedFileName, mock.MatchedBy(func(object string) bool {
+        return strings.HasSuffix(object, ".sqlite.gz"+models.EncryptionExtension)
+    })).Return(nil)
+    mocks.IStorageClient.EXPECT().UploadFile(mock.Anything, manifestFileName, mock.AnythingOfType(ss)).Return(nil)
+    mocks.IStorageClient.EXPECT().ListFiles(mock.Anything).Return(bucketFiles, nil)
+    mocks.IAdminRules.EXPECT().FilesToDelete(bucketFiles).Return(nil)
+
+    mocks.IBackupRunRepository.EXPECT().Create(mock.Anything).Return(nil)
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+    adminService.Compression = models.CompressionGzip
+    adminService.Encryptor = encryptor
+
+    // Backup
+    _, err := adminService.Backup(ctx, models.BackupTriggerScheduled)
+
+    // Check the manifest records the key
+    assert.Nil(t, err)
+    assert.Equal(t, "AES-256-GCM", manifest.Encryption)
+    assert.Equal(t, "2026-10", manifest.KeyID)
+    assert.Equal(t, "def", manifest.SHA256)
+    assert.Equal(t, "abc", manifest.DatabaseSHA256)
+}
+
+// encryptedBackup is a gzipped and encrypted backup with a manifest
+func encryptedBackup(mocks adminServiceTestMocks) models.BackupFile {
+    backup := models.BackupFile{Name: "entities-backup2.sqlite.gz.enc", Updated: time.Now(), Size: 60}
+    mocks.IStorageClient.EXPECT().ListFiles(mock.Anything).Return([]models.BackupFile{
+        backup, {Name: backup.Name + models.ManifestSuffix, Updated: time.Now()},
+    }, nil)
+    manifest := models.BackupManifest{
+        Object:         backup.Name,
+        Compression:    models.CompressionGzip,
+        Encryption:     "AES-256-GCM",
+        KeyID:          "2026-09",
+        SHA256:         "object-sum",
+        Size:           backup.Size,
+        DatabaseSHA256: "database-sum",
+    }
+    data, _ := json.Marshal(manifest)
+    expectManifest(mocks, backup.Name, data)
+    return backup
+}
+
+func TestRestoreEncrypted(t *testing.T) {
+    // Setup mocks
+    encryptor := mocks.NewIEncryptor(t)
+    mocks := adminServiceReqMocks(t)
+    backup := encryptedBackup(mocks)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(restoreFileName).Return(nil)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(downloadFileName).Return(nil)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(decryptedFileName).Return(nil)
+    mocks.IStorageClient.EXPECT().DownloadFile(mock.Anything, backup.Name, downloadFileName).Return(nil)
+    mocks.IFileUtil.EXPECT().Checksum(downloadFileName).Return("object-sum", 60, nil)
+    encryptor.EXPECT().Decrypt(downloadFileName, decryptedFileName).Return(nil)
+    mocks.ICompressor.EXPECT().Decompress(decryptedFileName, restoreFileName, models.CompressionGzip).Return(nil)
+    mocks.IFileUtil.EXPECT().Checksum(restoreFileName).Return("database-sum", 100, nil)
+    mocks.IAdminRepository.EXPECT().CheckIntegrity(restoreFileName).Return(nil)
+    mocks.IAdminRepository.EXPECT().CreateBackup(mock.AnythingOfType(ss)).Return(nil)
+    mocks.IAdminRepository.EXPECT().Restore(restoreFileName).Return(nil)
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+    adminService.Encryptor = encryptor
+
+    _, err := adminService.Restore(ctx, backup.Name)
+
+    assert.Nil(t, err)
+}
+
+func TestRestoreEncryptedTampered(t *testing.T) {
+    // Setup mocks
+    encryptor := mocks.NewIEncryptor(t)
+    mocks := adminServiceReqMocks(t)
+    backup := encryptedBackup(mocks)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(restoreFileName).Return(nil)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(downloadFileName).Return(nil)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(decryptedFileName).Return(nil)
+    mocks.IStorageClient.EXPECT().DownloadFile(mock.Anything, backup.Name, downloadFileName).Return(nil)
+    mocks.IFileUtil.EXPECT().Checksum(downloadFileName).Return("object-sum", 60, nil)
+    // Fail
+    encryptor.EXPECT().Decrypt(downloadFileName, decryptedFileName).Return(backupcrypt.ErrCorrupt)
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+    adminService.Encryptor = encryptor
+
+    _, err := adminService.Restore(ctx, backup.Name)
+
+    assert.True(t, errors.Is(err, ce.ErrDataInvalid))
+}
+
+func TestRestoreEncryptedWithoutKeys(t *testing.T) {
+    // Setup mocks
+    mocks := adminServiceReqMocks(t)
+    backup := encryptedBackup(mocks)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(restoreFileName).Return(nil)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(downloadFileName).Return(nil)
+    mocks.IStorageClient.EXPECT().DownloadFile(mock.Anything, backup.Name, downloadFileName).Return(nil)
+    mocks.IFileUtil.EXPECT().Checksum(downloadFileName).Return("object-sum", 60, nil)
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+
+    _, err := adminService.Restore(ctx, backup.Name)
+
+    assert.NotNil(t, err)
+    assert.False(t, errors.Is(err, ce.ErrDataInvalid))
+}
+
+func TestBackupRecordsFailure(t *testing.T) {
+    // Test data
+    expectedError := fmt.Errorf("disk full")
+    var recorded *models.BackupRun
+
+    // Setup mocks
+    mocks := adminServiceReqMocks(t)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(vacuumFileName).Return(nil)
+    mocks.IAdminRepository.EXPECT().CreateBackup(vacuumFileName).Return(expectedError)
+    mocks.IBackupRunRepository.EXPECT().Create(mock.Anything).
+        Run(func(run *models.BackupRun) { recorded = run }).Return(nil)
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+
+    // Backup
+    run, err := adminService.Backup(ctx, models.BackupTriggerManual)
+
+    // Check the failure is recorded
+    assert.True(t, errors.Is(err, expectedError))
+    assert.Equal(t, run, recorded)
+    assert.False(t, recorded.Succeeded())
+    assert.Equal(t, "disk full", recorded.Error)
+    assert.Equal(t, models.BackupTriggerManual, recorded.Trigger)
+    assert.Empty(t, recorded.Object)
+}
+
+func TestBackupRecordsSuccess(t *testing.T) {
+    // Data
+    bucketFiles := happyPathFiles()
+    var recorded *models.BackupRun
+
+    // Setup mocks
+    mocks := adminServiceReqMocks(t)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(vacuumFileName).Return(nil)
+    mocks.IAdminRepository.EXPECT().CreateBackup(vacuumFileName).Return(nil)
+    expectDescribed(mocks)
+    mocks.IStorageClient.EXPECT().UploadFile(mock.Anything, vacuumFileName, mock.AnythingOfType(ss)).Return(nil)
+    mocks.IStorageClient.EXPECT().UploadFile(mock.Anything, manifestFileName, mock.AnythingOfType(ss)).Return(nil)
+    mocks.IStorageClient.EXPECT().ListFiles(mock.Anything).Return(bucketFiles, nil)
+    mocks.IAdminRules.EXPECT().FilesToDelete(bucketFiles).Return(nil)
+    // Failing to record doesn't fail the backup
+    mocks.IBackupRunRepository.EXPECT().Create(mock.Anything).
+        Run(func(run *models.BackupRun) { recorded = run }).Return(fmt.Errorf("??"))
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+
+    // Backup
+    run, err := adminService.Backup(ctx, models.BackupTriggerScheduled)
+
+    assert.Nil(t, err)
+    assert.Equal(t, run, recorded)
+    assert.True(t, recorded.Succeeded())
+    assert.True(t, strings.HasPrefix(recorded.Object, models.BackupPrefix))
+}
+
+func TestBackupInProgress(t *testing.T) {
+    // Setup mocks, nothing is touched
+    mocks := adminServiceReqMocks(t)
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+
+    // A restore is running
+    adminService.mu.Lock()
+    defer adminService.mu.Unlock()
+
+    run, err := adminService.Backup(ctx, models.BackupTriggerManual)
+
+    assert.Nil(t, run)
+    assert.True(t, errors.Is(err, ce.ErrBackupInProgress))
+}
+
+func TestCheckDatabase(t *testing.T) {
+    // Test data
+    expectedError := fmt.Errorf("%w: integrity check failed", ce.ErrDataInvalid)
+
+    // Setup mocks
+    mocks := adminServiceReqMocks(t)
+    mocks.IAdminRepository.EXPECT().CheckDatabase().Return(expectedError)
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+
+    err := adminService.CheckDatabase()
+
+    assert.True(t, errors.Is(err, ce.ErrDataInvalid))
+}
+
+func TestBackupNotifiesFailure(t *testing.T) {
+    // Test data
+    expectedError := fmt.Errorf("disk full")
+
+    // Setup mocks
+    notifier := mocks.NewINotifier(t)
+    mocks := adminServiceReqMocks(t)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(vacuumFileName).Return(nil)
+    mocks.IAdminRepository.EXPECT().CreateBackup(vacuumFileName).Return(expectedError)
+    mocks.IBackupRunRepository.EXPECT().Create(mock.Anything).Return(nil)
+    notifier.EXPECT().Notify(mock.MatchedBy(func(n models.Notification) bool {
+        return n.Event == models.EventBackupFailed && n.Failure && n.Message == "disk full" &&
+            n.Subject == "Backup failed on host" && n.Fields["trigger"] == models.BackupTriggerScheduled
+    })).Return()
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+    adminService.Notifier = notifier
+    adminService.Hostname = "host"
+
+    _, err := adminService.Backup(ctx, models.BackupTriggerScheduled)
+
+    assert.True(t, errors.Is(err, expectedError))
+}
+
+func TestBackupNotifiesSuccess(t *testing.T) {
+    // Data
+    bucketFiles := happyPathFiles()
+
+    // Setup mocks
+    notifier := mocks.NewINotifier(t)
+    mocks := adminServiceReqMocks(t)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(vacuumFileName).Return(nil)
+    mocks.IAdminRepository.EXPECT().CreateBackup(vacuumFileName).Return(nil)
+    expectDescribed(mocks)
+    mocks.IStorageClient.EXPECT().UploadFile(mock.Anything, vacuumFileName, mock.AnythingOfType(ss)).Return(nil)
+    mocks.IStorageClient.EXPECT().UploadFile(mock.Anything, manifestFileName, mock.AnythingOfType(ss)).Return(nil)
+    mocks.IStorageClient.EXPECT().ListFiles(mock.Anything).Return(bucketFiles, nil)
+    mocks.IAdminRules.EXPECT().FilesToDelete(bucketFiles).Return(nil)
+    mocks.IBackupRunRepository.EXPECT().Create(mock.Anything).Return(nil)
+    // Whether it's sent is up to the notifier
+    notifier.EXPECT().Notify(mock.MatchedBy(func(n models.Notification) bool {
+        return n.Event == models.EventBackupSucceeded && !n.Failure &&
+            strings.HasPrefix(n.Fields["object"], models.BackupPrefix)
+    })).Return()
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+    adminService.Notifier = notifier
+
+    _, err := adminService.Backup(ctx, models.BackupTriggerManual)
+
+    assert.Nil(t, err)
+}
+
+type ctxKey struct{}
+
+func TestBackupPassesContext(t *testing.T) {
+    // Data
+    bucketFiles := happyPathFiles()
+    callerCtx := context.WithValue(ctx, ctxKey{}, "caller")
+    isCallerCtx := mock.MatchedBy(func(c context.Context) bool {
+        return c.Value(ctxKey{}) == "caller"
+    })
+
+    // Setup mocks, storage is given the caller's context so it can be cancelled
+    mocks := adminServiceReqMocks(t)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(vacuumFileName).Return(nil)
+    mocks.IAdminRepository.EXPECT().CreateBackup(vacuumFileName).Return(nil)
+    expectDescribed(mocks)
+    mocks.IStorageClient.EXPECT().UploadFile(isCallerCtx, vacuumFileName, mock.AnythingOfType(ss)).Return(nil)
+    mocks.IStorageClient.EXPECT().UploadFile(isCallerCtx, manifestFileName, mock.AnythingOfType(ss)).Return(nil)
+    mocks.IStorageClient.EXPECT().ListFiles(isCallerCtx).Return(bucketFiles, nil)
+    mocks.IAdminRules.EXPECT().FilesToDelete(bucketFiles).Return([]string{bucketFiles[2].Name})
+    mocks.IStorageClient.EXPECT().DeleteFile(isCallerCtx, bucketFiles[2].Name).Return(nil)
+    mocks.IBackupRunRepository.EXPECT().Create(mock.Anything).Return(nil)
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+
+    _, err := adminService.Backup(callerCtx, models.BackupTriggerManual)
+
+    assert.Nil(t, err)
+}
+
+func TestRestoreToTime(t *testing.T) {
+    // Test data
+    at := time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)
+    shipped := at.Add(-4 * time.Second)
+
+    // Setup mocks
+    migrator := mocks.NewIMigrator(t)
+    replicator := mocks.NewIReplicator(t)
+    mocks := adminServiceReqMocks(t)
+    mocks.IFileUtil.EXPECT().DeleteIfExists(restoreFileName).Return(nil)
+    replicator.EXPECT().Reconstruct(mock.Anything, at, restoreFileName).Return(shipped, nil)
+    mocks.IAdminRepository.EXPECT().CheckIntegrity(restoreFileName).Return(nil)
+    mocks.IAdminRepository.EXPECT().CreateBackup(mock.AnythingOfType(ss)).Return(nil)
+    mocks.IAdminRepository.EXPECT().Restore(restoreFileName).Return(nil)
+    migrator.EXPECT().Migrate().Return(nil)
+
+    // Inject service
+    adminService := injectedAdminService(mocks)
+    adminService.Replicator = replicator
+    adminService.Migrators = append(adminService.Migrators, migrator)
+
+    safetyCopy, restoredTo, err := adminService.RestoreToTime(ctx, at)
+
+    assert.Nil(t, err)
+    assert.Regexp(t, `^entities-pre-restore\d+\.sqlite$`, safetyCopy)
+    assert.Equal(t, shipped, restoredTo)
+}
+
+func TestRestoreToTimeErrors(t *testing.T) {
+    tests := []struct {
+        name           string
+        reconstructErr error
+        integrityErr   error
+        expectedErr    error
+    }{
+        {name: "before replication", reconstructErr: walship.ErrNoReplica, expectedErr: ce.ErrRecordNotFound},
+        {name: "corrupt", integrityErr: fmt.Errorf("%w: integrity check failed", ce.ErrDataInvalid),
+            expectedErr: ce.ErrDataInvalid},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            // Setup mocks
+            replicator := mocks.NewIReplicator(t)
+            mocks := adminServiceReqMocks(t)
+            mocks.IFileUtil.EXPECT().DeleteIfExists(restoreFileName).Return(nil)
+            replicator.EXPECT().Reconstruct(mock.Anything, mock.Anything, restoreFileName).
+                Return(time.Now(), tt.reconstructErr)
+            if tt.reconstructErr == nil {
+                mocks.IAdminRepository.EXPECT().CheckIntegrity(restoreFileName).Return(tt.integrityErr)
+            }
+
+            // Inject service
+            adminService := injectedAdminService(mocks)
+            adminService.Replicator = replicator
+
+            // The database is never touched
+            _, _, err := adminService.RestoreToTime(ctx, time.Now())
+
+            assert.ErrorIs(t, err, tt.expectedErr)
+            mocks.IFileUtil.AssertNumberOfCalls(t, "DeleteIfExists", 2)
+        })
+    }
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+
+	ce "github.com/apkatsikas/artist-entities/customerrors"
+	"github.com/apkatsikas/artist-entities/infrastructures/logutil"
+	"github.com/apkatsikas/artist-entities/interfaces"
+	"github.com/apkatsikas/artist-entities/models"
+)
+
+const (
+	apiKeyPrefix  = "ae_"
+	apiKeyBytes   = 32
+	displayPrefix = 11
+	maxNameLength = 75
+)
+
+var knownScopes = []string{models.ApiKeyScopeArtistWrite}
+
+type ApiKeyService struct {
+	ApiKeyRepository interfaces.IApiKeyRepository
+}
+
+func hashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func validScopes(scopes []string) bool {
+	if len(scopes) == 0 {
+		return false
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(knownScopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// Create makes a new key and returns it alongside the stored record
+// The key cannot be recovered after this call
+func (as *ApiKeyService) Create(name string, scopes []string, expiresAt *time.Time) (string, *models.ApiKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || !validScopes(scopes) {
+		return "", nil, ce.ErrDataInvalid
+	}
+	if len(name) > maxNameLength {
+		return "", nil, ce.ErrDataTooLong
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return "", nil, ce.ErrDataInvalid
+	}
+
+	secret := make([]byte, apiKeyBytes)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", nil, err
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	apiKey := &models.ApiKey{
+		Name:      name,
+		Prefix:    key[:displayPrefix],
+		Hash:      hashApiKey(key),
+		Scopes:    strings.Join(scopes, ","),
+		ExpiresAt: expiresAt,
+	}
+	err = as.ApiKeyRepository.Create(apiKey)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, apiKey, nil
+}
+
+func (as *ApiKeyService) List() ([]models.ApiKey, error) {
+	return as.ApiKeyRepository.List()
+}
+
+func (as *ApiKeyService) Revoke(id uint) error {
+	return as.ApiKeyRepository.Revoke(id, time.Now())
+}
+
+// IsAuthorized returns true if the key exists, is live and has the scope
+func (as *ApiKeyService) IsAuthorized(key string, scope string) bool {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return false
+	}
+
+	apiKey, err := as.ApiKeyRepository.GetByHash(hashApiKey(key))
+	if err != nil {
+		return false
+	}
+
+	now := time.Now()
+	if apiKey.RevokedAt != nil {
+		return false
+	}
+	if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(now) {
+		return false
+	}
+	if !slices.Contains(strings.Split(apiKey.Scopes, ","), scope) {
+		return false
+	}
+
+	// Failing to record usage shouldn't lock out automation
+	err = as.ApiKeyRepository.Touch(apiKey.ID, now)
+	if err != nil {
+		logutil.Error("Failed to record use of API key", "apiKeyID", apiKey.ID, "error", err)
+	}
+	return true
+}
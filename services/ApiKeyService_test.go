@@ -0,0 +1,115 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	ce "github.com/apkatsikas/artist-entities/customerrors"
+	"github.com/apkatsikas/artist-entities/interfaces/mocks"
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const keyName = "ingest"
+
+var writeScopes = []string{models.ApiKeyScopeArtistWrite}
+
+func TestCreateApiKey(t *testing.T) {
+	repository := mocks.NewIApiKeyRepository(t)
+	repository.EXPECT().Create(mock.AnythingOfType("*models.ApiKey")).Return(nil)
+
+	service := ApiKeyService{ApiKeyRepository: repository}
+	key, apiKey, err := service.Create(keyName, writeScopes, nil)
+
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(key, apiKeyPrefix))
+	// Only the hash is stored
+	require.Equal(t, hashApiKey(key), apiKey.Hash)
+	require.NotContains(t, apiKey.Hash, key)
+	require.Equal(t, key[:displayPrefix], apiKey.Prefix)
+	require.Equal(t, models.ApiKeyScopeArtistWrite, apiKey.Scopes)
+}
+
+func TestCreateApiKeyInvalid(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	var testData = []struct {
+		test      string
+		name      string
+		scopes    []string
+		expiresAt *time.Time
+		err       error
+	}{
+		{test: "blank name", name: " ", scopes: writeScopes, err: ce.ErrDataInvalid},
+		{test: "no scopes", name: keyName, err: ce.ErrDataInvalid},
+		{test: "unknown scope", name: keyName, scopes: []string{"admin"}, err: ce.ErrDataInvalid},
+		{test: "expired", name: keyName, scopes: writeScopes, expiresAt: &past, err: ce.ErrDataInvalid},
+		{test: "long name", name: strings.Repeat("a", 76), scopes: writeScopes, err: ce.ErrDataTooLong},
+	}
+	for _, tt := range testData {
+		t.Run(tt.test, func(t *testing.T) {
+			service := ApiKeyService{ApiKeyRepository: mocks.NewIApiKeyRepository(t)}
+			_, _, err := service.Create(tt.name, tt.scopes, tt.expiresAt)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestApiKeyIsAuthorized(t *testing.T) {
+	key := "ae_somekey"
+	record := &models.ApiKey{Scopes: models.ApiKeyScopeArtistWrite}
+	record.ID = 2
+
+	repository := mocks.NewIApiKeyRepository(t)
+	repository.EXPECT().GetByHash(hashApiKey(key)).Return(record, nil)
+	repository.EXPECT().Touch(uint(2), mock.AnythingOfType("time.Time")).Return(nil)
+
+	service := ApiKeyService{ApiKeyRepository: repository}
+	require.True(t, service.IsAuthorized(key, models.ApiKeyScopeArtistWrite))
+}
+
+func TestApiKeyIsAuthorizedTouchFails(t *testing.T) {
+	key := "ae_somekey"
+	record := &models.ApiKey{Scopes: models.ApiKeyScopeArtistWrite}
+
+	repository := mocks.NewIApiKeyRepository(t)
+	repository.EXPECT().GetByHash(hashApiKey(key)).Return(record, nil)
+	repository.EXPECT().Touch(mock.Anything, mock.Anything).Return(fmt.Errorf("locked"))
+
+	service := ApiKeyService{ApiKeyRepository: repository}
+	require.True(t, service.IsAuthorized(key, models.ApiKeyScopeArtistWrite))
+}
+
+func TestApiKeyIsAuthorizedFail(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+
+	var testData = []struct {
+		test   string
+		record *models.ApiKey
+		err    error
+	}{
+		{test: "unknown", err: ce.ErrRecordNotFound},
+		{test: "revoked", record: &models.ApiKey{Scopes: models.ApiKeyScopeArtistWrite, RevokedAt: &past}},
+		{test: "expired", record: &models.ApiKey{Scopes: models.ApiKeyScopeArtistWrite, ExpiresAt: &past}},
+		{test: "wrong scope", record: &models.ApiKey{Scopes: "artist:read"}},
+	}
+	for _, tt := range testData {
+		t.Run(tt.test, func(t *testing.T) {
+			key := "ae_somekey"
+			repository := mocks.NewIApiKeyRepository(t)
+			repository.EXPECT().GetByHash(hashApiKey(key)).Return(tt.record, tt.err)
+
+			service := ApiKeyService{ApiKeyRepository: repository}
+			require.False(t, service.IsAuthorized(key, models.ApiKeyScopeArtistWrite))
+		})
+	}
+}
+
+func TestApiKeyIsAuthorizedNotAKey(t *testing.T) {
+	// A JWT or garbage never hits the repository
+	service := ApiKeyService{ApiKeyRepository: mocks.NewIApiKeyRepository(t)}
+	require.False(t, service.IsAuthorized("eyJhbGciOi", models.ApiKeyScopeArtistWrite))
+}
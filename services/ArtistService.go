@@ -1,6 +1,9 @@
 package services
 
 import (
+	"context"
+
+	"github.com/apkatsikas/artist-entities/infrastructures/tracing"
 	"github.com/apkatsikas/artist-entities/interfaces"
 	"github.com/apkatsikas/artist-entities/models"
 )
@@ -10,9 +13,12 @@ type ArtistService struct {
 	Rules            interfaces.IArtistRules
 }
 
-func (as *ArtistService) Get(id uint) (*models.Artist, error) {
+func (as *ArtistService) Get(ctx context.Context, id uint) (artist *models.Artist, err error) {
+	ctx, span := tracing.Start(ctx, "ArtistService.Get")
+	defer func() { tracing.End(span, err) }()
+
 	// Get artist
-	artist, err := as.ArtistRepository.Get(id)
+	artist, err = as.ArtistRepository.Get(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -20,8 +26,11 @@ func (as *ArtistService) Get(id uint) (*models.Artist, error) {
 	return artist, nil
 }
 
-func (as *ArtistService) GetRandom() (*models.Artist, error) {
-	count, err := as.ArtistRepository.GetCount()
+func (as *ArtistService) GetRandom(ctx context.Context) (artist *models.Artist, err error) {
+	ctx, span := tracing.Start(ctx, "ArtistService.GetRandom")
+	defer func() { tracing.End(span, err) }()
+
+	count, err := as.ArtistRepository.GetCount(ctx)
 
 	if err != nil {
 		return nil, err
@@ -29,7 +38,7 @@ func (as *ArtistService) GetRandom() (*models.Artist, error) {
 
 	offset := as.Rules.RandomOffset(count)
 
-	artist, err := as.ArtistRepository.GetByOffset(offset)
+	artist, err = as.ArtistRepository.GetByOffset(ctx, offset)
 
 	if err != nil {
 		return nil, err
@@ -38,15 +47,18 @@ func (as *ArtistService) GetRandom() (*models.Artist, error) {
 	return artist, nil
 }
 
-func (as *ArtistService) Create(artistName string) (*models.Artist, error) {
+func (as *ArtistService) Create(ctx context.Context, artistName string) (artist *models.Artist, err error) {
+	ctx, span := tracing.Start(ctx, "ArtistService.Create")
+	defer func() { tracing.End(span, err) }()
+
 	// Clean
-	artistName, err := as.Rules.CleanArtistName(artistName)
+	artistName, err = as.Rules.CleanArtistName(artistName)
 	if err != nil {
 		return nil, err
 	}
 
 	// Write to repository
-	artist, err := as.ArtistRepository.Create(artistName)
+	artist, err = as.ArtistRepository.Create(ctx, artistName)
 	if err != nil {
 		return nil, err
 	}
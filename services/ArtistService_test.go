@@ -9,6 +9,7 @@ import (
 	"github.com/apkatsikas/artist-entities/models"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 const (
@@ -44,13 +45,13 @@ func TestGetArtist(t *testing.T) {
 
 	// Setup mocks
 	mocks := artistServiceReqMocks(t)
-	mocks.IArtistRepository.EXPECT().Get(artistID).Return(&artist, nil)
+	mocks.IArtistRepository.EXPECT().Get(mock.Anything, artistID).Return(&artist, nil)
 
 	// Inject service
 	artistService := injectedArtistService(mocks)
 
 	// Get artist
-	artistResult, err := artistService.Get(artistID)
+	artistResult, err := artistService.Get(ctx, artistID)
 
 	// Check artist result
 	assert.Equal(t, &artist, artistResult)
@@ -68,13 +69,13 @@ func TestGetArtistNoRecord(t *testing.T) {
 
 	// Setup mocks
 	mocks := artistServiceReqMocks(t)
-	mocks.IArtistRepository.EXPECT().Get(artistID).Return(nil, expectedError)
+	mocks.IArtistRepository.EXPECT().Get(mock.Anything, artistID).Return(nil, expectedError)
 
 	// Inject service
 	artistService := injectedArtistService(mocks)
 
 	// Get artist
-	artistResult, err := artistService.Get(artistID)
+	artistResult, err := artistService.Get(ctx, artistID)
 
 	// Check that we got no artist
 	assert.Nil(t, artistResult)
@@ -94,13 +95,13 @@ func TestCreateArtist(t *testing.T) {
 	// Setup mocks
 	mocks := artistServiceReqMocks(t)
 	mocks.IArtistRules.EXPECT().CleanArtistName(artistName).Return(artistName, nil)
-	mocks.IArtistRepository.EXPECT().Create(artistName).Return(&artist, nil)
+	mocks.IArtistRepository.EXPECT().Create(mock.Anything, artistName).Return(&artist, nil)
 
 	// Inject service
 	artistService := injectedArtistService(mocks)
 
 	// Create artist
-	artistResult, err := artistService.Create(artistName)
+	artistResult, err := artistService.Create(ctx, artistName)
 
 	// Check artist result
 	assert.Equal(t, &artist, artistResult)
@@ -119,13 +120,13 @@ func TestCreateArtistExists(t *testing.T) {
 	// Setup mocks
 	mocks := artistServiceReqMocks(t)
 	mocks.IArtistRules.EXPECT().CleanArtistName(artistName).Return(artistName, nil)
-	mocks.IArtistRepository.EXPECT().Create(artistName).Return(nil, expectedError)
+	mocks.IArtistRepository.EXPECT().Create(mock.Anything, artistName).Return(nil, expectedError)
 
 	// Inject service
 	artistService := injectedArtistService(mocks)
 
 	// Create artist
-	artistResult, err := artistService.Create(artistName)
+	artistResult, err := artistService.Create(ctx, artistName)
 
 	// Check that we got no artist
 	assert.Nil(t, artistResult)
@@ -149,7 +150,7 @@ func TestCreateArtistRulesFail(t *testing.T) {
 	artistService := injectedArtistService(mocks)
 
 	// Create artist
-	artistResult, err := artistService.Create(artistName)
+	artistResult, err := artistService.Create(ctx, artistName)
 
 	// Check that we got no artist
 	assert.Nil(t, artistResult)
@@ -172,15 +173,15 @@ func TestGetRandomArtist(t *testing.T) {
 
 	// Setup mocks
 	mocks := artistServiceReqMocks(t)
-	mocks.IArtistRepository.EXPECT().GetCount().Return(count, nil)
+	mocks.IArtistRepository.EXPECT().GetCount(mock.Anything).Return(count, nil)
 	mocks.IArtistRules.EXPECT().RandomOffset(count).Return(offsetValue)
-	mocks.IArtistRepository.EXPECT().GetByOffset(offsetValue).Return(&artist, nil)
+	mocks.IArtistRepository.EXPECT().GetByOffset(mock.Anything, offsetValue).Return(&artist, nil)
 
 	// Inject service
 	artistService := injectedArtistService(mocks)
 
 	// Get artist
-	artistResult, err := artistService.GetRandom()
+	artistResult, err := artistService.GetRandom(ctx)
 
 	// Check artist
 	assert.Equal(t, &artist, artistResult)
@@ -195,13 +196,13 @@ func TestGetRandomArtistCountError(t *testing.T) {
 
 	// Setup mocks
 	mocks := artistServiceReqMocks(t)
-	mocks.IArtistRepository.EXPECT().GetCount().Return(uint(0), expectedError)
+	mocks.IArtistRepository.EXPECT().GetCount(mock.Anything).Return(uint(0), expectedError)
 
 	// Inject service
 	artistService := injectedArtistService(mocks)
 
 	// Get artist
-	artistResult, err := artistService.GetRandom()
+	artistResult, err := artistService.GetRandom(ctx)
 
 	// Check that we got no artist
 	assert.Nil(t, artistResult)
@@ -219,15 +220,15 @@ func TestGetRandomArtistOffsetError(t *testing.T) {
 
 	// Setup mocks
 	mocks := artistServiceReqMocks(t)
-	mocks.IArtistRepository.EXPECT().GetCount().Return(count, nil)
+	mocks.IArtistRepository.EXPECT().GetCount(mock.Anything).Return(count, nil)
 	mocks.IArtistRules.EXPECT().RandomOffset(count).Return(offsetValue)
-	mocks.IArtistRepository.EXPECT().GetByOffset(offsetValue).Return(nil, expectedError)
+	mocks.IArtistRepository.EXPECT().GetByOffset(mock.Anything, offsetValue).Return(nil, expectedError)
 
 	// Inject service
 	artistService := injectedArtistService(mocks)
 
 	// Get artist
-	artistResult, err := artistService.GetRandom()
+	artistResult, err := artistService.GetRandom(ctx)
 
 	// Check that we got no artist
 	assert.Nil(t, artistResult)
@@ -1,61 +1,196 @@
 package services
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"time"
 
+	ce "github.com/apkatsikas/artist-entities/customerrors"
+	"github.com/apkatsikas/artist-entities/infrastructures/jwtkeys"
+	"github.com/apkatsikas/artist-entities/infrastructures/logutil"
+	"github.com/apkatsikas/artist-entities/infrastructures/tracing"
 	"github.com/apkatsikas/artist-entities/interfaces"
 	"github.com/apkatsikas/artist-entities/models"
+	"github.com/apkatsikas/artist-entities/viewmodels"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// dummyHash is compared against when a user doesn't exist
+// so unknown users take as long to reject as wrong passwords
+const dummyHash = "$2a$10$FB0lrtyiqn5mCbfCFuZoPuW1vcU8QWgyuz95hMlQjUIEyubxic2h2"
+
 type Claims struct {
 	Username string `json:"username"`
+	// TokenVersion must match the user's, bumping it revokes every token
+	TokenVersion uint   `json:"ver"`
+	Role         string `json:"role,omitempty"`
 	jwt.RegisteredClaims
 }
 
 type AuthService struct {
-	UserRepository  interfaces.IUserRepository
-	jwtSignatureKey []byte
+	UserRepository interfaces.IUserRepository
+	TokenDenylist  interfaces.ITokenDenylist
+	keyring        interfaces.IKeyring
 }
 
 func fiveMinuteExpiration() time.Time {
 	return time.Now().Add(5 * time.Minute)
 }
 
+func newTokenID() (string, error) {
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// SetJwtSigningKey signs and verifies with a single HS256 key
 func (as *AuthService) SetJwtSigningKey(signatureKey string) {
-	as.jwtSignatureKey = []byte(signatureKey)
+	if signatureKey == "" {
+		as.keyring = nil
+		return
+	}
+	as.keyring = jwtkeys.NewHMAC(signatureKey)
+}
+
+// SetKeyring signs with the keyring's active key and verifies by kid
+func (as *AuthService) SetKeyring(keyring interfaces.IKeyring) {
+	as.keyring = keyring
+}
+
+// keyFor looks up the key named by the token's kid
+// The key must use the same algorithm the token claims
+func (as *AuthService) keyFor(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, err := as.keyring.VerificationKey(kid)
+	if err != nil {
+		return nil, err
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, jwt.ErrTokenSignatureInvalid
+	}
+	return key.VerifyKey(), nil
+}
+
+// tokenError sorts jwt parsing errors into the ones callers act on
+func tokenError(err error) error {
+	if errors.Is(err, jwt.ErrTokenMalformed) {
+		return fmt.Errorf("%w: %v", ce.ErrTokenMalformed, err)
+	}
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return fmt.Errorf("%w: %v", ce.ErrTokenExpired, err)
+	}
+	return fmt.Errorf("%w: %v", ce.ErrTokenInvalid, err)
 }
 
-func (as *AuthService) IsAuthorized(token string) bool {
+// verify parses the token and checks it hasn't been revoked
+// The user it was issued to is returned too
+func (as *AuthService) verify(ctx context.Context, token string) (*Claims, *models.User, error) {
 	as.panicIfEmptyKey()
 
-	tkn, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		return as.jwtSignatureKey, nil
-	}, jwt.WithValidMethods([]string{"HS256"}))
+	claims := &Claims{}
+	tkn, err := jwt.ParseWithClaims(token, claims, as.keyFor,
+		jwt.WithValidMethods(as.keyring.Methods()), jwt.WithExpirationRequired())
 
 	if err != nil {
-		if err == jwt.ErrSignatureInvalid {
-			// unauthorized
-			return false
+		return nil, nil, tokenError(err)
+	}
+	if !tkn.Valid || claims.ID == "" || claims.Username == "" {
+		return nil, nil, ce.ErrTokenInvalid
+	}
+
+	// Logged out
+	if as.TokenDenylist.IsRevoked(claims.ID) {
+		return nil, nil, ce.ErrTokenInvalid
+	}
+
+	// Sessions revoked or password changed since the token was issued
+	user, err := as.UserRepository.Get(ctx, claims.Username)
+	if err != nil {
+		if errors.Is(err, ce.ErrRecordNotFound) {
+			return nil, nil, ce.ErrTokenInvalid
 		}
-		// bad request
-		return false
+		return nil, nil, err
 	}
-	if !tkn.Valid {
-		// unauthorized
-		return false
+	if user.TokenVersion != claims.TokenVersion {
+		return nil, nil, ce.ErrTokenInvalid
 	}
-	return true
+	return claims, user, nil
 }
 
-func (as *AuthService) CreateUser(name string, password string) (*models.User, error) {
+// Authorize returns nil for a usable token issued to a user with the role
+// The user's current role is checked, not the token's, so demotions apply straight away
+// Otherwise the error is ErrTokenMalformed, ErrTokenExpired, ErrTokenInvalid or ErrForbidden,
+// or something unexpected if the user couldn't be looked up
+func (as *AuthService) Authorize(ctx context.Context, token string, role string) (err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Authorize")
+	defer func() { tracing.End(span, err) }()
+
+	_, user, err := as.verify(ctx, token)
+	if err != nil {
+		return err
+	}
+	logutil.Annotate(ctx, "user", user.Name)
+	if !user.HasRole(role) {
+		return fmt.Errorf("%w %v", ce.ErrForbidden, role)
+	}
+	return nil
+}
+
+// Logout revokes the token until it would have expired
+func (as *AuthService) Logout(ctx context.Context, token string) (err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.Logout")
+	defer func() { tracing.End(span, err) }()
+
+	claims, _, err := as.verify(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	return as.TokenDenylist.Revoke(ctx, claims.ID, claims.ExpiresAt.Time)
+}
+
+// RevokeSessions invalidates every token issued to the user
+func (as *AuthService) RevokeSessions(ctx context.Context, name string) (err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.RevokeSessions")
+	defer func() { tracing.End(span, err) }()
+
+	return as.UserRepository.IncrementTokenVersion(ctx, name)
+}
+
+// ChangePassword sets a new password, invalidating the user's tokens
+func (as *AuthService) ChangePassword(ctx context.Context, name string, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return as.UserRepository.UpdatePassword(ctx, name, string(hashedPassword))
+}
+
+// JWKS returns the public keys other services can verify our tokens with
+func (as *AuthService) JWKS(ctx context.Context) viewmodels.JwksVM {
+	_, span := tracing.Start(ctx, "AuthService.JWKS")
+	defer span.End()
+
+	as.panicIfEmptyKey()
+
+	return as.keyring.JWKS()
+}
+
+func (as *AuthService) CreateUser(ctx context.Context, name string, password string) (*models.User, error) {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, err
 	}
 
-	user, err := as.UserRepository.Create(name, string(hashedPassword))
+	user, err := as.UserRepository.Create(ctx, name, string(hashedPassword))
 	if err != nil {
 		return nil, err
 	}
@@ -64,28 +199,65 @@ func (as *AuthService) CreateUser(name string, password string) (*models.User, e
 }
 
 func (as *AuthService) panicIfEmptyKey() {
-	if len(as.jwtSignatureKey) == 0 {
+	if as.keyring == nil || as.keyring.Len() == 0 {
 		panic("JWT signature key cannot be blank!")
 	}
 }
 
-func (as *AuthService) GenerateJWT(name string, password string) (string, error) {
+func (as *AuthService) GenerateJWT(ctx context.Context, name string, password string) (signed string, err error) {
+	ctx, span := tracing.Start(ctx, "AuthService.GenerateJWT")
+	defer func() { tracing.End(span, err) }()
+
 	as.panicIfEmptyKey()
 
-	user, err := as.UserRepository.Get(name)
+	user, err := as.UserRepository.Get(ctx, name)
 	if err != nil {
+		if errors.Is(err, ce.ErrRecordNotFound) {
+			bcrypt.CompareHashAndPassword([]byte(dummyHash), []byte(password))
+		}
 		return "", err
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return "", ce.ErrCredentialsInvalid
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return as.IssueJWT(ctx, user)
+}
+
+// IssueJWT signs a token for a user who has already been authenticated
+func (as *AuthService) IssueJWT(ctx context.Context, user *models.User) (signed string, err error) {
+	_, span := tracing.Start(ctx, "AuthService.IssueJWT")
+	defer func() { tracing.End(span, err) }()
+
+	as.panicIfEmptyKey()
+
+	jti, err := newTokenID()
 	if err != nil {
 		return "", err
 	}
 
-	claims := jwt.RegisteredClaims{
-		ExpiresAt: jwt.NewNumericDate(fiveMinuteExpiration()),
+	claims := Claims{
+		Username:     user.Name,
+		TokenVersion: user.TokenVersion,
+		Role:         user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(fiveMinuteExpiration()),
+		},
+	}
+
+	key, err := as.keyring.SigningKey()
+	if err != nil {
+		return "", err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(as.jwtSignatureKey)
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.SignKey())
 }
@@ -1,11 +1,18 @@
 package services
 
 import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"fmt"
 	"testing"
+	"time"
 
+	ce "github.com/apkatsikas/artist-entities/customerrors"
+	"github.com/apkatsikas/artist-entities/infrastructures/jwtkeys"
 	"github.com/apkatsikas/artist-entities/interfaces/mocks"
 	"github.com/apkatsikas/artist-entities/models"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -16,64 +23,119 @@ const (
 	hashedPassword = "$2a$10$FB0lrtyiqn5mCbfCFuZoPuW1vcU8QWgyuz95hMlQjUIEyubxic2h2"
 )
 
-func TestIsAuthorized(t *testing.T) {
+func notRevoked(t *testing.T) *mocks.ITokenDenylist {
+	tokenDenylist := mocks.NewITokenDenylist(t)
+	tokenDenylist.EXPECT().IsRevoked(mock.AnythingOfType("string")).Return(false)
+	return tokenDenylist
+}
+
+func TestAuthorize(t *testing.T) {
 	userRepository := mocks.NewIUserRepository(t)
-	userRepository.EXPECT().Get(userName).Return(&models.User{
+	userRepository.EXPECT().Get(mock.Anything, userName).Return(&models.User{
 		Name:     userName,
 		Password: hashedPassword,
+		Role:     models.RoleAdmin,
 	}, nil)
 
-	service := AuthService{UserRepository: userRepository}
+	service := AuthService{UserRepository: userRepository, TokenDenylist: notRevoked(t)}
 	service.SetJwtSigningKey(password)
 
-	token, err := service.GenerateJWT(userName, password)
+	token, err := service.GenerateJWT(ctx, userName, password)
 	require.NoError(t, err)
 
-	isAuthorized := service.IsAuthorized(token)
+	require.NoError(t, service.Authorize(ctx, token, models.RoleEditor))
+}
+
+func TestAuthorizeRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		userRole string
+		role     string
+		err      error
+	}{
+		{name: "admin as admin", userRole: models.RoleAdmin, role: models.RoleAdmin},
+		{name: "admin as editor", userRole: models.RoleAdmin, role: models.RoleEditor},
+		{name: "editor as editor", userRole: models.RoleEditor, role: models.RoleEditor},
+		{name: "editor as admin", userRole: models.RoleEditor, role: models.RoleAdmin, err: ce.ErrForbidden},
+	}
 
-	require.True(t, isAuthorized)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepository := mocks.NewIUserRepository(t)
+			userRepository.EXPECT().Get(mock.Anything, userName).Return(&models.User{
+				Name: userName,
+				Role: tt.userRole,
+			}, nil)
+			service := AuthService{UserRepository: userRepository, TokenDenylist: notRevoked(t)}
+			service.SetJwtSigningKey(password)
+
+			token, err := service.IssueJWT(ctx, &models.User{Name: userName, Role: models.RoleAdmin})
+			require.NoError(t, err)
+
+			// The user's role counts, not the one in the token
+			err = service.Authorize(ctx, token, tt.role)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
 }
 
 func TestGenerateJWTNoUser(t *testing.T) {
 	userRepository := mocks.NewIUserRepository(t)
-	userRepository.EXPECT().Get(userName).Return(nil, fmt.Errorf("user does not exist"))
+	userRepository.EXPECT().Get(mock.Anything, userName).Return(nil, fmt.Errorf("user does not exist"))
 
 	service := AuthService{UserRepository: userRepository}
 	service.SetJwtSigningKey(password)
 
-	token, err := service.GenerateJWT(userName, password)
+	token, err := service.GenerateJWT(ctx, userName, password)
 	require.Error(t, err)
 	require.Empty(t, token)
 }
 
+func TestGenerateJWTUnknownUser(t *testing.T) {
+	userRepository := mocks.NewIUserRepository(t)
+	userRepository.EXPECT().Get(mock.Anything, userName).Return(nil, ce.ErrRecordNotFound)
+
+	service := AuthService{UserRepository: userRepository}
+	service.SetJwtSigningKey(password)
+
+	// The dummy hash matches this password, but we still must fail
+	token, err := service.GenerateJWT(ctx, userName, password)
+	require.ErrorIs(t, err, ce.ErrRecordNotFound)
+	require.Empty(t, token)
+}
+
 func TestGenerateJWTWrongPassword(t *testing.T) {
 	userRepository := mocks.NewIUserRepository(t)
-	userRepository.EXPECT().Get(userName).Return(&models.User{
+	userRepository.EXPECT().Get(mock.Anything, userName).Return(&models.User{
 		Name:     userName,
 		Password: hashedPassword,
+		Role:     models.RoleAdmin,
 	}, nil)
 
 	service := AuthService{UserRepository: userRepository}
 	service.SetJwtSigningKey(password)
 
-	token, err := service.GenerateJWT(userName, "bloop")
-	require.Error(t, err)
+	token, err := service.GenerateJWT(ctx, userName, "bloop")
+	require.ErrorIs(t, err, ce.ErrCredentialsInvalid)
 	require.Empty(t, token)
 }
 
-func TestIsAuthorizedFail(t *testing.T) {
+func TestAuthorizeFail(t *testing.T) {
 	service := AuthService{}
 	service.SetJwtSigningKey(password)
 
-	isAuthorized := service.IsAuthorized("token")
-	require.False(t, isAuthorized)
+	require.ErrorIs(t, service.Authorize(ctx, "token", models.RoleEditor), ce.ErrTokenMalformed)
 }
 
-func TestEmptyKeyIsAuthorized(t *testing.T) {
+func TestEmptyKeyAuthorize(t *testing.T) {
 	service := AuthService{}
 
 	require.Panics(t, func() {
-		service.IsAuthorized("token")
+		service.Authorize(ctx, "token", models.RoleEditor)
 	})
 }
 
@@ -81,17 +143,269 @@ func TestEmptyKeyGenerateJwt(t *testing.T) {
 	service := AuthService{}
 
 	require.Panics(t, func() {
-		service.GenerateJWT(userName, password)
+		service.GenerateJWT(ctx, userName, password)
 	})
 }
 
 func TestCreateUser(t *testing.T) {
 	user := &models.User{}
 	userRepository := mocks.NewIUserRepository(t)
-	userRepository.EXPECT().Create(userName, mock.AnythingOfType("string")).Return(user, nil)
+	userRepository.EXPECT().Create(mock.Anything, userName, mock.AnythingOfType("string")).Return(user, nil)
 
 	service := AuthService{UserRepository: userRepository}
-	createdUser, err := service.CreateUser(userName, password)
+	createdUser, err := service.CreateUser(ctx, userName, password)
 	require.Nil(t, err)
 	require.Equal(t, user, createdUser)
 }
+
+func edKey(t *testing.T, kid string, activatesAt time.Time) *jwtkeys.Key {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return jwtkeys.NewKey(kid, jwt.SigningMethodEdDSA, private, private.Public(), activatesAt, time.Time{})
+}
+
+func userRepositoryFor(t *testing.T) *mocks.IUserRepository {
+	userRepository := mocks.NewIUserRepository(t)
+	userRepository.EXPECT().Get(mock.Anything, userName).Return(&models.User{
+		Name:     userName,
+		Password: hashedPassword,
+		Role:     models.RoleAdmin,
+	}, nil)
+	return userRepository
+}
+
+func TestGenerateJWTSetsKid(t *testing.T) {
+	keyring := jwtkeys.NewKeyring(edKey(t, "ed-1", time.Now().Add(-time.Hour)))
+
+	service := AuthService{UserRepository: userRepositoryFor(t), TokenDenylist: notRevoked(t)}
+	service.SetKeyring(keyring)
+
+	token, err := service.GenerateJWT(ctx, userName, password)
+	require.NoError(t, err)
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+	require.NoError(t, err)
+	require.Equal(t, "ed-1", parsed.Header["kid"])
+	require.Equal(t, "EdDSA", parsed.Header["alg"])
+
+	require.NoError(t, service.Authorize(ctx, token, models.RoleEditor))
+}
+
+func TestAuthorizeAfterRotation(t *testing.T) {
+	old := edKey(t, "old", time.Now().Add(-time.Hour))
+
+	service := AuthService{UserRepository: userRepositoryFor(t), TokenDenylist: notRevoked(t)}
+	service.SetKeyring(jwtkeys.NewKeyring(old))
+
+	token, err := service.GenerateJWT(ctx, userName, password)
+	require.NoError(t, err)
+
+	// Rotate in a newer key, the old one keeps verifying
+	service.SetKeyring(jwtkeys.NewKeyring(old, edKey(t, "new", time.Now())))
+	require.NoError(t, service.Authorize(ctx, token, models.RoleEditor))
+}
+
+func TestAuthorizeUnknownKid(t *testing.T) {
+	service := AuthService{UserRepository: userRepositoryFor(t)}
+	service.SetKeyring(jwtkeys.NewKeyring(edKey(t, "gone", time.Now().Add(-time.Hour))))
+
+	token, err := service.GenerateJWT(ctx, userName, password)
+	require.NoError(t, err)
+
+	service.SetKeyring(jwtkeys.NewKeyring(edKey(t, "other", time.Now().Add(-time.Hour))))
+	require.ErrorIs(t, service.Authorize(ctx, token, models.RoleEditor), ce.ErrTokenInvalid)
+}
+
+func TestAuthorizeRejectsAlgorithmSwap(t *testing.T) {
+	ed := edKey(t, "ed", time.Now().Add(-time.Hour))
+	service := AuthService{}
+	service.SetKeyring(jwtkeys.NewKeyring(ed, jwtkeys.NewKey("hs", jwt.SigningMethodHS256,
+		[]byte(password), []byte(password), time.Time{}, time.Time{})))
+
+	// HS256 token claiming to be signed by the EdDSA key
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	})
+	token.Header["kid"] = "ed"
+	signed, err := token.SignedString([]byte(password))
+	require.NoError(t, err)
+
+	require.ErrorIs(t, service.Authorize(ctx, signed, models.RoleEditor), ce.ErrTokenInvalid)
+}
+
+func TestJWKS(t *testing.T) {
+	service := AuthService{}
+	service.SetKeyring(jwtkeys.NewKeyring(edKey(t, "ed", time.Now())))
+
+	jwks := service.JWKS(ctx)
+	require.Len(t, jwks.Keys, 1)
+	require.Equal(t, "ed", jwks.Keys[0].Kid)
+}
+
+func TestAuthorizeRevoked(t *testing.T) {
+	tokenDenylist := mocks.NewITokenDenylist(t)
+	tokenDenylist.EXPECT().IsRevoked(mock.AnythingOfType("string")).Return(true)
+
+	service := AuthService{UserRepository: userRepositoryFor(t), TokenDenylist: tokenDenylist}
+	service.SetJwtSigningKey(password)
+
+	token, err := service.GenerateJWT(ctx, userName, password)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, service.Authorize(ctx, token, models.RoleEditor), ce.ErrTokenInvalid)
+}
+
+func TestAuthorizeStaleTokenVersion(t *testing.T) {
+	userRepository := mocks.NewIUserRepository(t)
+	// Issued at version 0
+	userRepository.EXPECT().Get(mock.Anything, userName).Return(&models.User{
+		Name:     userName,
+		Password: hashedPassword,
+		Role:     models.RoleAdmin,
+	}, nil).Once()
+	// Sessions revoked since
+	userRepository.EXPECT().Get(mock.Anything, userName).Return(&models.User{
+		Name:         userName,
+		Password:     hashedPassword,
+		TokenVersion: 1,
+	}, nil).Once()
+
+	service := AuthService{UserRepository: userRepository, TokenDenylist: notRevoked(t)}
+	service.SetJwtSigningKey(password)
+
+	token, err := service.GenerateJWT(ctx, userName, password)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, service.Authorize(ctx, token, models.RoleEditor), ce.ErrTokenInvalid)
+}
+
+func TestAuthorizeRequiresClaims(t *testing.T) {
+	service := AuthService{}
+	service.SetJwtSigningKey(password)
+
+	// Signed by us but missing jti and username
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	})
+	signed, err := token.SignedString([]byte(password))
+	require.NoError(t, err)
+
+	require.ErrorIs(t, service.Authorize(ctx, signed, models.RoleEditor), ce.ErrTokenInvalid)
+}
+
+func TestLogout(t *testing.T) {
+	tokenDenylist := mocks.NewITokenDenylist(t)
+	tokenDenylist.EXPECT().IsRevoked(mock.AnythingOfType("string")).Return(false)
+	tokenDenylist.EXPECT().Revoke(mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+		RunAndReturn(func(_ context.Context, jti string, expiresAt time.Time) error {
+			require.Len(t, jti, 32)
+			require.WithinDuration(t, fiveMinuteExpiration(), expiresAt, 5*time.Second)
+			return nil
+		})
+
+	service := AuthService{UserRepository: userRepositoryFor(t), TokenDenylist: tokenDenylist}
+	service.SetJwtSigningKey(password)
+
+	token, err := service.GenerateJWT(ctx, userName, password)
+	require.NoError(t, err)
+
+	require.NoError(t, service.Logout(ctx, token))
+}
+
+func TestLogoutInvalidToken(t *testing.T) {
+	service := AuthService{TokenDenylist: mocks.NewITokenDenylist(t)}
+	service.SetJwtSigningKey(password)
+
+	require.Error(t, service.Logout(ctx, "token"))
+}
+
+func TestRevokeSessions(t *testing.T) {
+	userRepository := mocks.NewIUserRepository(t)
+	userRepository.EXPECT().IncrementTokenVersion(mock.Anything, userName).Return(ce.ErrRecordNotFound)
+
+	service := AuthService{UserRepository: userRepository}
+	require.ErrorIs(t, service.RevokeSessions(ctx, userName), ce.ErrRecordNotFound)
+}
+
+func TestChangePassword(t *testing.T) {
+	userRepository := mocks.NewIUserRepository(t)
+	userRepository.EXPECT().UpdatePassword(mock.Anything, userName, mock.AnythingOfType("string")).
+		RunAndReturn(func(_ context.Context, name string, hash string) error {
+			require.NotEqual(t, "newpassword", hash)
+			return nil
+		})
+
+	service := AuthService{UserRepository: userRepository}
+	require.NoError(t, service.ChangePassword(ctx, userName, "newpassword"))
+}
+
+func TestAuthorizeExpired(t *testing.T) {
+	service := AuthService{}
+	service.SetJwtSigningKey(password)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		Username: userName,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "abc",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+		},
+	})
+	token.Header["kid"] = jwtkeys.DefaultKeyID
+	signed, err := token.SignedString([]byte(password))
+	require.NoError(t, err)
+
+	require.ErrorIs(t, service.Authorize(ctx, signed, models.RoleEditor), ce.ErrTokenExpired)
+}
+
+func TestAuthorizeDeletedUser(t *testing.T) {
+	userRepository := mocks.NewIUserRepository(t)
+	userRepository.EXPECT().Get(mock.Anything, userName).Return(&models.User{
+		Name:     userName,
+		Password: hashedPassword,
+		Role:     models.RoleAdmin,
+	}, nil).Once()
+	userRepository.EXPECT().Get(mock.Anything, userName).Return(nil, ce.ErrRecordNotFound).Once()
+
+	service := AuthService{UserRepository: userRepository, TokenDenylist: notRevoked(t)}
+	service.SetJwtSigningKey(password)
+
+	token, err := service.GenerateJWT(ctx, userName, password)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, service.Authorize(ctx, token, models.RoleEditor), ce.ErrTokenInvalid)
+}
+
+func TestAuthorizeUserLookupFails(t *testing.T) {
+	expectedError := fmt.Errorf("database is locked")
+	userRepository := mocks.NewIUserRepository(t)
+	userRepository.EXPECT().Get(mock.Anything, userName).Return(&models.User{
+		Name:     userName,
+		Password: hashedPassword,
+		Role:     models.RoleAdmin,
+	}, nil).Once()
+	userRepository.EXPECT().Get(mock.Anything, userName).Return(nil, expectedError).Once()
+
+	service := AuthService{UserRepository: userRepository, TokenDenylist: notRevoked(t)}
+	service.SetJwtSigningKey(password)
+
+	token, err := service.GenerateJWT(ctx, userName, password)
+	require.NoError(t, err)
+
+	err = service.Authorize(ctx, token, models.RoleEditor)
+	require.ErrorIs(t, err, expectedError)
+	require.NotErrorIs(t, err, ce.ErrTokenInvalid)
+}
+
+func TestIssueJWTIncludesRole(t *testing.T) {
+	service := AuthService{}
+	service.SetJwtSigningKey(password)
+
+	token, err := service.IssueJWT(ctx, &models.User{Name: userName, Role: models.RoleEditor})
+	require.NoError(t, err)
+
+	claims := &Claims{}
+	_, _, err = jwt.NewParser().ParseUnverified(token, claims)
+	require.NoError(t, err)
+	require.Equal(t, userName, claims.Username)
+	require.Equal(t, models.RoleEditor, claims.Role)
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/infrastructures/logutil"
+	"github.com/apkatsikas/artist-entities/interfaces"
+	"github.com/apkatsikas/artist-entities/models"
+)
+
+const (
+	DatabaseCheck = "database"
+	SchemaCheck   = "schema"
+	StorageCheck  = "storage"
+	BackupCheck   = "backup"
+)
+
+// HealthService checks whether we're ready to serve requests
+type HealthService struct {
+	DbHandler     interfaces.IDbHandler
+	Migrators     []interfaces.IMigrator
+	StorageClient interfaces.IStorageClient
+	// MaxBackupAge is how old the newest backup can get, 0 to not check backups
+	MaxBackupAge time.Duration
+	// CheckTimeout bounds each check
+	CheckTimeout time.Duration
+	// CacheFor reuses the checks' results, so probes can't hammer the database and storage
+	CacheFor time.Duration
+	// Started gives a new deployment MaxBackupAge to take its first backup
+	Started   time.Time
+	BuildInfo models.BuildInfo
+
+	mu       sync.Mutex
+	cached   models.Readiness
+	cachedAt time.Time
+	// newestBackup is the newest backup seen, storage is only listed once it's too old
+	newestBackup time.Time
+}
+
+// Ready runs every check, even once one has failed, so they're all reported
+// Results are reused for CacheFor, failed checks are logged with their errors
+func (hs *HealthService) Ready(ctx context.Context) models.Readiness {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if !hs.cachedAt.IsZero() && time.Since(hs.cachedAt) < hs.CacheFor {
+		return hs.cached
+	}
+
+	// The result is shared, so the caller going away mustn't fail it
+	ctx = context.WithoutCancel(ctx)
+	readiness := models.Readiness{}
+	readiness.Checks = append(readiness.Checks,
+		hs.check(ctx, DatabaseCheck, hs.DbHandler.Ping),
+		hs.check(ctx, SchemaCheck, func(context.Context) error {
+			return hs.checkMigrated()
+		}),
+		hs.check(ctx, StorageCheck, hs.StorageClient.Ping),
+	)
+	if hs.MaxBackupAge > 0 {
+		readiness.Checks = append(readiness.Checks, hs.check(ctx, BackupCheck, hs.checkBackupAge))
+	}
+	hs.cached, hs.cachedAt = readiness, time.Now()
+	return readiness
+}
+
+func (hs *HealthService) Version() models.BuildInfo {
+	return hs.BuildInfo
+}
+
+func (hs *HealthService) check(ctx context.Context, name string, run func(ctx context.Context) error) models.HealthCheck {
+	if hs.CheckTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, hs.CheckTimeout)
+		defer cancel()
+	}
+	start := time.Now()
+	check := models.HealthCheck{Name: name}
+	err := run(ctx)
+	check.Duration = time.Since(start)
+	if err != nil {
+		check.Error = err.Error()
+		logutil.FromContext(ctx).Warn("Readiness check failed", "check", name, "error", err)
+	}
+	return check
+}
+
+func (hs *HealthService) checkMigrated() error {
+	for _, migrator := range hs.Migrators {
+		err := migrator.CheckMigrated()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkBackupAge lists storage only when the newest backup seen is too old,
+// as a newer one can only have been taken since
+func (hs *HealthService) checkBackupAge(ctx context.Context) error {
+	if time.Since(hs.newestBackup) <= hs.MaxBackupAge {
+		return nil
+	}
+	// Not had the chance to backup yet
+	if time.Since(hs.Started) <= hs.MaxBackupAge {
+		return nil
+	}
+
+	files, err := hs.StorageClient.ListFiles(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list backups: %w", err)
+	}
+	for _, file := range files {
+		if models.IsBackup(file.Name) && file.Updated.After(hs.newestBackup) {
+			hs.newestBackup = file.Updated
+		}
+	}
+	newest := hs.newestBackup
+	if time.Since(newest) <= hs.MaxBackupAge {
+		return nil
+	}
+	if newest.IsZero() {
+		return fmt.Errorf("no backup in the last %v", hs.MaxBackupAge)
+	}
+	return fmt.Errorf("last backup was %v ago, over %v",
+		time.Since(newest).Round(time.Second), hs.MaxBackupAge)
+}
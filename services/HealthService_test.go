@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/apkatsikas/artist-entities/interfaces"
+	"github.com/apkatsikas/artist-entities/interfaces/mocks"
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const maxBackupAge = 26 * time.Hour
+
+func backupFile(age time.Duration) models.BackupFile {
+	return models.BackupFile{Name: models.BackupPrefix + "-1.db", Updated: time.Now().Add(-age)}
+}
+
+func failed(readiness models.Readiness) map[string]string {
+	errs := map[string]string{}
+	for _, check := range readiness.Checks {
+		if !check.Passed() {
+			errs[check.Name] = check.Error
+		}
+	}
+	return errs
+}
+
+func TestReady(t *testing.T) {
+	dbErr := errors.New("database is locked")
+	var testData = []struct {
+		test        string
+		pingErr     error
+		migratedErr error
+		storageErr  error
+		files       []models.BackupFile
+		listErr     error
+		started     time.Duration
+		failed      []string
+	}{
+		{test: "all passing", files: []models.BackupFile{backupFile(time.Hour)}, started: 48 * time.Hour},
+		{test: "database down", pingErr: dbErr, files: []models.BackupFile{backupFile(time.Hour)},
+			started: 48 * time.Hour, failed: []string{DatabaseCheck}},
+		{test: "not migrated", migratedErr: errors.New("missing table users"),
+			files: []models.BackupFile{backupFile(time.Hour)}, started: 48 * time.Hour, failed: []string{SchemaCheck}},
+		{test: "storage down", storageErr: errors.New("forbidden"), files: []models.BackupFile{backupFile(time.Hour)},
+			started: 48 * time.Hour, failed: []string{StorageCheck}},
+		{test: "listing fails", listErr: errors.New("forbidden"), started: 48 * time.Hour,
+			failed: []string{BackupCheck}},
+		{test: "old backup", files: []models.BackupFile{backupFile(30 * time.Hour)}, started: 48 * time.Hour,
+			failed: []string{BackupCheck}},
+		{test: "no backups", started: 48 * time.Hour, failed: []string{BackupCheck}},
+		{test: "manifests don't count", files: []models.BackupFile{
+			{Name: models.BackupPrefix + "-1.db" + models.ManifestSuffix, Updated: time.Now()}},
+			started: 48 * time.Hour, failed: []string{BackupCheck}},
+		{test: "recently started", started: time.Hour},
+	}
+	for _, tt := range testData {
+		t.Run(tt.test, func(t *testing.T) {
+			dbHandler := mocks.NewIDbHandler(t)
+			dbHandler.EXPECT().Ping(mock.Anything).Return(tt.pingErr)
+			migrator := mocks.NewIMigrator(t)
+			migrator.EXPECT().CheckMigrated().Return(tt.migratedErr)
+			storageClient := mocks.NewIStorageClient(t)
+			storageClient.EXPECT().Ping(mock.Anything).Return(tt.storageErr)
+			// Not listed while there's time to take the first backup
+			storageClient.EXPECT().ListFiles(mock.Anything).Return(tt.files, tt.listErr).Maybe()
+			service := HealthService{
+				DbHandler:     dbHandler,
+				Migrators:     []interfaces.IMigrator{migrator},
+				StorageClient: storageClient,
+				MaxBackupAge:  maxBackupAge,
+				CheckTimeout:  time.Second,
+				Started:       time.Now().Add(-tt.started),
+			}
+
+			readiness := service.Ready(context.Background())
+
+			require.Len(t, readiness.Checks, 4)
+			require.Equal(t, len(tt.failed) == 0, readiness.Ready())
+			errs := failed(readiness)
+			require.Len(t, errs, len(tt.failed))
+			for _, name := range tt.failed {
+				require.Contains(t, errs, name)
+			}
+		})
+	}
+}
+
+func TestReadyWithoutBackupCheck(t *testing.T) {
+	dbHandler := mocks.NewIDbHandler(t)
+	dbHandler.EXPECT().Ping(mock.Anything).Return(nil)
+	storageClient := mocks.NewIStorageClient(t)
+	storageClient.EXPECT().Ping(mock.Anything).Return(nil)
+	service := HealthService{DbHandler: dbHandler, StorageClient: storageClient}
+
+	readiness := service.Ready(context.Background())
+
+	require.True(t, readiness.Ready())
+	require.Len(t, readiness.Checks, 3)
+}
+
+func TestReadyCheckTimeout(t *testing.T) {
+	dbHandler := mocks.NewIDbHandler(t)
+	dbHandler.EXPECT().Ping(mock.Anything).RunAndReturn(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	storageClient := mocks.NewIStorageClient(t)
+	storageClient.EXPECT().Ping(mock.Anything).Return(nil)
+	service := HealthService{DbHandler: dbHandler, StorageClient: storageClient, CheckTimeout: 10 * time.Millisecond}
+
+	readiness := service.Ready(context.Background())
+
+	require.Equal(t, map[string]string{DatabaseCheck: context.DeadlineExceeded.Error()}, failed(readiness))
+}
+
+func TestReadyCached(t *testing.T) {
+	// Checked once, however many probes there are
+	dbHandler := mocks.NewIDbHandler(t)
+	dbHandler.EXPECT().Ping(mock.Anything).Return(errors.New("database is locked")).Once()
+	storageClient := mocks.NewIStorageClient(t)
+	storageClient.EXPECT().Ping(mock.Anything).Return(nil).Once()
+	service := HealthService{DbHandler: dbHandler, StorageClient: storageClient, CacheFor: time.Minute}
+
+	first := service.Ready(context.Background())
+	second := service.Ready(context.Background())
+
+	require.False(t, first.Ready())
+	require.Equal(t, first, second)
+}
+
+func TestReadyListsOnlyWhenBackupIsOld(t *testing.T) {
+	dbHandler := mocks.NewIDbHandler(t)
+	dbHandler.EXPECT().Ping(mock.Anything).Return(nil)
+	storageClient := mocks.NewIStorageClient(t)
+	storageClient.EXPECT().Ping(mock.Anything).Return(nil)
+	// The backup found is recent enough for the next check
+	storageClient.EXPECT().ListFiles(mock.Anything).Return([]models.BackupFile{backupFile(time.Hour)}, nil).Once()
+	service := HealthService{
+		DbHandler:     dbHandler,
+		StorageClient: storageClient,
+		MaxBackupAge:  maxBackupAge,
+		Started:       time.Now().Add(-48 * time.Hour),
+	}
+
+	for range 2 {
+		readiness := service.Ready(context.Background())
+		require.True(t, readiness.Ready())
+	}
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	ce "github.com/apkatsikas/artist-entities/customerrors"
+	"github.com/apkatsikas/artist-entities/interfaces"
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// loginTimeout is how long a user has to finish logging in with the provider
+const loginTimeout = 10 * time.Minute
+
+// oidcPassword is stored for users created from an identity
+// It isn't a bcrypt hash so password logins always fail
+const oidcPassword = "!"
+
+type pendingLogin struct {
+	nonce     string
+	verifier  string
+	expiresAt time.Time
+}
+
+type identityClaims struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+// OIDCService logs users in with an OpenID Connect provider
+// using the authorization code flow with PKCE
+type OIDCService struct {
+	AuthService    interfaces.IAuthService
+	UserRepository interfaces.IUserRepository
+
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// RoleMap gives the role for each verified email allowed to log in
+	RoleMap map[string]string
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+	pending  map[string]pendingLogin
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// ParseRoleMap reads a comma separated list of email=role pairs
+func ParseRoleMap(roleMap string) (map[string]string, error) {
+	roles := map[string]string{}
+	for _, pair := range strings.Split(roleMap, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		email, role, found := strings.Cut(pair, "=")
+		email = strings.ToLower(strings.TrimSpace(email))
+		role = strings.TrimSpace(role)
+		if !found || email == "" {
+			return nil, fmt.Errorf("%w: role mapping %q", ce.ErrDataInvalid, pair)
+		}
+		if role != models.RoleAdmin && role != models.RoleEditor {
+			return nil, fmt.Errorf("%w: unknown role %q for %v", ce.ErrDataInvalid, role, email)
+		}
+		roles[email] = role
+	}
+	return roles, nil
+}
+
+// config discovers the provider the first time it's needed
+func (s *OIDCService) config(ctx context.Context) (*oidc.Provider, *oauth2.Config, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.provider == nil {
+		provider, err := oidc.NewProvider(ctx, s.IssuerURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		s.provider = provider
+	}
+
+	return s.provider, &oauth2.Config{
+		ClientID:     s.ClientID,
+		ClientSecret: s.ClientSecret,
+		RedirectURL:  s.RedirectURL,
+		Endpoint:     s.provider.Endpoint(),
+		Scopes:       []string{oidc.ScopeOpenID, "email"},
+	}, nil
+}
+
+// AuthCodeURL starts a login, returning where to send the user
+func (s *OIDCService) AuthCodeURL(ctx context.Context) (string, error) {
+	_, config, err := s.config(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	state, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	s.mu.Lock()
+	now := time.Now()
+	if s.pending == nil {
+		s.pending = map[string]pendingLogin{}
+	}
+	for key, login := range s.pending {
+		if now.After(login.expiresAt) {
+			delete(s.pending, key)
+		}
+	}
+	s.pending[state] = pendingLogin{nonce: nonce, verifier: verifier, expiresAt: now.Add(loginTimeout)}
+	s.mu.Unlock()
+
+	return config.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// takePending returns the login for the state, which can only be used once
+func (s *OIDCService) takePending(state string) (pendingLogin, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	login, ok := s.pending[state]
+	delete(s.pending, state)
+	if !ok || time.Now().After(login.expiresAt) {
+		return pendingLogin{}, false
+	}
+	return login, true
+}
+
+// Exchange finishes a login, returning our own JWT for the user
+// Errors are ErrLoginStateInvalid, ErrTokenInvalid or ErrIdentityNotAllowed,
+// or something unexpected if the provider or database couldn't be reached
+func (s *OIDCService) Exchange(ctx context.Context, state string, code string) (string, error) {
+	login, ok := s.takePending(state)
+	if !ok {
+		return "", ce.ErrLoginStateInvalid
+	}
+
+	provider, config, err := s.config(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := config.Exchange(ctx, code, oauth2.VerifierOption(login.verifier))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ce.ErrTokenInvalid, err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return "", fmt.Errorf("%w: no id_token in token response", ce.ErrTokenInvalid)
+	}
+
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: s.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ce.ErrTokenInvalid, err)
+	}
+	if idToken.Nonce != login.nonce {
+		return "", fmt.Errorf("%w: nonce mismatch", ce.ErrTokenInvalid)
+	}
+
+	var claims identityClaims
+	err = idToken.Claims(&claims)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ce.ErrTokenInvalid, err)
+	}
+
+	user, err := s.userFor(ctx, idToken.Issuer, idToken.Subject, claims)
+	if err != nil {
+		return "", err
+	}
+
+	return s.AuthService.IssueJWT(ctx, user)
+}
+
+// userFor maps an identity to a local user
+// Only verified emails in the role map can log in, each time with the role they're mapped to
+// Identities already linked keep their user, otherwise the email is linked
+// to the user with that name, creating it if needed
+func (s *OIDCService) userFor(ctx context.Context, issuer string, subject string, claims identityClaims) (*models.User, error) {
+	email := strings.ToLower(claims.Email)
+	role, ok := s.RoleMap[email]
+	if !claims.EmailVerified || !ok {
+		return nil, ce.ErrIdentityNotAllowed
+	}
+
+	user, err := s.UserRepository.GetByIdentity(ctx, issuer, subject)
+	if errors.Is(err, ce.ErrRecordNotFound) {
+		user, err = s.UserRepository.CreateWithIdentity(ctx, email, oidcPassword, role, issuer, subject)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Promoted or demoted since they last logged in,
+	// or an existing user was linked who had another role
+	if user.Role != role {
+		err = s.UserRepository.UpdateRole(ctx, user.Name, role)
+		if err != nil {
+			return nil, err
+		}
+		user.Role = role
+	}
+	return user, nil
+}
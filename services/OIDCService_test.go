@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	ce "github.com/apkatsikas/artist-entities/customerrors"
+	"github.com/apkatsikas/artist-entities/infrastructures/oidctest"
+	"github.com/apkatsikas/artist-entities/interfaces/mocks"
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	clientID     = "artist-entities"
+	clientSecret = "client-secret"
+	redirectURL  = "http://localhost:8080/login/oidc/callback"
+	adminEmail   = "admin@example.com"
+)
+
+func oidcServiceFor(issuer *oidctest.Issuer,
+	userRepository *mocks.IUserRepository, authService *mocks.IAuthService) *OIDCService {
+	return &OIDCService{
+		AuthService:    authService,
+		UserRepository: userRepository,
+		IssuerURL:      issuer.URL(),
+		ClientID:       clientID,
+		ClientSecret:   clientSecret,
+		RedirectURL:    redirectURL,
+		RoleMap:        map[string]string{adminEmail: models.RoleAdmin},
+	}
+}
+
+// authorize follows the login URL to the issuer and returns the callback's state and code
+func authorize(t *testing.T, service *OIDCService) (string, string) {
+	loginURL, err := service.AuthCodeURL(context.Background())
+	require.NoError(t, err)
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	res, err := client.Get(loginURL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusFound, res.StatusCode)
+
+	callback, err := url.Parse(res.Header.Get("Location"))
+	require.NoError(t, err)
+	return callback.Query().Get("state"), callback.Query().Get("code")
+}
+
+func TestOIDCLoginLinkedIdentity(t *testing.T) {
+	tests := []struct {
+		name     string
+		userRole string
+	}{
+		{name: "same role", userRole: models.RoleAdmin},
+		{name: "role changed in the map", userRole: models.RoleEditor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := oidctest.NewIssuer(clientID, clientSecret)
+			defer issuer.Close()
+
+			user := &models.User{Name: "someone", Role: tt.userRole}
+			userRepository := mocks.NewIUserRepository(t)
+			userRepository.EXPECT().GetByIdentity(mock.Anything, issuer.URL(), issuer.Identity.Subject).Return(user, nil)
+			if tt.userRole != models.RoleAdmin {
+				userRepository.EXPECT().UpdateRole(mock.Anything, "someone", models.RoleAdmin).Return(nil)
+			}
+			authService := mocks.NewIAuthService(t)
+			authService.EXPECT().IssueJWT(mock.Anything, mock.MatchedBy(func(u *models.User) bool {
+				return u.Name == "someone" && u.Role == models.RoleAdmin
+			})).Return("token", nil)
+
+			service := oidcServiceFor(issuer, userRepository, authService)
+			state, code := authorize(t, service)
+
+			token, err := service.Exchange(context.Background(), state, code)
+			require.NoError(t, err)
+			require.Equal(t, "token", token)
+		})
+	}
+}
+
+func TestOIDCLoginLinkedIdentityRemovedFromMap(t *testing.T) {
+	issuer := oidctest.NewIssuer(clientID, clientSecret)
+	defer issuer.Close()
+
+	// The linked user isn't even looked up
+	service := oidcServiceFor(issuer, mocks.NewIUserRepository(t), mocks.NewIAuthService(t))
+	service.RoleMap = map[string]string{}
+	state, code := authorize(t, service)
+
+	_, err := service.Exchange(context.Background(), state, code)
+	require.ErrorIs(t, err, ce.ErrIdentityNotAllowed)
+}
+
+func TestOIDCLoginProvisionsMappedEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		userRole string
+	}{
+		{name: "new user", userRole: models.RoleAdmin},
+		// Linking keeps a local user's role, the map's is applied after
+		{name: "existing user with another role", userRole: models.RoleEditor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := oidctest.NewIssuer(clientID, clientSecret)
+			defer issuer.Close()
+			issuer.Identity.Email = "Admin@Example.com"
+
+			user := &models.User{Name: adminEmail, Role: tt.userRole}
+			userRepository := mocks.NewIUserRepository(t)
+			userRepository.EXPECT().GetByIdentity(mock.Anything, issuer.URL(), issuer.Identity.Subject).Return(nil, ce.ErrRecordNotFound)
+			userRepository.EXPECT().CreateWithIdentity(mock.Anything, adminEmail, oidcPassword, models.RoleAdmin,
+				issuer.URL(), issuer.Identity.Subject).Return(user, nil)
+			if tt.userRole != models.RoleAdmin {
+				userRepository.EXPECT().UpdateRole(mock.Anything, adminEmail, models.RoleAdmin).Return(nil)
+			}
+			authService := mocks.NewIAuthService(t)
+			authService.EXPECT().IssueJWT(mock.Anything, mock.MatchedBy(func(u *models.User) bool {
+				return u.Name == adminEmail && u.Role == models.RoleAdmin
+			})).Return("token", nil)
+
+			service := oidcServiceFor(issuer, userRepository, authService)
+			state, code := authorize(t, service)
+
+			token, err := service.Exchange(context.Background(), state, code)
+			require.NoError(t, err)
+			require.Equal(t, "token", token)
+		})
+	}
+}
+
+func TestOIDCLoginNotAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity oidctest.Identity
+	}{
+		{name: "unmapped email", identity: oidctest.Identity{
+			Subject: "1", Email: "someone@example.com", EmailVerified: true}},
+		{name: "unverified email", identity: oidctest.Identity{
+			Subject: "2", Email: adminEmail, EmailVerified: false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := oidctest.NewIssuer(clientID, clientSecret)
+			defer issuer.Close()
+			issuer.Identity = tt.identity
+
+			service := oidcServiceFor(issuer, mocks.NewIUserRepository(t), mocks.NewIAuthService(t))
+			state, code := authorize(t, service)
+
+			_, err := service.Exchange(context.Background(), state, code)
+			require.ErrorIs(t, err, ce.ErrIdentityNotAllowed)
+		})
+	}
+}
+
+func TestOIDCLoginInvalidIDToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(issuer *oidctest.Issuer)
+	}{
+		{name: "wrong audience", setup: func(issuer *oidctest.Issuer) { issuer.Audience = "someone-else" }},
+		{name: "wrong nonce", setup: func(issuer *oidctest.Issuer) { issuer.Nonce = "replayed" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := oidctest.NewIssuer(clientID, clientSecret)
+			defer issuer.Close()
+			tt.setup(issuer)
+
+			service := oidcServiceFor(issuer, mocks.NewIUserRepository(t), mocks.NewIAuthService(t))
+			state, code := authorize(t, service)
+
+			_, err := service.Exchange(context.Background(), state, code)
+			require.ErrorIs(t, err, ce.ErrTokenInvalid)
+		})
+	}
+}
+
+func TestOIDCLoginWrongVerifier(t *testing.T) {
+	issuer := oidctest.NewIssuer(clientID, clientSecret)
+	defer issuer.Close()
+
+	service := oidcServiceFor(issuer, mocks.NewIUserRepository(t), mocks.NewIAuthService(t))
+	state, code := authorize(t, service)
+	otherState, _ := authorize(t, service)
+
+	// Swap the verifier for one from another login
+	service.pending[state] = service.pending[otherState]
+
+	_, err := service.Exchange(context.Background(), state, code)
+	require.ErrorIs(t, err, ce.ErrTokenInvalid)
+}
+
+func TestOIDCLoginStateUsedOnce(t *testing.T) {
+	issuer := oidctest.NewIssuer(clientID, clientSecret)
+	defer issuer.Close()
+
+	user := &models.User{Name: adminEmail, Role: models.RoleAdmin}
+	userRepository := mocks.NewIUserRepository(t)
+	userRepository.EXPECT().GetByIdentity(mock.Anything, issuer.URL(), issuer.Identity.Subject).Return(user, nil)
+	authService := mocks.NewIAuthService(t)
+	authService.EXPECT().IssueJWT(mock.Anything, user).Return("token", nil)
+
+	service := oidcServiceFor(issuer, userRepository, authService)
+	state, code := authorize(t, service)
+
+	_, err := service.Exchange(context.Background(), state, code)
+	require.NoError(t, err)
+
+	_, err = service.Exchange(context.Background(), state, code)
+	require.ErrorIs(t, err, ce.ErrLoginStateInvalid)
+
+	_, err = service.Exchange(context.Background(), "unknown", code)
+	require.ErrorIs(t, err, ce.ErrLoginStateInvalid)
+}
+
+func TestParseRoleMap(t *testing.T) {
+	roles, err := ParseRoleMap(" Admin@Example.com=admin, editor@example.com=editor ,")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		adminEmail:           models.RoleAdmin,
+		"editor@example.com": models.RoleEditor,
+	}, roles)
+
+	_, err = ParseRoleMap("admin@example.com=owner")
+	require.ErrorIs(t, err, ce.ErrDataInvalid)
+
+	_, err = ParseRoleMap("admin@example.com")
+	require.ErrorIs(t, err, ce.ErrDataInvalid)
+}
@@ -1,27 +1,81 @@
 package rules
 
 import (
+    "fmt"
     "sort"
 
-    "github.com/apkatsikas/artist-entities/storageclient"
+    "github.com/apkatsikas/artist-entities/models"
 )
 
+// Retention is how many backups to keep
+// Daily, Weekly and Monthly keep the newest backup from each of that many
+// most recent days, weeks and months that have one
+type Retention struct {
+    Last    int
+    Daily   int
+    Weekly  int
+    Monthly int
+}
+
+// DefaultRetention keeps a week of dailies, a month of weeklies and half a year of monthlies
+var DefaultRetention = Retention{Last: 2, Daily: 7, Weekly: 4, Monthly: 6}
+
 type AdminRules struct {
+    Retention Retention
+}
+
+// keepPeriods keeps the newest file in each of the first count periods
+// files must be sorted newest first
+func keepPeriods(files []models.BackupFile, count int, period func(f models.BackupFile) string,
+    keep map[string]bool) {
+    seen := map[string]bool{}
+    for _, f := range files {
+        if len(seen) >= count {
+            return
+        }
+        key := period(f)
+        if !seen[key] {
+            seen[key] = true
+            keep[f.Name] = true
+        }
+    }
 }
 
-const max = 2
+// FilesToDelete returns every backup the retention policy doesn't keep, oldest first
+// Only backups are considered, not manifests or other objects, and the newest is always kept
+func (ar *AdminRules) FilesToDelete(files []models.BackupFile) []string {
+    var backups []models.BackupFile
+    for _, f := range files {
+        if models.IsBackup(f.Name) {
+            backups = append(backups, f)
+        }
+    }
+
+    // Sort by updated date, newest first
+    sort.SliceStable(backups, func(i, j int) bool {
+        return backups[i].Updated.After(backups[j].Updated)
+    })
 
-func (ar *AdminRules) FileToDelete(files []storageclient.BackupFile) string {
-    if len(files) > max {
-        // Sort by updated date, oldest first
-        sort.Slice(files[:], func(i, j int) bool {
-            return files[i].Updated.Before(files[j].Updated)
-        })
+    keep := map[string]bool{}
+    keepPeriods(backups, max(ar.Retention.Last, 1), func(f models.BackupFile) string {
+        return f.Name
+    }, keep)
+    keepPeriods(backups, ar.Retention.Daily, func(f models.BackupFile) string {
+        return f.Updated.UTC().Format("2006-01-02")
+    }, keep)
+    keepPeriods(backups, ar.Retention.Weekly, func(f models.BackupFile) string {
+        year, week := f.Updated.UTC().ISOWeek()
+        return fmt.Sprintf("%v-%v", year, week)
+    }, keep)
+    keepPeriods(backups, ar.Retention.Monthly, func(f models.BackupFile) string {
+        return f.Updated.UTC().Format("2006-01")
+    }, keep)
 
-        // Return oldest entry's name
-        return files[0].Name
+    var toDelete []string
+    for i := len(backups) - 1; i >= 0; i-- {
+        if !keep[backups[i].Name] {
+            toDelete = append(toDelete, backups[i].Name)
+        }
     }
-    // Return blank if less files than the max
-    // Nothing to delete
-    return ""
+    return toDelete
 }
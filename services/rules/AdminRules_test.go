@@ -1,80 +1,141 @@
 package rules
 
 import (
+    "fmt"
     "testing"
     "time"
 
-    sc "github.com/apkatsikas/artist-entities/storageclient"
+    "github.com/apkatsikas/artist-entities/models"
     "github.com/stretchr/testify/assert"
 )
 
+// now is a Wednesday
+var now = time.Date(2026, 10, 14, 2, 0, 0, 0, time.UTC)
+
+func backup(daysAgo int) models.BackupFile {
+    updated := now.AddDate(0, 0, -daysAgo)
+    return models.BackupFile{Name: fmt.Sprintf("%v%v.sqlite", models.BackupPrefix, updated.Unix()), Updated: updated}
+}
+
+// nightly returns a backup for each of the last days, newest first
+func nightly(days int) []models.BackupFile {
+    var files []models.BackupFile
+    for i := 0; i < days; i++ {
+        files = append(files, backup(i))
+    }
+    return files
+}
+
+func names(files ...models.BackupFile) []string {
+    var result []string
+    for _, f := range files {
+        result = append(result, f.Name)
+    }
+    return result
+}
+
 func TestAdminRules(t *testing.T) {
     var testData = []struct {
-        files        []sc.BackupFile
-        fileToDelete string
-        test         string
+        files         []models.BackupFile
+        retention     Retention
+        filesToDelete []string
+        test          string
     }{
         {
-            test:         "no files - nothing to delete",
-            files:        []sc.BackupFile{},
-            fileToDelete: "",
+            test:          "no files - nothing to delete",
+            files:         []models.BackupFile{},
+            retention:     Retention{Last: 2},
+            filesToDelete: nil,
         },
         {
-            test:         "1 file - nothing to delete",
-            files:        []sc.BackupFile{{Name: "a", Updated: time.Now()}},
-            fileToDelete: "",
+            test:          "2 files - nothing to delete",
+            files:         nightly(2),
+            retention:     Retention{Last: 2},
+            filesToDelete: nil,
         },
         {
-            test: "2 files - nothing to delete",
-            files: []sc.BackupFile{
-                {Name: "a", Updated: time.Now()},
-                {Name: "b", Updated: time.Now()},
-            },
-            fileToDelete: "",
+            test:          "3 files - delete oldest",
+            files:         []models.BackupFile{backup(1), backup(2), backup(0)},
+            retention:     Retention{Last: 2},
+            filesToDelete: names(backup(2)),
         },
         {
-            test: "3 files - delete oldest - middle",
-            files: []sc.BackupFile{
-                {Name: "a", Updated: time.Now()},
-                {Name: "b", Updated: time.Now().AddDate(0, -2, 0)},
-                {Name: "c", Updated: time.Now().AddDate(0, -1, 0)},
-            },
-            fileToDelete: "b",
+            test:          "5 files - delete every excess file, oldest first",
+            files:         []models.BackupFile{backup(3), backup(0), backup(4), backup(1), backup(2)},
+            retention:     Retention{Last: 2},
+            filesToDelete: names(backup(4), backup(3), backup(2)),
+        },
+        {
+            test:          "nothing retained - newest is still kept",
+            files:         nightly(3),
+            retention:     Retention{},
+            filesToDelete: names(backup(2), backup(1)),
         },
         {
-            test: "3 files - delete oldest - first",
-            files: []sc.BackupFile{
-                {Name: "a", Updated: time.Now().AddDate(0, -2, 0)},
-                {Name: "b", Updated: time.Now()},
-                {Name: "c", Updated: time.Now().AddDate(0, -1, 0)},
+            test: "other objects are ignored",
+            files: []models.BackupFile{
+                backup(0), backup(1), backup(2),
+                {Name: "notes.txt", Updated: now.AddDate(-1, 0, 0)},
+                {Name: "vacuum.sqlite", Updated: now.AddDate(-1, 0, 0)},
             },
-            fileToDelete: "a",
+            retention:     Retention{Last: 2},
+            filesToDelete: names(backup(2)),
         },
         {
-            test: "3 files - delete oldest - last",
-            files: []sc.BackupFile{
-                {Name: "a", Updated: time.Now().AddDate(0, -1, 0)},
-                {Name: "b", Updated: time.Now()},
-                {Name: "c", Updated: time.Now().AddDate(0, -2, 0)},
+            test: "daily - keeps the newest backup of each day",
+            files: []models.BackupFile{
+                backup(0), backup(1), backup(2), backup(3),
+                {Name: models.BackupPrefix + "-1-extra.sqlite", Updated: now.AddDate(0, 0, -1).Add(-time.Hour)},
             },
-            fileToDelete: "c",
+            retention:     Retention{Last: 1, Daily: 3},
+            filesToDelete: names(backup(3), models.BackupFile{Name: models.BackupPrefix + "-1-extra.sqlite"}),
         },
         {
-            test: "4 files - delete oldest - last",
-            files: []sc.BackupFile{
-                {Name: "a", Updated: time.Now().AddDate(0, -1, 0)},
-                {Name: "b", Updated: time.Now()},
-                {Name: "c", Updated: time.Now().AddDate(0, -2, 0)},
-                {Name: "d", Updated: time.Now().AddDate(0, -3, 0)},
+            test:      "weekly - keeps the newest backup of each week",
+            files:     nightly(15),
+            retention: Retention{Last: 1, Weekly: 3},
+            // Newest of this week (Wed), last week (Sun) and the week before (Sun)
+            filesToDelete: names(backup(14), backup(13), backup(12), backup(11), backup(9),
+                backup(8), backup(7), backup(6), backup(5), backup(4), backup(2), backup(1)),
+        },
+        {
+            test: "monthly - keeps the newest backup of each month",
+            files: []models.BackupFile{
+                backup(0), backup(10), backup(20), backup(40), backup(70), backup(100),
             },
-            fileToDelete: "d",
+            retention: Retention{Last: 1, Monthly: 3},
+            // October, September (20 days ago) and August (70 days ago)
+            filesToDelete: names(backup(100), backup(40), backup(10)),
+        },
+        {
+            test:      "grandfather-father-son",
+            files:     nightly(400),
+            retention: DefaultRetention,
+            filesToDelete: func() []string {
+                keep := map[int]bool{}
+                // Last 2 and 7 dailies
+                for i := 0; i < 7; i++ {
+                    keep[i] = true
+                }
+                // Sundays ending the last 3 weeks
+                keep[3], keep[10], keep[17] = true, true, true
+                // The last day of each of the last 5 months
+                keep[14], keep[44], keep[75], keep[106], keep[136] = true, true, true, true, true
+                var expected []string
+                for i := 399; i >= 0; i-- {
+                    if !keep[i] {
+                        expected = append(expected, backup(i).Name)
+                    }
+                }
+                return expected
+            }(),
         },
     }
     for _, tt := range testData {
         t.Run(tt.test, func(t *testing.T) {
-            rules := AdminRules{}
-            result := rules.FileToDelete(tt.files)
-            assert.Equal(t, result, tt.fileToDelete)
+            rules := AdminRules{Retention: tt.retention}
+            result := rules.FilesToDelete(tt.files)
+            assert.Equal(t, tt.filesToDelete, result)
         })
     }
 }
@@ -0,0 +1,34 @@
+package rules
+
+import "time"
+
+type LoginRules struct {
+}
+
+const (
+	freeLoginAttempts = 3
+	baseLoginDelay    = time.Second
+	lockoutThreshold  = 10
+	lockoutDuration   = 15 * time.Minute
+	failureMemory     = time.Hour
+)
+
+// Delay returns how long to wait after the latest failed login
+// before another attempt is allowed
+func (lr *LoginRules) Delay(failures uint) time.Duration {
+	// Lock out entirely once we cross the threshold
+	if failures >= lockoutThreshold {
+		return lockoutDuration
+	}
+	// Allow a few typos before slowing anyone down
+	if failures < freeLoginAttempts {
+		return 0
+	}
+	// Double the delay for every failure past the free ones
+	return baseLoginDelay << (failures - freeLoginAttempts)
+}
+
+// Expired returns true if failures are old enough to be forgotten
+func (lr *LoginRules) Expired(sinceLastFailure time.Duration) bool {
+	return sinceLastFailure > lockoutDuration+failureMemory
+}
@@ -0,0 +1,37 @@
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginRulesDelay(t *testing.T) {
+	var testData = []struct {
+		failures uint
+		delay    time.Duration
+		test     string
+	}{
+		{test: "no failures - no delay", failures: 0, delay: 0},
+		{test: "2 failures - no delay", failures: 2, delay: 0},
+		{test: "3 failures - base delay", failures: 3, delay: time.Second},
+		{test: "4 failures - doubled", failures: 4, delay: 2 * time.Second},
+		{test: "9 failures - last backoff", failures: 9, delay: 64 * time.Second},
+		{test: "10 failures - locked out", failures: 10, delay: lockoutDuration},
+		{test: "50 failures - still locked out", failures: 50, delay: lockoutDuration},
+	}
+	for _, tt := range testData {
+		t.Run(tt.test, func(t *testing.T) {
+			rules := LoginRules{}
+			assert.Equal(t, tt.delay, rules.Delay(tt.failures))
+		})
+	}
+}
+
+func TestLoginRulesExpired(t *testing.T) {
+	rules := LoginRules{}
+	assert.False(t, rules.Expired(time.Minute))
+	assert.False(t, rules.Expired(lockoutDuration))
+	assert.True(t, rules.Expired(lockoutDuration+failureMemory+time.Second))
+}
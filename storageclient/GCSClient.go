@@ -0,0 +1,150 @@
+package storageclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/apkatsikas/artist-entities/models"
+	"google.golang.org/api/iterator"
+)
+
+const credsEnvVar = "GOOGLE_APPLICATION_CREDENTIALS"
+
+// GCSClient stores backups in a Google Cloud Storage bucket
+type GCSClient struct {
+	client     *storage.Client
+	projectID  string
+	bucketName string
+	options    Options
+}
+
+func NewGCS(config GCSConfig, options Options) (*GCSClient, error) {
+	os.Setenv(credsEnvVar, config.CredsFile)
+
+	client, err := storage.NewClient(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCS client: %v", err)
+	}
+
+	return &GCSClient{
+		client:     client,
+		bucketName: config.Bucket,
+		projectID:  config.ProjectID,
+		options:    options,
+	}, nil
+}
+
+func (sc *GCSClient) DeleteFile(ctx context.Context, object string) error {
+	ctx, cancel := context.WithTimeout(ctx, sc.options.Timeout)
+	defer cancel()
+
+	objForDeletion := sc.client.Bucket(sc.bucketName).Object(object)
+
+	// Optional: set a generation-match precondition to avoid potential race
+	// conditions and data corruptions. The request to delete the file is aborted
+	// if the object's generation number does not match your precondition.
+	attrs, err := objForDeletion.Attrs(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting object attributes: %v", err)
+	}
+	objForDeletion = objForDeletion.If(storage.Conditions{GenerationMatch: attrs.Generation})
+
+	if err := objForDeletion.Delete(ctx); err != nil {
+		return fmt.Errorf("error during delete: %v", err)
+	}
+	return nil
+}
+
+// ListFiles lists files
+// Returns value because we need to sort later
+func (sc *GCSClient) ListFiles(ctx context.Context) ([]models.BackupFile, error) {
+	ctx, cancel := context.WithTimeout(ctx, sc.options.Timeout)
+	defer cancel()
+
+	it := sc.client.Bucket(sc.bucketName).Objects(ctx, nil)
+
+	var files []models.BackupFile
+
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		file := models.BackupFile{Name: attrs.Name, Updated: attrs.Updated, Size: attrs.Size}
+
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+// Ping checks the bucket exists and we can access it
+func (sc *GCSClient) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, sc.options.Timeout)
+	defer cancel()
+
+	_, err := sc.client.Bucket(sc.bucketName).Attrs(ctx)
+	return err
+}
+
+// DownloadFile downloads an object to destPath
+func (sc *GCSClient) DownloadFile(ctx context.Context, object string, destPath string) error {
+	ctx, cancel := context.WithTimeout(ctx, sc.options.TransferTimeout)
+	defer cancel()
+
+	rc, err := sc.client.Bucket(sc.bucketName).Object(object).NewReader(ctx)
+	if err != nil {
+		return fmt.Errorf("error during download: %v", err)
+	}
+	defer rc.Close()
+
+	return saveTo(ctx, destPath, rc)
+}
+
+// UploadFile uploads an object
+// Large files are uploaded in resumable chunks, a failed chunk is retried
+// rather than starting again
+func (sc *GCSClient) UploadFile(ctx context.Context, path string, destObject string) error {
+	blobFile, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer blobFile.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, sc.options.TransferTimeout)
+	defer cancel()
+
+	// Upload an object with storage.Writer.
+	obj := sc.client.Bucket(sc.bucketName).Object(destObject)
+
+	// Optional: set a generation-match precondition to avoid potential race
+	// conditions and data corruptions. The request to upload is aborted if the
+	// object's generation number does not match your precondition.
+	// For an object that does not yet exist, set the DoesNotExist precondition.
+	obj = obj.If(storage.Conditions{DoesNotExist: true})
+
+	// The precondition makes retrying the upload safe
+	obj = obj.Retryer(storage.WithPolicy(storage.RetryAlways))
+
+	// Upload an object with storage.Writer.
+	wc := obj.NewWriter(ctx)
+	wc.ChunkSize = int(sc.options.ChunkSize)
+	wc.ChunkRetryDeadline = sc.options.Timeout * time.Duration(sc.options.Retries+1)
+
+	if _, err := io.Copy(wc, blobFile); err != nil {
+		return fmt.Errorf("error on Copy to bucket %v", err)
+	}
+	if err := wc.Close(); err != nil {
+		return fmt.Errorf("error on Close during bucket upload: %v", err)
+	}
+
+	return nil
+}
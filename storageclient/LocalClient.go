@@ -0,0 +1,164 @@
+package storageclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/apkatsikas/artist-entities/models"
+)
+
+// LocalClient stores backups in a directory, useful for development
+// or with a mounted volume
+type LocalClient struct {
+	dir string
+}
+
+func NewLocal(dir string) (*LocalClient, error) {
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %v", err)
+	}
+	return &LocalClient{dir: dir}, nil
+}
+
+// path returns where an object lives, objects can't leave the directory
+func (lc *LocalClient) path(object string) (string, error) {
+	if object == "" || object != filepath.Base(object) || object == "." || object == ".." {
+		return "", fmt.Errorf("invalid object name %q", object)
+	}
+	return filepath.Join(lc.dir, object), nil
+}
+
+func (lc *LocalClient) DeleteFile(ctx context.Context, object string) error {
+	path, err := lc.path(object)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("error during delete: %v", err)
+	}
+	return nil
+}
+
+// ListFiles lists files
+func (lc *LocalClient) ListFiles(ctx context.Context) ([]models.BackupFile, error) {
+	entries, err := os.ReadDir(lc.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []models.BackupFile
+
+	for _, entry := range entries {
+		// Skip directories and uploads still in progress
+		if !entry.Type().IsRegular() || filepath.Ext(entry.Name()) == ".tmp" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, models.BackupFile{Name: entry.Name(), Updated: info.ModTime(), Size: info.Size()})
+	}
+
+	return files, nil
+}
+
+// Ping checks the directory is still there
+func (lc *LocalClient) Ping(ctx context.Context) error {
+	info, err := os.Stat(lc.dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%v is not a directory", lc.dir)
+	}
+	return nil
+}
+
+// DownloadFile copies an object out to destPath
+func (lc *LocalClient) DownloadFile(ctx context.Context, object string, destPath string) error {
+	path, err := lc.path(object)
+	if err != nil {
+		return err
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	return saveTo(ctx, destPath, src)
+}
+
+// UploadFile copies a file in, failing if the object already exists
+// Stops if ctx is done
+func (lc *LocalClient) UploadFile(ctx context.Context, path string, destObject string) error {
+	dest, err := lc.path(destObject)
+	if err != nil {
+		return err
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	// Write to a temporary file so a partial copy is never listed
+	tmp, err := os.CreateTemp(lc.dir, destObject+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, &contextReader{ctx: ctx, r: src}); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error on Copy to backup directory %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	// Link rather than rename so an existing object is never replaced
+	err = link(tmp.Name(), dest)
+	if errors.Is(err, syscall.EPERM) || errors.Is(err, errors.ErrUnsupported) {
+		// Some network and FUSE filesystems can't link, so claim the name
+		// first, then rename over our own placeholder
+		err = placeAt(tmp.Name(), dest)
+	}
+	if err != nil {
+		return fmt.Errorf("error on upload to backup directory: %v", err)
+	}
+	return nil
+}
+
+// link is replaced in tests, to act like a filesystem without hard links
+var link = os.Link
+
+// placeAt moves tmp to dest, failing if dest exists
+func placeAt(tmp string, dest string) error {
+	placeholder, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	placeholder.Close()
+
+	err = os.Rename(tmp, dest)
+	if err != nil {
+		os.Remove(dest)
+	}
+	return err
+}
@@ -0,0 +1,140 @@
+package storageclient
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var ctx = context.Background()
+
+func writeFile(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "vacuum.sqlite")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func TestLocalClient(t *testing.T) {
+	client, err := NewLocal(filepath.Join(t.TempDir(), "backups"))
+	require.NoError(t, err)
+	require.NoError(t, client.Ping(ctx))
+
+	require.NoError(t, client.UploadFile(ctx, writeFile(t, "first"), "entities-backup1.sqlite"))
+	require.NoError(t, client.UploadFile(ctx, writeFile(t, "second"), "entities-backup2.sqlite"))
+
+	files, err := client.ListFiles(ctx)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	require.Equal(t, "entities-backup1.sqlite", files[0].Name)
+	require.False(t, files[0].Updated.IsZero())
+
+	contents, err := os.ReadFile(filepath.Join(client.dir, "entities-backup2.sqlite"))
+	require.NoError(t, err)
+	require.Equal(t, "second", string(contents))
+
+	require.NoError(t, client.DeleteFile(ctx, "entities-backup1.sqlite"))
+	files, err = client.ListFiles(ctx)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, "entities-backup2.sqlite", files[0].Name)
+
+	// Unmounted
+	require.NoError(t, os.RemoveAll(client.dir))
+	require.Error(t, client.Ping(ctx))
+}
+
+func TestLocalClientNoOverwrite(t *testing.T) {
+	client, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, client.UploadFile(ctx, writeFile(t, "first"), "backup.sqlite"))
+	require.Error(t, client.UploadFile(ctx, writeFile(t, "second"), "backup.sqlite"))
+
+	contents, err := os.ReadFile(filepath.Join(client.dir, "backup.sqlite"))
+	require.NoError(t, err)
+	require.Equal(t, "first", string(contents))
+
+	// The failed upload leaves nothing behind
+	files, err := client.ListFiles(ctx)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+}
+
+func TestLocalClientWithoutHardLinks(t *testing.T) {
+	linkFn := link
+	link = func(oldname string, newname string) error {
+		return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: syscall.ENOTSUP}
+	}
+	t.Cleanup(func() { link = linkFn })
+	client, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, client.UploadFile(ctx, writeFile(t, "first"), "backup.sqlite"))
+	require.Error(t, client.UploadFile(ctx, writeFile(t, "second"), "backup.sqlite"))
+
+	contents, err := os.ReadFile(filepath.Join(client.dir, "backup.sqlite"))
+	require.NoError(t, err)
+	require.Equal(t, "first", string(contents))
+	files, err := client.ListFiles(ctx)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+}
+
+func TestLocalClientRejectsPaths(t *testing.T) {
+	client, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	for _, object := range []string{"", ".", "..", "../escape.sqlite", "nested/backup.sqlite"} {
+		require.Error(t, client.UploadFile(ctx, writeFile(t, "x"), object), object)
+		require.Error(t, client.DeleteFile(ctx, object), object)
+	}
+}
+
+func TestNew(t *testing.T) {
+	client, err := New(Config{Dir: t.TempDir()})
+	require.NoError(t, err)
+	require.IsType(t, &LocalClient{}, client)
+
+	_, err = New(Config{Backend: "ftp"})
+	require.Error(t, err)
+}
+
+func TestResolvedBackend(t *testing.T) {
+	require.Equal(t, BackendLocal, DefaultConfig.ResolvedBackend())
+	require.Equal(t, BackendGCS, Config{GCS: GCSConfig{Bucket: "backups"}}.ResolvedBackend())
+	require.Equal(t, BackendS3, Config{Backend: BackendS3, GCS: GCSConfig{Bucket: "backups"}}.ResolvedBackend())
+}
+
+func TestLocalClientDownload(t *testing.T) {
+	client, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, client.UploadFile(ctx, writeFile(t, "backup"), "backup.sqlite"))
+
+	dest := filepath.Join(t.TempDir(), "restore.sqlite")
+	require.NoError(t, client.DownloadFile(ctx, "backup.sqlite", dest))
+
+	contents, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	require.Equal(t, "backup", string(contents))
+
+	require.Error(t, client.DownloadFile(ctx, "missing.sqlite", dest))
+	require.Error(t, client.DownloadFile(ctx, "../backup.sqlite", dest))
+}
+
+func TestLocalClientCancelled(t *testing.T) {
+	client, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	err = client.UploadFile(cancelled, writeFile(t, "backup"), "backup.sqlite")
+
+	require.ErrorIs(t, err, context.Canceled)
+	files, err := client.ListFiles(ctx)
+	require.NoError(t, err)
+	require.Empty(t, files)
+}
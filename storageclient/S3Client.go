@@ -0,0 +1,224 @@
+package storageclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/apkatsikas/artist-entities/models"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3Client stores backups in an S3 bucket, or any S3-compatible service
+// such as MinIO when an endpoint is set
+type S3Client struct {
+	client     *s3.Client
+	bucketName string
+	options    Options
+}
+
+func NewS3(s3Config S3Config, options Options) (*S3Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), options.Timeout)
+	defer cancel()
+
+	// Credentials and region come from the usual AWS_* variables and files
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load S3 config: %v", err)
+	}
+
+	endpoint := s3Config.Endpoint
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			// Most S3-compatible services don't support virtual hosted buckets
+			o.UsePathStyle = true
+		}
+	})
+
+	return &S3Client{client: client, bucketName: s3Config.Bucket, options: options}, nil
+}
+
+func (sc *S3Client) DeleteFile(ctx context.Context, object string) error {
+	ctx, cancel := context.WithTimeout(ctx, sc.options.Timeout)
+	defer cancel()
+
+	_, err := sc.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(sc.bucketName),
+		Key:    aws.String(object),
+	})
+	if err != nil {
+		return fmt.Errorf("error during delete: %v", err)
+	}
+	return nil
+}
+
+// ListFiles lists files
+func (sc *S3Client) ListFiles(ctx context.Context) ([]models.BackupFile, error) {
+	ctx, cancel := context.WithTimeout(ctx, sc.options.Timeout)
+	defer cancel()
+
+	paginator := s3.NewListObjectsV2Paginator(sc.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(sc.bucketName),
+	})
+
+	var files []models.BackupFile
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			files = append(files, models.BackupFile{
+				Name:    aws.ToString(object.Key),
+				Updated: aws.ToTime(object.LastModified),
+				Size:    aws.ToInt64(object.Size),
+			})
+		}
+	}
+
+	return files, nil
+}
+
+// Ping checks the bucket exists and we can access it
+func (sc *S3Client) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, sc.options.Timeout)
+	defer cancel()
+
+	_, err := sc.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(sc.bucketName)})
+	return err
+}
+
+// DownloadFile downloads an object to destPath
+func (sc *S3Client) DownloadFile(ctx context.Context, object string, destPath string) error {
+	ctx, cancel := context.WithTimeout(ctx, sc.options.TransferTimeout)
+	defer cancel()
+
+	out, err := sc.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(sc.bucketName),
+		Key:    aws.String(object),
+	})
+	if err != nil {
+		return fmt.Errorf("error during download: %v", err)
+	}
+	defer out.Body.Close()
+
+	return saveTo(ctx, destPath, out.Body)
+}
+
+// UploadFile uploads an object, failing if it already exists
+// Files larger than a chunk are uploaded in parts, a failed part is retried
+// rather than starting again
+func (sc *S3Client) UploadFile(ctx context.Context, path string, destObject string) error {
+	blobFile, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer blobFile.Close()
+	info, err := blobFile.Stat()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sc.options.TransferTimeout)
+	defer cancel()
+
+	if info.Size() > sc.options.ChunkSize {
+		return sc.uploadParts(ctx, blobFile, info.Size(), destObject)
+	}
+
+	err = retry(ctx, sc.options.Retries, func() error {
+		ctx, cancel := context.WithTimeout(ctx, sc.options.Timeout)
+		defer cancel()
+
+		_, err := sc.client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(sc.bucketName),
+			Key:    aws.String(destObject),
+			Body:   io.NewSectionReader(blobFile, 0, info.Size()),
+			// Never replace an existing object, like the other backends
+			IfNoneMatch: aws.String("*"),
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error on upload to bucket: %w", err)
+	}
+	return nil
+}
+
+// uploadParts does a multipart upload, aborting it on failure so the parts
+// aren't left behind
+func (sc *S3Client) uploadParts(ctx context.Context, file io.ReaderAt, size int64, destObject string) error {
+	created, err := sc.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(sc.bucketName),
+		Key:    aws.String(destObject),
+	})
+	if err != nil {
+		return fmt.Errorf("error starting upload to bucket: %w", err)
+	}
+
+	err = sc.uploadPartsTo(ctx, file, size, destObject, created.UploadId)
+	if err != nil {
+		// Abort even if ctx is done
+		abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sc.options.Timeout)
+		defer cancel()
+		sc.client.AbortMultipartUpload(abortCtx, &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(sc.bucketName),
+			Key:      aws.String(destObject),
+			UploadId: created.UploadId,
+		})
+		return fmt.Errorf("error on upload to bucket: %w", err)
+	}
+	return nil
+}
+
+func (sc *S3Client) uploadPartsTo(ctx context.Context, file io.ReaderAt, size int64, destObject string,
+	uploadID *string) error {
+	var parts []types.CompletedPart
+	for offset, number := int64(0), int32(1); offset < size; offset, number = offset+sc.options.ChunkSize, number+1 {
+		length := min(sc.options.ChunkSize, size-offset)
+
+		var etag *string
+		err := retry(ctx, sc.options.Retries, func() error {
+			ctx, cancel := context.WithTimeout(ctx, sc.options.Timeout)
+			defer cancel()
+
+			out, err := sc.client.UploadPart(ctx, &s3.UploadPartInput{
+				Bucket:        aws.String(sc.bucketName),
+				Key:           aws.String(destObject),
+				UploadId:      uploadID,
+				PartNumber:    aws.Int32(number),
+				Body:          io.NewSectionReader(file, offset, length),
+				ContentLength: aws.Int64(length),
+			})
+			if err != nil {
+				return err
+			}
+			etag = out.ETag
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("part %v: %w", number, err)
+		}
+		parts = append(parts, types.CompletedPart{ETag: etag, PartNumber: aws.Int32(number)})
+	}
+
+	return retry(ctx, sc.options.Retries, func() error {
+		ctx, cancel := context.WithTimeout(ctx, sc.options.Timeout)
+		defer cancel()
+
+		_, err := sc.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(sc.bucketName),
+			Key:             aws.String(destObject),
+			UploadId:        uploadID,
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+			IfNoneMatch:     aws.String("*"),
+		})
+		return err
+	})
+}
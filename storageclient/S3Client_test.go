@@ -0,0 +1,252 @@
+package storageclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 implements just enough of the S3 API for a path style bucket
+type fakeS3 struct {
+	*httptest.Server
+	bucket string
+
+	mu      sync.Mutex
+	objects map[string]string
+	// uploads are multipart uploads in progress, by ID then part number
+	uploads map[string]map[int]string
+	// failures is how many times to fail each part number, -1 fails always
+	failures map[int]int
+	// partRequests counts requests for each part number
+	partRequests map[int]int
+	aborted      int
+}
+
+func newFakeS3(t *testing.T, bucket string) *fakeS3 {
+	f := &fakeS3{
+		bucket:       bucket,
+		objects:      map[string]string{},
+		uploads:      map[string]map[int]string{},
+		failures:     map[int]int{},
+		partRequests: map[int]int{},
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		key, inBucket := strings.CutPrefix(req.URL.Path, "/"+bucket)
+		key = strings.TrimPrefix(key, "/")
+		if !inBucket {
+			http.NotFound(res, req)
+			return
+		}
+		query := req.URL.Query()
+		uploadID := query.Get("uploadId")
+		completing := req.Method == http.MethodPost && uploadID != ""
+		putting := req.Method == http.MethodPut && uploadID == "" && key != ""
+		if _, exists := f.objects[key]; exists && (completing || putting) && req.Header.Get("If-None-Match") == "*" {
+			res.WriteHeader(http.StatusPreconditionFailed)
+			fmt.Fprint(res, `<Error><Code>PreconditionFailed</Code></Error>`)
+			return
+		}
+
+		switch {
+		case req.Method == http.MethodPost && query.Has("uploads"):
+			uploadID = fmt.Sprint(len(f.uploads) + 1)
+			f.uploads[uploadID] = map[int]string{}
+			fmt.Fprintf(res, `<InitiateMultipartUploadResult><Bucket>%v</Bucket><Key>%v</Key>`+
+				`<UploadId>%v</UploadId></InitiateMultipartUploadResult>`, bucket, key, uploadID)
+		case req.Method == http.MethodPut && uploadID != "":
+			number, _ := strconv.Atoi(query.Get("partNumber"))
+			f.partRequests[number]++
+			if f.failures[number] != 0 {
+				f.failures[number]--
+				res.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprint(res, `<Error><Code>InternalError</Code></Error>`)
+				return
+			}
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			f.uploads[uploadID][number] = string(body)
+			res.Header().Set("ETag", fmt.Sprintf(`"etag%v"`, number))
+		case req.Method == http.MethodPost && uploadID != "":
+			parts := f.uploads[uploadID]
+			numbers := []int{}
+			for number := range parts {
+				numbers = append(numbers, number)
+			}
+			sort.Ints(numbers)
+			var object strings.Builder
+			for _, number := range numbers {
+				object.WriteString(parts[number])
+			}
+			f.objects[key] = object.String()
+			delete(f.uploads, uploadID)
+			fmt.Fprintf(res, `<CompleteMultipartUploadResult><Key>%v</Key><ETag>"done"</ETag>`+
+				`</CompleteMultipartUploadResult>`, key)
+		case req.Method == http.MethodDelete && uploadID != "":
+			delete(f.uploads, uploadID)
+			f.aborted++
+			res.WriteHeader(http.StatusNoContent)
+		case req.Method == http.MethodPut && key != "":
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			f.objects[key] = string(body)
+		case req.Method == http.MethodGet && key != "":
+			body, found := f.objects[key]
+			if !found {
+				res.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(res, `<Error><Code>NoSuchKey</Code></Error>`)
+				return
+			}
+			fmt.Fprint(res, body)
+		case req.Method == http.MethodDelete && key != "":
+			delete(f.objects, key)
+			res.WriteHeader(http.StatusNoContent)
+		case req.Method == http.MethodHead && key == "":
+			res.WriteHeader(http.StatusOK)
+		case req.Method == http.MethodGet && key == "":
+			res.Header().Set("Content-Type", "application/xml")
+			fmt.Fprintf(res, `<ListBucketResult><Name>%v</Name><IsTruncated>false</IsTruncated>`, bucket)
+			for name := range f.objects {
+				fmt.Fprintf(res, `<Contents><Key>%v</Key><LastModified>2026-10-01T02:00:00.000Z</LastModified></Contents>`, name)
+			}
+			fmt.Fprint(res, `</ListBucketResult>`)
+		default:
+			http.Error(res, "unsupported", http.StatusMethodNotAllowed)
+		}
+	}))
+	return f
+}
+
+// s3Client returns a client for server with small chunks and no SDK retries
+func s3Client(t *testing.T, server *fakeS3, options Options) *S3Client {
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+	t.Setenv("AWS_MAX_ATTEMPTS", "1")
+
+	backoff := retryBackoff
+	retryBackoff = time.Millisecond
+	t.Cleanup(func() { retryBackoff = backoff })
+
+	client, err := NewS3(S3Config{Bucket: server.bucket, Endpoint: server.URL}, options)
+	require.NoError(t, err)
+	return client
+}
+
+func TestS3Client(t *testing.T) {
+	server := newFakeS3(t, "backups")
+	defer server.Close()
+	client := s3Client(t, server, DefaultOptions)
+	require.NoError(t, client.Ping(ctx))
+
+	require.NoError(t, client.UploadFile(ctx, writeFile(t, "backup"), "entities-backup1.sqlite"))
+
+	files, err := client.ListFiles(ctx)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, "entities-backup1.sqlite", files[0].Name)
+	require.Equal(t, 2026, files[0].Updated.Year())
+
+	dest := filepath.Join(t.TempDir(), "restore.sqlite")
+	require.NoError(t, client.DownloadFile(ctx, "entities-backup1.sqlite", dest))
+	contents, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	require.Equal(t, "backup", string(contents))
+	require.Error(t, client.DownloadFile(ctx, "missing.sqlite", dest))
+
+	require.NoError(t, client.DeleteFile(ctx, "entities-backup1.sqlite"))
+	files, err = client.ListFiles(ctx)
+	require.NoError(t, err)
+	require.Empty(t, files)
+}
+
+func chunkedOptions() Options {
+	options := DefaultOptions
+	options.ChunkSize = 4
+	options.Retries = 2
+	return options
+}
+
+func TestS3ClientUploadsInParts(t *testing.T) {
+	server := newFakeS3(t, "backups")
+	defer server.Close()
+	client := s3Client(t, server, chunkedOptions())
+	// Part 2 fails twice, then succeeds
+	server.failures[2] = 2
+
+	require.NoError(t, client.UploadFile(ctx, writeFile(t, "aaaabbbbcc"), "entities-backup1.sqlite"))
+
+	require.Equal(t, "aaaabbbbcc", server.objects["entities-backup1.sqlite"])
+	// Only the failed part was sent again
+	require.Equal(t, map[int]int{1: 1, 2: 3, 3: 1}, server.partRequests)
+}
+
+func TestS3ClientAbortsFailedUpload(t *testing.T) {
+	server := newFakeS3(t, "backups")
+	defer server.Close()
+	client := s3Client(t, server, chunkedOptions())
+	server.failures[2] = -1
+
+	err := client.UploadFile(ctx, writeFile(t, "aaaabbbbcc"), "entities-backup1.sqlite")
+
+	require.ErrorContains(t, err, "part 2")
+	require.Equal(t, 3, server.partRequests[2])
+	// Part 3 is never sent
+	require.Zero(t, server.partRequests[3])
+	require.Equal(t, 1, server.aborted)
+	require.Empty(t, server.uploads)
+	require.Empty(t, server.objects)
+}
+
+func TestS3ClientNoOverwrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+	}{
+		{name: "single request", options: DefaultOptions},
+		{name: "in parts", options: chunkedOptions()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeS3(t, "backups")
+			defer server.Close()
+			client := s3Client(t, server, tt.options)
+			server.objects["entities-backup1.sqlite"] = "first"
+
+			err := client.UploadFile(ctx, writeFile(t, "aaaabbbbcc"), "entities-backup1.sqlite")
+
+			require.ErrorContains(t, err, "PreconditionFailed")
+			require.Equal(t, "first", server.objects["entities-backup1.sqlite"])
+		})
+	}
+}
+
+func TestS3ClientCancelled(t *testing.T) {
+	server := newFakeS3(t, "backups")
+	defer server.Close()
+	client := s3Client(t, server, chunkedOptions())
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	err := client.UploadFile(cancelled, writeFile(t, "aaaabbbbcc"), "entities-backup1.sqlite")
+
+	require.ErrorIs(t, err, context.Canceled)
+	require.Empty(t, server.objects)
+}
@@ -5,128 +5,144 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"time"
 
-	"cloud.google.com/go/storage"
-	"github.com/apkatsikas/artist-entities/infrastructures/logutil"
-	"google.golang.org/api/iterator"
+	"github.com/apkatsikas/artist-entities/interfaces"
 )
 
 const (
-	timeoutSeconds = 60
-	credsEnvVar    = "GOOGLE_APPLICATION_CREDENTIALS"
+	BackendLocal = "local"
+	BackendS3    = "s3"
+	BackendGCS   = "gcs"
+
+	// MinChunkSize is the smallest part S3 accepts in a multipart upload
+	MinChunkSize = 5 << 20
 )
 
-type StorageClient struct {
-	client     *storage.Client
-	projectID  string
-	bucketName string
+// DefaultOptions suit a database of a few GB over a slow link
+var DefaultOptions = Options{
+	Timeout:         time.Minute,
+	TransferTimeout: time.Hour,
+	ChunkSize:       16 << 20,
+	Retries:         3,
 }
 
-type BackupFile struct {
-	Name    string
-	Updated time.Time
+// DefaultConfig keeps backups in a local directory
+var DefaultConfig = Config{Dir: "backups", Options: DefaultOptions}
+
+// retryBackoff is the wait before retrying a chunk, it doubles after each
+var retryBackoff = time.Second
+
+// Options tune how backends talk to storage
+type Options struct {
+	// Timeout bounds listing and deleting, and each chunk of an upload
+	Timeout time.Duration `yaml:"timeout" env:"STORAGE_TIMEOUT" usage:"Timeout for listing, deleting and each uploaded chunk"`
+	// TransferTimeout bounds a whole upload or download
+	TransferTimeout time.Duration `yaml:"transferTimeout" env:"STORAGE_TRANSFER_TIMEOUT" usage:"Timeout for a whole upload or download"`
+	// ChunkSize is how much of a large file is uploaded per request
+	ChunkSize int64 `yaml:"chunkSize" env:"STORAGE_CHUNK_SIZE" usage:"Bytes uploaded per request for large files"`
+	// Retries is how many times a failed chunk is retried
+	Retries int `yaml:"retries" env:"STORAGE_RETRIES" usage:"Times a failed chunk is retried"`
 }
 
-func New() *StorageClient {
-	os.Setenv(credsEnvVar, os.Getenv("GCS_CREDS_FILE"))
-
-	client, err := storage.NewClient(context.Background())
-	if err != nil {
-		logutil.Fatal("Failed to create StorageClient: %v", err)
-	}
-
-	return &StorageClient{
-		client:     client,
-		bucketName: os.Getenv("GCS_BUCKET_NAME"),
-		projectID:  os.Getenv("GCS_PROJECT_ID"),
-	}
-
+// Config picks a backend and tunes it
+type Config struct {
+	// Backend is empty to use GCS if a bucket is configured, otherwise the
+	// local directory so the server runs without any cloud credentials
+	Backend string    `yaml:"backend" env:"BACKUP_STORAGE" usage:"Backup storage, local, s3 or gcs"`
+	Dir     string    `yaml:"dir" env:"BACKUP_DIR" usage:"Directory for local backup storage"`
+	GCS     GCSConfig `yaml:"gcs"`
+	S3      S3Config  `yaml:"s3"`
+	Options `yaml:",inline"`
 }
 
-func (sc *StorageClient) DeleteFile(object string) error {
-	ctx := context.Background()
-	ctx, cancel := context.WithTimeout(ctx, time.Second*timeoutSeconds)
-
-	defer cancel()
+type GCSConfig struct {
+	Bucket    string `yaml:"bucket" env:"GCS_BUCKET_NAME" usage:"GCS bucket for backups"`
+	ProjectID string `yaml:"projectID" env:"GCS_PROJECT_ID" usage:"GCS project"`
+	CredsFile string `yaml:"credsFile" env:"GCS_CREDS_FILE" usage:"GCS service account credentials file"`
+}
 
-	objForDeletion := sc.client.Bucket(sc.bucketName).Object(object)
+type S3Config struct {
+	Bucket string `yaml:"bucket" env:"S3_BUCKET_NAME" usage:"S3 bucket for backups"`
+	// Endpoint is set for S3-compatible services such as MinIO
+	Endpoint string `yaml:"endpoint" env:"S3_ENDPOINT" usage:"Endpoint of an S3-compatible service"`
+}
 
-	// Optional: set a generation-match precondition to avoid potential race
-	// conditions and data corruptions. The request to delete the file is aborted
-	// if the object's generation number does not match your precondition.
-	attrs, err := objForDeletion.Attrs(ctx)
-	if err != nil {
-		return fmt.Errorf("error getting object attributes: %v", err)
+// ResolvedBackend is the backend New uses
+func (c Config) ResolvedBackend() string {
+	if c.Backend != "" {
+		return c.Backend
 	}
-	objForDeletion = objForDeletion.If(storage.Conditions{GenerationMatch: attrs.Generation})
-
-	if err := objForDeletion.Delete(ctx); err != nil {
-		return fmt.Errorf("error during delete: %v", err)
+	if c.GCS.Bucket != "" {
+		return BackendGCS
 	}
-	return nil
+	return BackendLocal
 }
 
-// ListFiles lists files
-// Returns value because we need to sort later
-func (sc *StorageClient) ListFiles() ([]BackupFile, error) {
-	ctx := context.Background()
-
-	ctx, cancel := context.WithTimeout(ctx, time.Second*timeoutSeconds)
-	defer cancel()
-
-	it := sc.client.Bucket(sc.bucketName).Objects(ctx, nil)
-
-	var files []BackupFile
+// New returns the configured backend
+func New(config Config) (interfaces.IStorageClient, error) {
+	backend := config.ResolvedBackend()
+	switch backend {
+	case BackendLocal:
+		return NewLocal(config.Dir)
+	case BackendS3:
+		return NewS3(config.S3, config.Options)
+	case BackendGCS:
+		return NewGCS(config.GCS, config.Options)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q, expected %v, %v or %v",
+			backend, BackendLocal, BackendS3, BackendGCS)
+	}
+}
 
-	for {
-		attrs, err := it.Next()
-		if err == iterator.Done {
-			break
+// retry calls fn until it succeeds, the retries run out or ctx is done
+func retry(ctx context.Context, retries int, fn func() error) error {
+	backoff := retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= retries || ctx.Err() != nil {
+			return err
 		}
-		if err != nil {
-			return nil, err
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
 		}
+		backoff *= 2
+	}
+}
 
-		file := BackupFile{Name: attrs.Name, Updated: attrs.Updated}
+// contextReader stops reading once ctx is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
 
-		files = append(files, file)
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
 	}
-
-	return files, nil
+	return cr.r.Read(p)
 }
 
-// UploadFile uploads an object
-func (sc *StorageClient) UploadFile(path string, destObject string) error {
-	blobFile, err := os.Open(path)
+// saveTo writes a downloaded object to destPath
+// It's written to a temporary file first so destPath is never left partial
+func saveTo(ctx context.Context, destPath string, r io.Reader) error {
+	tmp, err := os.CreateTemp(filepath.Dir(destPath), filepath.Base(destPath)+".*.tmp")
 	if err != nil {
 		return err
 	}
-	defer blobFile.Close()
-
-	ctx := context.Background()
-
-	ctx, cancel := context.WithTimeout(ctx, time.Second*timeoutSeconds)
-	defer cancel()
-
-	// Upload an object with storage.Writer.
-	obj := sc.client.Bucket(sc.bucketName).Object(destObject)
+	defer os.Remove(tmp.Name())
 
-	// Optional: set a generation-match precondition to avoid potential race
-	// conditions and data corruptions. The request to upload is aborted if the
-	// object's generation number does not match your precondition.
-	// For an object that does not yet exist, set the DoesNotExist precondition.
-	obj = obj.If(storage.Conditions{DoesNotExist: true})
-
-	// Upload an object with storage.Writer.
-	wc := obj.NewWriter(ctx)
-
-	if _, err := io.Copy(wc, blobFile); err != nil {
-		return fmt.Errorf("error on Copy to bucket %v", err)
+	if _, err := io.Copy(tmp, &contextReader{ctx: ctx, r: r}); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error on Copy from storage %w", err)
 	}
-	if err := wc.Close(); err != nil {
-		return fmt.Errorf("error on Close during bucket upload: %v", err)
+	if err := tmp.Close(); err != nil {
+		return err
 	}
 
-	return nil
+	return os.Rename(tmp.Name(), destPath)
 }
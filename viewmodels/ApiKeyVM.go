@@ -0,0 +1,26 @@
+package viewmodels
+
+import "time"
+
+type ApiKeyVM struct {
+	ID         uint
+	Name       string
+	Prefix     string
+	Scopes     []string
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+type NewApiKeyVM struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+// CreatedApiKeyVM is the only time the key itself is returned
+type CreatedApiKeyVM struct {
+	ApiKeyVM
+	Key string
+}
@@ -0,0 +1,41 @@
+package viewmodels
+
+import "time"
+
+type RestoreVM struct {
+	Name string
+}
+
+// RestoredVM names the local copy of the database taken before restoring
+type RestoredVM struct {
+	SafetyCopy string
+}
+
+// BackupVM is a stored backup, details come from its manifest if it has one
+type BackupVM struct {
+	Name        string
+	Updated     time.Time
+	Size        int64
+	Status      string
+	Compression string `json:",omitempty"`
+	KeyID       string `json:",omitempty"`
+	Artists     *int64 `json:",omitempty"`
+	Users       *int64 `json:",omitempty"`
+}
+
+// BackupRunVM is the outcome of a backup, Error is empty if it succeeded
+type BackupRunVM struct {
+	Trigger    string
+	Object     string
+	StartedAt  time.Time
+	DurationMs int64
+	Size       int64
+	Succeeded  bool
+	Error      string `json:",omitempty"`
+}
+
+// BackupsVM is the stored backups and the last run, if there's been one
+type BackupsVM struct {
+	Backups []BackupVM
+	LastRun *BackupRunVM
+}
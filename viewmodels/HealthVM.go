@@ -0,0 +1,24 @@
+package viewmodels
+
+// HealthCheckVM is one readiness check, why it failed is only logged
+type HealthCheckVM struct {
+	Status     string
+	DurationMs int64
+}
+
+// ReadinessVM has every check by name
+type ReadinessVM struct {
+	Status string
+	Checks map[string]HealthCheckVM
+}
+
+type StatusVM struct {
+	Status string
+}
+
+type VersionVM struct {
+	Version   string
+	Commit    string
+	BuildTime string
+	GoVersion string
+}
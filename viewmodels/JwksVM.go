@@ -0,0 +1,19 @@
+package viewmodels
+
+// JwkVM is a public key in JSON Web Key format
+type JwkVM struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EdDSA
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JwksVM struct {
+	Keys []JwkVM `json:"keys"`
+}
@@ -0,0 +1,15 @@
+package viewmodels
+
+import "time"
+
+// ScheduledJobVM is a scheduled job, times are null if there isn't one
+type ScheduledJobVM struct {
+	Name           string
+	Schedule       string
+	Running        bool
+	LastRun        *time.Time
+	LastDurationMs int64
+	LastError      string `json:",omitempty"`
+	Skipped        int
+	NextRun        *time.Time
+}